@@ -4,10 +4,13 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -21,6 +24,10 @@ import (
 	identity_proto "github.com/michaeldcanady/go-onedrive/internal/features/plugins/proto/identity"
 )
 
+// version is reported through GetMetadata. Release builds set it with
+// -ldflags "-X main.version=<version>".
+var version = "dev"
+
 func mustState() string {
 	uuid, err := uuid.NewV7()
 	if err != nil {
@@ -34,8 +41,14 @@ func mustState() string {
 	return uuid.String()
 }
 
+// graphBaseURL is the Microsoft Graph endpoint the plugin calls.
+const graphBaseURL = "https://graph.microsoft.com/v1.0"
+
 type AzureIdentityPlugin struct {
 	identity_proto.UnimplementedIdentityPluginServer
+
+	// graphURL overrides graphBaseURL, for tests.
+	graphURL string
 }
 
 func (p *AzureIdentityPlugin) Login(stream identity_proto.IdentityPlugin_LoginServer) error {
@@ -77,12 +90,12 @@ func (p *AzureIdentityPlugin) Login(stream identity_proto.IdentityPlugin_LoginSe
 
 	var token *oauth2.Token
 	switch method {
-	case "device":
+	case "device", "device-code":
 		token, err = p.loginDevice(stream, tenant, clientID, scopes)
 	case "client-secret":
 		token, err = p.loginClientSecret(stream.Context(), tenant, clientID, clientSecret, scopes)
 	default:
-		token, err = p.loginInteractive(stream, tenant, clientID, clientSecret, redirectURI, scopes)
+		token, err = p.loginInteractive(stream, tenant, clientID, clientSecret, redirectURI, scopes, opts["headless"] == "true")
 	}
 
 	if err != nil {
@@ -109,7 +122,10 @@ func (p *AzureIdentityPlugin) Login(stream identity_proto.IdentityPlugin_LoginSe
 	})
 }
 
-func (p *AzureIdentityPlugin) loginInteractive(stream identity_proto.IdentityPlugin_LoginServer, tenant, clientID, secret, redirect string, scopes []string) (*oauth2.Token, error) {
+// loginInteractive runs the authorization code flow. The code normally arrives on a local
+// listener; when headless is set, or the redirect is not local, the browser may be on
+// another machine and the user pastes the redirect address back instead.
+func (p *AzureIdentityPlugin) loginInteractive(stream identity_proto.IdentityPlugin_LoginServer, tenant, clientID, secret, redirect string, scopes []string, headless bool) (*oauth2.Token, error) {
 	config := &oauth2.Config{ClientID: clientID, ClientSecret: secret, RedirectURL: redirect, Scopes: scopes, Endpoint: microsoft.AzureADEndpoint(tenant)}
 
 	useLocal := false
@@ -125,7 +141,7 @@ func (p *AzureIdentityPlugin) loginInteractive(stream identity_proto.IdentityPlu
 		}
 	}
 
-	if useLocal {
+	if useLocal && !headless {
 		l, err := net.Listen("tcp", listenAddr)
 		if err != nil {
 			// Fallback to random port if specified port is busy
@@ -173,11 +189,18 @@ func (p *AzureIdentityPlugin) loginInteractive(stream identity_proto.IdentityPlu
 		}
 	}
 
+	if redirect == "" {
+		redirect = "http://127.0.0.1"
+	}
 	config.RedirectURL = redirect
-	_ = p.interact(stream, &identity_proto.InteractionRequest{Action: &identity_proto.InteractionRequest_DisplayMessage{DisplayMessage: &identity_proto.DisplayMessageRequest{Message: "URL: " + config.AuthCodeURL(mustState(), oauth2.AccessTypeOffline)}}})
-	fmt.Fprint(os.Stderr, "Code: ")
-	var code string
-	if _, err := fmt.Scan(&code); err != nil {
+	state := mustState()
+	_ = p.interact(stream, &identity_proto.InteractionRequest{Action: &identity_proto.InteractionRequest_OpenUrl{OpenUrl: &identity_proto.OpenUrlRequest{Url: config.AuthCodeURL(state, oauth2.AccessTypeOffline)}}})
+	answer, err := p.prompt(stream, "After signing in, paste the address your browser was redirected to (or just the code):")
+	if err != nil {
+		return nil, err
+	}
+	code, err := authCode(answer, state)
+	if err != nil {
 		return nil, err
 	}
 	return config.Exchange(stream.Context(), code)
@@ -190,8 +213,13 @@ func (p *AzureIdentityPlugin) loginDevice(stream identity_proto.IdentityPlugin_L
 		return nil, err
 	}
 
-	msg := fmt.Sprintf("To sign in, use a web browser to open the page %s and enter the code %s to authenticate.", da.VerificationURI, da.UserCode)
+	msg := fmt.Sprintf("To sign in, open the page below in a web browser on any device and enter the code %s to authenticate.", da.UserCode)
 	_ = p.interact(stream, &identity_proto.InteractionRequest{Action: &identity_proto.InteractionRequest_DisplayMessage{DisplayMessage: &identity_proto.DisplayMessageRequest{Message: msg}}})
+	verify := da.VerificationURI
+	if da.VerificationURIComplete != "" {
+		verify = da.VerificationURIComplete
+	}
+	_ = p.interact(stream, &identity_proto.InteractionRequest{Action: &identity_proto.InteractionRequest_OpenUrl{OpenUrl: &identity_proto.OpenUrlRequest{Url: verify}}})
 
 	return config.DeviceAccessToken(stream.Context(), da)
 }
@@ -230,6 +258,48 @@ func (p *AzureIdentityPlugin) Refresh(ctx context.Context, req *identity_proto.R
 	}
 	return &identity_proto.RefreshResponse{Token: &identity_proto.AccessToken{AccessToken: t.AccessToken, RefreshToken: t.RefreshToken, ExpiresAt: t.Expiry.Unix()}}, nil
 }
+
+// Logout signs the user out at Microsoft when the revoke_sessions option is set.
+// Microsoft has no endpoint to revoke a single refresh token, so the only revocation
+// available is revokeSignInSessions, which ends every session of the account on every
+// device. Without the option nothing is revoked and Success is false.
+func (p *AzureIdentityPlugin) Logout(ctx context.Context, req *identity_proto.LogoutRequest) (*identity_proto.LogoutResponse, error) {
+	if req.Options["revoke_sessions"] != "true" {
+		return &identity_proto.LogoutResponse{Success: false}, nil
+	}
+
+	tenant := req.Options["tenant_id"]
+	if tenant == "" {
+		tenant = "common"
+	}
+	expiresAt, _ := strconv.ParseInt(req.Options["expires_at"], 10, 64)
+	c := &oauth2.Config{ClientID: req.Options["client_id"], ClientSecret: req.Options["client_secret"], Endpoint: microsoft.AzureADEndpoint(tenant)}
+	// The token source refreshes the access token first if it has expired.
+	client := c.Client(ctx, &oauth2.Token{
+		AccessToken:  req.Options["access_token"],
+		RefreshToken: req.Options["refresh_token"],
+		Expiry:       time.Unix(expiresAt, 0),
+	})
+
+	graphURL := p.graphURL
+	if graphURL == "" {
+		graphURL = graphBaseURL
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, graphURL+"/me/revokeSignInSessions", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return nil, fmt.Errorf("revokeSignInSessions failed with status %d", resp.StatusCode)
+	}
+	return &identity_proto.LogoutResponse{Success: true}, nil
+}
+
 func (p *AzureIdentityPlugin) interact(stream identity_proto.IdentityPlugin_LoginServer, req *identity_proto.InteractionRequest) error {
 	if err := stream.Send(&identity_proto.LoginResponse{Payload: &identity_proto.LoginResponse_InteractionRequest{InteractionRequest: req}}); err != nil {
 		return err
@@ -237,9 +307,53 @@ func (p *AzureIdentityPlugin) interact(stream identity_proto.IdentityPlugin_Logi
 	_, err := stream.Recv()
 	return err
 }
+
+// prompt asks the host for a line of input and returns the answer.
+func (p *AzureIdentityPlugin) prompt(stream identity_proto.IdentityPlugin_LoginServer, message string) (string, error) {
+	req := &identity_proto.InteractionRequest{Action: &identity_proto.InteractionRequest_Prompt{Prompt: &identity_proto.PromptRequest{Message: message}}}
+	if err := stream.Send(&identity_proto.LoginResponse{Payload: &identity_proto.LoginResponse_InteractionRequest{InteractionRequest: req}}); err != nil {
+		return "", err
+	}
+	m, err := stream.Recv()
+	if err != nil {
+		return "", err
+	}
+	resp := m.GetInteractionResponse()
+	if resp == nil {
+		return "", fmt.Errorf("expected interaction response")
+	}
+	if resp.Error != "" {
+		return "", errors.New(resp.Error)
+	}
+	return strings.TrimSpace(resp.Value), nil
+}
+
+// authCode extracts the authorization code from answer, which is either the code itself
+// or the address the browser was redirected to, whose state must match the request.
+func authCode(answer, state string) (string, error) {
+	if answer == "" {
+		return "", fmt.Errorf("no authorization code entered")
+	}
+	u, err := url.Parse(answer)
+	if err != nil || u.Scheme == "" {
+		return answer, nil
+	}
+	q := u.Query()
+	if e := q.Get("error"); e != "" {
+		return "", fmt.Errorf("authorization failed: %s: %s", e, q.Get("error_description"))
+	}
+	if q.Get("state") != state {
+		return "", fmt.Errorf("state mismatch in redirect address")
+	}
+	if q.Get("code") == "" {
+		return "", fmt.Errorf("redirect address has no code")
+	}
+	return q.Get("code"), nil
+}
 func (p *AzureIdentityPlugin) GetMetadata(ctx context.Context, req *identity_proto.MetadataRequest) (*identity_proto.MetadataResponse, error) {
 	return &identity_proto.MetadataResponse{
 		Name:               "azure",
+		Version:            version,
 		Type:               "identity",
 		SupportedProviders: []string{"azure"},
 	}, nil
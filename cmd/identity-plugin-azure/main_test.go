@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	identity_proto "github.com/michaeldcanady/go-onedrive/internal/features/plugins/proto/identity"
+)
+
+func TestLogout(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		assert.Equal(t, "/me/revokeSignInSessions", r.URL.Path)
+		assert.Equal(t, "Bearer access", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"value":true}`))
+	}))
+	t.Cleanup(srv.Close)
+	p := &AzureIdentityPlugin{graphURL: srv.URL}
+
+	options := map[string]string{
+		"access_token":  "access",
+		"refresh_token": "refresh",
+		"expires_at":    strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10),
+	}
+	resp, err := p.Logout(context.Background(), &identity_proto.LogoutRequest{IdentityId: "alice", Options: options})
+	require.NoError(t, err)
+	assert.False(t, resp.Success, "nothing is revoked without revoke_sessions")
+	assert.Zero(t, calls)
+
+	options["revoke_sessions"] = "true"
+	resp, err = p.Logout(context.Background(), &identity_proto.LogoutRequest{IdentityId: "alice", Options: options})
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.Equal(t, 1, calls)
+}
+
+func TestAuthCode(t *testing.T) {
+	tests := []struct {
+		name    string
+		answer  string
+		want    string
+		wantErr string
+	}{
+		{"bare code", "4/0AbC", "4/0AbC", ""},
+		{"redirect address", "http://127.0.0.1/?code=abc&state=s1", "abc", ""},
+		{"state mismatch", "http://127.0.0.1/?code=abc&state=other", "", "state mismatch"},
+		{"provider error", "http://127.0.0.1/?error=access_denied&error_description=denied&state=s1", "", "access_denied"},
+		{"empty", "", "", "no authorization code"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := authCode(tt.answer, "s1")
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, code)
+		})
+	}
+}
@@ -4,9 +4,11 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"strings"
 	"time"
@@ -20,6 +22,10 @@ import (
 	identity_proto "github.com/michaeldcanady/go-onedrive/internal/features/plugins/proto/identity"
 )
 
+// version is reported through GetMetadata. Release builds set it with
+// -ldflags "-X main.version=<version>".
+var version = "dev"
+
 func mustState() string {
 	uuid, err := uuid.NewV7()
 	if err != nil {
@@ -30,8 +36,14 @@ func mustState() string {
 	return uuid.String()
 }
 
+// googleRevokeURL is Google's OAuth 2.0 token revocation endpoint.
+const googleRevokeURL = "https://oauth2.googleapis.com/revoke"
+
 type GoogleIdentityPlugin struct {
 	identity_proto.UnimplementedIdentityPluginServer
+
+	// revokeURL overrides googleRevokeURL, for tests.
+	revokeURL string
 }
 
 func (p *GoogleIdentityPlugin) Login(stream identity_proto.IdentityPlugin_LoginServer) error {
@@ -71,10 +83,10 @@ func (p *GoogleIdentityPlugin) Login(stream identity_proto.IdentityPlugin_LoginS
 
 	var t *oauth2.Token
 	switch method {
-	case "device":
+	case "device", "device-code":
 		t, err = p.loginDevice(stream, config)
 	default:
-		t, err = p.loginInteractive(stream, config, opts["redirect_uri"])
+		t, err = p.loginInteractive(stream, config, opts["redirect_uri"], opts["headless"] == "true")
 	}
 
 	if err != nil {
@@ -96,7 +108,10 @@ func (p *GoogleIdentityPlugin) Login(stream identity_proto.IdentityPlugin_LoginS
 	})
 }
 
-func (p *GoogleIdentityPlugin) loginInteractive(stream identity_proto.IdentityPlugin_LoginServer, config *oauth2.Config, redirect string) (*oauth2.Token, error) {
+// loginInteractive runs the authorization code flow. The code normally arrives on a local
+// listener; when headless is set, or the redirect is not local, the browser may be on
+// another machine and the user pastes the redirect address back instead.
+func (p *GoogleIdentityPlugin) loginInteractive(stream identity_proto.IdentityPlugin_LoginServer, config *oauth2.Config, redirect string, headless bool) (*oauth2.Token, error) {
 	useLocal := false
 	listenAddr := "127.0.0.1:0"
 
@@ -110,7 +125,7 @@ func (p *GoogleIdentityPlugin) loginInteractive(stream identity_proto.IdentityPl
 		}
 	}
 
-	if useLocal {
+	if useLocal && !headless {
 		l, err := net.Listen("tcp", listenAddr)
 		if err != nil {
 			// Fallback to random port if specified port is busy
@@ -157,11 +172,18 @@ func (p *GoogleIdentityPlugin) loginInteractive(stream identity_proto.IdentityPl
 		}
 	}
 
+	if redirect == "" || redirect == "urn:ietf:wg:oauth:2.0:oob" {
+		redirect = "http://127.0.0.1"
+	}
 	config.RedirectURL = redirect
-	_ = p.interact(stream, &identity_proto.InteractionRequest{Action: &identity_proto.InteractionRequest_DisplayMessage{DisplayMessage: &identity_proto.DisplayMessageRequest{Message: "URL: " + config.AuthCodeURL(mustState(), oauth2.AccessTypeOffline)}}})
-	fmt.Fprint(os.Stderr, "Code: ")
-	var code string
-	if _, err := fmt.Scan(&code); err != nil {
+	state := mustState()
+	_ = p.interact(stream, &identity_proto.InteractionRequest{Action: &identity_proto.InteractionRequest_OpenUrl{OpenUrl: &identity_proto.OpenUrlRequest{Url: config.AuthCodeURL(state, oauth2.AccessTypeOffline)}}})
+	answer, err := p.prompt(stream, "After signing in, paste the address your browser was redirected to (or just the code):")
+	if err != nil {
+		return nil, err
+	}
+	code, err := authCode(answer, state)
+	if err != nil {
 		return nil, err
 	}
 	return config.Exchange(stream.Context(), code)
@@ -173,8 +195,13 @@ func (p *GoogleIdentityPlugin) loginDevice(stream identity_proto.IdentityPlugin_
 		return nil, err
 	}
 
-	msg := fmt.Sprintf("To sign in, use a web browser to open the page %s and enter the code %s to authenticate.", da.VerificationURI, da.UserCode)
+	msg := fmt.Sprintf("To sign in, open the page below in a web browser on any device and enter the code %s to authenticate.", da.UserCode)
 	_ = p.interact(stream, &identity_proto.InteractionRequest{Action: &identity_proto.InteractionRequest_DisplayMessage{DisplayMessage: &identity_proto.DisplayMessageRequest{Message: msg}}})
+	verify := da.VerificationURI
+	if da.VerificationURIComplete != "" {
+		verify = da.VerificationURIComplete
+	}
+	_ = p.interact(stream, &identity_proto.InteractionRequest{Action: &identity_proto.InteractionRequest_OpenUrl{OpenUrl: &identity_proto.OpenUrlRequest{Url: verify}}})
 
 	return config.DeviceAccessToken(stream.Context(), da)
 }
@@ -202,6 +229,47 @@ func (p *GoogleIdentityPlugin) Refresh(ctx context.Context, req *identity_proto.
 	return &identity_proto.RefreshResponse{Token: &identity_proto.AccessToken{AccessToken: t.AccessToken, RefreshToken: t.RefreshToken, ExpiresAt: t.Expiry.Unix()}}, nil
 }
 
+// Logout revokes the refresh token, which also invalidates the access tokens issued
+// from it. A token Google no longer recognizes is already unusable, so it counts as
+// revoked.
+func (p *GoogleIdentityPlugin) Logout(ctx context.Context, req *identity_proto.LogoutRequest) (*identity_proto.LogoutResponse, error) {
+	token := req.Options["refresh_token"]
+	if token == "" {
+		token = req.Options["access_token"]
+	}
+	if token == "" {
+		return &identity_proto.LogoutResponse{Success: true}, nil
+	}
+
+	revokeURL := p.revokeURL
+	if revokeURL == "" {
+		revokeURL = googleRevokeURL
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, revokeURL, strings.NewReader(url.Values{"token": {token}}.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return &identity_proto.LogoutResponse{Success: true}, nil
+	}
+	var body struct {
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	_ = json.NewDecoder(resp.Body).Decode(&body)
+	if body.Error == "invalid_token" {
+		return &identity_proto.LogoutResponse{Success: true}, nil
+	}
+	return nil, fmt.Errorf("revoke failed with status %d: %s %s", resp.StatusCode, body.Error, body.ErrorDescription)
+}
+
 func (p *GoogleIdentityPlugin) interact(stream identity_proto.IdentityPlugin_LoginServer, req *identity_proto.InteractionRequest) error {
 	if err := stream.Send(&identity_proto.LoginResponse{Payload: &identity_proto.LoginResponse_InteractionRequest{InteractionRequest: req}}); err != nil {
 		return err
@@ -210,9 +278,53 @@ func (p *GoogleIdentityPlugin) interact(stream identity_proto.IdentityPlugin_Log
 	return err
 }
 
+// prompt asks the host for a line of input and returns the answer.
+func (p *GoogleIdentityPlugin) prompt(stream identity_proto.IdentityPlugin_LoginServer, message string) (string, error) {
+	req := &identity_proto.InteractionRequest{Action: &identity_proto.InteractionRequest_Prompt{Prompt: &identity_proto.PromptRequest{Message: message}}}
+	if err := stream.Send(&identity_proto.LoginResponse{Payload: &identity_proto.LoginResponse_InteractionRequest{InteractionRequest: req}}); err != nil {
+		return "", err
+	}
+	m, err := stream.Recv()
+	if err != nil {
+		return "", err
+	}
+	resp := m.GetInteractionResponse()
+	if resp == nil {
+		return "", fmt.Errorf("expected interaction response")
+	}
+	if resp.Error != "" {
+		return "", errors.New(resp.Error)
+	}
+	return strings.TrimSpace(resp.Value), nil
+}
+
+// authCode extracts the authorization code from answer, which is either the code itself
+// or the address the browser was redirected to, whose state must match the request.
+func authCode(answer, state string) (string, error) {
+	if answer == "" {
+		return "", fmt.Errorf("no authorization code entered")
+	}
+	u, err := url.Parse(answer)
+	if err != nil || u.Scheme == "" {
+		return answer, nil
+	}
+	q := u.Query()
+	if e := q.Get("error"); e != "" {
+		return "", fmt.Errorf("authorization failed: %s: %s", e, q.Get("error_description"))
+	}
+	if q.Get("state") != state {
+		return "", fmt.Errorf("state mismatch in redirect address")
+	}
+	if q.Get("code") == "" {
+		return "", fmt.Errorf("redirect address has no code")
+	}
+	return q.Get("code"), nil
+}
+
 func (p *GoogleIdentityPlugin) GetMetadata(ctx context.Context, req *identity_proto.MetadataRequest) (*identity_proto.MetadataResponse, error) {
 	return &identity_proto.MetadataResponse{
 		Name:               "google",
+		Version:            version,
 		Type:               "identity",
 		SupportedProviders: []string{"google"},
 	}, nil
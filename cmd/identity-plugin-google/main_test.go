@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	identity_proto "github.com/michaeldcanady/go-onedrive/internal/features/plugins/proto/identity"
+)
+
+func TestLogout(t *testing.T) {
+	var revoked []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.FormValue("token")
+		switch token {
+		case "stale":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"invalid_token","error_description":"Token expired or revoked"}`))
+		case "broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			revoked = append(revoked, token)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	p := &GoogleIdentityPlugin{revokeURL: srv.URL}
+
+	logout := func(options map[string]string) (*identity_proto.LogoutResponse, error) {
+		return p.Logout(context.Background(), &identity_proto.LogoutRequest{IdentityId: "alice", Options: options})
+	}
+
+	resp, err := logout(map[string]string{"access_token": "access", "refresh_token": "refresh"})
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.Equal(t, []string{"refresh"}, revoked, "the refresh token is revoked")
+
+	resp, err = logout(map[string]string{"refresh_token": "stale"})
+	require.NoError(t, err)
+	assert.True(t, resp.Success, "an unknown token is already revoked")
+
+	_, err = logout(map[string]string{"refresh_token": "broken"})
+	assert.Error(t, err)
+}
+
+func TestAuthCode(t *testing.T) {
+	tests := []struct {
+		name    string
+		answer  string
+		want    string
+		wantErr string
+	}{
+		{"bare code", "4/0AbC", "4/0AbC", ""},
+		{"redirect address", "http://127.0.0.1/?code=abc&state=s1", "abc", ""},
+		{"state mismatch", "http://127.0.0.1/?code=abc&state=other", "", "state mismatch"},
+		{"provider error", "http://127.0.0.1/?error=access_denied&error_description=denied&state=s1", "", "access_denied"},
+		{"empty", "", "", "no authorization code"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := authCode(tt.answer, "s1")
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, code)
+		})
+	}
+}
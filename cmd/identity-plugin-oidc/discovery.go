@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	// discoveryPath is where an issuer publishes its OpenID Provider metadata.
+	discoveryPath = "/.well-known/openid-configuration"
+
+	// requestTimeout bounds each request to the provider, so an unresponsive issuer
+	// fails the call instead of hanging it.
+	requestTimeout = 30 * time.Second
+)
+
+// httpClient makes every request to the provider: discovery, token, userinfo and
+// revocation.
+var httpClient = &http.Client{Timeout: requestTimeout}
+
+// withHTTPClient returns ctx carrying [httpClient] for the oauth2 package to use.
+func withHTTPClient(ctx context.Context) context.Context {
+	return context.WithValue(ctx, oauth2.HTTPClient, httpClient)
+}
+
+// providerMetadata is the part of an issuer's OpenID Provider metadata the plugin uses.
+type providerMetadata struct {
+	Issuer                      string `json:"issuer"`
+	AuthorizationEndpoint       string `json:"authorization_endpoint"`
+	TokenEndpoint               string `json:"token_endpoint"`
+	DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint"`
+	UserinfoEndpoint            string `json:"userinfo_endpoint"`
+	RevocationEndpoint          string `json:"revocation_endpoint"`
+}
+
+// discover fetches the OpenID Provider metadata of issuer. The metadata must name the
+// same issuer, so a misconfigured proxy cannot redirect logins elsewhere.
+func discover(ctx context.Context, issuer string) (*providerMetadata, error) {
+	issuer = strings.TrimSuffix(issuer, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, issuer+discoveryPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover %s: %w", issuer, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to discover %s: status %d", issuer, resp.StatusCode)
+	}
+
+	var m providerMetadata
+	if err := json.NewDecoder(resp.Body).Decode(&m); err != nil {
+		return nil, fmt.Errorf("invalid discovery document from %s: %w", issuer, err)
+	}
+	if strings.TrimSuffix(m.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("discovery document for %s names issuer %q", issuer, m.Issuer)
+	}
+	if m.TokenEndpoint == "" {
+		return nil, fmt.Errorf("discovery document for %s has no token_endpoint", issuer)
+	}
+	return &m, nil
+}
+
+// metadataCache holds the metadata of each issuer for the life of the plugin process, so
+// that Refresh and Logout do not fetch the discovery document on every call.
+type metadataCache struct {
+	mu      sync.Mutex
+	entries map[string]*providerMetadata
+}
+
+// get returns the metadata of issuer, discovering it on first use. Failed discoveries
+// are not cached.
+func (c *metadataCache) get(ctx context.Context, issuer string) (*providerMetadata, error) {
+	issuer = strings.TrimSuffix(issuer, "/")
+	c.mu.Lock()
+	m, ok := c.entries[issuer]
+	c.mu.Unlock()
+	if ok {
+		return m, nil
+	}
+
+	m, err := discover(ctx, issuer)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]*providerMetadata)
+	}
+	c.entries[issuer] = m
+	return m, nil
+}
+
+// endpoint returns the OAuth 2.0 endpoints in m.
+func (m *providerMetadata) endpoint() oauth2.Endpoint {
+	return oauth2.Endpoint{
+		AuthURL:       m.AuthorizationEndpoint,
+		TokenURL:      m.TokenEndpoint,
+		DeviceAuthURL: m.DeviceAuthorizationEndpoint,
+	}
+}
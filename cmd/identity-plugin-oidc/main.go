@@ -0,0 +1,466 @@
+// identity-plugin-oidc provides authentication against any OpenID Connect provider, such
+// as Keycloak, that publishes a discovery document.
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/go-plugin"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+
+	"github.com/michaeldcanady/go-onedrive/internal/core/plugins"
+	identity_proto "github.com/michaeldcanady/go-onedrive/internal/features/plugins/proto/identity"
+)
+
+// version is reported through GetMetadata. Release builds set it with
+// -ldflags "-X main.version=<version>".
+var version = "dev"
+
+const (
+	// providerName is the provider the plugin serves and records on identities.
+	providerName = "oidc"
+
+	// defaultScopes are requested when no scopes are configured. offline_access asks for
+	// a refresh token, which the host needs to keep the session alive.
+	defaultScopes = "openid profile email offline_access"
+	// defaultClientScopes are requested by the client credentials flow, which has no user
+	// and never receives a refresh token.
+	defaultClientScopes = "openid"
+
+	// callbackTimeout bounds how long the local listener waits for the browser.
+	callbackTimeout = 5 * time.Minute
+)
+
+func mustState() string {
+	uuid, err := uuid.NewV7()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to generate UUID for state: %v\n", err)
+		return "fallback-state-" + time.Now().String()
+	}
+
+	return uuid.String()
+}
+
+type OIDCIdentityPlugin struct {
+	identity_proto.UnimplementedIdentityPluginServer
+
+	// metadata caches the discovery document of each issuer.
+	metadata metadataCache
+}
+
+func (p *OIDCIdentityPlugin) Login(stream identity_proto.IdentityPlugin_LoginServer) error {
+	m, err := stream.Recv()
+	if err != nil || m.GetConfig() == nil {
+		return fmt.Errorf("expected config")
+	}
+
+	opts := m.GetConfig().Options
+	issuer := opts["issuer"]
+	if issuer == "" {
+		return fmt.Errorf("issuer is required")
+	}
+	clientID := opts["client_id"]
+	if clientID == "" {
+		return fmt.Errorf("client_id is required")
+	}
+
+	method := opts["method"]
+	if method == "" {
+		method = "interactive"
+	}
+	clientCredentials := isClientCredentials(method)
+	if clientCredentials && opts["client_secret"] == "" {
+		return fmt.Errorf("client_secret is required for the %s method", method)
+	}
+
+	meta, err := p.metadata.get(stream.Context(), issuer)
+	if err != nil {
+		return err
+	}
+
+	scopes := opts["scopes"]
+	if scopes == "" {
+		scopes = defaultScopes
+		if clientCredentials {
+			scopes = defaultClientScopes
+		}
+	}
+	config := &oauth2.Config{
+		ClientID:     clientID,
+		ClientSecret: opts["client_secret"],
+		Scopes:       splitScopes(scopes),
+		Endpoint:     meta.endpoint(),
+	}
+
+	var t *oauth2.Token
+	var identity *identity_proto.Identity
+	switch {
+	case clientCredentials:
+		t, err = p.loginClientCredentials(stream.Context(), config)
+		// There is no user, so the client itself is the identity.
+		identity = &identity_proto.Identity{Id: clientID, DisplayName: clientID, Provider: providerName}
+	case method == "device" || method == "device-code":
+		t, err = p.loginDevice(stream, config)
+	default:
+		t, err = p.loginInteractive(stream, config, opts["redirect_uri"], opts["headless"] == "true")
+	}
+	if err != nil {
+		return err
+	}
+
+	if identity == nil {
+		if identity, err = p.fetchIdentity(stream.Context(), meta, config, t); err != nil {
+			return err
+		}
+	}
+
+	return stream.Send(&identity_proto.LoginResponse{
+		Payload: &identity_proto.LoginResponse_Result{
+			Result: &identity_proto.LoginResult{
+				Token:    &identity_proto.AccessToken{AccessToken: t.AccessToken, RefreshToken: t.RefreshToken, ExpiresAt: t.Expiry.Unix(), Scopes: config.Scopes},
+				Identity: identity,
+			},
+		},
+	})
+}
+
+// loginInteractive runs the authorization code flow with PKCE. The code normally arrives
+// on a local listener; when headless is set, or the redirect is not local, the browser
+// may be on another machine and the user pastes the redirect address back instead.
+func (p *OIDCIdentityPlugin) loginInteractive(stream identity_proto.IdentityPlugin_LoginServer, config *oauth2.Config, redirect string, headless bool) (*oauth2.Token, error) {
+	ctx := withHTTPClient(stream.Context())
+	state := mustState()
+	verifier := oauth2.GenerateVerifier()
+
+	var code string
+	if u, ok := loopbackRedirect(redirect); ok && !headless {
+		l, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", u.Port()))
+		if err != nil {
+			// Fallback to random port if specified port is busy
+			l, err = net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				return nil, err
+			}
+		}
+		defer l.Close()
+
+		// Always update redirect URI to match actual listener
+		port := fmt.Sprint(l.Addr().(*net.TCPAddr).Port)
+		config.RedirectURL = "http://" + net.JoinHostPort(u.Hostname(), port) + u.Path
+
+		answers := make(chan string, 1)
+		s := &http.Server{
+			Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Query().Get("code") == "" && r.URL.Query().Get("error") == "" {
+					http.NotFound(w, r)
+					return
+				}
+				select {
+				case answers <- "http://" + r.Host + r.URL.RequestURI():
+					fmt.Fprintln(w, "Login complete. You can close this window.")
+				default:
+				}
+			}),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		go s.Serve(l)
+		defer s.Close()
+
+		_ = p.interact(stream, &identity_proto.InteractionRequest{Action: &identity_proto.InteractionRequest_OpenUrl{OpenUrl: &identity_proto.OpenUrlRequest{Url: config.AuthCodeURL(state, oauth2.S256ChallengeOption(verifier))}}})
+		select {
+		case answer := <-answers:
+			if code, err = authCode(answer, state); err != nil {
+				return nil, err
+			}
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(callbackTimeout):
+			return nil, fmt.Errorf("timed out waiting for the browser to complete the login")
+		}
+	} else {
+		if redirect == "" {
+			redirect = "http://127.0.0.1"
+		}
+		config.RedirectURL = redirect
+		_ = p.interact(stream, &identity_proto.InteractionRequest{Action: &identity_proto.InteractionRequest_OpenUrl{OpenUrl: &identity_proto.OpenUrlRequest{Url: config.AuthCodeURL(state, oauth2.S256ChallengeOption(verifier))}}})
+		answer, err := p.prompt(stream, "After signing in, paste the address your browser was redirected to (or just the code):")
+		if err != nil {
+			return nil, err
+		}
+		if code, err = authCode(answer, state); err != nil {
+			return nil, err
+		}
+	}
+	return config.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+}
+
+func (p *OIDCIdentityPlugin) loginDevice(stream identity_proto.IdentityPlugin_LoginServer, config *oauth2.Config) (*oauth2.Token, error) {
+	if config.Endpoint.DeviceAuthURL == "" {
+		return nil, fmt.Errorf("the provider does not support the device flow: no device_authorization_endpoint")
+	}
+	ctx := withHTTPClient(stream.Context())
+	da, err := config.DeviceAuth(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := fmt.Sprintf("To sign in, open the page below in a web browser on any device and enter the code %s to authenticate.", da.UserCode)
+	_ = p.interact(stream, &identity_proto.InteractionRequest{Action: &identity_proto.InteractionRequest_DisplayMessage{DisplayMessage: &identity_proto.DisplayMessageRequest{Message: msg}}})
+	verify := da.VerificationURI
+	if da.VerificationURIComplete != "" {
+		verify = da.VerificationURIComplete
+	}
+	_ = p.interact(stream, &identity_proto.InteractionRequest{Action: &identity_proto.InteractionRequest_OpenUrl{OpenUrl: &identity_proto.OpenUrlRequest{Url: verify}}})
+
+	return config.DeviceAccessToken(ctx, da)
+}
+
+// isClientCredentials reports whether method names the client credentials grant.
+func isClientCredentials(method string) bool {
+	return method == "client-secret" || method == "client-credentials"
+}
+
+func (p *OIDCIdentityPlugin) loginClientCredentials(ctx context.Context, config *oauth2.Config) (*oauth2.Token, error) {
+	c := &clientcredentials.Config{ClientID: config.ClientID, ClientSecret: config.ClientSecret, TokenURL: config.Endpoint.TokenURL, Scopes: config.Scopes}
+	return c.Token(withHTTPClient(ctx))
+}
+
+// claims are the standard OpenID Connect claims that describe an identity.
+type claims struct {
+	Sub               string `json:"sub"`
+	Name              string `json:"name"`
+	PreferredUsername string `json:"preferred_username"`
+	Email             string `json:"email"`
+}
+
+func (c *claims) identity() *identity_proto.Identity {
+	name := c.Name
+	if name == "" {
+		name = c.PreferredUsername
+	}
+	if name == "" {
+		name = c.Email
+	}
+	return &identity_proto.Identity{Id: c.Sub, DisplayName: name, Email: c.Email, Provider: providerName}
+}
+
+// fetchIdentity reads the user's claims from the userinfo endpoint or, when the provider
+// has none, from the ID token. The ID token came straight from the token endpoint over
+// TLS, so its signature need not be checked (OpenID Connect Core 3.1.3.7).
+func (p *OIDCIdentityPlugin) fetchIdentity(ctx context.Context, meta *providerMetadata, config *oauth2.Config, t *oauth2.Token) (*identity_proto.Identity, error) {
+	var c claims
+	if meta.UserinfoEndpoint != "" {
+		resp, err := config.Client(withHTTPClient(ctx), t).Get(meta.UserinfoEndpoint)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("userinfo failed with status %d", resp.StatusCode)
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&c); err != nil {
+			return nil, fmt.Errorf("invalid userinfo response: %w", err)
+		}
+	} else {
+		raw, _ := t.Extra("id_token").(string)
+		if raw == "" {
+			return nil, fmt.Errorf("the provider returned no ID token and has no userinfo endpoint")
+		}
+		parts := strings.Split(raw, ".")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("malformed ID token")
+		}
+		payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("malformed ID token: %w", err)
+		}
+		if err := json.Unmarshal(payload, &c); err != nil {
+			return nil, fmt.Errorf("malformed ID token: %w", err)
+		}
+	}
+	if c.Sub == "" {
+		return nil, fmt.Errorf("the provider returned no subject claim")
+	}
+	return c.identity(), nil
+}
+
+// Refresh redeems the refresh token. Client credentials tokens come without one, so for
+// identities that logged in with that method the grant is repeated instead.
+func (p *OIDCIdentityPlugin) Refresh(ctx context.Context, req *identity_proto.RefreshRequest) (*identity_proto.RefreshResponse, error) {
+	if req.Options["issuer"] == "" {
+		return nil, fmt.Errorf("issuer is required")
+	}
+	method := req.Options["method"]
+	if isClientCredentials(method) && req.Options["client_secret"] == "" {
+		return nil, fmt.Errorf("client_secret is required to renew a %s token", method)
+	}
+	meta, err := p.metadata.get(ctx, req.Options["issuer"])
+	if err != nil {
+		return nil, err
+	}
+	config := &oauth2.Config{ClientID: req.Options["client_id"], ClientSecret: req.Options["client_secret"], Endpoint: meta.endpoint()}
+
+	var t *oauth2.Token
+	if isClientCredentials(method) {
+		scopes := req.Options["scopes"]
+		if scopes == "" {
+			scopes = defaultClientScopes
+		}
+		config.Scopes = splitScopes(scopes)
+		t, err = p.loginClientCredentials(ctx, config)
+	} else {
+		t, err = config.TokenSource(withHTTPClient(ctx), &oauth2.Token{RefreshToken: req.RefreshToken}).Token()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &identity_proto.RefreshResponse{Token: &identity_proto.AccessToken{AccessToken: t.AccessToken, RefreshToken: t.RefreshToken, ExpiresAt: t.Expiry.Unix(), Scopes: config.Scopes}}, nil
+}
+
+// Logout revokes the refresh token, or the access token when there is none, at the
+// provider's revocation endpoint (RFC 7009). Providers without one cannot revoke, so
+// Success is false.
+func (p *OIDCIdentityPlugin) Logout(ctx context.Context, req *identity_proto.LogoutRequest) (*identity_proto.LogoutResponse, error) {
+	token, hint := req.Options["refresh_token"], "refresh_token"
+	if token == "" {
+		token, hint = req.Options["access_token"], "access_token"
+	}
+	if token == "" {
+		return &identity_proto.LogoutResponse{Success: true}, nil
+	}
+	if req.Options["issuer"] == "" {
+		return nil, fmt.Errorf("issuer is required")
+	}
+	meta, err := p.metadata.get(ctx, req.Options["issuer"])
+	if err != nil {
+		return nil, err
+	}
+	if meta.RevocationEndpoint == "" {
+		return &identity_proto.LogoutResponse{Success: false}, nil
+	}
+
+	form := url.Values{"token": {token}, "token_type_hint": {hint}, "client_id": {req.Options["client_id"]}}
+	if secret := req.Options["client_secret"]; secret != "" {
+		form.Set("client_secret", secret)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, meta.RevocationEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("revoke failed with status %d", resp.StatusCode)
+	}
+	return &identity_proto.LogoutResponse{Success: true}, nil
+}
+
+func (p *OIDCIdentityPlugin) interact(stream identity_proto.IdentityPlugin_LoginServer, req *identity_proto.InteractionRequest) error {
+	if err := stream.Send(&identity_proto.LoginResponse{Payload: &identity_proto.LoginResponse_InteractionRequest{InteractionRequest: req}}); err != nil {
+		return err
+	}
+	_, err := stream.Recv()
+	return err
+}
+
+// prompt asks the host for a line of input and returns the answer.
+func (p *OIDCIdentityPlugin) prompt(stream identity_proto.IdentityPlugin_LoginServer, message string) (string, error) {
+	req := &identity_proto.InteractionRequest{Action: &identity_proto.InteractionRequest_Prompt{Prompt: &identity_proto.PromptRequest{Message: message}}}
+	if err := stream.Send(&identity_proto.LoginResponse{Payload: &identity_proto.LoginResponse_InteractionRequest{InteractionRequest: req}}); err != nil {
+		return "", err
+	}
+	m, err := stream.Recv()
+	if err != nil {
+		return "", err
+	}
+	resp := m.GetInteractionResponse()
+	if resp == nil {
+		return "", fmt.Errorf("expected interaction response")
+	}
+	if resp.Error != "" {
+		return "", errors.New(resp.Error)
+	}
+	return strings.TrimSpace(resp.Value), nil
+}
+
+// authCode extracts the authorization code from answer, which is either the code itself
+// or the address the browser was redirected to, whose state must match the request.
+func authCode(answer, state string) (string, error) {
+	if answer == "" {
+		return "", fmt.Errorf("no authorization code entered")
+	}
+	u, err := url.Parse(answer)
+	if err != nil || u.Scheme == "" {
+		return answer, nil
+	}
+	q := u.Query()
+	if e := q.Get("error"); e != "" {
+		return "", fmt.Errorf("authorization failed: %s: %s", e, q.Get("error_description"))
+	}
+	if q.Get("state") != state {
+		return "", fmt.Errorf("state mismatch in redirect address")
+	}
+	if q.Get("code") == "" {
+		return "", fmt.Errorf("redirect address has no code")
+	}
+	return q.Get("code"), nil
+}
+
+// loopbackRedirect reports whether redirect, or the default when it is empty, points at
+// this machine, so the plugin can listen for it.
+func loopbackRedirect(redirect string) (*url.URL, bool) {
+	if redirect == "" {
+		return &url.URL{Scheme: "http", Host: "127.0.0.1"}, true
+	}
+	u, err := url.Parse(redirect)
+	if err != nil || u.Scheme != "http" {
+		return nil, false
+	}
+	return u, u.Hostname() == "127.0.0.1" || u.Hostname() == "localhost"
+}
+
+// splitScopes accepts scopes separated by spaces, as OAuth 2.0 writes them, or by commas.
+func splitScopes(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool { return r == ' ' || r == ',' })
+}
+
+func (p *OIDCIdentityPlugin) GetMetadata(ctx context.Context, req *identity_proto.MetadataRequest) (*identity_proto.MetadataResponse, error) {
+	return &identity_proto.MetadataResponse{
+		Name:               providerName,
+		Version:            version,
+		Type:               "identity",
+		SupportedProviders: []string{providerName},
+	}, nil
+}
+
+func main() {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Fprintf(os.Stderr, "plugin panicked: %v\n", r)
+			os.Exit(1)
+		}
+	}()
+
+	plugin.Serve(&plugin.ServeConfig{
+		HandshakeConfig: plugins.HandshakeConfig,
+		Plugins:         map[string]plugin.Plugin{"identity": &plugins.IdentityGRPCPlugin{Impl: &OIDCIdentityPlugin{}}},
+		GRPCServer:      plugins.CustomGRPCServer,
+	})
+}
@@ -0,0 +1,370 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	identity_proto "github.com/michaeldcanady/go-onedrive/internal/features/plugins/proto/identity"
+)
+
+// testProvider is a stand-in OpenID Connect provider. Authorization codes are issued by
+// the test through authorize, which plays the part of the user signing in.
+type testProvider struct {
+	*httptest.Server
+	issuer     string
+	noUserinfo bool
+	noRevoke   bool
+
+	mu          sync.Mutex
+	challenges  map[string]string
+	revoked     []string
+	discoveries int
+}
+
+func newTestProvider(t *testing.T) *testProvider {
+	t.Helper()
+	p := &testProvider{challenges: make(map[string]string)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+discoveryPath, p.discovery)
+	mux.HandleFunc("POST /token", p.token)
+	mux.HandleFunc("POST /device", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"device_code": "dc", "user_code": "ABCD-EFGH", "verification_uri": p.URL + "/activate", "interval": 1})
+	})
+	mux.HandleFunc("GET /userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access-user" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		writeJSON(w, map[string]any{"sub": "user-1", "preferred_username": "alice", "email": "alice@example.com"})
+	})
+	mux.HandleFunc("POST /revoke", func(w http.ResponseWriter, r *http.Request) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.revoked = append(p.revoked, r.FormValue("token_type_hint")+":"+r.FormValue("token"))
+	})
+	p.Server = httptest.NewServer(mux)
+	p.issuer = p.URL
+	t.Cleanup(p.Close)
+	return p
+}
+
+func (p *testProvider) discovery(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	p.discoveries++
+	p.mu.Unlock()
+	doc := map[string]any{
+		"issuer":                        p.issuer,
+		"authorization_endpoint":        p.URL + "/authorize",
+		"token_endpoint":                p.URL + "/token",
+		"device_authorization_endpoint": p.URL + "/device",
+	}
+	if !p.noUserinfo {
+		doc["userinfo_endpoint"] = p.URL + "/userinfo"
+	}
+	if !p.noRevoke {
+		doc["revocation_endpoint"] = p.URL + "/revoke"
+	}
+	writeJSON(w, doc)
+}
+
+// authorize signs the user in for the authorization request at authURL and returns the
+// address the provider redirects the browser to.
+func (p *testProvider) authorize(t *testing.T, authURL string) string {
+	t.Helper()
+	u, err := url.Parse(authURL)
+	require.NoError(t, err)
+	q := u.Query()
+	assert.Equal(t, "S256", q.Get("code_challenge_method"))
+
+	p.mu.Lock()
+	p.challenges["the-code"] = q.Get("code_challenge")
+	p.mu.Unlock()
+	return q.Get("redirect_uri") + "?" + url.Values{"code": {"the-code"}, "state": {q.Get("state")}}.Encode()
+}
+
+func (p *testProvider) token(w http.ResponseWriter, r *http.Request) {
+	user := map[string]any{"access_token": "access-user", "refresh_token": "refresh-user", "token_type": "Bearer", "expires_in": 3600}
+	if p.noUserinfo {
+		claims, _ := json.Marshal(map[string]any{"sub": "user-1", "name": "Alice", "email": "alice@example.com"})
+		user["id_token"] = "e30." + base64.RawURLEncoding.EncodeToString(claims) + ".sig"
+	}
+
+	switch r.FormValue("grant_type") {
+	case "authorization_code":
+		p.mu.Lock()
+		challenge := p.challenges[r.FormValue("code")]
+		p.mu.Unlock()
+		sum := sha256.Sum256([]byte(r.FormValue("code_verifier")))
+		if challenge == "" || base64.RawURLEncoding.EncodeToString(sum[:]) != challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			writeJSON(w, map[string]any{"error": "invalid_grant"})
+			return
+		}
+		writeJSON(w, user)
+	case "urn:ietf:params:oauth:grant-type:device_code":
+		writeJSON(w, user)
+	case "refresh_token":
+		writeJSON(w, map[string]any{"access_token": "access-refreshed", "token_type": "Bearer", "expires_in": 3600})
+	case "client_credentials":
+		writeJSON(w, map[string]any{"access_token": "access-client", "token_type": "Bearer", "expires_in": 300})
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		writeJSON(w, map[string]any{"error": "unsupported_grant_type"})
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// loginStream plays the host side of a login, answering each interaction with answer.
+type loginStream struct {
+	grpc.ServerStream
+	ctx     context.Context
+	options map[string]string
+	answer  func(*identity_proto.InteractionRequest) string
+
+	configSent bool
+	pending    *identity_proto.InteractionRequest
+	result     *identity_proto.LoginResult
+}
+
+func (s *loginStream) Context() context.Context { return s.ctx }
+
+func (s *loginStream) Send(m *identity_proto.LoginResponse) error {
+	if r := m.GetInteractionRequest(); r != nil {
+		s.pending = r
+	}
+	if r := m.GetResult(); r != nil {
+		s.result = r
+	}
+	return nil
+}
+
+func (s *loginStream) Recv() (*identity_proto.LoginRequest, error) {
+	if !s.configSent {
+		s.configSent = true
+		return &identity_proto.LoginRequest{Payload: &identity_proto.LoginRequest_Config{Config: &identity_proto.Config{Options: s.options}}}, nil
+	}
+	req := s.pending
+	s.pending = nil
+	var value string
+	if s.answer != nil {
+		value = s.answer(req)
+	}
+	return &identity_proto.LoginRequest{Payload: &identity_proto.LoginRequest_InteractionResponse{InteractionResponse: &identity_proto.InteractionResponse{Value: value}}}, nil
+}
+
+func TestLogin(t *testing.T) {
+	provider := newTestProvider(t)
+
+	// browser signs in at the URL the plugin opens and follows the redirect, like a
+	// browser on the same machine.
+	browser := func(t *testing.T) func(*identity_proto.InteractionRequest) string {
+		return func(req *identity_proto.InteractionRequest) string {
+			if open := req.GetOpenUrl(); open != nil {
+				resp, err := http.Get(provider.authorize(t, open.Url))
+				require.NoError(t, err)
+				resp.Body.Close()
+			}
+			return ""
+		}
+	}
+	// remote signs in on another machine and pastes the redirect address at the prompt.
+	remote := func(t *testing.T) func(*identity_proto.InteractionRequest) string {
+		var redirect string
+		return func(req *identity_proto.InteractionRequest) string {
+			if open := req.GetOpenUrl(); open != nil {
+				redirect = provider.authorize(t, open.Url)
+			}
+			if req.GetPrompt() != nil {
+				return redirect
+			}
+			return ""
+		}
+	}
+
+	tests := []struct {
+		name     string
+		options  map[string]string
+		answer   func(t *testing.T) func(*identity_proto.InteractionRequest) string
+		identity *identity_proto.Identity
+		access   string
+		wantErr  string
+	}{
+		{
+			name:     "authorization code on a local listener",
+			options:  map[string]string{},
+			answer:   browser,
+			identity: &identity_proto.Identity{Id: "user-1", DisplayName: "alice", Email: "alice@example.com", Provider: "oidc"},
+			access:   "access-user",
+		},
+		{
+			name:     "authorization code pasted back",
+			options:  map[string]string{"headless": "true"},
+			answer:   remote,
+			identity: &identity_proto.Identity{Id: "user-1", DisplayName: "alice", Email: "alice@example.com", Provider: "oidc"},
+			access:   "access-user",
+		},
+		{
+			name:     "device code",
+			options:  map[string]string{"method": "device-code"},
+			identity: &identity_proto.Identity{Id: "user-1", DisplayName: "alice", Email: "alice@example.com", Provider: "oidc"},
+			access:   "access-user",
+		},
+		{
+			name:     "client credentials",
+			options:  map[string]string{"method": "client-secret", "client_secret": "s3cret"},
+			identity: &identity_proto.Identity{Id: "odc", DisplayName: "odc", Provider: "oidc"},
+			access:   "access-client",
+		},
+		{
+			name:    "client credentials without a secret",
+			options: map[string]string{"method": "client-secret"},
+			wantErr: "client_secret is required",
+		},
+		{
+			name:    "without an issuer",
+			options: map[string]string{"issuer": ""},
+			wantErr: "issuer is required",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := map[string]string{"issuer": provider.URL, "client_id": "odc"}
+			for k, v := range tt.options {
+				options[k] = v
+			}
+			stream := &loginStream{ctx: context.Background(), options: options}
+			if tt.answer != nil {
+				stream.answer = tt.answer(t)
+			}
+
+			err := (&OIDCIdentityPlugin{}).Login(stream)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, stream.result)
+			assert.Equal(t, tt.access, stream.result.Token.AccessToken)
+			assert.Equal(t, tt.identity.Id, stream.result.Identity.Id)
+			assert.Equal(t, tt.identity.DisplayName, stream.result.Identity.DisplayName)
+			assert.Equal(t, tt.identity.Email, stream.result.Identity.Email)
+			assert.Equal(t, tt.identity.Provider, stream.result.Identity.Provider)
+		})
+	}
+}
+
+func TestLogin_IDTokenClaims(t *testing.T) {
+	provider := newTestProvider(t)
+	provider.noUserinfo = true
+	stream := &loginStream{ctx: context.Background(), options: map[string]string{"issuer": provider.URL, "client_id": "odc", "method": "device"}}
+
+	require.NoError(t, (&OIDCIdentityPlugin{}).Login(stream))
+	assert.Equal(t, "user-1", stream.result.Identity.Id)
+	assert.Equal(t, "Alice", stream.result.Identity.DisplayName)
+}
+
+func TestDiscover_IssuerMismatch(t *testing.T) {
+	provider := newTestProvider(t)
+	provider.issuer = "https://elsewhere.example.com"
+	_, err := discover(context.Background(), provider.URL)
+	assert.ErrorContains(t, err, "names issuer")
+}
+
+func TestRefresh(t *testing.T) {
+	provider := newTestProvider(t)
+	resp, err := (&OIDCIdentityPlugin{}).Refresh(context.Background(), &identity_proto.RefreshRequest{
+		RefreshToken: "refresh-user",
+		Options:      map[string]string{"issuer": provider.URL, "client_id": "odc"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "access-refreshed", resp.Token.AccessToken)
+}
+
+func TestRefresh_ClientCredentials(t *testing.T) {
+	provider := newTestProvider(t)
+	p := &OIDCIdentityPlugin{}
+	options := map[string]string{"issuer": provider.URL, "client_id": "odc", "method": "client-secret", "client_secret": "s3cret"}
+
+	resp, err := p.Refresh(context.Background(), &identity_proto.RefreshRequest{Options: options})
+	require.NoError(t, err)
+	assert.Equal(t, "access-client", resp.Token.AccessToken, "the client credentials grant is repeated")
+	assert.Empty(t, resp.Token.RefreshToken)
+
+	delete(options, "client_secret")
+	_, err = p.Refresh(context.Background(), &identity_proto.RefreshRequest{Options: options})
+	assert.ErrorContains(t, err, "client_secret is required")
+}
+
+func TestLogout(t *testing.T) {
+	provider := newTestProvider(t)
+	p := &OIDCIdentityPlugin{}
+	options := map[string]string{"issuer": provider.URL, "client_id": "odc", "refresh_token": "refresh-user", "access_token": "access-user"}
+
+	resp, err := p.Logout(context.Background(), &identity_proto.LogoutRequest{Options: options})
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.Equal(t, []string{"refresh_token:refresh-user"}, provider.revoked)
+
+	provider.noRevoke = true
+	resp, err = (&OIDCIdentityPlugin{}).Logout(context.Background(), &identity_proto.LogoutRequest{Options: options})
+	require.NoError(t, err)
+	assert.False(t, resp.Success, "nothing is revoked without a revocation endpoint")
+}
+
+func TestDiscover_CachedPerIssuer(t *testing.T) {
+	provider := newTestProvider(t)
+	p := &OIDCIdentityPlugin{}
+	options := map[string]string{"issuer": provider.URL, "client_id": "odc"}
+
+	for range 3 {
+		_, err := p.Refresh(context.Background(), &identity_proto.RefreshRequest{RefreshToken: "refresh-user", Options: options})
+		require.NoError(t, err)
+	}
+	options["issuer"] = provider.URL + "/"
+	options["refresh_token"] = "refresh-user"
+	_, err := p.Logout(context.Background(), &identity_proto.LogoutRequest{Options: options})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, provider.discoveries)
+}
+
+func TestAuthCode(t *testing.T) {
+	tests := []struct {
+		name    string
+		answer  string
+		want    string
+		wantErr string
+	}{
+		{"bare code", "4/0AbC", "4/0AbC", ""},
+		{"redirect address", "http://127.0.0.1/?code=abc&state=s1", "abc", ""},
+		{"state mismatch", "http://127.0.0.1/?code=abc&state=other", "", "state mismatch"},
+		{"provider error", "http://127.0.0.1/?error=access_denied&error_description=denied&state=s1", "", "access_denied"},
+		{"empty", "", "", "no authorization code"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := authCode(tt.answer, "s1")
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, code)
+		})
+	}
+}
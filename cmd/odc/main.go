@@ -3,6 +3,7 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"os"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/spf13/cobra"
+	"go.etcd.io/bbolt"
 
 	"github.com/michaeldcanady/go-onedrive/internal/core/di"
 	"github.com/michaeldcanady/go-onedrive/internal/core/logger"
@@ -22,7 +24,9 @@ import (
 	"github.com/michaeldcanady/go-onedrive/internal/features/identity"
 	"github.com/michaeldcanady/go-onedrive/internal/features/mount"
 	"github.com/michaeldcanady/go-onedrive/internal/features/profile"
+	"github.com/michaeldcanady/go-onedrive/internal/features/snapshot"
 	"github.com/michaeldcanady/go-onedrive/internal/features/storage"
+	"github.com/michaeldcanady/go-onedrive/internal/features/syncer"
 	"github.com/michaeldcanady/go-onedrive/internal/features/vfs"
 	"github.com/michaeldcanady/go-onedrive/pkg/format"
 
@@ -32,13 +36,20 @@ import (
 	drive_get_cmd "github.com/michaeldcanady/go-onedrive/internal/features/drive/cmd/drive/get"
 	drive_list_cmd "github.com/michaeldcanady/go-onedrive/internal/features/drive/cmd/drive/list"
 
+	agent_cmd "github.com/michaeldcanady/go-onedrive/internal/features/identity/cmd/agent"
 	identity_list_cmd "github.com/michaeldcanady/go-onedrive/internal/features/identity/cmd/identity/list"
 	identity_login_cmd "github.com/michaeldcanady/go-onedrive/internal/features/identity/cmd/identity/login"
 	identity_logout_cmd "github.com/michaeldcanady/go-onedrive/internal/features/identity/cmd/identity/logout"
+	identity_migrate_cmd "github.com/michaeldcanady/go-onedrive/internal/features/identity/cmd/identity/migrate"
 
 	mount_add_cmd "github.com/michaeldcanady/go-onedrive/internal/features/mount/cmd/mount/add"
 	mount_list_cmd "github.com/michaeldcanady/go-onedrive/internal/features/mount/cmd/mount/list"
 	mount_remove_cmd "github.com/michaeldcanady/go-onedrive/internal/features/mount/cmd/mount/remove"
+	plugin_info_cmd "github.com/michaeldcanady/go-onedrive/internal/features/plugins/cmd/plugin/info"
+	plugin_install_cmd "github.com/michaeldcanady/go-onedrive/internal/features/plugins/cmd/plugin/install"
+	plugin_list_cmd "github.com/michaeldcanady/go-onedrive/internal/features/plugins/cmd/plugin/list"
+	plugin_refresh_cmd "github.com/michaeldcanady/go-onedrive/internal/features/plugins/cmd/plugin/refresh"
+	plugin_remove_cmd "github.com/michaeldcanady/go-onedrive/internal/features/plugins/cmd/plugin/remove"
 
 	profile_create_cmd "github.com/michaeldcanady/go-onedrive/internal/features/profile/cmd/profile/create"
 	profile_current_cmd "github.com/michaeldcanady/go-onedrive/internal/features/profile/cmd/profile/current"
@@ -54,21 +65,35 @@ import (
 	mv_cmd "github.com/michaeldcanady/go-onedrive/internal/features/fs/cmd/mv"
 	rm_cmd "github.com/michaeldcanady/go-onedrive/internal/features/fs/cmd/rm"
 	stat_cmd "github.com/michaeldcanady/go-onedrive/internal/features/fs/cmd/stat"
+	sync_cmd "github.com/michaeldcanady/go-onedrive/internal/features/fs/cmd/sync"
 	touch_cmd "github.com/michaeldcanady/go-onedrive/internal/features/fs/cmd/touch"
 	upload_cmd "github.com/michaeldcanady/go-onedrive/internal/features/fs/cmd/upload"
 
 	edit_cmd "github.com/michaeldcanady/go-onedrive/internal/features/editor/cmd/edit"
+
+	fuse_cmd "github.com/michaeldcanady/go-onedrive/internal/features/fusefs/cmd/fuse"
+	serve_s3_cmd "github.com/michaeldcanady/go-onedrive/internal/features/serve/cmd/serve/s3"
+	serve_webdav_cmd "github.com/michaeldcanady/go-onedrive/internal/features/serve/cmd/serve/webdav"
 )
 
 var (
-	pluginsDir string
-	rootCmd    = &cobra.Command{
+	pluginsDir       string
+	noCache          bool
+	skipPluginVerify bool
+	rootCmd          = &cobra.Command{
 		Use:     "odc",
 		Short:   "OneDrive CLI",
 		Version: "0.1.0-dev",
 		PersistentPreRun: func(cmd *cobra.Command, args []string) {
 			requestID := uuid.New().String()
 			ctx := logger.WithRequestID(cmd.Context(), requestID)
+			if noCache {
+				ctx = vfs.WithoutCache(ctx)
+			}
+			if skipPluginVerify {
+				container.Logger().Warn("plugin checksum and signature verification is disabled")
+				ctx = plugins.WithoutVerification(ctx)
+			}
 			cmd.SetContext(ctx)
 		},
 	}
@@ -89,6 +114,8 @@ func main() {
 	}()
 
 	rootCmd.PersistentFlags().StringVar(&pluginsDir, "plugins-dir", "", "Path to the plugins directory (default: ~/.config/odc/plugins)")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Bypass cached file metadata and query storage directly")
+	rootCmd.PersistentFlags().BoolVar(&skipPluginVerify, "skip-plugin-verification", false, "Load plugins whose checksum or signature does not verify")
 
 	if err := bootstrap(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
@@ -152,13 +179,18 @@ func bootstrap() error {
 		}
 	}
 
+	registerBuiltinPlugins()
 	pm := plugins.NewPluginManager(configService, l, pluginRepo)
 
-	identityRepo, err := identity.NewBoltRepository(s.DB())
+	identityRepo, err := newIdentityRepository(configService, s.DB())
 	if err != nil {
 		return err
 	}
-	ts := identity.NewTokenService(identityRepo, pm, configService, l)
+	agentSocket, err := identity.DefaultAgentSocket()
+	if err != nil {
+		return err
+	}
+	ts := identity.NewAgentClient(agentSocket, identity.NewTokenService(identityRepo, pm, configService, l), l)
 	is := identity.NewIdentityService(identityRepo, pm, ts, l)
 
 	// Phase 3: VFS and Mounts
@@ -167,8 +199,26 @@ func bootstrap() error {
 		return err
 	}
 	ms := mount.NewMountService(mountRepo, l)
-	v := vfs.NewOrchestrator(ms, pm, ts, is, l)
-	v = vfs.LoggingMiddleware(l)(v)
+	cache, err := newMetadataCache(configService, ms, s.DB(), l)
+	if err != nil {
+		return err
+	}
+	v := newVFS(ms, pm, ts, is, cache, l)
+
+	// Long-running commands release state.db and cache metadata in memory.
+	sn := snapshot.NewService(s, configService, pm,
+		func(db *bbolt.DB) (identity.Repository, error) {
+			return newIdentityRepository(configService, db)
+		},
+		func(ms mount.Service, pm plugins.Manager, ts identity.TokenService, is identity.Service) (vfs.VFS, error) {
+			cache, err := newMetadataCache(configService, ms, nil, l)
+			if err != nil {
+				return nil, err
+			}
+			return newVFS(ms, pm, ts, is, cache, l), nil
+		},
+		l,
+	)
 
 	// Phase 4: Drive and Editor
 	driveRepo, err := drive.NewBoltRepository(s.DB())
@@ -188,13 +238,116 @@ func bootstrap() error {
 	f := format.NewFactory()
 	r := resolver.NewResolverService(v, is, ds, configService)
 
-	container = di.NewContainer(l, s, configService, profileService, pm, ts, is, ms, v, ds, es, f, r)
+	// Phase 6: Synchronization
+	syncRepo, err := syncer.NewBoltRepository(s.DB())
+	if err != nil {
+		return err
+	}
+	sy := syncer.NewSyncService(v, syncRepo, l)
+
+	container = di.NewContainer(l, s, configService, profileService, pm, ts, is, ms, v, ds, es, f, r, sy, sn)
 
 	registerCommands(container)
 
 	return nil
 }
 
+// newVFS stacks the VFS middleware on an orchestrator of the mounts of ms. cache is nil
+// when caching is disabled.
+func newVFS(ms mount.Service, pm plugins.Manager, ts identity.TokenService, is identity.Service, cache vfs.Middleware, l logger.Service) vfs.VFS {
+	v := vfs.NewOrchestrator(ms, pm, ts, is, l)
+	v = vfs.RetryMiddleware(ms, vfs.DefaultRetryPolicy, l)(v)
+	if cache != nil {
+		v = cache(v)
+	}
+	return vfs.LoggingMiddleware(l)(v)
+}
+
+// newMetadataCache builds the VFS caching middleware for the mounts of ms from
+// configuration. It returns nil when caching is disabled by a zero TTL. A nil db selects
+// the memory store whatever the configuration says, for commands that do not keep
+// state.db open.
+func newMetadataCache(c config.Service, ms mount.Service, db *bbolt.DB, l logger.Service) (vfs.Middleware, error) {
+	ttl := vfs.DefaultCacheTTL
+	if val, err := c.Get(config.KeyCoreCacheTTL); err == nil && val != nil {
+		parsed, err := time.ParseDuration(fmt.Sprintf("%v", val))
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", config.KeyCoreCacheTTL, err)
+		}
+		ttl = parsed
+	}
+	if ttl <= 0 {
+		return nil, nil
+	}
+
+	store := "memory"
+	if val, err := c.Get(config.KeyCoreCacheStore); err == nil && val != nil {
+		store = fmt.Sprintf("%v", val)
+	}
+	if db == nil && store == "bolt" {
+		store = "memory"
+	}
+
+	switch store {
+	case "memory":
+		return vfs.CachingMiddleware(ms, vfs.NewMemoryCache(), ttl, l), nil
+	case "bolt":
+		cache, err := vfs.NewBoltCache(db)
+		if err != nil {
+			return nil, err
+		}
+		return vfs.CachingMiddleware(ms, cache, ttl, l), nil
+	default:
+		return nil, fmt.Errorf("invalid %s: unknown store %q", config.KeyCoreCacheStore, store)
+	}
+}
+
+// newIdentityRepository returns the identity repository in db, keeping tokens in the
+// store the configuration selects.
+func newIdentityRepository(c config.Service, db *bbolt.DB) (identity.Repository, error) {
+	tokenStore, err := newTokenStore(c, db)
+	if err != nil {
+		return nil, err
+	}
+	return identity.NewBoltRepositoryWithTokenStore(db, tokenStore)
+}
+
+// tokenPassphraseEnv supplies the passphrase for the encrypted token store.
+const tokenPassphraseEnv = "ODC_TOKEN_PASSPHRASE"
+
+// newTokenStore builds the store tokens are kept in from configuration. It returns nil
+// for the default plaintext store.
+func newTokenStore(c config.Service, db *bbolt.DB) (identity.TokenStore, error) {
+	store := "plain"
+	if val, err := c.Get(config.KeyIdentityTokenStore); err == nil && val != nil {
+		store = fmt.Sprintf("%v", val)
+	}
+
+	switch store {
+	case "plain":
+		return nil, nil
+	case "encrypted":
+		secret := []byte(os.Getenv(tokenPassphraseEnv))
+		if len(secret) == 0 {
+			if val, err := c.Get(config.KeyIdentityTokenKeyFile); err == nil && val != nil && fmt.Sprintf("%v", val) != "" {
+				data, err := os.ReadFile(fmt.Sprintf("%v", val))
+				if err != nil {
+					return nil, fmt.Errorf("failed to read %s: %w", config.KeyIdentityTokenKeyFile, err)
+				}
+				secret = bytes.TrimSpace(data)
+			}
+		}
+		if len(secret) == 0 {
+			return nil, fmt.Errorf("the encrypted token store needs a passphrase: set $%s or %s", tokenPassphraseEnv, config.KeyIdentityTokenKeyFile)
+		}
+		return identity.NewEncryptedTokenStore(db, secret)
+	case "keyring":
+		return identity.NewKeyringTokenStore()
+	default:
+		return nil, fmt.Errorf("invalid %s: unknown store %q", config.KeyIdentityTokenStore, store)
+	}
+}
+
 func registerCommands(c di.Container) {
 	// Config
 	configCmd := &cobra.Command{Use: "config", Short: "Manage configuration"}
@@ -216,7 +369,9 @@ func registerCommands(c di.Container) {
 	identityCmd.AddCommand(identity_login_cmd.CreateLoginCmd(c))
 	identityCmd.AddCommand(identity_logout_cmd.CreateLogoutCmd(c))
 	identityCmd.AddCommand(identity_list_cmd.CreateListCmd(c))
+	identityCmd.AddCommand(identity_migrate_cmd.CreateMigrateCmd(c))
 	rootCmd.AddCommand(identityCmd)
+	rootCmd.AddCommand(agent_cmd.CreateAgentCmd(c))
 
 	// Mount
 	mountCmd := &cobra.Command{Use: "mount", Short: "Manage mount points"}
@@ -225,6 +380,15 @@ func registerCommands(c di.Container) {
 	mountCmd.AddCommand(mount_remove_cmd.CreateRemoveCmd(c))
 	rootCmd.AddCommand(mountCmd)
 
+	// Plugin
+	pluginCmd := &cobra.Command{Use: "plugin", Short: "Manage storage and identity plugins"}
+	pluginCmd.AddCommand(plugin_list_cmd.CreateListCmd(c))
+	pluginCmd.AddCommand(plugin_info_cmd.CreateInfoCmd(c))
+	pluginCmd.AddCommand(plugin_install_cmd.CreateInstallCmd(c))
+	pluginCmd.AddCommand(plugin_remove_cmd.CreateRemoveCmd(c))
+	pluginCmd.AddCommand(plugin_refresh_cmd.CreateRefreshCmd(c))
+	rootCmd.AddCommand(pluginCmd)
+
 	// Drive
 	driveCmd := &cobra.Command{Use: "drive", Short: "Manage storage drives"}
 	driveCmd.AddCommand(drive_get_cmd.CreateGetCmd(c))
@@ -240,9 +404,19 @@ func registerCommands(c di.Container) {
 	rootCmd.AddCommand(mv_cmd.CreateMvCmd(c))
 	rootCmd.AddCommand(rm_cmd.CreateRmCmd(c))
 	rootCmd.AddCommand(stat_cmd.CreateStatCmd(c))
+	rootCmd.AddCommand(sync_cmd.CreateSyncCmd(c))
 	rootCmd.AddCommand(touch_cmd.CreateTouchCmd(c))
 	rootCmd.AddCommand(upload_cmd.CreateUploadCmd(c))
 
 	// Editor
 	rootCmd.AddCommand(edit_cmd.CreateEditCmd(c))
+
+	// FUSE
+	rootCmd.AddCommand(fuse_cmd.CreateFuseCmd(c))
+
+	// Serve
+	serveCmd := &cobra.Command{Use: "serve", Short: "Serve the virtual file system over a network protocol"}
+	serveCmd.AddCommand(serve_s3_cmd.CreateS3Cmd(c))
+	serveCmd.AddCommand(serve_webdav_cmd.CreateWebdavCmd(c))
+	rootCmd.AddCommand(serveCmd)
 }
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"github.com/michaeldcanady/go-onedrive/internal/core/plugins"
+	"github.com/michaeldcanady/go-onedrive/internal/features/plugins/storage/googledrive"
+	"github.com/michaeldcanady/go-onedrive/internal/features/plugins/storage/local"
+	"github.com/michaeldcanady/go-onedrive/internal/features/plugins/storage/onedrive"
+	"github.com/michaeldcanady/go-onedrive/pkg/pluginsdk"
+)
+
+// registerBuiltinPlugins registers the storage plugins that ship with odc, so they are
+// served in-process instead of being launched from the plugin directory.
+func registerBuiltinPlugins() {
+	plugins.RegisterStorage("storage-local", local.NewStoragePlugin(),
+		plugins.WithGRPCServer(plugins.CustomGRPCServerWithErrors(pluginsdk.ToStatus)))
+	plugins.RegisterStorage("storage-onedrive", onedrive.NewStoragePlugin(),
+		plugins.WithGRPCServer(plugins.CustomGRPCServerWithErrors(onedrive.ToStatus)))
+	plugins.RegisterStorage("storage-googledrive", googledrive.NewStoragePlugin(),
+		plugins.WithGRPCServer(plugins.CustomGRPCServerWithErrors(googledrive.ToStatus)))
+}
@@ -25,6 +25,7 @@ type Dependency struct {
 var dependencyRegistry = map[string]Dependency{
 	"Logger":        {"Logger", "logger.Service", "github.com/michaeldcanady/go-onedrive/internal/core/logger", ""},
 	"DB":            {"DB", "*persistence.DB", "github.com/michaeldcanady/go-onedrive/pkg/persistence", ""},
+	"Storage":       {"Storage", "storage.Service[storage.BoltDB]", "github.com/michaeldcanady/go-onedrive/internal/features/storage", ""},
 	"PluginManager": {"PluginManager", "plugins.Manager", "github.com/michaeldcanady/go-onedrive/internal/core/plugins", ""},
 	"VFS":           {"VFS", "vfs.VFS", "github.com/michaeldcanady/go-onedrive/internal/features/vfs", ""},
 	"Formatter":     {"Formatter", "format.Factory", "github.com/michaeldcanady/go-onedrive/pkg/format", ""},
@@ -38,6 +39,8 @@ var dependencyRegistry = map[string]Dependency{
 	"FS":            {"VFS", "vfs.VFS", "github.com/michaeldcanady/go-onedrive/internal/features/vfs", ""},
 	"Editor":        {"Editor", "editor.Service", "github.com/michaeldcanady/go-onedrive/internal/features/editor", ""},
 	"Resolver":      {"Resolver", "resolver.Service", "github.com/michaeldcanady/go-onedrive/internal/core/resolver", ""},
+	"Sync":          {"Sync", "syncer.Service", "github.com/michaeldcanady/go-onedrive/internal/features/syncer", ""},
+	"Snapshot":      {"Snapshot", "snapshot.Service", "github.com/michaeldcanady/go-onedrive/internal/features/snapshot", ""},
 }
 
 type Spec struct {
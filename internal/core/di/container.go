@@ -14,7 +14,9 @@ import (
 	"github.com/michaeldcanady/go-onedrive/internal/features/identity"
 	"github.com/michaeldcanady/go-onedrive/internal/features/mount"
 	"github.com/michaeldcanady/go-onedrive/internal/features/profile"
+	"github.com/michaeldcanady/go-onedrive/internal/features/snapshot"
 	"github.com/michaeldcanady/go-onedrive/internal/features/storage"
+	"github.com/michaeldcanady/go-onedrive/internal/features/syncer"
 	"github.com/michaeldcanady/go-onedrive/internal/features/vfs"
 	"github.com/michaeldcanady/go-onedrive/pkg/format"
 )
@@ -36,6 +38,8 @@ type Container interface {
 	Mounts() mount.Service
 	Editor() editor.Service
 	Resolver() resolver.Service
+	Sync() syncer.Service
+	Snapshot() snapshot.Service
 
 	Shutdown(ctx context.Context) error
 }
@@ -54,6 +58,8 @@ type container struct {
 	editor        editor.Service
 	formatter     format.Factory
 	resolver      resolver.Service
+	sync          syncer.Service
+	snapshot      snapshot.Service
 
 	services []any
 }
@@ -73,8 +79,10 @@ func NewContainer(
 	e editor.Service,
 	f format.Factory,
 	r resolver.Service,
+	sy syncer.Service,
+	sn snapshot.Service,
 ) Container {
-	services := []any{l, s, c, p, pm, ts, is, ms, v, d, e, f, r}
+	services := []any{l, s, c, p, pm, ts, is, ms, v, d, e, f, r, sy, sn}
 
 	return &container{
 		logger:        l,
@@ -90,6 +98,8 @@ func NewContainer(
 		editor:        e,
 		formatter:     f,
 		resolver:      r,
+		sync:          sy,
+		snapshot:      sn,
 		services:      services,
 	}
 }
@@ -108,6 +118,8 @@ func (c *container) Token() identity.TokenService   { return c.tokenService }
 func (c *container) Mounts() mount.Service          { return c.mounts }
 func (c *container) Editor() editor.Service         { return c.editor }
 func (c *container) Resolver() resolver.Service     { return c.resolver }
+func (c *container) Sync() syncer.Service           { return c.sync }
+func (c *container) Snapshot() snapshot.Service     { return c.snapshot }
 
 func (c *container) Shutdown(ctx context.Context) error {
 	var errs []error
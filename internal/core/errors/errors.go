@@ -4,6 +4,7 @@ package errors
 import (
 	"errors"
 	"fmt"
+	"time"
 )
 
 // Common error variables used for consistent error reporting across domain boundaries.
@@ -16,9 +17,37 @@ var (
 	ErrInvalidInput     = errors.New("invalid input")
 	ErrInvalidPath      = errors.New("invalid path")
 	ErrNotEmpty         = errors.New("not empty")
+	ErrIsADirectory     = errors.New("is a directory")
 	ErrUnavailable      = errors.New("unavailable")
+	ErrThrottled        = errors.New("throttled")
+	ErrCursorExpired    = errors.New("cursor expired")
+	ErrPrecondition     = errors.New("precondition failed")
 )
 
+// RetryAfterError annotates a transient error with the delay the backend asked callers
+// to wait before trying again.
+type RetryAfterError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *RetryAfterError) Error() string {
+	return fmt.Sprintf("%v (retry after %s)", e.Err, e.RetryAfter)
+}
+
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
+
+// RetryAfter returns the retry delay suggested anywhere in the error chain.
+func RetryAfter(err error) (time.Duration, bool) {
+	var e *RetryAfterError
+	if errors.As(err, &e) {
+		return e.RetryAfter, true
+	}
+	return 0, false
+}
+
 // Wrap returns an error with the provided message prepended to the original error's message.
 // It returns nil if the provided error is nil.
 func Wrap(err error, message string) error {
@@ -0,0 +1,17 @@
+// Package loggertest provides a [logger.Service] for tests.
+package loggertest
+
+import "github.com/michaeldcanady/go-onedrive/internal/core/logger"
+
+// Nop is a [logger.Service] that discards every entry.
+type Nop struct{}
+
+func (Nop) Debug(msg string, fields ...any)     {}
+func (Nop) Info(msg string, fields ...any)      {}
+func (Nop) Warn(msg string, fields ...any)      {}
+func (Nop) Error(msg string, fields ...any)     {}
+func (Nop) Fatal(msg string, fields ...any)     {}
+func (l Nop) With(fields ...any) logger.Service { return l }
+func (Nop) Sync() error                         { return nil }
+func (Nop) SetLevel(level string) error         { return nil }
+func (Nop) GetLevel() string                    { return "info" }
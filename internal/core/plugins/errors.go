@@ -2,9 +2,12 @@ package plugins
 
 import (
 	"strings"
+	"time"
 
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 
 	"github.com/michaeldcanady/go-onedrive/internal/core/errors"
 )
@@ -33,14 +36,48 @@ func FromGRPC(err error) error {
 	case codes.Internal:
 		return errors.ErrInternal
 	case codes.Unavailable:
-		return errors.ErrUnavailable
+		return withRetryAfter(st, errors.ErrUnavailable)
+	case codes.ResourceExhausted:
+		return withRetryAfter(st, errors.ErrThrottled)
+	case codes.Aborted:
+		// Conditional requests, such as writes with an if_match option, that lost a race
+		return errors.ErrPrecondition
 	case codes.FailedPrecondition:
 		// Often used for directory not empty or similar
 		if strings.Contains(st.Message(), "not empty") {
 			return errors.ErrNotEmpty
 		}
+		if strings.Contains(st.Message(), "cursor expired") {
+			return errors.ErrCursorExpired
+		}
+		if strings.Contains(st.Message(), "is a directory") {
+			return errors.ErrIsADirectory
+		}
 		return err
 	default:
 		return err
 	}
 }
+
+// withRetryAfter attaches the RetryInfo detail of st, if any, to err.
+func withRetryAfter(st *status.Status, err error) error {
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.RetryInfo); ok && info.GetRetryDelay() != nil {
+			return &errors.RetryAfterError{Err: err, RetryAfter: info.GetRetryDelay().AsDuration()}
+		}
+	}
+	return err
+}
+
+// RetryableError returns a gRPC status error telling the host the request may be retried.
+// Plugins use [codes.ResourceExhausted] when throttled and [codes.Unavailable] when the
+// backend is temporarily down. A positive retryAfter is sent as a RetryInfo detail.
+func RetryableError(code codes.Code, msg string, retryAfter time.Duration) error {
+	st := status.New(code, msg)
+	if retryAfter > 0 {
+		if detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)}); err == nil {
+			st = detailed
+		}
+	}
+	return st.Err()
+}
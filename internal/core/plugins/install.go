@@ -0,0 +1,251 @@
+package plugins
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/michaeldcanady/go-onedrive/internal/core/logger"
+)
+
+func (m *pluginManager) Install(ctx context.Context, source string) (*Metadata, error) {
+	l := logger.WithContext(m.logger, ctx)
+
+	pluginDir := m.getPluginDir()
+	if err := os.MkdirAll(pluginDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create plugin directory: %w", err)
+	}
+
+	// Stage the binary under a hidden name so that a plugin which fails verification
+	// never replaces a working one.
+	staged, err := os.CreateTemp(pluginDir, ".install-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to stage plugin: %w", err)
+	}
+	stagedName := filepath.Base(staged.Name())
+	defer os.Remove(staged.Name())
+
+	name, sig, err := copyPluginBinary(staged, source)
+	if cerr := staged.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(name, ".") || name == "" {
+		return nil, fmt.Errorf("invalid plugin name %q", name)
+	}
+	if m.registry.Has(name) {
+		return nil, fmt.Errorf("plugin %q is built into odc and cannot be replaced", name)
+	}
+	if err := os.Chmod(staged.Name(), 0755); err != nil {
+		return nil, err
+	}
+
+	var signature string
+	if sig != nil {
+		if signature, err = decodeSignature(sig); err != nil {
+			return nil, fmt.Errorf("%s: %w", source, err)
+		}
+	}
+	keys, err := m.trustedKeys()
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) > 0 {
+		if signature == "" {
+			return nil, fmt.Errorf("%s: %w: no signature found", source, ErrPluginUnsigned)
+		}
+		if err := verifyFile(staged.Name(), signature, keys); err != nil {
+			return nil, fmt.Errorf("%s: %w", source, err)
+		}
+	}
+
+	// The staged binary was verified above; nothing is recorded for it to be checked against.
+	meta, err := m.probeMetadata(WithoutVerification(ctx), stagedName)
+	m.stopPlugin(stagedName)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a valid odc plugin: %w", source, err)
+	}
+	meta.PluginPath = name
+	meta.Signature = signature
+
+	// The old binary may still be running; stop it before it is replaced.
+	m.stopPlugin(name)
+	if err := os.Rename(staged.Name(), filepath.Join(pluginDir, name)); err != nil {
+		return nil, fmt.Errorf("failed to install plugin: %w", err)
+	}
+	if err := m.repo.Set(name, meta); err != nil {
+		return nil, fmt.Errorf("failed to record plugin metadata: %w", err)
+	}
+
+	l.Debug("installed plugin", "name", name, "version", meta.Version, "checksum", meta.Checksum)
+	return meta, nil
+}
+
+func (m *pluginManager) Remove(ctx context.Context, name string) error {
+	if name == "" || name != filepath.Base(name) {
+		return fmt.Errorf("invalid plugin name %q", name)
+	}
+	if m.registry.Has(name) {
+		return fmt.Errorf("plugin %q is built into odc and cannot be removed", name)
+	}
+
+	cached, _ := m.repo.Get(name)
+	m.stopPlugin(name)
+
+	err := os.Remove(filepath.Join(m.getPluginDir(), name))
+	if errors.Is(err, os.ErrNotExist) && cached == nil {
+		return fmt.Errorf("plugin %q not found", name)
+	}
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove plugin: %w", err)
+	}
+
+	if err := m.repo.Delete(name); err != nil {
+		return fmt.Errorf("failed to remove plugin metadata: %w", err)
+	}
+	logger.WithContext(m.logger, ctx).Debug("removed plugin", "name", name)
+	return nil
+}
+
+func (m *pluginManager) Refresh(ctx context.Context) (*RefreshResult, error) {
+	l := logger.WithContext(m.logger, ctx)
+
+	cached, err := m.repo.List()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RefreshResult{}
+	for _, meta := range cached {
+		name := meta.PluginPath
+		checksum, err := fileChecksum(filepath.Join(m.getPluginDir(), name))
+		if errors.Is(err, os.ErrNotExist) {
+			if err := m.repo.Delete(name); err != nil {
+				return result, err
+			}
+			result.Removed = append(result.Removed, name)
+			continue
+		}
+		if err != nil {
+			return result, err
+		}
+
+		if checksum != meta.Checksum && !verificationDisabled(ctx) {
+			l.Warn("plugin binary does not match its recorded checksum", "name", name)
+			result.Modified = append(result.Modified, name)
+			continue
+		}
+		if checksum != meta.Checksum {
+			m.stopPlugin(name)
+			fresh, err := m.probeMetadata(ctx, name)
+			if err != nil {
+				// Leave nothing behind that would hide the broken plugin.
+				l.Warn("plugin no longer answers GetMetadata", "name", name, "error", err)
+				if err := m.repo.Delete(name); err != nil {
+					return result, err
+				}
+				result.Removed = append(result.Removed, name)
+				continue
+			}
+			if err := m.repo.Set(name, fresh); err != nil {
+				return result, err
+			}
+			meta = fresh
+			result.Updated = append(result.Updated, name)
+		}
+		result.Plugins = append(result.Plugins, meta)
+	}
+	return result, nil
+}
+
+// copyPluginBinary writes the plugin at source to dst and returns the plugin's name and
+// its detached signature, if one is supplied. source is either the binary itself, with an
+// optional signature in source.sig, or a gzip-compressed tarball holding exactly one
+// executable and optionally <executable>.sig.
+func copyPluginBinary(dst io.Writer, source string) (string, []byte, error) {
+	f, err := os.Open(source)
+	if err != nil {
+		return "", nil, err
+	}
+	defer f.Close()
+
+	if !strings.HasSuffix(source, ".tar.gz") && !strings.HasSuffix(source, ".tgz") {
+		if _, err := io.Copy(dst, f); err != nil {
+			return "", nil, err
+		}
+		sig, err := os.ReadFile(source + ".sig")
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return "", nil, err
+		}
+		return filepath.Base(source), sig, nil
+	}
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read %s: %w", source, err)
+	}
+	defer gz.Close()
+
+	var name string
+	sigs := make(map[string][]byte)
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to read %s: %w", source, err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if strings.HasSuffix(hdr.Name, ".sig") {
+			data, err := io.ReadAll(io.LimitReader(tr, 1<<10))
+			if err != nil {
+				return "", nil, err
+			}
+			sigs[filepath.Base(hdr.Name)] = data
+			continue
+		}
+		if hdr.FileInfo().Mode()&0111 == 0 {
+			continue
+		}
+		if name != "" {
+			return "", nil, fmt.Errorf("%s contains more than one executable", source)
+		}
+		name = filepath.Base(hdr.Name)
+		if _, err := io.Copy(dst, tr); err != nil {
+			return "", nil, err
+		}
+	}
+	if name == "" {
+		return "", nil, fmt.Errorf("%s does not contain an executable", source)
+	}
+	return name, sigs[name+".sig"], nil
+}
+
+// fileChecksum returns the hex-encoded SHA-256 digest of the file at path.
+func fileChecksum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,172 @@
+package plugins
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+
+	"github.com/michaeldcanady/go-onedrive/internal/core/logger/loggertest"
+	"github.com/michaeldcanady/go-onedrive/internal/features/config"
+)
+
+// staticConfig is a [config.Service] backed by a map.
+type staticConfig map[string]any
+
+func (c staticConfig) Get(key string) (any, error)        { return c[key], nil }
+func (c staticConfig) Set(key string, value string) error { c[key] = value; return nil }
+func (c staticConfig) All() (map[string]any, error)       { return c, nil }
+
+func newTestManager(t *testing.T) (*pluginManager, string) {
+	t.Helper()
+	dir := t.TempDir()
+	pluginDir := filepath.Join(dir, "plugins")
+	require.NoError(t, os.MkdirAll(pluginDir, 0755))
+
+	db, err := bbolt.Open(filepath.Join(dir, "odc.db"), 0600, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	repo, err := NewBoltRepository(db)
+	require.NoError(t, err)
+
+	cfg := staticConfig{config.KeyCorePluginsDir: pluginDir, config.KeyCoreLogDir: filepath.Join(dir, "logs")}
+	return NewPluginManager(cfg, loggertest.Nop{}, repo).(*pluginManager), pluginDir
+}
+
+func writeTarball(t *testing.T, files map[string]os.FileMode) string {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, mode := range files {
+		body := []byte("content of " + name)
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: int64(mode), Size: int64(len(body)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write(body)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+
+	path := filepath.Join(t.TempDir(), "plugin.tar.gz")
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0644))
+	return path
+}
+
+func TestCopyPluginBinary(t *testing.T) {
+	t.Run("binary", func(t *testing.T) {
+		src := filepath.Join(t.TempDir(), "storage-test")
+		require.NoError(t, os.WriteFile(src, []byte("binary"), 0755))
+
+		var dst bytes.Buffer
+		name, sig, err := copyPluginBinary(&dst, src)
+		require.NoError(t, err)
+		assert.Equal(t, "storage-test", name)
+		assert.Equal(t, "binary", dst.String())
+		assert.Nil(t, sig)
+	})
+
+	t.Run("binary with signature", func(t *testing.T) {
+		src := filepath.Join(t.TempDir(), "storage-test")
+		require.NoError(t, os.WriteFile(src, []byte("binary"), 0755))
+		require.NoError(t, os.WriteFile(src+".sig", []byte("signature"), 0644))
+
+		_, sig, err := copyPluginBinary(&bytes.Buffer{}, src)
+		require.NoError(t, err)
+		assert.Equal(t, "signature", string(sig))
+	})
+
+	t.Run("tarball", func(t *testing.T) {
+		src := writeTarball(t, map[string]os.FileMode{"dist/storage-test": 0755, "dist/README.md": 0644, "dist/storage-test.sig": 0644})
+
+		var dst bytes.Buffer
+		name, sig, err := copyPluginBinary(&dst, src)
+		require.NoError(t, err)
+		assert.Equal(t, "storage-test", name)
+		assert.Equal(t, "content of dist/storage-test", dst.String())
+		assert.Equal(t, "content of dist/storage-test.sig", string(sig))
+	})
+
+	t.Run("tarball with several executables", func(t *testing.T) {
+		src := writeTarball(t, map[string]os.FileMode{"storage-a": 0755, "storage-b": 0755})
+		_, _, err := copyPluginBinary(&bytes.Buffer{}, src)
+		assert.ErrorContains(t, err, "more than one executable")
+	})
+
+	t.Run("tarball without executables", func(t *testing.T) {
+		src := writeTarball(t, map[string]os.FileMode{"README.md": 0644})
+		_, _, err := copyPluginBinary(&bytes.Buffer{}, src)
+		assert.ErrorContains(t, err, "does not contain an executable")
+	})
+}
+
+func TestPluginManager_Install_RejectsInvalidPlugin(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the plugin")
+	}
+	m, pluginDir := newTestManager(t)
+
+	src := filepath.Join(t.TempDir(), "storage-broken")
+	require.NoError(t, os.WriteFile(src, []byte("#!/bin/sh\nexit 1\n"), 0755))
+
+	_, err := m.Install(context.Background(), src)
+	assert.ErrorContains(t, err, "not a valid odc plugin")
+
+	entries, err := os.ReadDir(pluginDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "nothing is left in the plugin directory")
+	meta, err := m.repo.Get("storage-broken")
+	require.NoError(t, err)
+	assert.Nil(t, meta)
+}
+
+func TestPluginManager_Remove(t *testing.T) {
+	ctx := context.Background()
+	m, pluginDir := newTestManager(t)
+
+	require.NoError(t, os.WriteFile(filepath.Join(pluginDir, "storage-test"), []byte("binary"), 0755))
+	require.NoError(t, m.repo.Set("storage-test", &Metadata{Name: "test", PluginPath: "storage-test"}))
+
+	require.NoError(t, m.Remove(ctx, "storage-test"))
+	assert.NoFileExists(t, filepath.Join(pluginDir, "storage-test"))
+	meta, err := m.repo.Get("storage-test")
+	require.NoError(t, err)
+	assert.Nil(t, meta)
+
+	assert.ErrorContains(t, m.Remove(ctx, "storage-test"), "not found")
+	assert.ErrorContains(t, m.Remove(ctx, "../odc.db"), "invalid plugin name")
+}
+
+func TestPluginManager_Refresh(t *testing.T) {
+	m, pluginDir := newTestManager(t)
+
+	path := filepath.Join(pluginDir, "storage-current")
+	require.NoError(t, os.WriteFile(path, []byte("binary"), 0755))
+	checksum, err := fileChecksum(path)
+	require.NoError(t, err)
+
+	require.NoError(t, m.repo.Set("storage-current", &Metadata{Name: "current", PluginPath: "storage-current", Checksum: checksum}))
+	require.NoError(t, m.repo.Set("storage-gone", &Metadata{Name: "gone", PluginPath: "storage-gone", Checksum: "abc"}))
+	require.NoError(t, os.WriteFile(filepath.Join(pluginDir, "storage-modified"), []byte("tampered"), 0755))
+	require.NoError(t, m.repo.Set("storage-modified", &Metadata{Name: "modified", PluginPath: "storage-modified", Checksum: checksum}))
+
+	result, err := m.Refresh(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"storage-gone"}, result.Removed)
+	assert.Equal(t, []string{"storage-modified"}, result.Modified)
+	assert.Empty(t, result.Updated)
+	require.Len(t, result.Plugins, 1)
+	assert.Equal(t, "current", result.Plugins[0].Name)
+
+	cached, err := m.repo.List()
+	require.NoError(t, err)
+	assert.Len(t, cached, 2, "modified plugins keep their recorded checksum")
+}
@@ -2,16 +2,18 @@ package plugins
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/go-plugin"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/metadata"
 
 	"github.com/michaeldcanady/go-onedrive/internal/core/logger"
@@ -36,29 +38,51 @@ const (
 )
 
 type cachedPlugin struct {
+	name       string
+	key        string
 	client     *plugin.Client
 	rpcClient  plugin.ClientProtocol
 	grpcClient *grpc.ClientConn
 	service    any
 	cleanup    func()
+	// checkedAt is when the plugin last passed a health check, in Unix nanoseconds. It
+	// is read and written by concurrent callers without holding the manager's lock.
+	checkedAt atomic.Int64
 }
 
-type pluginManager struct {
-	config config.Service
-	logger logger.Service
-	repo   Repository
+// exited reports whether the plugin's process has exited. In-process plugins have no
+// process and never exit.
+func (p *cachedPlugin) exited() bool {
+	return p.client != nil && p.client.Exited()
+}
 
-	mu      sync.Mutex
-	plugins map[string]*cachedPlugin
+type pluginManager struct {
+	config   config.Service
+	logger   logger.Service
+	repo     Repository
+	registry *Registry
+
+	mu         sync.Mutex
+	plugins    map[string]*cachedPlugin
+	supervisor *supervisor
 }
 
 // NewPluginManager returns a new [Manager] initialized with the required dependencies.
+// It serves the plugins registered in [DefaultRegistry] in-process.
 func NewPluginManager(cs config.Service, l logger.Service, repo Repository) Manager {
+	return NewPluginManagerWithRegistry(cs, l, repo, DefaultRegistry)
+}
+
+// NewPluginManagerWithRegistry returns a new [Manager] that serves the plugins in r
+// in-process, in preference to binaries of the same name in the plugin directory.
+func NewPluginManagerWithRegistry(cs config.Service, l logger.Service, repo Repository, r *Registry) Manager {
 	return &pluginManager{
-		config:  cs,
-		logger:  l,
-		repo:    repo,
-		plugins: make(map[string]*cachedPlugin),
+		config:     cs,
+		logger:     l,
+		repo:       repo,
+		registry:   r,
+		plugins:    make(map[string]*cachedPlugin),
+		supervisor: newSupervisor(),
 	}
 }
 
@@ -70,39 +94,50 @@ func (m *pluginManager) GetIdentityPlugin(name string) (identity_proto.IdentityP
 	return &identityProxy{manager: m, name: name}, nil
 }
 
-func (m *pluginManager) getRawStoragePlugin(name string) (storage_proto.StorageServiceClient, error) {
-	raw, err := m.dispensePlugin(name, PluginTypeStorage)
+func (m *pluginManager) getRawStoragePlugin(ctx context.Context, name string) (storage_proto.StorageServiceClient, error) {
+	p, err := m.dispensePlugin(ctx, name, PluginTypeStorage)
 	if err != nil {
 		return nil, err
 	}
-	return raw.(storage_proto.StorageServiceClient), nil
+	return p.service.(storage_proto.StorageServiceClient), nil
 }
 
-func (m *pluginManager) getRawIdentityPlugin(name string) (identity_proto.IdentityPluginClient, error) {
-	raw, err := m.dispensePlugin(name, PluginTypeIdentity)
+func (m *pluginManager) getRawIdentityPlugin(ctx context.Context, name string) (identity_proto.IdentityPluginClient, error) {
+	p, err := m.dispensePlugin(ctx, name, PluginTypeIdentity)
 	if err != nil {
 		return nil, err
 	}
-	return raw.(identity_proto.IdentityPluginClient), nil
+	return p.service.(identity_proto.IdentityPluginClient), nil
 }
 
 func (m *pluginManager) ListPlugins(ctx context.Context) ([]*Metadata, error) {
 	l := logger.WithContext(m.logger, ctx)
 
+	var results []*Metadata
+	for _, name := range m.registry.Names() {
+		if meta := m.getPluginMetadata(ctx, name); meta != nil {
+			results = append(results, meta)
+		}
+	}
+
 	pluginDir := m.getPluginDir()
 	files, err := os.ReadDir(pluginDir)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return nil, nil
+			return results, nil
 		}
 		return nil, err
 	}
 
-	var results []*Metadata
 	for _, f := range files {
 		if f.IsDir() {
 			continue
 		}
+		// In-process plugins take precedence over binaries of the same name.
+		if m.registry.Has(f.Name()) {
+			l.Warn("ignoring plugin binary shadowed by a built-in plugin", "path", filepath.Join(pluginDir, f.Name()))
+			continue
+		}
 
 		path := filepath.Join(pluginDir, f.Name())
 		info, err := os.Stat(path)
@@ -111,53 +146,149 @@ func (m *pluginManager) ListPlugins(ctx context.Context) ([]*Metadata, error) {
 			continue
 		}
 
-		// Only attempt to load if it's an executable file
-		if info.Mode()&0111 == 0 {
+		// Only attempt to load if it's an executable file. Hidden files are installs in progress.
+		if info.Mode()&0111 == 0 || strings.HasPrefix(f.Name(), ".") {
 			continue
 		}
 
 		if meta := m.getPluginMetadata(ctx, f.Name()); meta != nil {
 			results = append(results, meta)
-			if err := m.repo.Set(f.Name(), meta); err != nil {
-				l.Warn("failed to cache plugin metadata", "path", f.Name(), "error", err)
-			}
+			m.cacheMetadata(ctx, f.Name(), meta)
 		}
 	}
 
 	return results, nil
 }
 
+func (m *pluginManager) GetMetadata(ctx context.Context, name string) (*Metadata, error) {
+	meta := m.getPluginMetadata(ctx, name)
+	if meta == nil {
+		return nil, fmt.Errorf("plugin %q not found", name)
+	}
+	if !meta.Builtin {
+		m.cacheMetadata(ctx, name, meta)
+	}
+	return meta, nil
+}
+
+// cacheMetadata updates the metadata recorded for an installed plugin, keeping the
+// checksum and signature recorded at install time. Nothing is recorded for plugins that
+// were not installed, so discovery never vouches for a binary.
+func (m *pluginManager) cacheMetadata(ctx context.Context, name string, meta *Metadata) {
+	l := logger.WithContext(m.logger, ctx)
+	recorded, err := m.repo.Get(name)
+	if err != nil || recorded == nil {
+		return
+	}
+	c := *meta
+	c.Checksum, c.Signature = recorded.Checksum, recorded.Signature
+	if err := m.repo.Set(name, &c); err != nil {
+		l.Warn("failed to cache plugin metadata", "path", name, "error", err)
+	}
+}
+
 func (m *pluginManager) getPluginMetadata(ctx context.Context, name string) *Metadata {
-	// Check cache
-	if meta, err := m.repo.Get(name); err == nil && meta != nil {
+	// In-process plugins are cheap to ask and may change with every build of odc.
+	if m.registry.Has(name) {
+		meta, err := m.probeMetadata(ctx, name)
+		if err != nil {
+			logger.WithContext(m.logger, ctx).Debug("failed to read plugin metadata", "path", name, "error", err)
+			return nil
+		}
 		return meta
 	}
 
-	// Try as storage first
-	if client, err := m.getRawStoragePlugin(name); err == nil {
-		if meta, err := client.GetMetadata(ctx, &storage_proto.MetadataRequest{}); err == nil {
+	// Check cache. Storage entries written before capability reporting are refreshed.
+	cached, err := m.repo.Get(name)
+	if err == nil && cached != nil {
+		if cached.Type != PluginTypeStorage || cached.Capabilities != nil {
+			return cached
+		}
+	}
+
+	meta, err := m.probeMetadata(ctx, name)
+	if err != nil {
+		logger.WithContext(m.logger, ctx).Debug("failed to read plugin metadata", "path", name, "error", err)
+		return nil
+	}
+	if cached != nil {
+		// The probe verified the binary against the recorded signature.
+		meta.Signature = cached.Signature
+	}
+	return meta
+}
+
+// probeMetadata launches the named plugin and asks it for its metadata, trying the
+// storage protocol before the identity protocol. The cache is not consulted.
+func (m *pluginManager) probeMetadata(ctx context.Context, name string) (*Metadata, error) {
+	builtin := m.registry.Has(name)
+	var checksum string
+	if !builtin {
+		sum, err := fileChecksum(filepath.Join(m.getPluginDir(), name))
+		if err != nil {
+			return nil, err
+		}
+		checksum = sum
+	}
+
+	// Try as storage first. A failed handshake fails for either protocol. In-process
+	// plugins are only asked over the protocols they registered.
+	var err error
+	if _, ok := m.registry.lookup(name, PluginTypeStorage); ok || !builtin {
+		client, cerr := m.getRawStoragePlugin(ctx, name)
+		if cerr != nil {
+			return nil, fmt.Errorf("plugin handshake failed: %w", cerr)
+		}
+		var meta *storage_proto.MetadataResponse
+		if meta, err = client.GetMetadata(ctx, &storage_proto.MetadataRequest{}); err == nil {
 			return &Metadata{
 				Name:               meta.Name,
 				Type:               meta.Type,
 				SupportedProviders: meta.SupportedProviders,
 				PluginPath:         name,
-			}
+				Version:            meta.Version,
+				Checksum:           checksum,
+				Builtin:            builtin,
+				Capabilities:       capabilitiesFromProto(meta.Capabilities),
+			}, nil
 		}
 	}
 
 	// Try as identity
-	if client, err := m.getRawIdentityPlugin(name); err == nil {
-		if meta, err := client.GetMetadata(ctx, &identity_proto.MetadataRequest{}); err == nil {
-			return &Metadata{
-				Name:               meta.Name,
-				Type:               meta.Type,
-				SupportedProviders: meta.SupportedProviders,
-				PluginPath:         name,
+	var idMeta *identity_proto.MetadataResponse
+	idClient, idErr := m.getRawIdentityPlugin(ctx, name)
+	if idErr == nil {
+		idMeta, idErr = idClient.GetMetadata(ctx, &identity_proto.MetadataRequest{})
+	}
+	if idErr == nil {
+		return &Metadata{
+			Name:               idMeta.Name,
+			Type:               idMeta.Type,
+			SupportedProviders: idMeta.SupportedProviders,
+			PluginPath:         name,
+			Version:            idMeta.Version,
+			Checksum:           checksum,
+			Builtin:            builtin,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("plugin does not serve a storage or identity service: %w", errors.Join(err, idErr))
+}
+
+// stopPlugin terminates any running processes of the named plugin.
+func (m *pluginManager) stopPlugin(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, pluginType := range []string{PluginTypeStorage, PluginTypeIdentity} {
+		cacheKey := fmt.Sprintf("%s:%s", name, pluginType)
+		if p, ok := m.plugins[cacheKey]; ok {
+			if p.cleanup != nil {
+				p.cleanup()
 			}
+			delete(m.plugins, cacheKey)
 		}
 	}
-
-	return nil
 }
 
 func (m *pluginManager) Shutdown(ctx context.Context) error {
@@ -173,24 +304,65 @@ func (m *pluginManager) Shutdown(ctx context.Context) error {
 	return nil
 }
 
-func (m *pluginManager) dispensePlugin(name, pluginType string) (any, error) {
+// dispensePlugin returns the running plugin process serving pluginType, launching one if
+// none is running or the running one fails its health check.
+func (m *pluginManager) dispensePlugin(ctx context.Context, name, pluginType string) (*cachedPlugin, error) {
+	cacheKey := fmt.Sprintf("%s:%s", name, pluginType)
+
+	m.mu.Lock()
+	p, ok := m.plugins[cacheKey]
+	m.mu.Unlock()
+	if ok {
+		if m.healthy(ctx, p) {
+			return p, nil
+		}
+		// The process crashed or hung while idle; replace it. Only the caller that
+		// evicts it counts the failure, however many saw it fail.
+		logger.WithContext(m.logger, ctx).Warn("restarting plugin", "name", name)
+		if m.evict(p) {
+			m.supervisor.failure(name)
+		}
+	}
+
+	if err := m.supervisor.admit(ctx, name); err != nil {
+		if errors.Is(err, ErrCircuitOpen) {
+			return nil, m.pluginError(name, fmt.Errorf("%s: %w", name, err))
+		}
+		return nil, err
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	cacheKey := fmt.Sprintf("%s:%s", name, pluginType)
+	// Another caller may have launched the plugin while this one waited.
 	if p, ok := m.plugins[cacheKey]; ok {
-		// Check if connection is still alive
-		if p.grpcClient != nil && p.grpcClient.GetState() != connectivity.Shutdown {
-			return p.service, nil
+		if !p.exited() {
+			return p, nil
 		}
-		// Connection lost or shutdown, cleanup and re-dispense
+		delete(m.plugins, cacheKey)
 		if p.cleanup != nil {
 			p.cleanup()
 		}
-		delete(m.plugins, cacheKey)
 	}
 
-	client, cleanup, err := m.getClient(name, pluginType)
+	if reg, ok := m.registry.lookup(name, pluginType); ok {
+		raw, conn, cleanup, err := reg.serve(ctx)
+		if err != nil {
+			return nil, err
+		}
+		p = &cachedPlugin{
+			name:       name,
+			key:        cacheKey,
+			grpcClient: conn,
+			service:    raw,
+			cleanup:    cleanup,
+		}
+		p.checkedAt.Store(m.supervisor.now().UnixNano())
+		m.plugins[cacheKey] = p
+		return p, nil
+	}
+
+	client, cleanup, err := m.getClient(ctx, name, pluginType)
 	if err != nil {
 		return nil, err
 	}
@@ -198,7 +370,11 @@ func (m *pluginManager) dispensePlugin(name, pluginType string) (any, error) {
 	rpcClient, err := client.Client()
 	if err != nil {
 		cleanup()
-		return nil, fmt.Errorf("failed to get rpc client: %w", err)
+		if errors.Is(err, plugin.ErrChecksumsDoNotMatch) {
+			return nil, fmt.Errorf("%s: %w; %s", name, ErrPluginModified, verifyHint)
+		}
+		m.supervisor.failure(name)
+		return nil, m.pluginError(name, fmt.Errorf("failed to get rpc client: %w", err))
 	}
 
 	// Get gRPC Client connection for health checks
@@ -210,18 +386,21 @@ func (m *pluginManager) dispensePlugin(name, pluginType string) (any, error) {
 		return nil, fmt.Errorf("failed to dispense %s plugin: %w", pluginType, err)
 	}
 
-	m.plugins[cacheKey] = &cachedPlugin{
+	p = &cachedPlugin{
+		name:       name,
+		key:        cacheKey,
 		client:     client,
 		rpcClient:  rpcClient,
 		grpcClient: grpcClient,
 		service:    raw,
 		cleanup:    cleanup,
 	}
-
-	return raw, nil
+	p.checkedAt.Store(m.supervisor.now().UnixNano())
+	m.plugins[cacheKey] = p
+	return p, nil
 }
 
-func (m *pluginManager) getClient(name string, pluginType string) (*plugin.Client, func(), error) {
+func (m *pluginManager) getClient(ctx context.Context, name string, pluginType string) (*plugin.Client, func(), error) {
 	pluginDir := m.getPluginDir()
 	logDir := m.getLogDir()
 
@@ -230,6 +409,11 @@ func (m *pluginManager) getClient(name string, pluginType string) (*plugin.Clien
 		return nil, nil, fmt.Errorf("plugin not found: %s", path)
 	}
 
+	secure, err := m.secureConfig(ctx, name, path)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	pluginMap := make(map[string]plugin.Plugin)
 	if pluginType == PluginTypeStorage {
 		pluginMap[PluginTypeStorage] = &StorageGRPCPlugin{}
@@ -244,16 +428,19 @@ func (m *pluginManager) getClient(name string, pluginType string) (*plugin.Clien
 		return nil, nil, fmt.Errorf("failed to create log directory: %w", err)
 	}
 
-	logFilePath := filepath.Join(logDir, fmt.Sprintf("plugin-%s.log", name))
-	logFile, err := os.OpenFile(logFilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	logFile, err := os.OpenFile(m.logPath(name), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to open plugin log file: %w", err)
 	}
+	if info, err := logFile.Stat(); err == nil {
+		m.supervisor.launched(name, info.Size())
+	}
 
 	client := plugin.NewClient(&plugin.ClientConfig{
 		HandshakeConfig: HandshakeConfig,
 		Plugins:         pluginMap,
 		Cmd:             cmd,
+		SecureConfig:    secure,
 		SyncStderr:      logFile,
 		Logger: hclog.New(&hclog.LoggerOptions{
 			Name:   "plugin",
@@ -299,6 +486,30 @@ func CustomGRPCServer(opts []grpc.ServerOption) *grpc.Server {
 	return grpc.NewServer(opts...)
 }
 
+// CustomGRPCServerWithErrors is like [CustomGRPCServer] but passes every error a handler
+// returns through mapErr, so plugins can translate backend errors into gRPC statuses in
+// one place.
+func CustomGRPCServerWithErrors(mapErr func(error) error) func([]grpc.ServerOption) *grpc.Server {
+	return func(opts []grpc.ServerOption) *grpc.Server {
+		opts = append(opts,
+			grpc.ChainUnaryInterceptor(requestIDServerUnaryInterceptor, func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+				resp, err := handler(ctx, req)
+				if err != nil {
+					return nil, mapErr(err)
+				}
+				return resp, nil
+			}),
+			grpc.ChainStreamInterceptor(requestIDServerStreamInterceptor, func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+				if err := handler(srv, ss); err != nil {
+					return mapErr(err)
+				}
+				return nil
+			}),
+		)
+		return grpc.NewServer(opts...)
+	}
+}
+
 func requestIDServerUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
 	return handler(extractRequestIDFromIncoming(ctx), req)
 }
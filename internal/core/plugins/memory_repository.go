@@ -0,0 +1,60 @@
+package plugins
+
+import (
+	"sort"
+	"sync"
+)
+
+type memoryRepository struct {
+	mu      sync.RWMutex
+	plugins map[string]*Metadata
+}
+
+// NewMemoryRepository returns a [Repository] that lives only as long as the process. The
+// agent uses it so it can verify plugins it relaunches without holding state.db open.
+func NewMemoryRepository() Repository {
+	return &memoryRepository{plugins: make(map[string]*Metadata)}
+}
+
+func (r *memoryRepository) Get(path string) (*Metadata, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	meta, ok := r.plugins[path]
+	if !ok {
+		return nil, nil
+	}
+	c := *meta
+	return &c, nil
+}
+
+func (r *memoryRepository) Set(path string, meta *Metadata) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c := *meta
+	r.plugins[path] = &c
+	return nil
+}
+
+func (r *memoryRepository) Delete(path string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.plugins, path)
+	return nil
+}
+
+func (r *memoryRepository) List() ([]*Metadata, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	paths := make([]string, 0, len(r.plugins))
+	for path := range r.plugins {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	results := make([]*Metadata, 0, len(paths))
+	for _, path := range paths {
+		c := *r.plugins[path]
+		results = append(results, &c)
+	}
+	return results, nil
+}
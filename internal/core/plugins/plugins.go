@@ -14,6 +14,48 @@ type Metadata struct {
 	Type               string
 	SupportedProviders []string
 	PluginPath         string
+	// Version is the version the plugin reports about itself.
+	Version string `json:",omitempty"`
+	// Checksum is the hex-encoded SHA-256 digest of the plugin binary. Plugins that
+	// no longer match it are refused.
+	Checksum string `json:",omitempty"`
+	// Signature is the base64-encoded detached ed25519 signature of the plugin binary
+	// supplied at install time, if any.
+	Signature string `json:",omitempty"`
+	// Builtin is set for plugins served in-process from a [Registry]. They have no
+	// binary, so no checksum or signature, and are never cached.
+	Builtin bool `json:",omitempty"`
+	// Capabilities is the optional behavior a storage plugin reports. It is nil for
+	// identity plugins and for storage plugins that predate capability reporting.
+	Capabilities *Capabilities `json:",omitempty"`
+}
+
+// Capabilities describes the optional operations a storage plugin implements natively.
+// The host uses it to pick a strategy, such as copying and deleting when Move is absent.
+type Capabilities struct {
+	Move            bool `json:"move" yaml:"move"`
+	Copy            bool `json:"copy" yaml:"copy"`
+	RangedRead      bool `json:"ranged_read" yaml:"ranged_read"`
+	ETagConcurrency bool `json:"etag_concurrency" yaml:"etag_concurrency"`
+	Delta           bool `json:"delta" yaml:"delta"`
+	// Hashes lists the content hash algorithms reported on nodes, e.g. "sha256".
+	Hashes []string `json:"hashes" yaml:"hashes"`
+}
+
+// capabilitiesFromProto converts the capabilities reported over gRPC, returning nil when
+// the plugin did not report any.
+func capabilitiesFromProto(c *storage_proto.Capabilities) *Capabilities {
+	if c == nil {
+		return nil
+	}
+	return &Capabilities{
+		Move:            c.Move,
+		Copy:            c.Copy,
+		RangedRead:      c.RangedRead,
+		ETagConcurrency: c.EtagConcurrency,
+		Delta:           c.Delta,
+		Hashes:          c.Hashes,
+	}
 }
 
 // Manager coordinates the lifecycle and communication of external plugins.
@@ -28,10 +70,39 @@ type Manager interface {
 	// ListPlugins returns a list of all discovered plugins and their metadata.
 	ListPlugins(ctx context.Context) ([]*Metadata, error)
 
+	// GetMetadata returns the metadata of the named plugin, including its capabilities.
+	GetMetadata(ctx context.Context, name string) (*Metadata, error)
+
+	// Install copies a plugin binary, or the single executable in a .tar.gz archive, into
+	// the plugin directory. The plugin must complete the handshake and answer GetMetadata
+	// before it replaces any plugin of the same name.
+	Install(ctx context.Context, source string) (*Metadata, error)
+
+	// Remove stops the named plugin and deletes its binary and cached metadata.
+	Remove(ctx context.Context, name string) error
+
+	// Refresh drops cached metadata for plugins that no longer exist and reports those
+	// whose binary has changed. Under [WithoutVerification], changed binaries are accepted
+	// and their metadata re-read.
+	Refresh(ctx context.Context) (*RefreshResult, error)
+
 	// Shutdown terminates all active plugin processes and cleans up associated resources.
 	Shutdown(ctx context.Context) error
 }
 
+// RefreshResult reports the outcome of [Manager.Refresh].
+type RefreshResult struct {
+	// Plugins is the metadata of every cached plugin that still exists.
+	Plugins []*Metadata
+	// Updated names the plugins whose binary changed since they were cached.
+	Updated []string
+	// Removed names the plugins whose binary no longer exists.
+	Removed []string
+	// Modified names the plugins whose binary no longer matches its recorded checksum.
+	// They are refused until reinstalled.
+	Modified []string
+}
+
 // TokenTransport is an [http.RoundTripper] that injects a bearer token into the Authorization header of every request.
 type TokenTransport struct {
 	Token string
@@ -9,93 +9,189 @@ import (
 	storage_proto "github.com/michaeldcanady/go-onedrive/internal/features/plugins/proto/storage"
 )
 
-type storageProxy struct {
-	manager *pluginManager
-	name    string
+// unary dispenses a plugin and makes one call to it under the configured call timeout,
+// letting the supervisor inspect the outcome.
+func unary[C, R any](ctx context.Context, m *pluginManager, dispense func(context.Context) (*cachedPlugin, C, error), call func(context.Context, C) (R, error)) (R, error) {
+	p, c, err := dispense(ctx)
+	if err != nil {
+		var zero R
+		return zero, err
+	}
+
+	callCtx := ctx
+	if timeout := m.callTimeout(); timeout > 0 {
+		var cancel context.CancelFunc
+		callCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	resp, err := call(callCtx, c)
+	return resp, m.observe(ctx, p, err)
 }
 
-func (p *storageProxy) client() (storage_proto.StorageServiceClient, error) {
-	return p.manager.getRawStoragePlugin(p.name)
+// observer returns a function passing stream errors from p through [pluginManager.observe].
+func (m *pluginManager) observer(ctx context.Context, p *cachedPlugin) func(error) error {
+	return func(err error) error {
+		return m.observe(ctx, p, err)
+	}
 }
 
-func (p *storageProxy) List(ctx context.Context, in *storage_proto.ListRequest, opts ...grpc.CallOption) (*storage_proto.ListResponse, error) {
-	c, err := p.client()
+// supervisedServerStream reports the errors of a server stream to the supervisor.
+type supervisedServerStream[Res any] struct {
+	grpc.ServerStreamingClient[Res]
+	observe  func(error) error
+	received bool
+}
+
+func (s *supervisedServerStream[Res]) Recv() (*Res, error) {
+	msg, err := s.ServerStreamingClient.Recv()
 	if err != nil {
-		return nil, err
+		return nil, s.observe(err)
+	}
+	if !s.received {
+		// Readers often stop before EOF; a delivered message shows the plugin is working.
+		s.received = true
+		s.observe(nil)
 	}
-	return c.List(ctx, in, opts...)
+	return msg, nil
 }
 
-func (p *storageProxy) Stat(ctx context.Context, in *storage_proto.StatRequest, opts ...grpc.CallOption) (*storage_proto.StatResponse, error) {
-	c, err := p.client()
+// supervisedClientStream reports the outcome of a client stream to the supervisor.
+type supervisedClientStream[Req, Res any] struct {
+	grpc.ClientStreamingClient[Req, Res]
+	observe func(error) error
+}
+
+func (s *supervisedClientStream[Req, Res]) CloseAndRecv() (*Res, error) {
+	msg, err := s.ClientStreamingClient.CloseAndRecv()
+	return msg, s.observe(err)
+}
+
+// supervisedBidiStream reports the errors of a bidirectional stream to the supervisor.
+type supervisedBidiStream[Req, Res any] struct {
+	grpc.BidiStreamingClient[Req, Res]
+	observe  func(error) error
+	received bool
+}
+
+func (s *supervisedBidiStream[Req, Res]) Recv() (*Res, error) {
+	msg, err := s.BidiStreamingClient.Recv()
 	if err != nil {
-		return nil, err
+		return nil, s.observe(err)
+	}
+	if !s.received {
+		// Readers often stop before EOF; a delivered message shows the plugin is working.
+		s.received = true
+		s.observe(nil)
 	}
-	return c.Stat(ctx, in, opts...)
+	return msg, nil
 }
 
-func (p *storageProxy) Mkdir(ctx context.Context, in *storage_proto.MkdirRequest, opts ...grpc.CallOption) (*storage_proto.MkdirResponse, error) {
-	c, err := p.client()
+type storageProxy struct {
+	manager *pluginManager
+	name    string
+}
+
+func (p *storageProxy) dispense(ctx context.Context) (*cachedPlugin, storage_proto.StorageServiceClient, error) {
+	c, err := p.manager.dispensePlugin(ctx, p.name, PluginTypeStorage)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	return c.Mkdir(ctx, in, opts...)
+	return c, c.service.(storage_proto.StorageServiceClient), nil
+}
+
+func (p *storageProxy) List(ctx context.Context, in *storage_proto.ListRequest, opts ...grpc.CallOption) (*storage_proto.ListResponse, error) {
+	return unary(ctx, p.manager, p.dispense, func(ctx context.Context, c storage_proto.StorageServiceClient) (*storage_proto.ListResponse, error) {
+		return c.List(ctx, in, opts...)
+	})
+}
+
+func (p *storageProxy) Stat(ctx context.Context, in *storage_proto.StatRequest, opts ...grpc.CallOption) (*storage_proto.StatResponse, error) {
+	return unary(ctx, p.manager, p.dispense, func(ctx context.Context, c storage_proto.StorageServiceClient) (*storage_proto.StatResponse, error) {
+		return c.Stat(ctx, in, opts...)
+	})
+}
+
+func (p *storageProxy) Mkdir(ctx context.Context, in *storage_proto.MkdirRequest, opts ...grpc.CallOption) (*storage_proto.MkdirResponse, error) {
+	return unary(ctx, p.manager, p.dispense, func(ctx context.Context, c storage_proto.StorageServiceClient) (*storage_proto.MkdirResponse, error) {
+		return c.Mkdir(ctx, in, opts...)
+	})
 }
 
 func (p *storageProxy) Read(ctx context.Context, in *storage_proto.ReadRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[storage_proto.ReadResponse], error) {
-	c, err := p.client()
+	pl, c, err := p.dispense(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return c.Read(ctx, in, opts...)
+	stream, err := c.Read(ctx, in, opts...)
+	if err != nil {
+		return nil, p.manager.observe(ctx, pl, err)
+	}
+	return &supervisedServerStream[storage_proto.ReadResponse]{ServerStreamingClient: stream, observe: p.manager.observer(ctx, pl)}, nil
 }
 
 func (p *storageProxy) Write(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[storage_proto.WriteRequest, storage_proto.WriteResponse], error) {
-	c, err := p.client()
+	pl, c, err := p.dispense(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return c.Write(ctx, opts...)
+	stream, err := c.Write(ctx, opts...)
+	if err != nil {
+		return nil, p.manager.observe(ctx, pl, err)
+	}
+	return &supervisedClientStream[storage_proto.WriteRequest, storage_proto.WriteResponse]{ClientStreamingClient: stream, observe: p.manager.observer(ctx, pl)}, nil
 }
 
 func (p *storageProxy) Delete(ctx context.Context, in *storage_proto.DeleteRequest, opts ...grpc.CallOption) (*storage_proto.DeleteResponse, error) {
-	c, err := p.client()
-	if err != nil {
-		return nil, err
-	}
-	return c.Delete(ctx, in, opts...)
+	return unary(ctx, p.manager, p.dispense, func(ctx context.Context, c storage_proto.StorageServiceClient) (*storage_proto.DeleteResponse, error) {
+		return c.Delete(ctx, in, opts...)
+	})
 }
 
 func (p *storageProxy) Move(ctx context.Context, in *storage_proto.MoveRequest, opts ...grpc.CallOption) (*storage_proto.MoveResponse, error) {
-	c, err := p.client()
+	return unary(ctx, p.manager, p.dispense, func(ctx context.Context, c storage_proto.StorageServiceClient) (*storage_proto.MoveResponse, error) {
+		return c.Move(ctx, in, opts...)
+	})
+}
+
+// Copy is exempt from the call timeout: a backend may copy server-side and poll until
+// the copy finishes, which can take far longer than any other unary call.
+func (p *storageProxy) Copy(ctx context.Context, in *storage_proto.CopyRequest, opts ...grpc.CallOption) (*storage_proto.CopyResponse, error) {
+	pl, c, err := p.dispense(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return c.Move(ctx, in, opts...)
+	resp, err := c.Copy(ctx, in, opts...)
+	return resp, p.manager.observe(ctx, pl, err)
 }
 
 func (p *storageProxy) ListDrives(ctx context.Context, in *storage_proto.ListDrivesRequest, opts ...grpc.CallOption) (*storage_proto.ListDrivesResponse, error) {
-	c, err := p.client()
-	if err != nil {
-		return nil, err
-	}
-	return c.ListDrives(ctx, in, opts...)
+	return unary(ctx, p.manager, p.dispense, func(ctx context.Context, c storage_proto.StorageServiceClient) (*storage_proto.ListDrivesResponse, error) {
+		return c.ListDrives(ctx, in, opts...)
+	})
 }
 
 func (p *storageProxy) GetDrive(ctx context.Context, in *storage_proto.GetDriveRequest, opts ...grpc.CallOption) (*storage_proto.GetDriveResponse, error) {
-	c, err := p.client()
-	if err != nil {
-		return nil, err
-	}
-	return c.GetDrive(ctx, in, opts...)
+	return unary(ctx, p.manager, p.dispense, func(ctx context.Context, c storage_proto.StorageServiceClient) (*storage_proto.GetDriveResponse, error) {
+		return c.GetDrive(ctx, in, opts...)
+	})
 }
 
 func (p *storageProxy) GetMetadata(ctx context.Context, in *storage_proto.MetadataRequest, opts ...grpc.CallOption) (*storage_proto.MetadataResponse, error) {
-	c, err := p.client()
+	return unary(ctx, p.manager, p.dispense, func(ctx context.Context, c storage_proto.StorageServiceClient) (*storage_proto.MetadataResponse, error) {
+		return c.GetMetadata(ctx, in, opts...)
+	})
+}
+
+func (p *storageProxy) Delta(ctx context.Context, in *storage_proto.DeltaRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[storage_proto.DeltaResponse], error) {
+	pl, c, err := p.dispense(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return c.GetMetadata(ctx, in, opts...)
+	stream, err := c.Delta(ctx, in, opts...)
+	if err != nil {
+		return nil, p.manager.observe(ctx, pl, err)
+	}
+	return &supervisedServerStream[storage_proto.DeltaResponse]{ServerStreamingClient: stream, observe: p.manager.observer(ctx, pl)}, nil
 }
 
 type identityProxy struct {
@@ -103,46 +199,46 @@ type identityProxy struct {
 	name    string
 }
 
-func (p *identityProxy) client() (identity_proto.IdentityPluginClient, error) {
-	return p.manager.getRawIdentityPlugin(p.name)
+func (p *identityProxy) dispense(ctx context.Context) (*cachedPlugin, identity_proto.IdentityPluginClient, error) {
+	c, err := p.manager.dispensePlugin(ctx, p.name, PluginTypeIdentity)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c, c.service.(identity_proto.IdentityPluginClient), nil
 }
 
 func (p *identityProxy) Login(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[identity_proto.LoginRequest, identity_proto.LoginResponse], error) {
-	c, err := p.client()
+	pl, c, err := p.dispense(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return c.Login(ctx, opts...)
+	stream, err := c.Login(ctx, opts...)
+	if err != nil {
+		return nil, p.manager.observe(ctx, pl, err)
+	}
+	return &supervisedBidiStream[identity_proto.LoginRequest, identity_proto.LoginResponse]{BidiStreamingClient: stream, observe: p.manager.observer(ctx, pl)}, nil
 }
 
 func (p *identityProxy) Refresh(ctx context.Context, in *identity_proto.RefreshRequest, opts ...grpc.CallOption) (*identity_proto.RefreshResponse, error) {
-	c, err := p.client()
-	if err != nil {
-		return nil, err
-	}
-	return c.Refresh(ctx, in, opts...)
+	return unary(ctx, p.manager, p.dispense, func(ctx context.Context, c identity_proto.IdentityPluginClient) (*identity_proto.RefreshResponse, error) {
+		return c.Refresh(ctx, in, opts...)
+	})
 }
 
 func (p *identityProxy) ListIdentities(ctx context.Context, in *identity_proto.ListIdentitiesRequest, opts ...grpc.CallOption) (*identity_proto.ListIdentitiesResponse, error) {
-	c, err := p.client()
-	if err != nil {
-		return nil, err
-	}
-	return c.ListIdentities(ctx, in, opts...)
+	return unary(ctx, p.manager, p.dispense, func(ctx context.Context, c identity_proto.IdentityPluginClient) (*identity_proto.ListIdentitiesResponse, error) {
+		return c.ListIdentities(ctx, in, opts...)
+	})
 }
 
 func (p *identityProxy) Logout(ctx context.Context, in *identity_proto.LogoutRequest, opts ...grpc.CallOption) (*identity_proto.LogoutResponse, error) {
-	c, err := p.client()
-	if err != nil {
-		return nil, err
-	}
-	return c.Logout(ctx, in, opts...)
+	return unary(ctx, p.manager, p.dispense, func(ctx context.Context, c identity_proto.IdentityPluginClient) (*identity_proto.LogoutResponse, error) {
+		return c.Logout(ctx, in, opts...)
+	})
 }
 
 func (p *identityProxy) GetMetadata(ctx context.Context, in *identity_proto.MetadataRequest, opts ...grpc.CallOption) (*identity_proto.MetadataResponse, error) {
-	c, err := p.client()
-	if err != nil {
-		return nil, err
-	}
-	return c.GetMetadata(ctx, in, opts...)
+	return unary(ctx, p.manager, p.dispense, func(ctx context.Context, c identity_proto.IdentityPluginClient) (*identity_proto.MetadataResponse, error) {
+		return c.GetMetadata(ctx, in, opts...)
+	})
 }
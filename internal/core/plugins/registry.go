@@ -0,0 +1,165 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/hashicorp/go-plugin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+
+	identity_proto "github.com/michaeldcanady/go-onedrive/internal/features/plugins/proto/identity"
+	storage_proto "github.com/michaeldcanady/go-onedrive/internal/features/plugins/proto/storage"
+)
+
+// bufconnSize is the buffer size of the in-memory connection to an in-process plugin.
+const bufconnSize = 1 << 20
+
+// Registry holds plugins that are served inside the odc process instead of being
+// launched as subprocesses. The [Manager] resolves a name against its registry before
+// looking in the plugin directory.
+type Registry struct {
+	mu      sync.RWMutex
+	plugins map[pluginKey]*registration
+}
+
+type pluginKey struct {
+	name       string
+	pluginType string
+}
+
+// registration is a plugin registered under one name and type.
+type registration struct {
+	plugin     plugin.GRPCPlugin
+	grpcServer func([]grpc.ServerOption) *grpc.Server
+}
+
+// RegisterOption configures how an in-process plugin is served.
+type RegisterOption func(*registration)
+
+// WithGRPCServer sets the function creating the plugin's gRPC server, as the GRPCServer
+// field of [plugin.ServeConfig] does for a plugin binary. It defaults to [CustomGRPCServer].
+func WithGRPCServer(f func([]grpc.ServerOption) *grpc.Server) RegisterOption {
+	return func(r *registration) {
+		r.grpcServer = f
+	}
+}
+
+// NewRegistry returns an empty [Registry].
+func NewRegistry() *Registry {
+	return &Registry{plugins: make(map[pluginKey]*registration)}
+}
+
+// DefaultRegistry is the registry of managers created with [NewPluginManager].
+var DefaultRegistry = NewRegistry()
+
+// RegisterStorage registers impl in [DefaultRegistry] as the storage plugin called name.
+func RegisterStorage(name string, impl storage_proto.StorageServiceServer, opts ...RegisterOption) {
+	DefaultRegistry.RegisterStorage(name, impl, opts...)
+}
+
+// RegisterIdentity registers impl in [DefaultRegistry] as the identity plugin called name.
+func RegisterIdentity(name string, impl identity_proto.IdentityPluginServer, opts ...RegisterOption) {
+	DefaultRegistry.RegisterIdentity(name, impl, opts...)
+}
+
+// RegisterStorage registers impl as the storage plugin called name, e.g. "storage-local",
+// replacing any earlier registration.
+func (r *Registry) RegisterStorage(name string, impl storage_proto.StorageServiceServer, opts ...RegisterOption) {
+	r.register(name, PluginTypeStorage, &StorageGRPCPlugin{Impl: impl}, opts)
+}
+
+// RegisterIdentity registers impl as the identity plugin called name, e.g.
+// "identity-azure", replacing any earlier registration.
+func (r *Registry) RegisterIdentity(name string, impl identity_proto.IdentityPluginServer, opts ...RegisterOption) {
+	r.register(name, PluginTypeIdentity, &IdentityGRPCPlugin{Impl: impl}, opts)
+}
+
+func (r *Registry) register(name, pluginType string, p plugin.GRPCPlugin, opts []RegisterOption) {
+	reg := &registration{plugin: p, grpcServer: CustomGRPCServer}
+	for _, opt := range opts {
+		opt(reg)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.plugins[pluginKey{name, pluginType}] = reg
+}
+
+// Has reports whether a plugin of any type is registered as name.
+func (r *Registry) Has(name string) bool {
+	_, storage := r.lookup(name, PluginTypeStorage)
+	_, identity := r.lookup(name, PluginTypeIdentity)
+	return storage || identity
+}
+
+// Names returns the names of the registered plugins.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	var names []string
+	for key := range r.plugins {
+		if name := key.name; !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func (r *Registry) lookup(name, pluginType string) (*registration, bool) {
+	if r == nil {
+		return nil, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	reg, ok := r.plugins[pluginKey{name, pluginType}]
+	return reg, ok
+}
+
+// serve starts the registered plugin on an in-memory connection and returns a client
+// for it. The gRPC health service is registered as go-plugin does, so in-process
+// plugins are supervised like subprocesses.
+func (reg *registration) serve(ctx context.Context) (any, *grpc.ClientConn, func(), error) {
+	lis := bufconn.Listen(bufconnSize)
+
+	server := reg.grpcServer(nil)
+	if err := reg.plugin.GRPCServer(nil, server); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to register in-process plugin: %w", err)
+	}
+	healthCheck := health.NewServer()
+	healthCheck.SetServingStatus(plugin.GRPCServiceName, grpc_health_v1.HealthCheckResponse_SERVING)
+	grpc_health_v1.RegisterHealthServer(server, healthCheck)
+	go server.Serve(lis)
+
+	conn, err := grpc.NewClient("passthrough:///in-process",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(requestIDUnaryInterceptor),
+		grpc.WithStreamInterceptor(requestIDStreamInterceptor),
+	)
+	if err != nil {
+		server.Stop()
+		return nil, nil, nil, fmt.Errorf("failed to connect to in-process plugin: %w", err)
+	}
+	cleanup := func() {
+		conn.Close()
+		server.Stop()
+	}
+
+	raw, err := reg.plugin.GRPCClient(ctx, nil, conn)
+	if err != nil {
+		cleanup()
+		return nil, nil, nil, err
+	}
+	return raw, conn, cleanup, nil
+}
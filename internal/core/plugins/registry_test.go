@@ -0,0 +1,133 @@
+package plugins
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/michaeldcanady/go-onedrive/internal/core/logger"
+	identity_proto "github.com/michaeldcanady/go-onedrive/internal/features/plugins/proto/identity"
+	storage_proto "github.com/michaeldcanady/go-onedrive/internal/features/plugins/proto/storage"
+)
+
+type memoryStorage struct {
+	storage_proto.UnimplementedStorageServiceServer
+	files map[string]string
+	// requestIDs records the request ID each call arrived with.
+	requestIDs []string
+}
+
+func (s *memoryStorage) GetMetadata(ctx context.Context, _ *storage_proto.MetadataRequest) (*storage_proto.MetadataResponse, error) {
+	return &storage_proto.MetadataResponse{
+		Name:               "memory",
+		Type:               PluginTypeStorage,
+		SupportedProviders: []string{"memory"},
+		Capabilities:       &storage_proto.Capabilities{Copy: true},
+	}, nil
+}
+
+func (s *memoryStorage) List(ctx context.Context, req *storage_proto.ListRequest) (*storage_proto.ListResponse, error) {
+	s.requestIDs = append(s.requestIDs, logger.GetRequestID(ctx))
+	var nodes []*storage_proto.Node
+	for name := range s.files {
+		nodes = append(nodes, &storage_proto.Node{Name: name, Path: "/" + name})
+	}
+	return &storage_proto.ListResponse{Nodes: nodes}, nil
+}
+
+func (s *memoryStorage) Read(req *storage_proto.ReadRequest, stream grpc.ServerStreamingServer[storage_proto.ReadResponse]) error {
+	data, ok := s.files[filepath.Base(req.Path)]
+	if !ok {
+		return status.Error(codes.NotFound, req.Path)
+	}
+	return stream.Send(&storage_proto.ReadResponse{Chunk: []byte(data)})
+}
+
+type memoryIdentity struct {
+	identity_proto.UnimplementedIdentityPluginServer
+}
+
+func (memoryIdentity) GetMetadata(ctx context.Context, _ *identity_proto.MetadataRequest) (*identity_proto.MetadataResponse, error) {
+	return &identity_proto.MetadataResponse{Name: "memory", Type: PluginTypeIdentity}, nil
+}
+
+func newRegistryTestManager(t *testing.T) (*pluginManager, *memoryStorage, string) {
+	t.Helper()
+	m, pluginDir := newTestManager(t)
+	t.Cleanup(func() { m.Shutdown(context.Background()) })
+
+	storage := &memoryStorage{files: map[string]string{"a.txt": "hello"}}
+	m.registry = NewRegistry()
+	m.registry.RegisterStorage("storage-memory", storage)
+	m.registry.RegisterIdentity("identity-memory", memoryIdentity{})
+	return m, storage, pluginDir
+}
+
+func TestPluginManager_InProcess(t *testing.T) {
+	ctx := logger.WithRequestID(context.Background(), "req-1")
+	m, storage, _ := newRegistryTestManager(t)
+
+	client, err := m.GetStoragePlugin("storage-memory")
+	require.NoError(t, err)
+
+	resp, err := client.List(ctx, &storage_proto.ListRequest{Path: "/"})
+	require.NoError(t, err)
+	require.Len(t, resp.Nodes, 1)
+	assert.Equal(t, "a.txt", resp.Nodes[0].Name)
+	assert.Equal(t, []string{"req-1"}, storage.requestIDs, "request IDs cross the in-memory connection")
+
+	stream, err := client.Read(ctx, &storage_proto.ReadRequest{Path: "/a.txt"})
+	require.NoError(t, err)
+	chunk, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(chunk.Chunk))
+	_, err = stream.Recv()
+	assert.ErrorIs(t, err, io.EOF)
+
+	stream, err = client.Read(ctx, &storage_proto.ReadRequest{Path: "/missing"})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.NotFound, status.Code(err), "plugin errors are returned unchanged")
+}
+
+func TestPluginManager_InProcessMetadata(t *testing.T) {
+	ctx := context.Background()
+	m, _, pluginDir := newRegistryTestManager(t)
+
+	// A binary of the same name is shadowed by the in-process plugin.
+	require.NoError(t, os.WriteFile(filepath.Join(pluginDir, "storage-memory"), []byte("binary"), 0755))
+
+	meta, err := m.GetMetadata(ctx, "storage-memory")
+	require.NoError(t, err)
+	assert.Equal(t, "memory", meta.Name)
+	assert.True(t, meta.Builtin)
+	assert.Empty(t, meta.Checksum)
+	require.NotNil(t, meta.Capabilities)
+	assert.True(t, meta.Capabilities.Copy)
+
+	idMeta, err := m.GetMetadata(ctx, "identity-memory")
+	require.NoError(t, err)
+	assert.Equal(t, PluginTypeIdentity, idMeta.Type)
+
+	metas, err := m.ListPlugins(ctx)
+	require.NoError(t, err)
+	var names []string
+	for _, meta := range metas {
+		names = append(names, meta.PluginPath)
+	}
+	assert.ElementsMatch(t, []string{"storage-memory", "identity-memory"}, names)
+
+	cached, err := m.repo.List()
+	require.NoError(t, err)
+	assert.Empty(t, cached, "in-process plugins are not cached")
+
+	assert.ErrorContains(t, m.Remove(ctx, "storage-memory"), "built into odc")
+}
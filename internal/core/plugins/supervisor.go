@@ -0,0 +1,339 @@
+package plugins
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/hashicorp/go-plugin"
+
+	coreerrors "github.com/michaeldcanady/go-onedrive/internal/core/errors"
+	"github.com/michaeldcanady/go-onedrive/internal/core/logger"
+	"github.com/michaeldcanady/go-onedrive/internal/features/config"
+)
+
+// Supervision defaults.
+const (
+	// DefaultCallTimeout bounds unary plugin calls unless [config.KeyCorePluginsCallTimeout]
+	// overrides it. Streams and Copy are bounded only by the caller's context.
+	DefaultCallTimeout = 60 * time.Second
+
+	healthCheckInterval = 10 * time.Second
+	healthCheckTimeout  = 2 * time.Second
+	restartBaseDelay    = 100 * time.Millisecond
+	restartMaxDelay     = 5 * time.Second
+	breakerThreshold    = 3
+	breakerCooldown     = 30 * time.Second
+	logTailLines        = 20
+	logTailBytes        = 16 << 10
+)
+
+// ErrCircuitOpen is returned without launching a plugin that has failed repeatedly.
+// Calls are let through again once the cooldown has passed.
+var ErrCircuitOpen = errors.New("plugin disabled after repeated failures")
+
+// PluginError reports a plugin that crashed, hung or failed to start, together with the
+// last lines it wrote to its log file.
+type PluginError struct {
+	// Name is the plugin binary name.
+	Name string
+	// Err is the underlying failure. Crashes and hangs wrap [coreerrors.ErrUnavailable].
+	Err error
+	// LogPath is the plugin's log file.
+	LogPath string
+	// Log holds the last lines written to LogPath since the plugin was launched.
+	Log []string
+}
+
+func (e *PluginError) Error() string {
+	if len(e.Log) == 0 {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%v\nlast lines of %s:\n  %s", e.Err, e.LogPath, strings.Join(e.Log, "\n  "))
+}
+
+func (e *PluginError) Unwrap() error {
+	return e.Err
+}
+
+// pluginState is the supervision record kept for each plugin name.
+type pluginState struct {
+	// failures counts consecutive crashes, hangs and failed launches.
+	failures  int
+	openUntil time.Time
+	// logStart is the size of the log file when the plugin was last launched.
+	logStart int64
+}
+
+// supervisor tracks plugin failures across restarts. It delays relaunching a plugin
+// that keeps failing and, past [breakerThreshold] consecutive failures, refuses to
+// launch it until [breakerCooldown] has passed.
+type supervisor struct {
+	mu     sync.Mutex
+	states map[string]*pluginState
+	now    func() time.Time
+	sleep  func(ctx context.Context, d time.Duration) error
+}
+
+func newSupervisor() *supervisor {
+	return &supervisor{
+		states: make(map[string]*pluginState),
+		now:    time.Now,
+		sleep:  sleepContext,
+	}
+}
+
+func (s *supervisor) state(name string) *pluginState {
+	st, ok := s.states[name]
+	if !ok {
+		st = &pluginState{}
+		s.states[name] = st
+	}
+	return st
+}
+
+// admit is called before launching name. It returns [ErrCircuitOpen] while the breaker
+// is open and otherwise waits out the restart backoff.
+func (s *supervisor) admit(ctx context.Context, name string) error {
+	s.mu.Lock()
+	st := s.state(name)
+	now := s.now()
+	if now.Before(st.openUntil) {
+		wait := st.openUntil.Sub(now).Round(time.Second)
+		s.mu.Unlock()
+		return fmt.Errorf("%w; retrying in %s", ErrCircuitOpen, wait)
+	}
+	delay := restartBackoff(st.failures)
+	s.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	return s.sleep(ctx, delay)
+}
+
+// success clears the failure record of name.
+func (s *supervisor) success(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if st, ok := s.states[name]; ok {
+		st.failures = 0
+		st.openUntil = time.Time{}
+	}
+}
+
+// failure records a crash, hang or failed launch of name and reports whether the
+// breaker is now open.
+func (s *supervisor) failure(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := s.state(name)
+	st.failures++
+	if st.failures >= breakerThreshold {
+		st.openUntil = s.now().Add(breakerCooldown)
+		return true
+	}
+	return false
+}
+
+func (s *supervisor) launched(name string, logStart int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state(name).logStart = logStart
+}
+
+func (s *supervisor) logStart(name string) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state(name).logStart
+}
+
+// restartBackoff returns the delay before relaunching a plugin after the given number
+// of consecutive failures.
+func restartBackoff(failures int) time.Duration {
+	if failures <= 0 {
+		return 0
+	}
+	if shift := failures - 1; shift < 32 && restartBaseDelay<<shift < restartMaxDelay {
+		return restartBaseDelay << shift
+	}
+	return restartMaxDelay
+}
+
+// callTimeout returns the deadline applied to unary plugin calls.
+func (m *pluginManager) callTimeout() time.Duration {
+	if val, err := m.config.Get(config.KeyCorePluginsCallTimeout); err == nil && val != nil {
+		if d, err := time.ParseDuration(fmt.Sprintf("%v", val)); err == nil {
+			return d
+		}
+		m.logger.Warn("ignoring invalid plugin call timeout", "key", config.KeyCorePluginsCallTimeout, "value", val)
+	}
+	return DefaultCallTimeout
+}
+
+// checkHealth asks the plugin's gRPC health service whether it is serving.
+func checkHealth(ctx context.Context, p *cachedPlugin) error {
+	if p.exited() {
+		return errors.New("plugin process exited")
+	}
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+	_, err := grpc_health_v1.NewHealthClient(p.grpcClient).Check(ctx, &grpc_health_v1.HealthCheckRequest{
+		Service: plugin.GRPCServiceName,
+	})
+	return err
+}
+
+// healthy reports whether the cached plugin can serve another call, health-checking it
+// when it has not been checked for [healthCheckInterval].
+func (m *pluginManager) healthy(ctx context.Context, p *cachedPlugin) bool {
+	if p.exited() {
+		return false
+	}
+	now := m.supervisor.now()
+	if now.Sub(time.Unix(0, p.checkedAt.Load())) < healthCheckInterval {
+		return true
+	}
+	if err := checkHealth(ctx, p); err != nil {
+		logger.WithContext(m.logger, ctx).Warn("plugin failed health check", "name", p.name, "error", err)
+		return false
+	}
+	p.checkedAt.Store(now.UnixNano())
+	return true
+}
+
+// observe inspects the outcome of a call to p. Failures caused by the plugin process
+// crashing or hanging stop it, count against its breaker and are returned as a
+// [PluginError]; the next call starts a fresh process. Other errors are returned as-is.
+// ctx is the caller's context, without the per-call deadline.
+func (m *pluginManager) observe(ctx context.Context, p *cachedPlugin, err error) error {
+	if err == nil || errors.Is(err, io.EOF) {
+		m.supervisor.success(p.name)
+		return err
+	}
+	if ctx.Err() != nil {
+		return err
+	}
+
+	var cause error
+	switch status.Code(err) {
+	case codes.Unavailable, codes.Internal:
+		if checkHealth(ctx, p) == nil {
+			// The backend behind the plugin is unavailable, not the plugin itself.
+			return err
+		}
+		cause = fmt.Errorf("%w: plugin %s stopped responding: %s", coreerrors.ErrUnavailable, p.name, status.Convert(err).Message())
+	case codes.DeadlineExceeded:
+		if checkHealth(ctx, p) == nil {
+			return err
+		}
+		cause = fmt.Errorf("%w: plugin %s did not respond within %s", coreerrors.ErrUnavailable, p.name, m.callTimeout())
+	default:
+		return err
+	}
+
+	// Every call in flight on the process sees it fail; only the one that evicts it
+	// counts the failure.
+	if !m.evict(p) {
+		return m.pluginError(p.name, cause)
+	}
+	return m.recordFailure(ctx, p.name, cause)
+}
+
+// recordFailure counts a failure of name against its breaker and returns it with the
+// tail of the plugin's log.
+func (m *pluginManager) recordFailure(ctx context.Context, name string, cause error) error {
+	if m.supervisor.failure(name) {
+		logger.WithContext(m.logger, ctx).Warn("plugin disabled after repeated failures", "name", name, "cooldown", breakerCooldown)
+	}
+	return m.pluginError(name, cause)
+}
+
+// pluginError wraps err with the lines the named plugin logged since it was launched.
+func (m *pluginManager) pluginError(name string, err error) error {
+	path := m.logPath(name)
+	return &PluginError{
+		Name:    name,
+		Err:     err,
+		LogPath: path,
+		Log:     readLogTail(path, m.supervisor.logStart(name), logTailLines),
+	}
+}
+
+// evict stops p and forgets it, and reports whether it did. It does nothing when p has
+// already been evicted or replaced.
+func (m *pluginManager) evict(p *cachedPlugin) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.plugins[p.key] != p {
+		return false
+	}
+	delete(m.plugins, p.key)
+	if p.cleanup != nil {
+		p.cleanup()
+	}
+	return true
+}
+
+func (m *pluginManager) logPath(name string) string {
+	return filepath.Join(m.getLogDir(), fmt.Sprintf("plugin-%s.log", name))
+}
+
+// readLogTail returns up to n non-empty lines written to the file at path after offset.
+func readLogTail(path string, offset int64, n int) []string {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil
+	}
+	start := max(offset, info.Size()-logTailBytes, 0)
+	if _, err := f.Seek(start, io.SeekStart); err != nil {
+		return nil
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil
+	}
+
+	lines := strings.Split(string(data), "\n")
+	if start > offset {
+		// The first line was cut by the size limit.
+		lines = lines[1:]
+	}
+	var tail []string
+	for _, line := range lines {
+		if line = strings.TrimRight(line, "\r "); line != "" {
+			tail = append(tail, line)
+		}
+	}
+	if len(tail) > n {
+		tail = tail[len(tail)-n:]
+	}
+	return tail
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
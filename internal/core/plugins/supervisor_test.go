@@ -0,0 +1,193 @@
+package plugins
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	coreerrors "github.com/michaeldcanady/go-onedrive/internal/core/errors"
+	"github.com/michaeldcanady/go-onedrive/internal/features/config"
+	storage_proto "github.com/michaeldcanady/go-onedrive/internal/features/plugins/proto/storage"
+)
+
+func TestSupervisor_Breaker(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(0, 0)
+	var slept []time.Duration
+
+	s := newSupervisor()
+	s.now = func() time.Time { return now }
+	s.sleep = func(_ context.Context, d time.Duration) error {
+		slept = append(slept, d)
+		return nil
+	}
+
+	require.NoError(t, s.admit(ctx, "storage-test"))
+	assert.Empty(t, slept, "a healthy plugin launches immediately")
+
+	assert.False(t, s.failure("storage-test"))
+	require.NoError(t, s.admit(ctx, "storage-test"))
+	assert.False(t, s.failure("storage-test"))
+	require.NoError(t, s.admit(ctx, "storage-test"))
+	assert.Equal(t, []time.Duration{restartBaseDelay, 2 * restartBaseDelay}, slept)
+
+	assert.True(t, s.failure("storage-test"), "the breaker opens at the threshold")
+	assert.ErrorIs(t, s.admit(ctx, "storage-test"), ErrCircuitOpen)
+	require.NoError(t, s.admit(ctx, "storage-other"), "breakers are per plugin")
+
+	now = now.Add(breakerCooldown)
+	require.NoError(t, s.admit(ctx, "storage-test"), "one attempt is let through after the cooldown")
+	assert.True(t, s.failure("storage-test"), "a failed attempt reopens the breaker")
+	assert.ErrorIs(t, s.admit(ctx, "storage-test"), ErrCircuitOpen)
+
+	now = now.Add(breakerCooldown)
+	s.success("storage-test")
+	slept = nil
+	require.NoError(t, s.admit(ctx, "storage-test"))
+	assert.Empty(t, slept, "success clears the failure count")
+}
+
+func TestRestartBackoff(t *testing.T) {
+	assert.Equal(t, time.Duration(0), restartBackoff(0))
+	assert.Equal(t, restartBaseDelay, restartBackoff(1))
+	assert.Equal(t, 4*restartBaseDelay, restartBackoff(3))
+	assert.Equal(t, restartMaxDelay, restartBackoff(10))
+	assert.Equal(t, restartMaxDelay, restartBackoff(100))
+}
+
+func TestReadLogTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plugin-test.log")
+
+	assert.Nil(t, readLogTail(path, 0, 5), "a missing log has no tail")
+
+	earlier := "from an earlier run\n"
+	var lines []string
+	for i := range 10 {
+		lines = append(lines, strings.Repeat("x", i+1))
+	}
+	require.NoError(t, os.WriteFile(path, []byte(earlier+strings.Join(lines, "\n")+"\n\n"), 0644))
+
+	assert.Equal(t, lines[7:], readLogTail(path, 0, 3))
+	assert.Equal(t, lines, readLogTail(path, int64(len(earlier)), 20), "lines from before the launch are skipped")
+
+	big := strings.Repeat("y", logTailBytes) + "\npanic: boom\n"
+	require.NoError(t, os.WriteFile(path, []byte(big), 0644))
+	assert.Equal(t, []string{"panic: boom"}, readLogTail(path, 0, 20), "a line cut by the size limit is dropped")
+}
+
+func TestPluginError(t *testing.T) {
+	cause := errors.Join(coreerrors.ErrUnavailable, errors.New("plugin storage-test stopped responding"))
+	err := error(&PluginError{
+		Name:    "storage-test",
+		Err:     cause,
+		LogPath: "/logs/plugin-storage-test.log",
+		Log:     []string{"panic: boom", "goroutine 1 [running]:"},
+	})
+
+	assert.ErrorIs(t, err, coreerrors.ErrUnavailable)
+	assert.Equal(t, err, FromGRPC(err), "plugin errors are not gRPC statuses")
+	assert.Contains(t, err.Error(), "last lines of /logs/plugin-storage-test.log:\n  panic: boom\n  goroutine 1 [running]:")
+
+	assert.Equal(t, cause.Error(), (&PluginError{Name: "storage-test", Err: cause}).Error())
+}
+
+func TestPluginManager_ConcurrentDispense(t *testing.T) {
+	ctx := context.Background()
+	m, _, _ := newRegistryTestManager(t)
+
+	// Every dispense finds the last health check stale, so concurrent callers check and
+	// record the plugin's health at the same time.
+	start := time.Now()
+	var ticks atomic.Int64
+	m.supervisor.now = func() time.Time {
+		return start.Add(time.Duration(ticks.Add(1)) * healthCheckInterval)
+	}
+
+	first, err := m.dispensePlugin(ctx, "storage-memory", PluginTypeStorage)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 10 {
+				p, err := m.dispensePlugin(ctx, "storage-memory", PluginTypeStorage)
+				assert.NoError(t, err)
+				assert.Same(t, first, p, "a healthy plugin is reused")
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestPluginManager_ObserveCountsCrashOnce(t *testing.T) {
+	ctx := context.Background()
+	m, _, _ := newRegistryTestManager(t)
+
+	p, err := m.dispensePlugin(ctx, "storage-memory", PluginTypeStorage)
+	require.NoError(t, err)
+	p.cleanup()
+
+	// Every call in flight on the stopped plugin fails the same way.
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := m.observe(ctx, p, status.Error(codes.Unavailable, "connection closed"))
+			var pluginErr *PluginError
+			assert.ErrorAs(t, err, &pluginErr)
+		}()
+	}
+	wg.Wait()
+
+	m.supervisor.mu.Lock()
+	defer m.supervisor.mu.Unlock()
+	assert.Equal(t, 1, m.supervisor.states["storage-memory"].failures, "one crash is one failure")
+}
+
+// slowStorage takes delay to answer Move and Copy.
+type slowStorage struct {
+	storage_proto.UnimplementedStorageServiceServer
+	delay time.Duration
+}
+
+func (s slowStorage) Move(ctx context.Context, _ *storage_proto.MoveRequest) (*storage_proto.MoveResponse, error) {
+	time.Sleep(s.delay)
+	return &storage_proto.MoveResponse{}, nil
+}
+
+func (s slowStorage) Copy(ctx context.Context, _ *storage_proto.CopyRequest) (*storage_proto.CopyResponse, error) {
+	time.Sleep(s.delay)
+	return &storage_proto.CopyResponse{}, nil
+}
+
+func TestStorageProxy_CopyIgnoresCallTimeout(t *testing.T) {
+	ctx := context.Background()
+	m, _ := newTestManager(t)
+	t.Cleanup(func() { m.Shutdown(context.Background()) })
+	require.NoError(t, m.config.Set(config.KeyCorePluginsCallTimeout, "20ms"))
+	m.registry = NewRegistry()
+	m.registry.RegisterStorage("storage-slow", slowStorage{delay: 100 * time.Millisecond})
+
+	client, err := m.GetStoragePlugin("storage-slow")
+	require.NoError(t, err)
+
+	_, err = client.Copy(ctx, &storage_proto.CopyRequest{Source: "/a", Destination: "/b"})
+	assert.NoError(t, err, "a server-side copy may outlast the call timeout")
+
+	_, err = client.Move(ctx, &storage_proto.MoveRequest{Source: "/a", Destination: "/b"})
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err), "other unary calls are still bounded")
+}
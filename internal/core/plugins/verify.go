@@ -0,0 +1,167 @@
+package plugins
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/go-plugin"
+
+	"github.com/michaeldcanady/go-onedrive/internal/core/logger"
+	"github.com/michaeldcanady/go-onedrive/internal/features/config"
+)
+
+var (
+	// ErrPluginModified is returned when a plugin binary no longer matches the checksum
+	// recorded when it was installed.
+	ErrPluginModified = errors.New("plugin binary has been modified since it was installed")
+	// ErrPluginUnsigned is returned in signature mode when a plugin has no signature from
+	// a trusted key.
+	ErrPluginUnsigned = errors.New("plugin is not signed by a trusted key")
+)
+
+// verifyHint tells the user how to get past a failed verification.
+const verifyHint = "reinstall it with 'odc plugin install' or pass --skip-plugin-verification to load it anyway"
+
+type skipVerificationKey struct{}
+
+// WithoutVerification returns a context under which plugins are launched without
+// checking their checksum or signature, and under which [Manager.Refresh] accepts
+// modified binaries.
+func WithoutVerification(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipVerificationKey{}, true)
+}
+
+func verificationDisabled(ctx context.Context) bool {
+	disabled, _ := ctx.Value(skipVerificationKey{}).(bool)
+	return disabled
+}
+
+// secureConfig checks the plugin at path against what was recorded when it was
+// installed. A plugin with no recorded checksum, such as one copied into the plugin
+// directory by hand, is trusted on first use: its checksum is recorded with a warning. In
+// signature mode the recorded signature must verify against a trusted key. The returned
+// config makes go-plugin refuse to start the binary unless its SHA-256 matches the
+// recorded checksum; it is nil only when verification is disabled.
+func (m *pluginManager) secureConfig(ctx context.Context, name, path string) (*plugin.SecureConfig, error) {
+	if verificationDisabled(ctx) {
+		logger.WithContext(m.logger, ctx).Debug("loading plugin without verification", "name", name)
+		return nil, nil
+	}
+
+	meta, err := m.repo.Get(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plugin metadata: %w", err)
+	}
+
+	keys, err := m.trustedKeys()
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) > 0 {
+		if meta == nil || meta.Signature == "" {
+			return nil, fmt.Errorf("%s: %w; %s", name, ErrPluginUnsigned, verifyHint)
+		}
+		if err := verifyFile(path, meta.Signature, keys); err != nil {
+			return nil, fmt.Errorf("%s: %w; %s", name, err, verifyHint)
+		}
+	}
+	if meta == nil || meta.Checksum == "" {
+		if meta, err = m.trustOnFirstUse(ctx, name, path, meta); err != nil {
+			return nil, err
+		}
+	}
+
+	sum, err := hex.DecodeString(meta.Checksum)
+	if err != nil {
+		return nil, fmt.Errorf("invalid checksum recorded for plugin %s: %w", name, err)
+	}
+	return &plugin.SecureConfig{Checksum: sum, Hash: sha256.New()}, nil
+}
+
+// trustOnFirstUse records the checksum of the plugin at path, which was not installed
+// with 'odc plugin install', so that later launches are pinned to it.
+func (m *pluginManager) trustOnFirstUse(ctx context.Context, name, path string, meta *Metadata) (*Metadata, error) {
+	checksum, err := fileChecksum(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to checksum plugin %s: %w", name, err)
+	}
+	trusted := &Metadata{PluginPath: name}
+	if meta != nil {
+		c := *meta
+		trusted = &c
+	}
+	trusted.Checksum = checksum
+	if err := m.repo.Set(name, trusted); err != nil {
+		return nil, fmt.Errorf("failed to record checksum of plugin %s: %w", name, err)
+	}
+	logger.WithContext(m.logger, ctx).Warn("trusting plugin that was not installed with 'odc plugin install'; later launches are pinned to its checksum", "name", name, "checksum", checksum)
+	return trusted, nil
+}
+
+// trustedKeys returns the ed25519 public keys configured under
+// [config.KeyCorePluginsTrustedKeys]. Any configured key turns on signature mode.
+func (m *pluginManager) trustedKeys() ([]ed25519.PublicKey, error) {
+	val, err := m.config.Get(config.KeyCorePluginsTrustedKeys)
+	if err != nil || val == nil {
+		return nil, nil
+	}
+
+	var encoded []string
+	switch v := val.(type) {
+	case []any:
+		for _, k := range v {
+			encoded = append(encoded, fmt.Sprintf("%v", k))
+		}
+	default:
+		encoded = strings.FieldsFunc(fmt.Sprintf("%v", v), func(r rune) bool { return r == ',' || r == ' ' })
+	}
+
+	var keys []ed25519.PublicKey
+	for _, k := range encoded {
+		raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(k))
+		if err != nil || len(raw) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid ed25519 public key %q in %s", k, config.KeyCorePluginsTrustedKeys)
+		}
+		keys = append(keys, ed25519.PublicKey(raw))
+	}
+	return keys, nil
+}
+
+// verifyFile reports whether the base64-encoded detached signature sig was made over the
+// contents of the file at path by one of keys.
+func verifyFile(path, sig string, keys []ed25519.PublicKey) error {
+	raw, err := base64.StdEncoding.DecodeString(sig)
+	if err != nil {
+		return fmt.Errorf("invalid plugin signature: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if ed25519.Verify(key, data, raw) {
+			return nil
+		}
+	}
+	return ErrPluginUnsigned
+}
+
+// decodeSignature accepts a detached signature as raw bytes or as base64 text and
+// returns it base64-encoded.
+func decodeSignature(data []byte) (string, error) {
+	if len(data) == ed25519.SignatureSize {
+		return base64.StdEncoding.EncodeToString(data), nil
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(raw) != ed25519.SignatureSize {
+		return "", errors.New("invalid ed25519 signature: expected 64 raw or base64-encoded bytes")
+	}
+	return base64.StdEncoding.EncodeToString(raw), nil
+}
@@ -0,0 +1,159 @@
+package plugins
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/michaeldcanady/go-onedrive/internal/core/logger/loggertest"
+	"github.com/michaeldcanady/go-onedrive/internal/features/config"
+)
+
+func TestPluginManager_SecureConfig(t *testing.T) {
+	ctx := context.Background()
+	m, pluginDir := newTestManager(t)
+
+	path := filepath.Join(pluginDir, "storage-test")
+	require.NoError(t, os.WriteFile(path, []byte("binary"), 0755))
+	checksum, err := fileChecksum(path)
+	require.NoError(t, err)
+
+	t.Run("discovery records nothing", func(t *testing.T) {
+		m.cacheMetadata(ctx, "storage-test", &Metadata{PluginPath: "storage-test", Checksum: checksum})
+		meta, err := m.repo.Get("storage-test")
+		require.NoError(t, err)
+		assert.Nil(t, meta)
+
+		secure, err := m.secureConfig(WithoutVerification(ctx), "storage-test", path)
+		require.NoError(t, err)
+		assert.Nil(t, secure)
+		meta, err = m.repo.Get("storage-test")
+		require.NoError(t, err)
+		assert.Nil(t, meta, "unverified launches record nothing")
+	})
+
+	t.Run("plugins that were not installed are trusted on first use", func(t *testing.T) {
+		secure, err := m.secureConfig(ctx, "storage-test", path)
+		require.NoError(t, err)
+		require.NotNil(t, secure)
+		sum := sha256.Sum256([]byte("binary"))
+		assert.Equal(t, sum[:], secure.Checksum)
+
+		meta, err := m.repo.Get("storage-test")
+		require.NoError(t, err)
+		require.NotNil(t, meta)
+		assert.Equal(t, checksum, meta.Checksum)
+	})
+
+	require.NoError(t, m.repo.Set("storage-test", &Metadata{PluginPath: "storage-test", Checksum: checksum}))
+
+	t.Run("installed plugins are pinned to their checksum", func(t *testing.T) {
+		secure, err := m.secureConfig(ctx, "storage-test", path)
+		require.NoError(t, err)
+		require.NotNil(t, secure)
+		sum := sha256.Sum256([]byte("binary"))
+		assert.Equal(t, sum[:], secure.Checksum)
+
+		ok, err := secure.Check(path)
+		require.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("pins copied into memory outlive the database", func(t *testing.T) {
+		metas, err := m.repo.List()
+		require.NoError(t, err)
+		repo := NewMemoryRepository()
+		for _, meta := range metas {
+			require.NoError(t, repo.Set(meta.PluginPath, meta))
+		}
+		detached := NewPluginManager(m.config, loggertest.Nop{}, repo).(*pluginManager)
+
+		secure, err := detached.secureConfig(ctx, "storage-test", path)
+		require.NoError(t, err)
+		require.NotNil(t, secure)
+		ok, err := secure.Check(path)
+		require.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("metadata updates keep the recorded checksum", func(t *testing.T) {
+		m.cacheMetadata(ctx, "storage-test", &Metadata{Name: "test", PluginPath: "storage-test", Version: "2.0.0", Checksum: "other"})
+		meta, err := m.repo.Get("storage-test")
+		require.NoError(t, err)
+		require.NotNil(t, meta)
+		assert.Equal(t, "2.0.0", meta.Version)
+		assert.Equal(t, checksum, meta.Checksum)
+	})
+
+	t.Run("verification can be skipped", func(t *testing.T) {
+		secure, err := m.secureConfig(WithoutVerification(ctx), "storage-test", path)
+		require.NoError(t, err)
+		assert.Nil(t, secure)
+	})
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	m.config.(staticConfig)[config.KeyCorePluginsTrustedKeys] = []any{base64.StdEncoding.EncodeToString(pub)}
+
+	t.Run("signature mode refuses unsigned plugins", func(t *testing.T) {
+		_, err := m.secureConfig(ctx, "storage-test", path)
+		assert.ErrorIs(t, err, ErrPluginUnsigned)
+		assert.ErrorContains(t, err, "--skip-plugin-verification")
+	})
+
+	t.Run("signature mode accepts trusted signatures", func(t *testing.T) {
+		sig := base64.StdEncoding.EncodeToString(ed25519.Sign(priv, []byte("binary")))
+		require.NoError(t, m.repo.Set("storage-test", &Metadata{PluginPath: "storage-test", Checksum: checksum, Signature: sig}))
+
+		secure, err := m.secureConfig(ctx, "storage-test", path)
+		require.NoError(t, err)
+		assert.NotNil(t, secure)
+	})
+
+	t.Run("signature mode refuses other signers", func(t *testing.T) {
+		_, other, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+		sig := base64.StdEncoding.EncodeToString(ed25519.Sign(other, []byte("binary")))
+		require.NoError(t, m.repo.Set("storage-test", &Metadata{PluginPath: "storage-test", Checksum: checksum, Signature: sig}))
+
+		_, err = m.secureConfig(ctx, "storage-test", path)
+		assert.ErrorIs(t, err, ErrPluginUnsigned)
+	})
+}
+
+func TestPluginManager_Install_RequiresSignature(t *testing.T) {
+	m, _ := newTestManager(t)
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	m.config.(staticConfig)[config.KeyCorePluginsTrustedKeys] = base64.StdEncoding.EncodeToString(pub)
+
+	src := filepath.Join(t.TempDir(), "storage-test")
+	require.NoError(t, os.WriteFile(src, []byte("binary"), 0755))
+
+	_, err = m.Install(context.Background(), src)
+	assert.ErrorIs(t, err, ErrPluginUnsigned)
+}
+
+func TestDecodeSignature(t *testing.T) {
+	raw := make([]byte, ed25519.SignatureSize)
+	encoded := base64.StdEncoding.EncodeToString(raw)
+
+	got, err := decodeSignature(raw)
+	require.NoError(t, err)
+	assert.Equal(t, encoded, got)
+
+	got, err = decodeSignature([]byte(encoded + "\n"))
+	require.NoError(t, err)
+	assert.Equal(t, encoded, got)
+
+	_, err = decodeSignature([]byte("not a signature"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,231 @@
+// Package progress renders VFS transfer progress for the CLI. It draws live progress
+// bars when attached to a terminal and emits periodic JSON lines for machine consumers.
+package progress
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mattn/go-isatty"
+
+	"github.com/michaeldcanady/go-onedrive/internal/features/vfs"
+	"github.com/michaeldcanady/go-onedrive/pkg/format"
+)
+
+const (
+	// FormatText renders progress bars on a terminal and a plain summary otherwise.
+	FormatText = "text"
+	// FormatJSON emits one JSON object per line.
+	FormatJSON = "json"
+
+	// redrawInterval bounds how often progress bars are redrawn.
+	redrawInterval = 100 * time.Millisecond
+	// jsonInterval bounds how often a JSON progress line is emitted for a single file.
+	jsonInterval = time.Second
+	// barWidth is the number of cells used by the bar itself.
+	barWidth = 24
+	// nameWidth is the number of cells reserved for the file path.
+	nameWidth = 32
+)
+
+// Renderer displays the progress of one or more concurrent transfers.
+// Its methods are safe for concurrent use.
+type Renderer interface {
+	// Update records a progress event. Pass it as a [vfs.ProgressFunc].
+	Update(p vfs.Progress)
+	// Summary reports the aggregate result once every transfer has finished.
+	Summary(s *vfs.Summary)
+	// Close stops rendering and removes any in-flight display.
+	Close() error
+}
+
+// NewRenderer returns a [Renderer] for the given output format. JSON lines and the text
+// summary are written to stdout; progress bars are drawn on stderr, and only when stderr
+// is a terminal.
+func NewRenderer(stdout, stderr io.Writer, outputFormat string) Renderer {
+	if outputFormat == FormatJSON {
+		return &jsonRenderer{w: stdout, last: make(map[string]time.Time)}
+	}
+	if isTerminal(stderr) {
+		return &barRenderer{w: stderr, summary: stdout, active: make(map[string]*vfs.Progress)}
+	}
+	return &plainRenderer{w: stdout}
+}
+
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// plainRenderer ignores progress events and prints the summary as a single line.
+type plainRenderer struct {
+	w io.Writer
+}
+
+func (r *plainRenderer) Update(vfs.Progress) {}
+
+func (r *plainRenderer) Summary(s *vfs.Summary) {
+	fmt.Fprintln(r.w, s)
+}
+
+func (r *plainRenderer) Close() error { return nil }
+
+// event is the envelope for every JSON line.
+type event struct {
+	Type string `json:"type"`
+	*vfs.Progress
+	Summary *vfs.Summary `json:"summary,omitempty"`
+}
+
+// jsonRenderer writes a "progress" line per file at most once per interval, always
+// including the final update, and a closing "summary" line.
+type jsonRenderer struct {
+	mu   sync.Mutex
+	w    io.Writer
+	enc  *json.Encoder
+	last map[string]time.Time
+}
+
+func (r *jsonRenderer) Update(p vfs.Progress) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if last, ok := r.last[p.Path]; ok && !p.Done && now.Sub(last) < jsonInterval {
+		return
+	}
+	if p.Done {
+		delete(r.last, p.Path)
+	} else {
+		r.last[p.Path] = now
+	}
+	r.encode(event{Type: "progress", Progress: &p})
+}
+
+func (r *jsonRenderer) Summary(s *vfs.Summary) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.encode(event{Type: "summary", Summary: s})
+}
+
+func (r *jsonRenderer) Close() error { return nil }
+
+func (r *jsonRenderer) encode(e event) {
+	if r.enc == nil {
+		r.enc = json.NewEncoder(r.w)
+	}
+	_ = r.enc.Encode(e)
+}
+
+// barRenderer redraws one line per in-flight transfer using ANSI escape sequences.
+// Finished transfers are printed once above the live block.
+type barRenderer struct {
+	mu       sync.Mutex
+	w        io.Writer
+	summary  io.Writer
+	active   map[string]*vfs.Progress
+	order    []string
+	finished []vfs.Progress
+	drawn    int
+	last     time.Time
+}
+
+func (r *barRenderer) Update(p vfs.Progress) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if p.Done {
+		if _, ok := r.active[p.Path]; ok {
+			delete(r.active, p.Path)
+			r.order = slices.DeleteFunc(r.order, func(s string) bool { return s == p.Path })
+		}
+		r.finished = append(r.finished, p)
+		r.draw()
+		return
+	}
+
+	if _, ok := r.active[p.Path]; !ok {
+		r.order = append(r.order, p.Path)
+	}
+	r.active[p.Path] = &p
+
+	if time.Since(r.last) >= redrawInterval {
+		r.draw()
+	}
+}
+
+func (r *barRenderer) Summary(s *vfs.Summary) {
+	r.Close()
+	fmt.Fprintln(r.summary, s)
+}
+
+func (r *barRenderer) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.active = make(map[string]*vfs.Progress)
+	r.order = nil
+	r.draw()
+	return nil
+}
+
+// draw must be called with mu held.
+func (r *barRenderer) draw() {
+	var b strings.Builder
+	if r.drawn > 0 {
+		// Return to the first line of the previous live block.
+		fmt.Fprintf(&b, "\x1b[%dF", r.drawn)
+	}
+	for _, p := range r.finished {
+		b.WriteString("\x1b[2K")
+		b.WriteString(formatLine(p))
+		b.WriteByte('\n')
+	}
+	for _, path := range r.order {
+		b.WriteString("\x1b[2K")
+		b.WriteString(formatLine(*r.active[path]))
+		b.WriteByte('\n')
+	}
+	// Clear anything left over from a taller previous block.
+	b.WriteString("\x1b[J")
+
+	io.WriteString(r.w, b.String())
+	r.finished = r.finished[:0]
+	r.drawn = len(r.order)
+	r.last = time.Now()
+}
+
+// formatLine renders a single progress line such as
+// "/docs/report.pdf  [=========>      ]  62%  1.2 MiB/2.0 MiB  850.0 KiB/s".
+func formatLine(p vfs.Progress) string {
+	name := p.Path
+	if len(name) > nameWidth {
+		name = "..." + name[len(name)-nameWidth+3:]
+	}
+
+	if p.Total <= 0 {
+		return fmt.Sprintf("%-*s  %s  %s/s", nameWidth, name, format.Bytes(float64(p.Transferred)), format.Bytes(p.Rate))
+	}
+
+	ratio := float64(p.Transferred) / float64(p.Total)
+	ratio = min(max(ratio, 0), 1)
+	filled := int(ratio * barWidth)
+
+	bar := strings.Repeat("=", filled)
+	if filled < barWidth {
+		bar += ">" + strings.Repeat(" ", barWidth-filled-1)
+	}
+
+	return fmt.Sprintf("%-*s  [%s] %3.0f%%  %s/%s  %s/s",
+		nameWidth, name, bar, ratio*100,
+		format.Bytes(float64(p.Transferred)), format.Bytes(float64(p.Total)), format.Bytes(p.Rate))
+}
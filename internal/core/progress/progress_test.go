@@ -0,0 +1,83 @@
+package progress
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/michaeldcanady/go-onedrive/internal/features/vfs"
+)
+
+func TestNewRenderer(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	assert.IsType(t, &jsonRenderer{}, NewRenderer(&stdout, &stderr, FormatJSON))
+	assert.IsType(t, &plainRenderer{}, NewRenderer(&stdout, &stderr, FormatText))
+}
+
+func TestJSONRenderer(t *testing.T) {
+	var out bytes.Buffer
+	r := NewRenderer(&out, &out, FormatJSON)
+
+	r.Update(vfs.Progress{Path: "/a", Transferred: 1, Total: 10})
+	// Throttled: a second update for the same file within the interval is dropped.
+	r.Update(vfs.Progress{Path: "/a", Transferred: 5, Total: 10})
+	r.Update(vfs.Progress{Path: "/b", Transferred: 2, Total: 4})
+	r.Update(vfs.Progress{Path: "/a", Transferred: 10, Total: 10, Done: true})
+	r.Summary(&vfs.Summary{Files: 2, Bytes: 14, Duration: time.Second})
+	assert.NoError(t, r.Close())
+
+	var events []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var e map[string]any
+		assert.NoError(t, json.Unmarshal([]byte(line), &e))
+		events = append(events, e)
+	}
+
+	if assert.Len(t, events, 4) {
+		assert.Equal(t, "progress", events[0]["type"])
+		assert.Equal(t, "/a", events[0]["path"])
+		assert.Equal(t, float64(1), events[0]["transferred"])
+		assert.Equal(t, "/b", events[1]["path"])
+		assert.Equal(t, true, events[2]["done"])
+		assert.Equal(t, "summary", events[3]["type"])
+		assert.Equal(t, float64(2), events[3]["summary"].(map[string]any)["files"])
+	}
+}
+
+func TestFormatLine(t *testing.T) {
+	tests := []struct {
+		name string
+		p    vfs.Progress
+		want []string
+	}{
+		{
+			name: "known total",
+			p:    vfs.Progress{Path: "/docs/report.pdf", Transferred: 512, Total: 1024, Rate: 2048},
+			want: []string{"/docs/report.pdf", "[============>           ]", " 50%", "512 B/1.0 KiB", "2.0 KiB/s"},
+		},
+		{
+			name: "unknown total",
+			p:    vfs.Progress{Path: "/stream", Transferred: 2048},
+			want: []string{"/stream", "2.0 KiB", "0 B/s"},
+		},
+		{
+			name: "long path is truncated from the left",
+			p:    vfs.Progress{Path: "/" + strings.Repeat("x", 40) + "/end.txt", Total: 1},
+			want: []string{"...", "x/end.txt"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line := formatLine(tt.p)
+			for _, w := range tt.want {
+				assert.Contains(t, line, w)
+			}
+		})
+	}
+}
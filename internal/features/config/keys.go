@@ -3,10 +3,31 @@ package config
 const (
 	// KeyCorePluginsDir is the configuration key for the plugins directory.
 	KeyCorePluginsDir = "core.plugins_dir"
+	// KeyCorePluginsTrustedKeys is the configuration key for the base64-encoded ed25519
+	// public keys that plugin signatures must verify against. Setting it requires every
+	// plugin to be signed.
+	KeyCorePluginsTrustedKeys = "core.plugins_trusted_keys"
+	// KeyCorePluginsCallTimeout is the configuration key for how long a unary plugin call
+	// other than Copy may take before the plugin is considered hung.
+	KeyCorePluginsCallTimeout = "core.plugins_call_timeout"
 	// KeyCoreLogDir is the configuration key for the log directory.
 	KeyCoreLogDir = "core.log_dir"
 	// KeyCoreVFSCWD is the configuration key for the virtual current working directory.
 	KeyCoreVFSCWD = "core.vfs_cwd"
+	// KeyCoreCacheTTL is the configuration key for how long VFS metadata is cached.
+	KeyCoreCacheTTL = "core.cache_ttl"
+	// KeyCoreCacheStore is the configuration key for where VFS metadata is cached.
+	KeyCoreCacheStore = "core.cache_store"
+
+	// KeyIdentityTokenStore is the configuration key for where authentication tokens are
+	// stored: plain, encrypted or keyring.
+	KeyIdentityTokenStore = "identity.token_store"
+	// KeyIdentityTokenKeyFile is the configuration key for the file whose contents encrypt
+	// tokens when the encrypted store is used without a passphrase.
+	KeyIdentityTokenKeyFile = "identity.token_key_file"
+	// KeyIdentityInteraction is the configuration key for how login interacts with the
+	// user: auto, browser, headless or none.
+	KeyIdentityInteraction = "identity.interaction"
 
 	// KeyIdentityAzureClientID is the configuration key for the Azure client ID.
 	KeyIdentityAzureClientID = "identity.azure.client_id"
@@ -23,4 +44,14 @@ const (
 	KeyIdentityGoogleClientSecret = "identity.google.client_secret"
 	// KeyIdentityGoogleRedirectURI is the configuration key for the Google redirect URI.
 	KeyIdentityGoogleRedirectURI = "identity.google.redirect_uri"
+
+	// KeyIdentityOIDCIssuer is the configuration key for the OpenID Connect issuer URL.
+	KeyIdentityOIDCIssuer = "identity.oidc.issuer"
+	// KeyIdentityOIDCClientID is the configuration key for the OpenID Connect client ID.
+	KeyIdentityOIDCClientID = "identity.oidc.client_id"
+	// KeyIdentityOIDCClientSecret is the configuration key for the OpenID Connect client
+	// secret, which public clients leave unset.
+	KeyIdentityOIDCClientSecret = "identity.oidc.client_secret"
+	// KeyIdentityOIDCRedirectURI is the configuration key for the OpenID Connect redirect URI.
+	KeyIdentityOIDCRedirectURI = "identity.oidc.redirect_uri"
 )
@@ -37,22 +37,22 @@ func (m *mockVFS) Mkdir(ctx context.Context, path string) error {
 	return args.Error(0)
 }
 
-func (m *mockVFS) Remove(ctx context.Context, path string) error {
+func (m *mockVFS) Remove(ctx context.Context, path string, options ...vfs.TreeOption) (*vfs.Summary, error) {
 	args := m.Called(ctx, path)
-	return args.Error(0)
+	return &vfs.Summary{}, args.Error(0)
 }
 
-func (m *mockVFS) Move(ctx context.Context, src, dst string) error {
+func (m *mockVFS) Move(ctx context.Context, src, dst string, options ...vfs.TreeOption) (*vfs.Summary, error) {
 	args := m.Called(ctx, src, dst)
-	return args.Error(0)
+	return &vfs.Summary{}, args.Error(0)
 }
 
-func (m *mockVFS) Copy(ctx context.Context, src, dst string) error {
+func (m *mockVFS) Copy(ctx context.Context, src, dst string, options ...vfs.TreeOption) (*vfs.Summary, error) {
 	args := m.Called(ctx, src, dst)
-	return args.Error(0)
+	return &vfs.Summary{}, args.Error(0)
 }
 
-func (m *mockVFS) Read(ctx context.Context, path string) (io.ReadCloser, error) {
+func (m *mockVFS) Read(ctx context.Context, path string, options ...vfs.ReadOption) (io.ReadCloser, error) {
 	args := m.Called(ctx, path)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -61,14 +61,19 @@ func (m *mockVFS) Read(ctx context.Context, path string) (io.ReadCloser, error)
 }
 
 func (m *mockVFS) Write(ctx context.Context, path string, reader io.Reader, options ...vfs.WriteOption) error {
-	opts := make(map[string]string)
+	opts := vfs.WriteOptions{Metadata: make(map[string]string)}
 	for _, opt := range options {
-		opt(opts)
+		opt(&opts)
 	}
-	args := m.Called(ctx, path, reader, opts)
+	args := m.Called(ctx, path, reader, opts.Metadata)
 	return args.Error(0)
 }
 
+func (m *mockVFS) Changes(ctx context.Context, path, cursor string) (*vfs.ChangeSet, error) {
+	args := m.Called(ctx, path, cursor)
+	return args.Get(0).(*vfs.ChangeSet), args.Error(1)
+}
+
 type mockEditor struct {
 	mock.Mock
 }
@@ -24,7 +24,7 @@ func CreateCatCmd(container di.Container) *cobra.Command {
 	)
 
 	cmd := &cobra.Command{
-		Use:   "cat <path>",
+		Use:   "cat <path> [flags]",
 		Short: "Display file contents",
 		Args:  cobra.ExactArgs(1),
 		PreRunE: func(cmd *cobra.Command, args []string) error {
@@ -52,6 +52,8 @@ func CreateCatCmd(container di.Container) *cobra.Command {
 			return handler.Finalize(c)
 		},
 	}
+	cmd.Flags().Int64Var(&opts.Offset, "offset", 0, "Byte offset at which to start reading")
+	cmd.Flags().Int64Var(&opts.Length, "length", 0, "Maximum number of bytes to read (0 reads to the end of the file)")
 
 	return cmd
 }
@@ -1,12 +1,18 @@
 package cat
 
 import (
+	"fmt"
 	"io"
 	"os"
+
+	"github.com/michaeldcanady/go-onedrive/internal/features/vfs"
 )
 
 // Validate performs initial validation of the command options.
 func (c *Command) Validate(ctx *CommandContext) error {
+	if ctx.Options.Offset < 0 || ctx.Options.Length < 0 {
+		return fmt.Errorf("offset and length must not be negative")
+	}
 	return nil
 }
 
@@ -17,7 +23,7 @@ func (c *Command) Resolve(ctx *CommandContext) error {
 
 // Execute performs the core business logic of the command.
 func (c *Command) Execute(ctx *CommandContext) error {
-	reader, err := c.fS.Read(ctx.Ctx, ctx.Options.Path)
+	reader, err := c.fS.Read(ctx.Ctx, ctx.Options.Path, vfs.WithRange(ctx.Options.Offset, ctx.Options.Length))
 	if err != nil {
 		return err
 	}
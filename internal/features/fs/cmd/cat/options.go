@@ -7,7 +7,9 @@ import (
 
 // Options encapsulates all user-provided arguments and flags for the command.
 type Options struct {
-	Path string // The filesystem path to the file to display.
+	Path   string // The filesystem path to the file to display.
+	Offset int64  // Byte offset at which to start reading
+	Length int64  // Maximum number of bytes to read (0 reads to the end of the file)
 
 	// Stdout receives standard output messages.
 	Stdout io.Writer
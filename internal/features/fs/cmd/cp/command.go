@@ -56,6 +56,8 @@ func CreateCpCmd(container di.Container) *cobra.Command {
 		},
 	}
 	cmd.Flags().BoolVarP(&opts.Recursive, "recursive", "r", false, "Copy directories recursively")
+	cmd.Flags().IntVarP(&opts.Parallel, "parallel", "j", 4, "Number of files to transfer in parallel")
+	cmd.Flags().StringVarP(&opts.Format, "format", "o", "text", "Output format (text, json)")
 
 	return cmd
 }
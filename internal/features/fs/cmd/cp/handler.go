@@ -2,6 +2,9 @@ package cp
 
 import (
 	"fmt"
+
+	"github.com/michaeldcanady/go-onedrive/internal/core/progress"
+	"github.com/michaeldcanady/go-onedrive/internal/features/vfs"
 )
 
 // Validate performs initial validation of the command options.
@@ -22,11 +25,29 @@ func (c *Command) Resolve(ctx *CommandContext) error {
 
 // Execute performs the core business logic of the command.
 func (c *Command) Execute(ctx *CommandContext) error {
-	return c.fS.Copy(ctx.Ctx, ctx.Options.Source, ctx.Options.Destination)
+	r := progress.NewRenderer(ctx.Options.Stdout, ctx.Options.Stderr, ctx.Options.Format)
+	defer r.Close()
+
+	options := []vfs.TreeOption{
+		vfs.WithConcurrency(ctx.Options.Parallel),
+		vfs.WithTransferProgress(r.Update),
+	}
+	if ctx.Options.Recursive {
+		options = append(options, vfs.WithRecursive())
+	}
+
+	summary, err := c.fS.Copy(ctx.Ctx, ctx.Options.Source, ctx.Options.Destination, options...)
+	if summary != nil {
+		r.Summary(summary)
+	}
+	return err
 }
 
 // Finalize performs any cleanup or final output formatting.
 func (c *Command) Finalize(ctx *CommandContext) error {
+	if ctx.Options.Format == progress.FormatJSON {
+		return nil
+	}
 	fmt.Printf("Copied %s to %s\n", ctx.Options.Source, ctx.Options.Destination)
 	return nil
 }
@@ -10,6 +10,8 @@ type Options struct {
 	Source      string // The path to the item to copy.
 	Destination string // The path where the item should be copied.
 	Recursive   bool   // Copy directories recursively
+	Parallel    int    // Number of files to transfer in parallel
+	Format      string // Output format (text, json)
 
 	// Stdout receives standard output messages.
 	Stdout io.Writer
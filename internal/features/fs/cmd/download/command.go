@@ -56,6 +56,9 @@ func CreateDownloadCmd(container di.Container) *cobra.Command {
 		},
 	}
 	cmd.Flags().BoolVarP(&opts.Recursive, "recursive", "r", false, "Download directories recursively")
+	cmd.Flags().BoolVarP(&opts.Resume, "resume", "c", false, "Resume a partial download by appending to an existing destination file")
+	cmd.Flags().IntVarP(&opts.Parallel, "parallel", "j", 4, "Number of files to transfer in parallel")
+	cmd.Flags().StringVarP(&opts.Format, "format", "o", "text", "Output format (text, json)")
 
 	return cmd
 }
@@ -1,9 +1,17 @@
 package download
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/michaeldcanady/go-onedrive/internal/core/progress"
+	"github.com/michaeldcanady/go-onedrive/internal/features/vfs"
 )
 
 // Validate performs initial validation of the command options.
@@ -24,24 +32,94 @@ func (c *Command) Resolve(ctx *CommandContext) error {
 
 // Execute performs the core business logic of the command.
 func (c *Command) Execute(ctx *CommandContext) error {
-	reader, err := c.fS.Read(ctx.Ctx, ctx.Options.Source)
+	node, err := c.fS.Stat(ctx.Ctx, ctx.Options.Source)
 	if err != nil {
 		return err
 	}
-	defer reader.Close()
+	if node.Type == vfs.DirectoryType && !ctx.Options.Recursive {
+		return fmt.Errorf("%s: %w (use --recursive)", ctx.Options.Source, vfs.ErrIsADirectory)
+	}
+
+	r := progress.NewRenderer(ctx.Options.Stdout, ctx.Options.Stderr, ctx.Options.Format)
+	defer r.Close()
+
+	sched := vfs.NewScheduler(ctx.Ctx, ctx.Options.Parallel)
+	if node.Type != vfs.DirectoryType {
+		err = sched.Submit(ctx.Options.Source, func(tctx context.Context) (int64, error) {
+			return c.downloadFile(tctx, ctx.Options.Resume, ctx.Options.Source, ctx.Options.Destination, r.Update)
+		})
+	} else {
+		err = c.downloadTree(ctx, sched, r.Update)
+	}
 
-	f, err := os.Create(ctx.Options.Destination)
+	summary := sched.Wait()
 	if err != nil {
 		return err
 	}
+
+	r.Summary(summary)
+	return summary.Err()
+}
+
+// downloadTree mirrors the remote directory tree under the local destination,
+// submitting a transfer to sched for every file.
+func (c *Command) downloadTree(ctx *CommandContext, sched *vfs.Scheduler, report vfs.ProgressFunc) error {
+	return vfs.Walk(ctx.Ctx, c.fS, ctx.Options.Source, func(p string, n *vfs.Node, err error) error {
+		if err != nil {
+			sched.Fail(p, err)
+			return nil
+		}
+
+		rel := strings.TrimPrefix(strings.TrimPrefix(p, path.Clean(ctx.Options.Source)), "/")
+		dst := filepath.Join(ctx.Options.Destination, filepath.FromSlash(rel))
+
+		if n.Type == vfs.DirectoryType {
+			if err := os.MkdirAll(dst, 0o755); err != nil {
+				sched.Fail(p, err)
+				return fs.SkipDir
+			}
+			sched.AddDirectory()
+			return nil
+		}
+
+		return sched.Submit(p, func(tctx context.Context) (int64, error) {
+			return c.downloadFile(tctx, ctx.Options.Resume, p, dst, report)
+		})
+	})
+}
+
+// downloadFile copies a single remote file to the local path dst, returning the number of bytes written.
+func (c *Command) downloadFile(ctx context.Context, resume bool, src, dst string, report vfs.ProgressFunc) (int64, error) {
+	var offset int64
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if resume {
+		// Continue from the bytes already on disk.
+		if info, err := os.Stat(dst); err == nil {
+			offset = info.Size()
+		}
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+
+	reader, err := c.fS.Read(ctx, src, vfs.WithRange(offset, 0), vfs.WithReadProgress(report))
+	if err != nil {
+		return 0, err
+	}
+	defer reader.Close()
+
+	f, err := os.OpenFile(dst, flags, 0o666)
+	if err != nil {
+		return 0, err
+	}
 	defer f.Close()
 
-	_, err = io.Copy(f, reader)
-	return err
+	return io.Copy(f, reader)
 }
 
 // Finalize performs any cleanup or final output formatting.
 func (c *Command) Finalize(ctx *CommandContext) error {
+	if ctx.Options.Format == progress.FormatJSON {
+		return nil
+	}
 	fmt.Printf("Downloaded %s to %s\n", ctx.Options.Source, ctx.Options.Destination)
 	return nil
 }
@@ -10,6 +10,9 @@ type Options struct {
 	Source      string // The remote path on OneDrive.
 	Destination string // The local path where the item should be downloaded.
 	Recursive   bool   // Download directories recursively
+	Resume      bool   // Resume a partial download by appending to an existing destination file
+	Parallel    int    // Number of files to transfer in parallel
+	Format      string // Output format (text, json)
 
 	// Stdout receives standard output messages.
 	Stdout io.Writer
@@ -24,7 +24,7 @@ func CreateMvCmd(container di.Container) *cobra.Command {
 	)
 
 	cmd := &cobra.Command{
-		Use:   "mv <source> <destination>",
+		Use:   "mv <source> <destination> [flags]",
 		Short: "Move files and directories",
 		Args:  cobra.ExactArgs(2),
 		PreRunE: func(cmd *cobra.Command, args []string) error {
@@ -55,6 +55,8 @@ func CreateMvCmd(container di.Container) *cobra.Command {
 			return handler.Finalize(c)
 		},
 	}
+	cmd.Flags().IntVarP(&opts.Parallel, "parallel", "j", 4, "Number of files to transfer in parallel")
+	cmd.Flags().StringVarP(&opts.Format, "format", "o", "text", "Output format (text, json)")
 
 	return cmd
 }
@@ -2,6 +2,9 @@ package mv
 
 import (
 	"fmt"
+
+	"github.com/michaeldcanady/go-onedrive/internal/core/progress"
+	"github.com/michaeldcanady/go-onedrive/internal/features/vfs"
 )
 
 // Validate performs initial validation of the command options.
@@ -22,11 +25,24 @@ func (c *Command) Resolve(ctx *CommandContext) error {
 
 // Execute performs the core business logic of the command.
 func (c *Command) Execute(ctx *CommandContext) error {
-	return c.fS.Move(ctx.Ctx, ctx.Options.Source, ctx.Options.Destination)
+	r := progress.NewRenderer(ctx.Options.Stdout, ctx.Options.Stderr, ctx.Options.Format)
+	defer r.Close()
+
+	summary, err := c.fS.Move(ctx.Ctx, ctx.Options.Source, ctx.Options.Destination,
+		vfs.WithConcurrency(ctx.Options.Parallel),
+		vfs.WithTransferProgress(r.Update),
+	)
+	if summary != nil {
+		r.Summary(summary)
+	}
+	return err
 }
 
 // Finalize performs any cleanup or final output formatting.
 func (c *Command) Finalize(ctx *CommandContext) error {
+	if ctx.Options.Format == progress.FormatJSON {
+		return nil
+	}
 	fmt.Printf("Moved %s to %s\n", ctx.Options.Source, ctx.Options.Destination)
 	return nil
 }
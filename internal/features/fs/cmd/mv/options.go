@@ -9,6 +9,8 @@ import (
 type Options struct {
 	Source      string // The current path of the item.
 	Destination string // The new path of the item.
+	Parallel    int    // Number of files to transfer in parallel
+	Format      string // Output format (text, json)
 
 	// Stdout receives standard output messages.
 	Stdout io.Writer
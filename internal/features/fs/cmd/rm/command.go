@@ -24,7 +24,7 @@ func CreateRmCmd(container di.Container) *cobra.Command {
 	)
 
 	cmd := &cobra.Command{
-		Use:   "rm <path>",
+		Use:   "rm <path> [flags]",
 		Short: "Remove files and directories",
 		Args:  cobra.ExactArgs(1),
 		PreRunE: func(cmd *cobra.Command, args []string) error {
@@ -52,6 +52,7 @@ func CreateRmCmd(container di.Container) *cobra.Command {
 			return handler.Finalize(c)
 		},
 	}
+	cmd.Flags().BoolVarP(&opts.Recursive, "recursive", "r", false, "Remove directories and their contents recursively")
 
 	return cmd
 }
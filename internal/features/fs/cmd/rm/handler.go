@@ -2,6 +2,8 @@ package rm
 
 import (
 	"fmt"
+
+	"github.com/michaeldcanady/go-onedrive/internal/features/vfs"
 )
 
 // Validate performs initial validation of the command options.
@@ -19,7 +21,16 @@ func (c *Command) Resolve(ctx *CommandContext) error {
 
 // Execute performs the core business logic of the command.
 func (c *Command) Execute(ctx *CommandContext) error {
-	return c.fS.Remove(ctx.Ctx, ctx.Options.Path)
+	var options []vfs.TreeOption
+	if ctx.Options.Recursive {
+		options = append(options, vfs.WithRecursive())
+	}
+
+	summary, err := c.fS.Remove(ctx.Ctx, ctx.Options.Path, options...)
+	if summary != nil && summary.Directories > 0 {
+		fmt.Fprintln(ctx.Options.Stdout, summary)
+	}
+	return err
 }
 
 // Finalize performs any cleanup or final output formatting.
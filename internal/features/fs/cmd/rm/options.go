@@ -7,7 +7,8 @@ import (
 
 // Options encapsulates all user-provided arguments and flags for the command.
 type Options struct {
-	Path string // The path to the file or directory to remove.
+	Path      string // The path to the file or directory to remove.
+	Recursive bool   // Remove directories and their contents recursively
 
 	// Stdout receives standard output messages.
 	Stdout io.Writer
@@ -0,0 +1,66 @@
+// Code generated by spec-gen. DO NOT EDIT.
+package sync
+
+import (
+	"github.com/michaeldcanady/go-onedrive/internal/core/di"
+	"github.com/spf13/cobra"
+)
+
+// CreateSyncCmd returns a new [cobra.Command] initialized with dependencies
+// and configured to execute the "sync" operation.
+func CreateSyncCmd(container di.Container) *cobra.Command {
+	var opts Options
+	var c *CommandContext
+
+	l := container.Logger().With("command", "sync")
+
+	// Create the handler using the generated factory
+	var handler Handler = NewCommand(
+		container.Sync(),
+		container.Profile(),
+		container.Logger(),
+		l,
+		container.Resolver(),
+	)
+
+	cmd := &cobra.Command{
+		Use:   "sync <source> <destination> [flags]",
+		Short: "Synchronize two directory trees",
+		Args:  cobra.ExactArgs(2),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				opts.Source = args[0]
+			}
+			if len(args) > 1 {
+				opts.Destination = args[1]
+			}
+			opts.Stdout = cmd.OutOrStdout()
+			opts.Stderr = cmd.ErrOrStderr()
+
+			c = &CommandContext{
+				Ctx:     cmd.Context(),
+				Options: opts,
+			}
+
+			if err := handler.Validate(c); err != nil {
+				return err
+			}
+
+			return handler.Resolve(c)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := handler.Execute(c); err != nil {
+				return err
+			}
+			return handler.Finalize(c)
+		},
+	}
+	cmd.Flags().BoolVar(&opts.TwoWay, "two-way", false, "Propagate changes in both directions")
+	cmd.Flags().BoolVarP(&opts.DryRun, "dry-run", "n", false, "Print the planned changes without applying them")
+	cmd.Flags().BoolVar(&opts.Delete, "delete", false, "Delete destination entries that are not in the source (one-way only)")
+	cmd.Flags().StringVar(&opts.Conflict, "conflict", "skip", "How to resolve files changed on both sides (skip, newer, source, destination)")
+	cmd.Flags().IntVarP(&opts.Parallel, "parallel", "j", 4, "Number of files to transfer in parallel")
+	cmd.Flags().StringVarP(&opts.Format, "format", "o", "text", "Output format (text, json)")
+
+	return cmd
+}
@@ -0,0 +1,100 @@
+package sync
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/michaeldcanady/go-onedrive/internal/core/progress"
+	"github.com/michaeldcanady/go-onedrive/internal/features/syncer"
+)
+
+// Validate ensures that the provided options are semantically correct.
+func (c *Command) Validate(ctx *CommandContext) error {
+	if ctx.Options.Source == "" {
+		return fmt.Errorf("source path is required")
+	}
+	if ctx.Options.Destination == "" {
+		return fmt.Errorf("destination path is required")
+	}
+	switch syncer.ConflictPolicy(ctx.Options.Conflict) {
+	case syncer.ConflictSkip, syncer.ConflictNewer, syncer.ConflictSource, syncer.ConflictDestination:
+	default:
+		return fmt.Errorf("unknown conflict policy: %s", ctx.Options.Conflict)
+	}
+	if ctx.Options.Delete && ctx.Options.TwoWay {
+		return fmt.Errorf("--delete cannot be combined with --two-way")
+	}
+	return nil
+}
+
+// Resolve translates user input into domain entities using the [resolver.Service].
+func (c *Command) Resolve(ctx *CommandContext) error {
+	return c.BaseResolve(ctx)
+}
+
+// Execute performs the primary business logic of the "sync" command.
+func (c *Command) Execute(ctx *CommandContext) error {
+	r := progress.NewRenderer(ctx.Options.Stdout, ctx.Options.Stderr, ctx.Options.Format)
+	defer r.Close()
+
+	opts := syncer.Options{
+		Mode:        syncer.ModeOneWay,
+		DryRun:      ctx.Options.DryRun,
+		Delete:      ctx.Options.Delete,
+		Conflict:    syncer.ConflictPolicy(ctx.Options.Conflict),
+		Concurrency: ctx.Options.Parallel,
+		Progress:    r.Update,
+	}
+	if ctx.Options.TwoWay {
+		opts.Mode = syncer.ModeTwoWay
+	}
+
+	if opts.DryRun {
+		actions, err := c.sync.Plan(ctx.Ctx, ctx.Options.Source, ctx.Options.Destination, opts)
+		if err != nil {
+			return err
+		}
+		printActions(ctx.Options.Stdout, ctx.Options.Format, actions)
+		return nil
+	}
+
+	result, err := c.sync.Sync(ctx.Ctx, ctx.Options.Source, ctx.Options.Destination, opts)
+	if result != nil {
+		printActions(ctx.Options.Stdout, ctx.Options.Format, result.Actions)
+		r.Summary(result.Summary)
+	}
+	return err
+}
+
+// Finalize performs post-execution tasks such as output formatting or resource cleanup.
+func (c *Command) Finalize(ctx *CommandContext) error {
+	if ctx.Options.Format == progress.FormatJSON {
+		return nil
+	}
+	if ctx.Options.DryRun {
+		fmt.Printf("Dry run: no changes made to %s\n", ctx.Options.Destination)
+		return nil
+	}
+	fmt.Printf("Synchronized %s with %s\n", ctx.Options.Source, ctx.Options.Destination)
+	return nil
+}
+
+// actionEvent is the JSON line written for each planned action.
+type actionEvent struct {
+	Type string `json:"type"`
+	syncer.Action
+}
+
+func printActions(w io.Writer, outputFormat string, actions []syncer.Action) {
+	if outputFormat == progress.FormatJSON {
+		enc := json.NewEncoder(w)
+		for _, a := range actions {
+			_ = enc.Encode(actionEvent{Type: "action", Action: a})
+		}
+		return
+	}
+	for _, a := range actions {
+		fmt.Fprintf(w, "%-8s %s (%s)\n", a.Op, a.Target, a.Reason)
+	}
+}
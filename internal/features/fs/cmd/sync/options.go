@@ -0,0 +1,23 @@
+// Code generated by spec-gen. DO NOT EDIT.
+package sync
+
+import (
+	"io"
+)
+
+// Options encapsulates all user-provided arguments and flags for the command.
+type Options struct {
+	Source      string // The directory to synchronize from.
+	Destination string // The directory to synchronize to.
+	TwoWay      bool   // Propagate changes in both directions
+	DryRun      bool   // Print the planned changes without applying them
+	Delete      bool   // Delete destination entries that are not in the source (one-way only)
+	Conflict    string // How to resolve files changed on both sides (skip, newer, source, destination)
+	Parallel    int    // Number of files to transfer in parallel
+	Format      string // Output format (text, json)
+
+	// Stdout receives standard output messages.
+	Stdout io.Writer
+	// Stderr receives error and diagnostic messages.
+	Stderr io.Writer
+}
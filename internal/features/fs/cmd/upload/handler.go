@@ -1,8 +1,16 @@
 package upload
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/michaeldcanady/go-onedrive/internal/core/progress"
+	"github.com/michaeldcanady/go-onedrive/internal/features/vfs"
 )
 
 // Validate performs initial validation of the command options.
@@ -23,17 +31,91 @@ func (c *Command) Resolve(ctx *CommandContext) error {
 
 // Execute performs the core business logic of the command.
 func (c *Command) Execute(ctx *CommandContext) error {
-	f, err := os.Open(ctx.Options.Source)
+	info, err := os.Stat(ctx.Options.Source)
 	if err != nil {
 		return err
 	}
+
+	if info.IsDir() && !ctx.Options.Recursive {
+		return fmt.Errorf("%s: %w (use --recursive)", ctx.Options.Source, vfs.ErrIsADirectory)
+	}
+
+	r := progress.NewRenderer(ctx.Options.Stdout, ctx.Options.Stderr, ctx.Options.Format)
+	defer r.Close()
+
+	sched := vfs.NewScheduler(ctx.Ctx, ctx.Options.Parallel)
+	if !info.IsDir() {
+		err = sched.Submit(ctx.Options.Source, func(tctx context.Context) (int64, error) {
+			return c.uploadFile(tctx, ctx.Options.Source, ctx.Options.Destination, r.Update)
+		})
+	} else {
+		err = c.uploadTree(ctx, sched, r.Update)
+	}
+
+	summary := sched.Wait()
+	if err != nil {
+		return err
+	}
+
+	r.Summary(summary)
+	return summary.Err()
+}
+
+// uploadTree mirrors the local directory tree under the remote destination,
+// submitting a transfer to sched for every file.
+func (c *Command) uploadTree(ctx *CommandContext, sched *vfs.Scheduler, report vfs.ProgressFunc) error {
+	return filepath.WalkDir(ctx.Options.Source, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			sched.Fail(p, err)
+			return nil
+		}
+
+		rel, err := filepath.Rel(ctx.Options.Source, p)
+		if err != nil {
+			sched.Fail(p, err)
+			return nil
+		}
+		dst := path.Join(ctx.Options.Destination, filepath.ToSlash(rel))
+
+		if d.IsDir() {
+			if err := c.fS.Mkdir(ctx.Ctx, dst); err != nil && !errors.Is(err, vfs.ErrAlreadyExists) {
+				sched.Fail(p, err)
+				return fs.SkipDir
+			}
+			sched.AddDirectory()
+			return nil
+		}
+
+		return sched.Submit(p, func(tctx context.Context) (int64, error) {
+			return c.uploadFile(tctx, p, dst, report)
+		})
+	})
+}
+
+// uploadFile copies a single local file to the remote path dst, returning its size in bytes.
+func (c *Command) uploadFile(ctx context.Context, src, dst string, report vfs.ProgressFunc) (int64, error) {
+	f, err := os.Open(src)
+	if err != nil {
+		return 0, err
+	}
 	defer f.Close()
 
-	return c.fS.Write(ctx.Ctx, ctx.Options.Destination, f)
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := c.fS.Write(ctx, dst, f, vfs.WithWriteProgress(info.Size(), report)); err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
 }
 
 // Finalize performs any cleanup or final output formatting.
 func (c *Command) Finalize(ctx *CommandContext) error {
+	if ctx.Options.Format == progress.FormatJSON {
+		return nil
+	}
 	fmt.Printf("Uploaded %s to %s\n", ctx.Options.Source, ctx.Options.Destination)
 	return nil
 }
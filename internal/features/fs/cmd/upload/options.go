@@ -10,6 +10,8 @@ type Options struct {
 	Source      string // The local path to the file or directory.
 	Destination string // The remote path on OneDrive where the item should be uploaded.
 	Recursive   bool   // Upload directories recursively
+	Parallel    int    // Number of files to transfer in parallel
+	Format      string // Output format (text, json)
 
 	// Stdout receives standard output messages.
 	Stdout io.Writer
@@ -0,0 +1,61 @@
+// Code generated by spec-gen. DO NOT EDIT.
+package fuse
+
+import (
+	"github.com/michaeldcanady/go-onedrive/internal/core/di"
+	"github.com/spf13/cobra"
+)
+
+// CreateFuseCmd returns a new [cobra.Command] initialized with dependencies
+// and configured to execute the "fuse" operation.
+func CreateFuseCmd(container di.Container) *cobra.Command {
+	var opts Options
+	var c *CommandContext
+
+	l := container.Logger().With("command", "fuse")
+
+	// Create the handler using the generated factory
+	var handler Handler = NewCommand(
+		container.Snapshot(),
+		container.Logger(),
+		l,
+		container.Resolver(),
+	)
+
+	cmd := &cobra.Command{
+		Use:   "fuse <mountpoint> [flags]",
+		Short: "Mount the virtual file system with FUSE",
+		Long:  `Expose the odc virtual file system at a local directory so that regular tools can read and write every mount.`,
+		Args:  cobra.ExactArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				opts.Mountpoint = args[0]
+			}
+			opts.Stdout = cmd.OutOrStdout()
+			opts.Stderr = cmd.ErrOrStderr()
+
+			c = &CommandContext{
+				Ctx:     cmd.Context(),
+				Options: opts,
+			}
+
+			if err := handler.Validate(c); err != nil {
+				return err
+			}
+
+			return handler.Resolve(c)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := handler.Execute(c); err != nil {
+				return err
+			}
+			return handler.Finalize(c)
+		},
+	}
+	cmd.Flags().StringVar(&opts.Root, "root", "/", "The virtual directory to expose at the mount point")
+	cmd.Flags().BoolVar(&opts.ReadOnly, "read-only", false, "Reject every change to the mounted files")
+	cmd.Flags().BoolVar(&opts.AllowOther, "allow-other", false, "Allow other users to access the mount")
+	cmd.Flags().BoolVar(&opts.Debug, "debug", false, "Log every FUSE request")
+
+	return cmd
+}
@@ -0,0 +1,80 @@
+package fuse
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/michaeldcanady/go-onedrive/internal/features/fusefs"
+)
+
+// Validate ensures that the provided options are semantically correct.
+func (c *Command) Validate(ctx *CommandContext) error {
+	if ctx.Options.Mountpoint == "" {
+		return fmt.Errorf("mountpoint is required")
+	}
+	info, err := os.Stat(ctx.Options.Mountpoint)
+	if err != nil {
+		return fmt.Errorf("invalid mountpoint: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("invalid mountpoint: %s is not a directory", ctx.Options.Mountpoint)
+	}
+	return nil
+}
+
+// Resolve translates user input into domain entities using the [resolver.Service].
+func (c *Command) Resolve(ctx *CommandContext) error {
+	return c.BaseResolve(ctx)
+}
+
+// Execute performs the primary business logic of the "fuse" command.
+func (c *Command) Execute(ctx *CommandContext) error {
+	snap, err := c.snapshot.Take(ctx.Ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := snap.Close(context.Background()); err != nil {
+			c.l.Warn("failed to write tokens back to state.db", "error", err)
+		}
+	}()
+
+	server, err := fusefs.Mount(ctx.Options.Mountpoint, snap.VFS, fusefs.Options{
+		Root:       ctx.Options.Root,
+		ReadOnly:   ctx.Options.ReadOnly,
+		AllowOther: ctx.Options.AllowOther,
+		Debug:      ctx.Options.Debug,
+	}, c.l)
+	if err != nil {
+		return fmt.Errorf("failed to mount %s: %w", ctx.Options.Mountpoint, err)
+	}
+	fmt.Fprintf(ctx.Options.Stdout, "Mounted %s at %s (press Ctrl+C to unmount)\n", ctx.Options.Root, ctx.Options.Mountpoint)
+
+	sigCtx, stop := signal.NotifyContext(ctx.Ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	done := make(chan struct{})
+	go func() {
+		server.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-sigCtx.Done():
+		if err := server.Unmount(); err != nil {
+			return fmt.Errorf("failed to unmount %s: %w", ctx.Options.Mountpoint, err)
+		}
+		<-done
+	}
+	return nil
+}
+
+// Finalize performs post-execution tasks such as output formatting or resource cleanup.
+func (c *Command) Finalize(ctx *CommandContext) error {
+	fmt.Fprintf(ctx.Options.Stdout, "Unmounted %s\n", ctx.Options.Mountpoint)
+	return nil
+}
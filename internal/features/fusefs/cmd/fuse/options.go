@@ -0,0 +1,20 @@
+// Code generated by spec-gen. DO NOT EDIT.
+package fuse
+
+import (
+	"io"
+)
+
+// Options encapsulates all user-provided arguments and flags for the command.
+type Options struct {
+	Mountpoint string // The local directory to mount the virtual file system on.
+	Root       string // The virtual directory to expose at the mount point
+	ReadOnly   bool   // Reject every change to the mounted files
+	AllowOther bool   // Allow other users to access the mount
+	Debug      bool   // Log every FUSE request
+
+	// Stdout receives standard output messages.
+	Stdout io.Writer
+	// Stderr receives error and diagnostic messages.
+	Stderr io.Writer
+}
@@ -0,0 +1,7 @@
+// Package fusefs exposes the virtual file system as a FUSE filesystem, so that ordinary
+// tools can work on every mount through the host's own file APIs. Directory listings
+// and attributes come from the VFS, reads are served with ranged fetches, and writes
+// are buffered in a temporary file that is uploaded when the file is closed.
+//
+// Mounting is supported on Linux only.
+package fusefs
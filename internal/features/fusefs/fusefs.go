@@ -0,0 +1,32 @@
+package fusefs
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrUnsupported is returned by [Mount] on platforms without FUSE support.
+var ErrUnsupported = errors.New("fuse mounts are not supported on this platform")
+
+// Options configures a FUSE mount.
+type Options struct {
+	// Root is the VFS directory exposed at the mount point. Defaults to "/".
+	Root string
+	// ReadOnly rejects every operation that would modify the VFS.
+	ReadOnly bool
+	// AllowOther lets users other than the one mounting access the filesystem.
+	AllowOther bool
+	// Debug logs every FUSE request.
+	Debug bool
+	// Timeout is how long the kernel may cache names and attributes. Defaults to one second.
+	Timeout time.Duration
+}
+
+// Server is a mounted filesystem.
+type Server interface {
+	// Wait blocks until the filesystem is unmounted.
+	Wait()
+
+	// Unmount detaches the filesystem from its mount point.
+	Unmount() error
+}
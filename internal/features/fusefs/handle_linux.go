@@ -0,0 +1,216 @@
+package fusefs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"syscall"
+
+	fusefs "github.com/hanwen/go-fuse/v2/fs"
+	"github.com/hanwen/go-fuse/v2/fuse"
+
+	"github.com/michaeldcanady/go-onedrive/internal/core/logger"
+	"github.com/michaeldcanady/go-onedrive/internal/features/vfs"
+)
+
+// handle is an open file. Read-only handles stream content from the VFS, reopening at
+// the requested offset whenever reads are not sequential. Writable handles work on a
+// temporary copy that is uploaded on flush, guarded by the ETag seen when opening.
+type handle struct {
+	fs   *filesystem
+	path string
+
+	// ctx outlives individual FUSE requests so that a stream can serve several reads.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu     sync.Mutex
+	reader io.ReadCloser
+	pos    int64
+	tmp    *os.File
+	etag   string
+	dirty  bool
+}
+
+var (
+	_ fusefs.FileReader    = (*handle)(nil)
+	_ fusefs.FileWriter    = (*handle)(nil)
+	_ fusefs.FileFlusher   = (*handle)(nil)
+	_ fusefs.FileFsyncer   = (*handle)(nil)
+	_ fusefs.FileReleaser  = (*handle)(nil)
+	_ fusefs.FileGetattrer = (*handle)(nil)
+)
+
+func newReadHandle(fs *filesystem, p string) *handle {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &handle{fs: fs, path: p, ctx: ctx, cancel: cancel}
+}
+
+// newWriteHandle creates a writable handle for p. When keep is set the current content
+// is downloaded first, so that partial writes preserve the rest of the file.
+func newWriteHandle(ctx context.Context, fs *filesystem, p, etag string, keep bool) (*handle, error) {
+	tmp, err := os.CreateTemp("", "odc-fuse-*")
+	if err != nil {
+		return nil, err
+	}
+	h := newReadHandle(fs, p)
+	h.tmp, h.etag = tmp, etag
+
+	if keep {
+		r, err := fs.vfs.Read(ctx, p)
+		if err == nil {
+			_, err = io.Copy(tmp, r)
+			r.Close()
+		}
+		if err != nil {
+			h.Release(ctx)
+			return nil, err
+		}
+	}
+	return h, nil
+}
+
+func (h *handle) writable() bool {
+	return h.tmp != nil
+}
+
+func (h *handle) Read(ctx context.Context, dest []byte, off int64) (fuse.ReadResult, syscall.Errno) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.writable() {
+		n, err := h.tmp.ReadAt(dest, off)
+		if err != nil && err != io.EOF {
+			return nil, syscall.EIO
+		}
+		return fuse.ReadResultData(dest[:n]), 0
+	}
+
+	if h.reader == nil || off != h.pos {
+		if h.reader != nil {
+			h.reader.Close()
+		}
+		r, err := h.fs.vfs.Read(h.ctx, h.path, vfs.WithRange(off, 0))
+		if err != nil {
+			h.reader = nil
+			return nil, h.fail(ctx, "read", err)
+		}
+		h.reader, h.pos = r, off
+	}
+
+	n, err := io.ReadFull(h.reader, dest)
+	h.pos += int64(n)
+	if err != nil && err != io.EOF && !errors.Is(err, io.ErrUnexpectedEOF) {
+		h.reader.Close()
+		h.reader = nil
+		return nil, h.fail(ctx, "read", err)
+	}
+	return fuse.ReadResultData(dest[:n]), 0
+}
+
+func (h *handle) Write(ctx context.Context, data []byte, off int64) (uint32, syscall.Errno) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !h.writable() {
+		return 0, syscall.EBADF
+	}
+	n, err := h.tmp.WriteAt(data, off)
+	if n > 0 {
+		h.dirty = true
+	}
+	if err != nil {
+		return uint32(n), syscall.EIO
+	}
+	return uint32(n), 0
+}
+
+func (h *handle) truncate(size int64) syscall.Errno {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := h.tmp.Truncate(size); err != nil {
+		return syscall.EIO
+	}
+	h.dirty = true
+	return 0
+}
+
+// Flush uploads pending changes. Writes are conditional on the ETag the file had when
+// it was opened, so changes made elsewhere in the meantime are not overwritten.
+func (h *handle) Flush(ctx context.Context) syscall.Errno {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !h.dirty {
+		return 0
+	}
+	if _, err := h.tmp.Seek(0, io.SeekStart); err != nil {
+		return syscall.EIO
+	}
+	var opts []vfs.WriteOption
+	if h.etag != "" {
+		opts = append(opts, vfs.WithIfMatch(h.etag))
+	}
+	if err := h.fs.vfs.Write(ctx, h.path, h.tmp, opts...); err != nil {
+		return h.fail(ctx, "flush", err)
+	}
+	h.dirty = false
+
+	// Later flushes from the same handle must match the version just written.
+	if v, err := h.fs.vfs.Stat(ctx, h.path); err == nil {
+		h.etag = v.ETag
+	}
+	return 0
+}
+
+func (h *handle) Fsync(ctx context.Context, flags uint32) syscall.Errno {
+	return h.Flush(ctx)
+}
+
+func (h *handle) Release(ctx context.Context) syscall.Errno {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.cancel()
+	if h.reader != nil {
+		h.reader.Close()
+		h.reader = nil
+	}
+	if h.tmp != nil {
+		if h.dirty {
+			logger.WithContext(h.fs.logger, ctx).Warn("discarding unflushed fuse writes", "path", h.path)
+		}
+		h.tmp.Close()
+		os.Remove(h.tmp.Name())
+		h.tmp = nil
+	}
+	return 0
+}
+
+func (h *handle) Getattr(ctx context.Context, out *fuse.AttrOut) syscall.Errno {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !h.writable() {
+		v, err := h.fs.vfs.Stat(ctx, h.path)
+		if err != nil {
+			return h.fail(ctx, "getattr", err)
+		}
+		fillAttr(v, &out.Attr)
+		return 0
+	}
+	info, err := h.tmp.Stat()
+	if err != nil {
+		return syscall.EIO
+	}
+	fillAttr(&vfs.Node{Type: vfs.FileType, Size: info.Size(), ModifiedAt: info.ModTime().Unix()}, &out.Attr)
+	return 0
+}
+
+func (h *handle) fail(ctx context.Context, op string, err error) syscall.Errno {
+	logger.WithContext(h.fs.logger, ctx).Warn("fuse operation failed", "operation", op, "path", h.path, "error", err)
+	return toErrno(err)
+}
@@ -0,0 +1,104 @@
+package fusefs
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path"
+	"syscall"
+	"time"
+
+	fusefs "github.com/hanwen/go-fuse/v2/fs"
+	"github.com/hanwen/go-fuse/v2/fuse"
+
+	"github.com/michaeldcanady/go-onedrive/internal/core/logger"
+	"github.com/michaeldcanady/go-onedrive/internal/features/vfs"
+)
+
+// Mount exposes v at the host directory dir and returns once the filesystem is ready.
+// The caller must unmount the returned [Server] when done.
+func Mount(dir string, v vfs.VFS, opts Options, l logger.Service) (Server, error) {
+	if opts.Root == "" {
+		opts.Root = "/"
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = time.Second
+	}
+
+	root := &node{fs: &filesystem{vfs: v, root: path.Clean(opts.Root), readOnly: opts.ReadOnly, logger: l}}
+	server, err := fusefs.Mount(dir, root, &fusefs.Options{
+		MountOptions: fuse.MountOptions{
+			AllowOther:  opts.AllowOther,
+			Debug:       opts.Debug,
+			FsName:      "odc",
+			Name:        "odc",
+			DirectMount: true,
+		},
+		EntryTimeout:    &opts.Timeout,
+		AttrTimeout:     &opts.Timeout,
+		NegativeTimeout: &opts.Timeout,
+		UID:             uint32(os.Getuid()),
+		GID:             uint32(os.Getgid()),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return server, nil
+}
+
+// filesystem holds the state shared by every node of a mount.
+type filesystem struct {
+	vfs      vfs.VFS
+	root     string
+	readOnly bool
+	logger   logger.Service
+}
+
+// toErrno maps VFS errors onto the errno values tools expect.
+func toErrno(err error) syscall.Errno {
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, vfs.ErrNotFound):
+		return syscall.ENOENT
+	case errors.Is(err, vfs.ErrAlreadyExists):
+		return syscall.EEXIST
+	case errors.Is(err, vfs.ErrPermissionDenied):
+		return syscall.EACCES
+	case errors.Is(err, vfs.ErrNotEmpty):
+		return syscall.ENOTEMPTY
+	case errors.Is(err, vfs.ErrNotADirectory):
+		return syscall.ENOTDIR
+	case errors.Is(err, vfs.ErrIsADirectory):
+		return syscall.EISDIR
+	case errors.Is(err, vfs.ErrInvalidPath):
+		return syscall.EINVAL
+	case errors.Is(err, vfs.ErrUnavailable), errors.Is(err, vfs.ErrThrottled):
+		return syscall.EAGAIN
+	case errors.Is(err, context.Canceled):
+		return syscall.EINTR
+	default:
+		return syscall.EIO
+	}
+}
+
+// fillAttr describes n in out.
+func fillAttr(n *vfs.Node, out *fuse.Attr) {
+	if n.Type == vfs.DirectoryType {
+		out.Mode = syscall.S_IFDIR | 0755
+	} else {
+		out.Mode = syscall.S_IFREG | 0644
+		out.Size = uint64(n.Size)
+		out.Blocks = (out.Size + 511) / 512
+	}
+	mtime := uint64(max(n.ModifiedAt, 0))
+	out.Mtime, out.Ctime, out.Atime = mtime, mtime, mtime
+	out.Nlink = 1
+}
+
+func stableMode(n *vfs.Node) uint32 {
+	if n.Type == vfs.DirectoryType {
+		return syscall.S_IFDIR
+	}
+	return syscall.S_IFREG
+}
@@ -0,0 +1,163 @@
+package fusefs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/michaeldcanady/go-onedrive/internal/core/logger/loggertest"
+	"github.com/michaeldcanady/go-onedrive/internal/features/vfs"
+	"github.com/michaeldcanady/go-onedrive/internal/features/vfs/vfstest"
+)
+
+func mountVFS(t *testing.T, fs vfs.VFS, opts Options) string {
+	t.Helper()
+	dir := t.TempDir()
+	opts.Timeout = time.Millisecond
+	server, err := Mount(dir, fs, opts, loggertest.Nop{})
+	if err != nil {
+		t.Skipf("fuse unavailable: %v", err)
+	}
+	t.Cleanup(func() { _ = server.Unmount() })
+	return dir
+}
+
+func TestMount(t *testing.T) {
+	fs := vfstest.NewMemFS(map[string]string{"/docs/a.txt": "hello world"})
+	dir := mountVFS(t, fs, Options{})
+
+	t.Run("lists and reads files", func(t *testing.T) {
+		entries, err := os.ReadDir(filepath.Join(dir, "docs"))
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, "a.txt", entries[0].Name())
+
+		data, err := os.ReadFile(filepath.Join(dir, "docs", "a.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "hello world", string(data))
+	})
+
+	t.Run("reads ranges", func(t *testing.T) {
+		f, err := os.Open(filepath.Join(dir, "docs", "a.txt"))
+		require.NoError(t, err)
+		defer f.Close()
+
+		buf := make([]byte, 5)
+		_, err = f.ReadAt(buf, 6)
+		require.NoError(t, err)
+		assert.Equal(t, "world", string(buf))
+	})
+
+	t.Run("creates files on close", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "docs", "b.txt"), []byte("new"), 0644))
+
+		data, ok := fs.File("/docs/b.txt")
+		assert.True(t, ok)
+		assert.Equal(t, "new", data)
+	})
+
+	t.Run("updates files with if-match", func(t *testing.T) {
+		f, err := os.OpenFile(filepath.Join(dir, "docs", "a.txt"), os.O_WRONLY|os.O_APPEND, 0)
+		require.NoError(t, err)
+		_, err = f.WriteString("!")
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+
+		data, _ := fs.File("/docs/a.txt")
+		assert.Equal(t, "hello world!", data)
+		assert.Contains(t, fs.IfMatch(), "1")
+	})
+
+	t.Run("manages directories", func(t *testing.T) {
+		require.NoError(t, os.Mkdir(filepath.Join(dir, "docs", "sub"), 0755))
+		require.NoError(t, os.Rename(filepath.Join(dir, "docs", "b.txt"), filepath.Join(dir, "docs", "sub", "b.txt")))
+
+		data, ok := fs.File("/docs/sub/b.txt")
+		assert.True(t, ok)
+		assert.Equal(t, "new", data)
+
+		err := os.Remove(filepath.Join(dir, "docs", "sub"))
+		assert.ErrorIs(t, err, syscall.ENOTEMPTY)
+		require.NoError(t, os.Remove(filepath.Join(dir, "docs", "sub", "b.txt")))
+		require.NoError(t, os.Remove(filepath.Join(dir, "docs", "sub")))
+
+		_, err = fs.Stat(context.Background(), "/docs/sub")
+		assert.ErrorIs(t, err, vfs.ErrNotFound)
+	})
+}
+
+func TestMount_ReadOnly(t *testing.T) {
+	fs := vfstest.NewMemFS(map[string]string{"/a.txt": "hello"})
+	dir := mountVFS(t, fs, Options{ReadOnly: true})
+
+	data, err := os.ReadFile(filepath.Join(dir, "a.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+
+	err = os.WriteFile(filepath.Join(dir, "a.txt"), []byte("changed"), 0644)
+	assert.ErrorIs(t, err, syscall.EROFS)
+	err = os.Mkdir(filepath.Join(dir, "dir"), 0755)
+	assert.ErrorIs(t, err, syscall.EROFS)
+}
+
+func TestMount_LocalPlugin(t *testing.T) {
+	local := vfstest.NewLocal(t, "/local")
+	dir := mountVFS(t, local, Options{})
+	require.NoError(t, os.WriteFile(local.Path("/local/a.txt"), []byte("hello world"), 0644))
+
+	t.Run("reads files", func(t *testing.T) {
+		data, err := os.ReadFile(filepath.Join(dir, "local", "a.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "hello world", string(data))
+	})
+
+	t.Run("writes files", func(t *testing.T) {
+		require.NoError(t, os.Mkdir(filepath.Join(dir, "local", "docs"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "local", "docs", "b.txt"), []byte("new"), 0644))
+
+		data, err := os.ReadFile(local.Path("/local/docs/b.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "new", string(data))
+	})
+
+	t.Run("renames files", func(t *testing.T) {
+		require.NoError(t, os.Rename(filepath.Join(dir, "local", "docs", "b.txt"), filepath.Join(dir, "local", "c.txt")))
+
+		assert.NoFileExists(t, local.Path("/local/docs/b.txt"))
+		data, err := os.ReadFile(local.Path("/local/c.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "new", string(data))
+	})
+
+	t.Run("updates files with if-match", func(t *testing.T) {
+		f, err := os.OpenFile(filepath.Join(dir, "local", "a.txt"), os.O_WRONLY|os.O_APPEND, 0)
+		require.NoError(t, err)
+		_, err = f.WriteString("!")
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+
+		data, err := os.ReadFile(local.Path("/local/a.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "hello world!", string(data))
+	})
+
+	t.Run("keeps changes made elsewhere", func(t *testing.T) {
+		f, err := os.OpenFile(filepath.Join(dir, "local", "a.txt"), os.O_WRONLY|os.O_APPEND, 0)
+		require.NoError(t, err)
+		_, err = f.WriteString("?")
+		require.NoError(t, err)
+
+		require.NoError(t, os.WriteFile(local.Path("/local/a.txt"), []byte("edited elsewhere"), 0644))
+		assert.ErrorIs(t, f.Close(), syscall.EIO)
+
+		data, err := os.ReadFile(local.Path("/local/a.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "edited elsewhere", string(data))
+	})
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package fusefs
+
+import (
+	"github.com/michaeldcanady/go-onedrive/internal/core/logger"
+	"github.com/michaeldcanady/go-onedrive/internal/features/vfs"
+)
+
+// Mount reports [ErrUnsupported]; FUSE mounts are available on Linux only.
+func Mount(dir string, v vfs.VFS, opts Options, l logger.Service) (Server, error) {
+	return nil, ErrUnsupported
+}
@@ -0,0 +1,216 @@
+package fusefs
+
+import (
+	"context"
+	"path"
+	"syscall"
+
+	fusefs "github.com/hanwen/go-fuse/v2/fs"
+	"github.com/hanwen/go-fuse/v2/fuse"
+
+	"github.com/michaeldcanady/go-onedrive/internal/core/logger"
+	"github.com/michaeldcanady/go-onedrive/internal/features/vfs"
+)
+
+// node is a file or directory in the mounted tree. Its VFS path is derived from its
+// position in the inode tree, so renames need no bookkeeping.
+type node struct {
+	fusefs.Inode
+	fs *filesystem
+}
+
+var (
+	_ fusefs.NodeGetattrer = (*node)(nil)
+	_ fusefs.NodeSetattrer = (*node)(nil)
+	_ fusefs.NodeLookuper  = (*node)(nil)
+	_ fusefs.NodeReaddirer = (*node)(nil)
+	_ fusefs.NodeOpener    = (*node)(nil)
+	_ fusefs.NodeCreater   = (*node)(nil)
+	_ fusefs.NodeMkdirer   = (*node)(nil)
+	_ fusefs.NodeUnlinker  = (*node)(nil)
+	_ fusefs.NodeRmdirer   = (*node)(nil)
+	_ fusefs.NodeRenamer   = (*node)(nil)
+)
+
+func (n *node) vfsPath() string {
+	return path.Join(n.fs.root, n.Path(nil))
+}
+
+func (n *node) child(name string) string {
+	return path.Join(n.vfsPath(), name)
+}
+
+func (n *node) newChild(ctx context.Context, v *vfs.Node, out *fuse.EntryOut) *fusefs.Inode {
+	fillAttr(v, &out.Attr)
+	return n.NewInode(ctx, &node{fs: n.fs}, fusefs.StableAttr{Mode: stableMode(v)})
+}
+
+func (n *node) fail(ctx context.Context, op, p string, err error) syscall.Errno {
+	errno := toErrno(err)
+	if errno != syscall.ENOENT {
+		logger.WithContext(n.fs.logger, ctx).Warn("fuse operation failed", "operation", op, "path", p, "error", err)
+	}
+	return errno
+}
+
+func (n *node) Getattr(ctx context.Context, f fusefs.FileHandle, out *fuse.AttrOut) syscall.Errno {
+	if h, ok := f.(*handle); ok && h.writable() {
+		return h.Getattr(ctx, out)
+	}
+	v, err := n.fs.vfs.Stat(ctx, n.vfsPath())
+	if err != nil {
+		return n.fail(ctx, "getattr", n.vfsPath(), err)
+	}
+	fillAttr(v, &out.Attr)
+	return 0
+}
+
+// Setattr supports truncation, which editors and shell redirection rely on. Other
+// attribute changes are accepted and ignored, since the backends do not store them.
+func (n *node) Setattr(ctx context.Context, f fusefs.FileHandle, in *fuse.SetAttrIn, out *fuse.AttrOut) syscall.Errno {
+	if size, ok := in.GetSize(); ok {
+		if n.fs.readOnly {
+			return syscall.EROFS
+		}
+		if h, ok := f.(*handle); ok && h.writable() {
+			if errno := h.truncate(int64(size)); errno != 0 {
+				return errno
+			}
+		} else if errno := n.truncate(ctx, int64(size)); errno != 0 {
+			return errno
+		}
+	}
+	return n.Getattr(ctx, f, out)
+}
+
+// truncate resizes the file through a temporary handle and uploads the result.
+func (n *node) truncate(ctx context.Context, size int64) syscall.Errno {
+	flags := uint32(syscall.O_WRONLY)
+	if size == 0 {
+		flags |= syscall.O_TRUNC
+	}
+	h, errno := n.openHandle(ctx, flags)
+	if errno != 0 {
+		return errno
+	}
+	defer h.Release(ctx)
+	if errno := h.truncate(size); errno != 0 {
+		return errno
+	}
+	return h.Flush(ctx)
+}
+
+func (n *node) Lookup(ctx context.Context, name string, out *fuse.EntryOut) (*fusefs.Inode, syscall.Errno) {
+	v, err := n.fs.vfs.Stat(ctx, n.child(name))
+	if err != nil {
+		return nil, n.fail(ctx, "lookup", n.child(name), err)
+	}
+	return n.newChild(ctx, v, out), 0
+}
+
+func (n *node) Readdir(ctx context.Context) (fusefs.DirStream, syscall.Errno) {
+	nodes, err := n.fs.vfs.List(ctx, n.vfsPath())
+	if err != nil {
+		return nil, n.fail(ctx, "readdir", n.vfsPath(), err)
+	}
+	entries := make([]fuse.DirEntry, 0, len(nodes))
+	for _, v := range nodes {
+		entries = append(entries, fuse.DirEntry{Name: v.Name, Mode: stableMode(v)})
+	}
+	return fusefs.NewListDirStream(entries), 0
+}
+
+func (n *node) Open(ctx context.Context, flags uint32) (fusefs.FileHandle, uint32, syscall.Errno) {
+	h, errno := n.openHandle(ctx, flags)
+	if errno != 0 {
+		return nil, 0, errno
+	}
+	return h, 0, 0
+}
+
+func (n *node) openHandle(ctx context.Context, flags uint32) (*handle, syscall.Errno) {
+	p := n.vfsPath()
+	if flags&syscall.O_ACCMODE == syscall.O_RDONLY {
+		return newReadHandle(n.fs, p), 0
+	}
+	if n.fs.readOnly {
+		return nil, syscall.EROFS
+	}
+
+	v, err := n.fs.vfs.Stat(ctx, p)
+	if err != nil {
+		return nil, n.fail(ctx, "open", p, err)
+	}
+	if v.Type == vfs.DirectoryType {
+		return nil, syscall.EISDIR
+	}
+	h, err := newWriteHandle(ctx, n.fs, p, v.ETag, flags&syscall.O_TRUNC == 0)
+	if err != nil {
+		return nil, n.fail(ctx, "open", p, err)
+	}
+	return h, 0
+}
+
+func (n *node) Create(ctx context.Context, name string, flags uint32, mode uint32, out *fuse.EntryOut) (*fusefs.Inode, fusefs.FileHandle, uint32, syscall.Errno) {
+	if n.fs.readOnly {
+		return nil, nil, 0, syscall.EROFS
+	}
+	p := n.child(name)
+	h, err := newWriteHandle(ctx, n.fs, p, "", false)
+	if err != nil {
+		return nil, nil, 0, n.fail(ctx, "create", p, err)
+	}
+	// The file appears in the backend when the handle is first flushed.
+	h.dirty = true
+	inode := n.newChild(ctx, &vfs.Node{Name: name, Path: p, Type: vfs.FileType}, out)
+	return inode, h, 0, 0
+}
+
+func (n *node) Mkdir(ctx context.Context, name string, mode uint32, out *fuse.EntryOut) (*fusefs.Inode, syscall.Errno) {
+	if n.fs.readOnly {
+		return nil, syscall.EROFS
+	}
+	p := n.child(name)
+	if err := n.fs.vfs.Mkdir(ctx, p); err != nil {
+		return nil, n.fail(ctx, "mkdir", p, err)
+	}
+	return n.newChild(ctx, &vfs.Node{Name: name, Path: p, Type: vfs.DirectoryType}, out), 0
+}
+
+func (n *node) Unlink(ctx context.Context, name string) syscall.Errno {
+	return n.remove(ctx, name)
+}
+
+func (n *node) Rmdir(ctx context.Context, name string) syscall.Errno {
+	return n.remove(ctx, name)
+}
+
+func (n *node) remove(ctx context.Context, name string) syscall.Errno {
+	if n.fs.readOnly {
+		return syscall.EROFS
+	}
+	p := n.child(name)
+	if _, err := n.fs.vfs.Remove(ctx, p); err != nil {
+		return n.fail(ctx, "remove", p, err)
+	}
+	return 0
+}
+
+func (n *node) Rename(ctx context.Context, name string, newParent fusefs.InodeEmbedder, newName string, flags uint32) syscall.Errno {
+	if n.fs.readOnly {
+		return syscall.EROFS
+	}
+	// Exchanging entries or refusing to replace cannot be expressed through the VFS.
+	if flags != 0 {
+		return syscall.ENOTSUP
+	}
+	parent, ok := newParent.(*node)
+	if !ok {
+		return syscall.EXDEV
+	}
+	src, dst := n.child(name), parent.child(newName)
+	if _, err := n.fs.vfs.Move(ctx, src, dst, vfs.WithRecursive()); err != nil {
+		return n.fail(ctx, "rename", src, err)
+	}
+	return 0
+}
@@ -0,0 +1,149 @@
+package identity
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/michaeldcanady/go-onedrive/internal/core/logger"
+)
+
+const (
+	// DefaultAgentInterval is how often the agent looks for tokens that are due a refresh.
+	DefaultAgentInterval = time.Minute
+	// DefaultAgentRefreshBefore is how long before expiry the agent refreshes a token. It
+	// is well ahead of the five minutes GetToken allows, so clients never refresh inline.
+	DefaultAgentRefreshBefore = 15 * time.Minute
+
+	// agentSocketEnv overrides where the agent listens and clients look for it.
+	agentSocketEnv = "ODC_AGENT_SOCKET"
+)
+
+// DefaultAgentSocket returns the path of the agent's Unix socket, which is
+// ~/.config/odc/agent.sock unless $ODC_AGENT_SOCKET is set.
+func DefaultAgentSocket() (string, error) {
+	if p := os.Getenv(agentSocketEnv); p != "" {
+		return p, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "odc", "agent.sock"), nil
+}
+
+// Agent keeps the tokens in its repository fresh and serves them to other odc processes
+// over HTTP, normally on a Unix socket.
+type Agent struct {
+	tokens        TokenService
+	repo          Repository
+	refreshBefore time.Duration
+	logger        logger.Service
+}
+
+// NewAgent returns a new [*Agent] that serves tokens through ts and refreshes those in
+// repo, which must be the repository ts reads from, once they expire within
+// refreshBefore.
+func NewAgent(ts TokenService, repo Repository, refreshBefore time.Duration, l logger.Service) *Agent {
+	return &Agent{
+		tokens:        ts,
+		repo:          repo,
+		refreshBefore: refreshBefore,
+		logger:        l,
+	}
+}
+
+// Run refreshes due tokens every interval until ctx is done.
+func (a *Agent) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		a.RefreshDue(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RefreshDue refreshes every token that expires within the agent's refresh window and
+// returns how many were refreshed. Failures are logged and retried on the next pass.
+func (a *Agent) RefreshDue(ctx context.Context) int {
+	l := logger.WithContext(a.logger, ctx)
+
+	identities, err := a.repo.ListIdentities()
+	if err != nil {
+		l.Error("failed to list identities", "error", err)
+		return 0
+	}
+
+	refreshed := 0
+	for _, i := range identities {
+		token, err := a.repo.GetToken(i.Provider, i.ID)
+		if err != nil || token == nil || time.Until(token.ExpiresAt) >= a.refreshBefore {
+			continue
+		}
+		if _, err := a.tokens.RefreshToken(ctx, i.Provider, i.ID); err != nil {
+			l.Warn("failed to refresh token", "provider", i.Provider, "identity", i.ID, "error", err)
+			continue
+		}
+		l.Info("refreshed token", "provider", i.Provider, "identity", i.ID)
+		refreshed++
+	}
+	return refreshed
+}
+
+// forget drops an identity and its token, so the agent neither refreshes nor serves them
+// again, and returns the token it held.
+func (a *Agent) forget(provider, identityID string) (*Token, error) {
+	token, err := a.repo.GetToken(provider, identityID)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.repo.DeleteToken(provider, identityID); err != nil {
+		return nil, err
+	}
+	return token, a.repo.DeleteIdentity(identityID)
+}
+
+// ServeHTTP answers GET /token, POST /refresh and DELETE /token, all identifying the
+// token by the provider and identity query parameters. DELETE /token makes the agent
+// forget the identity and answers with the token it held, if any.
+func (a *Agent) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	provider, identityID := r.URL.Query().Get("provider"), r.URL.Query().Get("identity")
+	if provider == "" || identityID == "" {
+		http.Error(w, "provider and identity are required", http.StatusBadRequest)
+		return
+	}
+
+	var token *Token
+	var err error
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/token":
+		token, err = a.tokens.GetToken(r.Context(), provider, identityID)
+	case r.Method == http.MethodPost && r.URL.Path == "/refresh":
+		token, err = a.tokens.RefreshToken(r.Context(), provider, identityID)
+	case r.Method == http.MethodDelete && r.URL.Path == "/token":
+		token, err = a.forget(provider, identityID)
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	if token == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(token); err != nil {
+		logger.WithContext(a.logger, r.Context()).Warn("failed to write token", "error", err)
+	}
+}
@@ -0,0 +1,164 @@
+package identity
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/michaeldcanady/go-onedrive/internal/core/logger"
+)
+
+// agentTimeout bounds a request to the agent, which may include a refresh it performs
+// on the caller's behalf.
+const agentTimeout = 30 * time.Second
+
+// AgentClient is a [TokenService] that asks a running agent for tokens and falls back to
+// a local [TokenService] when no agent answers.
+type AgentClient struct {
+	local  TokenService
+	client *http.Client
+	logger logger.Service
+}
+
+// NewAgentClient returns a new [*AgentClient] that reaches the agent on the Unix socket
+// at socket and otherwise uses local.
+func NewAgentClient(socket string, local TokenService, l logger.Service) *AgentClient {
+	return NewAgentClientWithTransport(&http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socket)
+		},
+	}, local, l)
+}
+
+// NewAgentClientWithTransport returns a new [*AgentClient] that reaches the agent through
+// transport.
+func NewAgentClientWithTransport(transport http.RoundTripper, local TokenService, l logger.Service) *AgentClient {
+	return &AgentClient{
+		local:  local,
+		client: &http.Client{Transport: transport, Timeout: agentTimeout},
+		logger: l,
+	}
+}
+
+// Local returns the service used when no agent answers. The agent itself serves tokens
+// from it so that it never calls itself.
+func (c *AgentClient) Local() TokenService {
+	return c.local
+}
+
+// Available reports whether an agent answers on the client's socket.
+func (c *AgentClient) Available(ctx context.Context) bool {
+	_, err := c.call(ctx, http.MethodGet, "/token", "", "")
+	var agentErr *agentError
+	return err == nil || errors.As(err, &agentErr)
+}
+
+func (c *AgentClient) GetToken(ctx context.Context, provider string, identityID string) (*Token, error) {
+	return c.fetch(ctx, http.MethodGet, "/token", provider, identityID, c.local.GetToken)
+}
+
+func (c *AgentClient) RefreshToken(ctx context.Context, provider string, identityID string) (*Token, error) {
+	return c.fetch(ctx, http.MethodPost, "/refresh", provider, identityID, c.local.RefreshToken)
+}
+
+func (c *AgentClient) SaveToken(ctx context.Context, provider string, identityID string, token *Token) error {
+	return c.local.SaveToken(ctx, provider, identityID, token)
+}
+
+func (c *AgentClient) RevokeToken(ctx context.Context, provider string, identityID string, options map[string]string) (bool, error) {
+	if err := c.forget(ctx, provider, identityID); err != nil {
+		return false, err
+	}
+	return c.local.RevokeToken(ctx, provider, identityID, options)
+}
+
+func (c *AgentClient) DeleteToken(ctx context.Context, provider string, identityID string) error {
+	if err := c.forget(ctx, provider, identityID); err != nil {
+		return err
+	}
+	return c.local.DeleteToken(ctx, provider, identityID)
+}
+
+func (c *AgentClient) MigrateTokens(ctx context.Context) (int, error) {
+	return c.local.MigrateTokens(ctx)
+}
+
+// fetch asks the agent for a token, falling back to local when the agent cannot provide
+// one. A token from the agent is saved locally, so state.db keeps any refresh token the
+// agent rotated.
+func (c *AgentClient) fetch(ctx context.Context, method, path, provider, identityID string, local func(context.Context, string, string) (*Token, error)) (*Token, error) {
+	l := logger.WithContext(c.logger, ctx)
+
+	token, err := c.call(ctx, method, path, provider, identityID)
+	if err != nil {
+		l.Debug("token agent unavailable, using local token service", "error", err)
+		return local(ctx, provider, identityID)
+	}
+	if err := c.local.SaveToken(ctx, provider, identityID, token); err != nil {
+		l.Warn("failed to save token from agent", "provider", provider, "identity", identityID, "error", err)
+	}
+	return token, nil
+}
+
+// forget asks a running agent to drop an identity, so that it stops refreshing the token
+// and no later fetch saves it back. The token the agent held may carry a refresh token
+// rotated since state.db last saw it, so it is saved locally for revocation to use.
+// Nothing happens when no agent answers.
+func (c *AgentClient) forget(ctx context.Context, provider, identityID string) error {
+	token, err := c.call(ctx, http.MethodDelete, "/token", provider, identityID)
+	var agentErr *agentError
+	switch {
+	case errors.As(err, &agentErr):
+		return fmt.Errorf("failed to remove %s:%s from the token agent: %w", provider, identityID, err)
+	case err != nil:
+		logger.WithContext(c.logger, ctx).Debug("token agent unavailable", "error", err)
+		return nil
+	case token == nil:
+		return nil
+	}
+	return c.local.SaveToken(ctx, provider, identityID, token)
+}
+
+// agentError is a request the agent answered with an error.
+type agentError struct {
+	status  int
+	message string
+}
+
+func (e *agentError) Error() string {
+	return fmt.Sprintf("agent returned %d: %s", e.status, e.message)
+}
+
+func (c *AgentClient) call(ctx context.Context, method, path, provider, identityID string) (*Token, error) {
+	q := url.Values{"provider": {provider}, "identity": {identityID}}
+	req, err := http.NewRequestWithContext(ctx, method, "http://agent"+path+"?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNoContent {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, &agentError{status: resp.StatusCode, message: strings.TrimSpace(string(body))}
+	}
+	var token Token
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, fmt.Errorf("invalid token from agent: %w", err)
+	}
+	return &token, nil
+}
@@ -0,0 +1,247 @@
+package identity
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/michaeldcanady/go-onedrive/internal/core/logger/loggertest"
+	"github.com/michaeldcanady/go-onedrive/internal/core/plugins"
+	"github.com/michaeldcanady/go-onedrive/internal/features/config"
+	identity_proto "github.com/michaeldcanady/go-onedrive/internal/features/plugins/proto/identity"
+)
+
+// fakeIdentityPlugin counts refreshes and issues a new access token for each one. When
+// gate is set, refreshes wait for it to close. Refresh options and logouts are recorded,
+// and logouts are answered with logoutErr.
+type fakeIdentityPlugin struct {
+	identity_proto.IdentityPluginClient
+	refreshes      atomic.Int32
+	gate           chan struct{}
+	mu             sync.Mutex
+	refreshOptions []map[string]string
+	logouts        []*identity_proto.LogoutRequest
+	logoutErr      error
+}
+
+func (p *fakeIdentityPlugin) Logout(ctx context.Context, in *identity_proto.LogoutRequest, opts ...grpc.CallOption) (*identity_proto.LogoutResponse, error) {
+	p.logouts = append(p.logouts, in)
+	if p.logoutErr != nil {
+		return nil, p.logoutErr
+	}
+	return &identity_proto.LogoutResponse{Success: true}, nil
+}
+
+func (p *fakeIdentityPlugin) Refresh(ctx context.Context, in *identity_proto.RefreshRequest, opts ...grpc.CallOption) (*identity_proto.RefreshResponse, error) {
+	n := p.refreshes.Add(1)
+	p.mu.Lock()
+	p.refreshOptions = append(p.refreshOptions, in.Options)
+	p.mu.Unlock()
+	if p.gate != nil {
+		<-p.gate
+	}
+	return &identity_proto.RefreshResponse{Token: &identity_proto.AccessToken{
+		AccessToken:  "access-" + strconv.Itoa(int(n)),
+		RefreshToken: "refresh-" + strconv.Itoa(int(n)),
+		ExpiresAt:    time.Now().Add(time.Hour).Unix(),
+	}}, nil
+}
+
+// fakePlugins is a [plugins.Manager] that only serves identity plugins.
+type fakePlugins struct {
+	plugins.Manager
+	identity *fakeIdentityPlugin
+}
+
+func (f fakePlugins) GetIdentityPlugin(name string) (identity_proto.IdentityPluginClient, error) {
+	return f.identity, nil
+}
+
+func newTestTokenService(t *testing.T, repo Repository, plugin *fakeIdentityPlugin) *DefaultTokenService {
+	t.Helper()
+	cs := config.NewConfigService(config.NewYAMLRepository(filepath.Join(t.TempDir(), "config.yaml")), loggertest.Nop{})
+	return NewTokenService(repo, fakePlugins{identity: plugin}, cs, loggertest.Nop{})
+}
+
+func saveTestToken(t *testing.T, repo Repository, id string, expiresIn time.Duration) {
+	t.Helper()
+	require.NoError(t, repo.SaveIdentity(&Identity{ID: id, Provider: "azure"}))
+	require.NoError(t, repo.SaveToken("azure", id, &Token{AccessToken: "old", RefreshToken: "old-refresh", ExpiresAt: time.Now().Add(expiresIn)}))
+}
+
+func TestTokenService_RefreshTokenSingleFlight(t *testing.T) {
+	repo := NewMemoryRepository()
+	saveTestToken(t, repo, "alice", time.Minute)
+	plugin := &fakeIdentityPlugin{gate: make(chan struct{})}
+	ts := newTestTokenService(t, repo, plugin)
+
+	const callers = 8
+	var wg sync.WaitGroup
+	tokens := make([]*Token, callers)
+	for i := range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			token, err := ts.GetToken(context.Background(), "azure", "alice")
+			assert.NoError(t, err)
+			tokens[i] = token
+		}()
+	}
+
+	require.Eventually(t, func() bool { return plugin.refreshes.Load() == 1 }, time.Second, time.Millisecond)
+	// Give the other callers time to join the refresh in flight before it completes.
+	time.Sleep(20 * time.Millisecond)
+	close(plugin.gate)
+	wg.Wait()
+
+	assert.EqualValues(t, 1, plugin.refreshes.Load(), "concurrent callers share one refresh")
+	for _, token := range tokens {
+		require.NotNil(t, token)
+		assert.Equal(t, "access-1", token.AccessToken)
+	}
+
+	_, err := ts.RefreshToken(context.Background(), "azure", "alice")
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, plugin.refreshes.Load(), "a later refresh runs again")
+}
+
+func TestAgent_RefreshDue(t *testing.T) {
+	repo := NewMemoryRepository()
+	saveTestToken(t, repo, "due", 10*time.Minute)
+	saveTestToken(t, repo, "fresh", time.Hour)
+	plugin := &fakeIdentityPlugin{}
+	agent := NewAgent(newTestTokenService(t, repo, plugin), repo, DefaultAgentRefreshBefore, loggertest.Nop{})
+
+	assert.Equal(t, 1, agent.RefreshDue(context.Background()))
+
+	due, err := repo.GetToken("azure", "due")
+	require.NoError(t, err)
+	assert.Equal(t, "access-1", due.AccessToken)
+	fresh, err := repo.GetToken("azure", "fresh")
+	require.NoError(t, err)
+	assert.Equal(t, "old", fresh.AccessToken)
+}
+
+func TestAgentClient(t *testing.T) {
+	agentRepo := NewMemoryRepository()
+	saveTestToken(t, agentRepo, "alice", time.Hour)
+	require.NoError(t, agentRepo.SaveToken("azure", "alice", &Token{AccessToken: "from-agent", RefreshToken: "agent-refresh", ExpiresAt: time.Now().Add(time.Hour)}))
+	agentPlugin := &fakeIdentityPlugin{}
+	agent := NewAgent(newTestTokenService(t, agentRepo, agentPlugin), agentRepo, DefaultAgentRefreshBefore, loggertest.Nop{})
+
+	srv := httptest.NewServer(agent)
+	t.Cleanup(srv.Close)
+	toAgent := &http.Transport{DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, "tcp", srv.Listener.Addr().String())
+	}}
+
+	localRepo := NewMemoryRepository()
+	saveTestToken(t, localRepo, "alice", time.Hour)
+	local := newTestTokenService(t, localRepo, &fakeIdentityPlugin{})
+
+	t.Run("served by the agent", func(t *testing.T) {
+		client := NewAgentClientWithTransport(toAgent, local, loggertest.Nop{})
+		assert.True(t, client.Available(context.Background()))
+
+		token, err := client.GetToken(context.Background(), "azure", "alice")
+		require.NoError(t, err)
+		assert.Equal(t, "from-agent", token.AccessToken)
+
+		saved, err := localRepo.GetToken("azure", "alice")
+		require.NoError(t, err)
+		assert.Equal(t, "from-agent", saved.AccessToken, "tokens from the agent are saved locally")
+
+		token, err = client.RefreshToken(context.Background(), "azure", "alice")
+		require.NoError(t, err)
+		assert.Equal(t, "access-1", token.AccessToken)
+		assert.EqualValues(t, 1, agentPlugin.refreshes.Load(), "the agent performs the refresh")
+	})
+
+	t.Run("falls back without an agent", func(t *testing.T) {
+		client := NewAgentClient(filepath.Join(t.TempDir(), "missing.sock"), local, loggertest.Nop{})
+		assert.False(t, client.Available(context.Background()))
+
+		require.NoError(t, localRepo.SaveToken("azure", "alice", &Token{AccessToken: "local", ExpiresAt: time.Now().Add(time.Hour)}))
+		token, err := client.GetToken(context.Background(), "azure", "alice")
+		require.NoError(t, err)
+		assert.Equal(t, "local", token.AccessToken)
+	})
+
+	t.Run("falls back for identities the agent does not hold", func(t *testing.T) {
+		client := NewAgentClientWithTransport(toAgent, local, loggertest.Nop{})
+		saveTestToken(t, localRepo, "bob", time.Hour)
+		token, err := client.GetToken(context.Background(), "azure", "bob")
+		require.NoError(t, err)
+		assert.Equal(t, "old", token.AccessToken)
+	})
+}
+
+func TestAgent_ServeHTTP(t *testing.T) {
+	agent := NewAgent(newTestTokenService(t, NewMemoryRepository(), &fakeIdentityPlugin{}), NewMemoryRepository(), time.Minute, loggertest.Nop{})
+
+	rec := httptest.NewRecorder()
+	agent.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/token", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	agent.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/token?provider=azure&identity=alice", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = httptest.NewRecorder()
+	agent.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/token?provider=azure&identity=alice", nil))
+	assert.Equal(t, http.StatusNoContent, rec.Code, "forgetting an unknown identity succeeds")
+
+	rec = httptest.NewRecorder()
+	agent.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/token?provider=azure&identity=alice", nil))
+	assert.Equal(t, http.StatusBadGateway, rec.Code)
+}
+
+func TestAgentClient_LogoutWithAgent(t *testing.T) {
+	ctx := context.Background()
+	agentRepo := NewMemoryRepository()
+	saveTestToken(t, agentRepo, "alice", time.Hour)
+	require.NoError(t, agentRepo.SaveToken("azure", "alice", &Token{AccessToken: "from-agent", RefreshToken: "rotated-refresh", ExpiresAt: time.Now().Add(time.Hour)}))
+	agent := NewAgent(newTestTokenService(t, agentRepo, &fakeIdentityPlugin{}), agentRepo, DefaultAgentRefreshBefore, loggertest.Nop{})
+
+	srv := httptest.NewServer(agent)
+	t.Cleanup(srv.Close)
+	toAgent := &http.Transport{DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, "tcp", srv.Listener.Addr().String())
+	}}
+
+	localRepo := NewMemoryRepository()
+	saveTestToken(t, localRepo, "alice", time.Hour)
+	plugin := &fakeIdentityPlugin{}
+	client := NewAgentClientWithTransport(toAgent, newTestTokenService(t, localRepo, plugin), loggertest.Nop{})
+	service := NewIdentityService(localRepo, fakePlugins{identity: plugin}, client, loggertest.Nop{})
+
+	revoked, err := service.Logout(ctx, "alice", nil)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+	require.Len(t, plugin.logouts, 1)
+	assert.Equal(t, "rotated-refresh", plugin.logouts[0].Options["refresh_token"], "the token the agent held is revoked")
+
+	held, err := agentRepo.GetToken("azure", "alice")
+	require.NoError(t, err)
+	assert.Nil(t, held, "the agent forgets the token")
+	assert.Zero(t, agent.RefreshDue(ctx), "the agent stops refreshing the identity")
+
+	_, err = client.GetToken(ctx, "azure", "alice")
+	assert.Error(t, err)
+	saved, err := localRepo.GetToken("azure", "alice")
+	require.NoError(t, err)
+	assert.Nil(t, saved, "nothing saves the token back")
+}
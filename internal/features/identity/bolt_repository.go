@@ -13,24 +13,38 @@ var (
 )
 
 type boltRepository struct {
-	db *bbolt.DB
+	db     *bbolt.DB
+	tokens TokenStore
+	// plain holds tokens written before a secure store was configured. It is nil when
+	// tokens is itself the plaintext store.
+	plain TokenStore
 }
 
-// NewBoltRepository creates a new bbolt-based identity repository.
+// NewBoltRepository creates a new bbolt-based identity repository that stores tokens
+// unencrypted alongside the identities.
 func NewBoltRepository(db *bbolt.DB) (Repository, error) {
+	return NewBoltRepositoryWithTokenStore(db, nil)
+}
+
+// NewBoltRepositoryWithTokenStore creates a new bbolt-based identity repository that
+// keeps tokens in the given store. A nil store keeps them unencrypted in db.
+func NewBoltRepositoryWithTokenStore(db *bbolt.DB, tokens TokenStore) (Repository, error) {
 	err := db.Update(func(tx *bbolt.Tx) error {
 		_, err := tx.CreateBucketIfNotExists(identitiesBucket)
-		if err != nil {
-			return err
-		}
-		_, err = tx.CreateBucketIfNotExists(tokensBucket)
 		return err
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize identity buckets: %w", err)
 	}
 
-	return &boltRepository{db: db}, nil
+	plain, err := NewPlainTokenStore(db)
+	if err != nil {
+		return nil, err
+	}
+	if tokens == nil {
+		return &boltRepository{db: db, tokens: plain}, nil
+	}
+	return &boltRepository{db: db, tokens: tokens, plain: plain}, nil
 }
 
 func (r *boltRepository) SaveIdentity(i *Identity) error {
@@ -83,40 +97,92 @@ func (r *boltRepository) DeleteIdentity(id string) error {
 	})
 }
 
+func tokenKey(provider string, identityID string) string {
+	return fmt.Sprintf("%s:%s", provider, identityID)
+}
+
 func (r *boltRepository) SaveToken(provider string, identityID string, t *Token) error {
-	key := fmt.Sprintf("%s:%s", provider, identityID)
-	return r.db.Update(func(tx *bbolt.Tx) error {
-		b := tx.Bucket(tokensBucket)
-		// nolint:gosec
-		data, err := json.Marshal(t)
-		if err != nil {
-			return err
-		}
-		return b.Put([]byte(key), data)
-	})
+	key := tokenKey(provider, identityID)
+	// nolint:gosec
+	data, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	if err := r.tokens.Put(key, data); err != nil {
+		return err
+	}
+	// A fresh token supersedes any plaintext copy left from before migration.
+	if r.plain != nil {
+		return r.plain.Delete(key)
+	}
+	return nil
 }
 
 func (r *boltRepository) GetToken(provider string, identityID string) (*Token, error) {
-	key := fmt.Sprintf("%s:%s", provider, identityID)
-	var t Token
-	err := r.db.View(func(tx *bbolt.Tx) error {
-		b := tx.Bucket(tokensBucket)
-		v := b.Get([]byte(key))
-		if v == nil {
-			return nil
+	key := tokenKey(provider, identityID)
+	data, err := r.tokens.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil && r.plain != nil {
+		if legacy, err := r.plain.Get(key); err == nil && legacy != nil {
+			return nil, fmt.Errorf("the token for %s is stored unencrypted; run 'odc identity migrate' to move it to the configured token store", key)
 		}
-		return json.Unmarshal(v, &t)
-	})
+	}
+	if data == nil {
+		return nil, nil
+	}
+
+	var t Token
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, err
+	}
 	if t.AccessToken == "" {
 		return nil, nil
 	}
-	return &t, err
+	return &t, nil
 }
 
 func (r *boltRepository) DeleteToken(provider string, identityID string) error {
-	key := fmt.Sprintf("%s:%s", provider, identityID)
-	return r.db.Update(func(tx *bbolt.Tx) error {
-		b := tx.Bucket(tokensBucket)
-		return b.Delete([]byte(key))
+	key := tokenKey(provider, identityID)
+	if err := r.tokens.Delete(key); err != nil {
+		return err
+	}
+	if r.plain != nil {
+		return r.plain.Delete(key)
+	}
+	return nil
+}
+
+func (r *boltRepository) MigrateTokens() (int, error) {
+	if r.plain == nil {
+		return 0, nil
+	}
+
+	var keys []string
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(tokensBucket).ForEach(func(k, v []byte) error {
+			keys = append(keys, string(k))
+			return nil
+		})
 	})
+	if err != nil {
+		return 0, err
+	}
+
+	migrated := 0
+	for _, key := range keys {
+		data, err := r.plain.Get(key)
+		if err != nil {
+			return migrated, err
+		}
+		if err := r.tokens.Put(key, data); err != nil {
+			return migrated, fmt.Errorf("failed to migrate token %s: %w", key, err)
+		}
+		if err := r.plain.Delete(key); err != nil {
+			return migrated, err
+		}
+		migrated++
+	}
+	return migrated, nil
 }
@@ -0,0 +1,58 @@
+// Code generated by spec-gen. DO NOT EDIT.
+package agent
+
+import (
+	"github.com/michaeldcanady/go-onedrive/internal/core/di"
+	"github.com/spf13/cobra"
+)
+
+// CreateAgentCmd returns a new [cobra.Command] initialized with dependencies
+// and configured to execute the "agent" operation.
+func CreateAgentCmd(container di.Container) *cobra.Command {
+	var opts Options
+	var c *CommandContext
+
+	l := container.Logger().With("command", "agent")
+
+	// Create the handler using the generated factory
+	var handler Handler = NewCommand(
+		container.Token(),
+		container.Snapshot(),
+		container.Logger(),
+		l,
+		container.Resolver(),
+	)
+
+	cmd := &cobra.Command{
+		Use:   "agent [flags]",
+		Short: "Keep tokens fresh in the background",
+		Long: `Run a long-lived agent that refreshes authentication tokens before they expire and
+serves them to other odc commands over a Unix socket.
+`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			opts.Stdout = cmd.OutOrStdout()
+			opts.Stderr = cmd.ErrOrStderr()
+
+			c = &CommandContext{
+				Ctx:     cmd.Context(),
+				Options: opts,
+			}
+
+			if err := handler.Validate(c); err != nil {
+				return err
+			}
+
+			return handler.Resolve(c)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := handler.Execute(c); err != nil {
+				return err
+			}
+			return handler.Finalize(c)
+		},
+	}
+	cmd.Flags().StringVar(&opts.Interval, "interval", "1m", "How often to look for tokens that are due a refresh")
+	cmd.Flags().StringVar(&opts.RefreshBefore, "refresh-before", "15m", "How long before expiry a token is refreshed")
+
+	return cmd
+}
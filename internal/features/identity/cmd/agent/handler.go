@@ -0,0 +1,143 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/michaeldcanady/go-onedrive/internal/features/identity"
+	"github.com/michaeldcanady/go-onedrive/internal/features/snapshot"
+)
+
+// shutdownTimeout bounds how long in-flight requests may run after an interrupt.
+const shutdownTimeout = 10 * time.Second
+
+// Validate ensures that the provided options are semantically correct.
+func (c *Command) Validate(ctx *CommandContext) error {
+	if _, err := parseDuration("--interval", ctx.Options.Interval); err != nil {
+		return err
+	}
+	_, err := parseDuration("--refresh-before", ctx.Options.RefreshBefore)
+	return err
+}
+
+// Resolve translates user input into domain entities using the [resolver.Service].
+func (c *Command) Resolve(ctx *CommandContext) error {
+	return c.BaseResolve(ctx)
+}
+
+// Execute performs the primary business logic of the "agent" command.
+func (c *Command) Execute(ctx *CommandContext) error {
+	interval, _ := parseDuration("--interval", ctx.Options.Interval)
+	refreshBefore, _ := parseDuration("--refresh-before", ctx.Options.RefreshBefore)
+
+	socket, err := identity.DefaultAgentSocket()
+	if err != nil {
+		return err
+	}
+
+	if client, ok := c.token.(*identity.AgentClient); ok && client.Available(ctx.Ctx) {
+		return fmt.Errorf("an agent is already running on %s", socket)
+	}
+
+	// bbolt locks state.db for as long as it is open, which would block every other
+	// command. From here on the agent works from a snapshot in memory, whose plugin
+	// manager verifies relaunched plugins against the recorded metadata and whose
+	// repository writes refreshed tokens back to state.db.
+	snap, err := c.snapshot.Take(ctx.Ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := snap.Close(context.Background()); err != nil {
+			c.l.Warn("failed to write tokens back to state.db", "error", err)
+		}
+	}()
+	if err := c.load(ctx.Ctx, snap); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(socket), 0700); err != nil {
+		return err
+	}
+	if err := os.Remove(socket); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove stale socket %s: %w", socket, err)
+	}
+	listener, err := listen(socket)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", socket, err)
+	}
+	defer os.Remove(socket)
+
+	agent := identity.NewAgent(snap.Tokens, snap.Identities, refreshBefore, c.l)
+	server := &http.Server{Handler: agent, ReadHeaderTimeout: 30 * time.Second}
+	fmt.Fprintf(ctx.Options.Stdout, "Agent listening on %s (press Ctrl+C to stop)\n", socket)
+
+	sigCtx, stop := signal.NotifyContext(ctx.Ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go agent.Run(sigCtx, interval)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.Serve(listener)
+	}()
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("agent failed: %w", err)
+	case <-sigCtx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to stop agent: %w", err)
+	}
+	if err := <-errCh; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("agent failed: %w", err)
+	}
+	return nil
+}
+
+// Finalize performs post-execution tasks such as output formatting or resource cleanup.
+func (c *Command) Finalize(ctx *CommandContext) error {
+	fmt.Fprintln(ctx.Options.Stdout, "Stopped agent")
+	return nil
+}
+
+// load refreshes the token of every identity in snap. Identities whose token cannot be
+// refreshed are dropped from memory and left to refresh themselves.
+func (c *Command) load(ctx context.Context, snap *snapshot.Snapshot) error {
+	identities, err := snap.Identities.ListIdentities()
+	if err != nil {
+		return fmt.Errorf("failed to list identities: %w", err)
+	}
+
+	for _, i := range identities {
+		if _, err := snap.Tokens.RefreshToken(ctx, i.Provider, i.ID); err != nil {
+			c.l.Warn("not keeping token fresh", "provider", i.Provider, "identity", i.ID, "error", err)
+			if err := snap.Identities.DeleteToken(i.Provider, i.ID); err != nil {
+				return err
+			}
+			if err := snap.Identities.DeleteIdentity(i.ID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func parseDuration(flag, value string) (time.Duration, error) {
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid %s %q: must be a positive duration such as 1m", flag, value)
+	}
+	return d, nil
+}
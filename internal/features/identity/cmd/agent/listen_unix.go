@@ -0,0 +1,16 @@
+//go:build unix
+
+package agent
+
+import (
+	"net"
+	"syscall"
+)
+
+// listen listens on the Unix socket at path. The socket is created with the umask set
+// to 077, so no other user can connect to it even briefly.
+func listen(path string) (net.Listener, error) {
+	old := syscall.Umask(0o077)
+	defer syscall.Umask(old)
+	return net.Listen("unix", path)
+}
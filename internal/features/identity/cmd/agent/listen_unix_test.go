@@ -0,0 +1,23 @@
+//go:build unix
+
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListen_OwnerOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agent.sock")
+	l, err := listen(path)
+	require.NoError(t, err)
+	defer l.Close()
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Zero(t, info.Mode().Perm()&0o077, "only the owner may connect")
+}
@@ -0,0 +1,17 @@
+// Code generated by spec-gen. DO NOT EDIT.
+package agent
+
+import (
+	"io"
+)
+
+// Options encapsulates all user-provided arguments and flags for the command.
+type Options struct {
+	Interval      string // How often to look for tokens that are due a refresh
+	RefreshBefore string // How long before expiry a token is refreshed
+
+	// Stdout receives standard output messages.
+	Stdout io.Writer
+	// Stderr receives error and diagnostic messages.
+	Stderr io.Writer
+}
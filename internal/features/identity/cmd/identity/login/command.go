@@ -28,7 +28,7 @@ func CreateLoginCmd(container di.Container) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "login [flags]",
 		Short: "Authenticate with an identity provider",
-		Long: `Authenticate with a cloud provider (Azure, Google) using various methods (Interactive, Device Code, Service Principal).
+		Long: `Authenticate with a cloud provider (Azure, Google, or any OpenID Connect provider) using various methods (Interactive, Device Code, Service Principal).
 You can specify the provider and method via flags or in your configuration.
 `,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
@@ -53,7 +53,7 @@ You can specify the provider and method via flags or in your configuration.
 			return handler.Finalize(c)
 		},
 	}
-	cmd.Flags().StringVar(&opts.Provider, "provider", "azure", "The identity provider to use (e.g., azure, google)")
+	cmd.Flags().StringVar(&opts.Provider, "provider", "azure", "The identity provider to use (e.g., azure, google, oidc)")
 	cmd.Flags().StringVar(&opts.Id, "id", "", "The specific identity (email) to authenticate")
 	cmd.Flags().StringVar(&opts.Alias, "alias", "", "An optional human-friendly name for this identity")
 	cmd.Flags().BoolVar(&opts.ShowToken, "show-token", false, "Display the access token after login")
@@ -62,7 +62,10 @@ You can specify the provider and method via flags or in your configuration.
 	cmd.Flags().StringVar(&opts.TenantId, "tenant-id", "", "Azure AD tenant ID (Azure only)")
 	cmd.Flags().StringVar(&opts.ClientId, "client-id", "", "Client ID for the application")
 	cmd.Flags().StringVar(&opts.ClientSecret, "client-secret", "", "Client secret for the application")
+	cmd.Flags().StringVar(&opts.Issuer, "issuer", "", "OpenID Connect issuer URL (oidc only)")
 	cmd.Flags().StringVar(&opts.Scopes, "scopes", "", "Comma-separated list of scopes to request")
+	cmd.Flags().StringVar(&opts.Interaction, "interaction", "", "How to interact during login (auto, browser, headless, none)")
+	cmd.Flags().StringVar(&opts.Timeout, "timeout", "", "Give up if login has not completed within this duration (e.g. 5m)")
 
 	return cmd
 }
@@ -1,11 +1,27 @@
 package login
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"time"
+
+	"github.com/michaeldcanady/go-onedrive/internal/features/config"
+	"github.com/michaeldcanady/go-onedrive/internal/features/identity"
 )
 
 // Validate performs initial validation of the command options.
 func (c *Command) Validate(ctx *CommandContext) error {
+	if ctx.Options.Timeout != "" {
+		if d, err := time.ParseDuration(ctx.Options.Timeout); err != nil || d <= 0 {
+			return fmt.Errorf("invalid --timeout %q: must be a positive duration such as 5m", ctx.Options.Timeout)
+		}
+	}
+	if ctx.Options.Interaction != "" {
+		if _, err := identity.ParseInteractionMode(ctx.Options.Interaction); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -46,13 +62,33 @@ func (c *Command) Execute(ctx *CommandContext) error {
 	options["client_secret"] = getOption(ctx.Options.ClientSecret, "identity."+provider+".client_secret")
 	options["scopes"] = getOption(ctx.Options.Scopes, "identity."+provider+".scopes")
 	options["redirect_uri"] = getOption("", "identity."+provider+".redirect_uri")
+	options["issuer"] = getOption(ctx.Options.Issuer, "identity."+provider+".issuer")
+
+	mode, err := identity.ParseInteractionMode(getOption(ctx.Options.Interaction, config.KeyIdentityInteraction))
+	if err != nil {
+		return err
+	}
+	if mode != identity.InteractionBrowser {
+		// The browser may be on another machine, so plugins ask for the code to be
+		// pasted back rather than waiting for a local redirect.
+		options["headless"] = "true"
+	}
+	interactor := identity.NewTerminalInteractor(os.Stdin, ctx.Options.Stderr, mode, c.l)
+
+	loginCtx := ctx.Ctx
+	if ctx.Options.Timeout != "" {
+		timeout, _ := time.ParseDuration(ctx.Options.Timeout)
+		var cancel context.CancelFunc
+		loginCtx, cancel = context.WithTimeout(loginCtx, timeout)
+		defer cancel()
+	}
 
-	_, err := c.identity.Login(ctx.Ctx, provider, options)
+	_, err = c.identity.Login(loginCtx, provider, options, interactor)
 	return err
 }
 
 // Finalize performs any cleanup or final output formatting.
 func (c *Command) Finalize(ctx *CommandContext) error {
-	fmt.Println("Login successful")
+	fmt.Fprintln(ctx.Options.Stdout, "Login successful")
 	return nil
 }
@@ -7,7 +7,7 @@ import (
 
 // Options encapsulates all user-provided arguments and flags for the command.
 type Options struct {
-	Provider     string // The identity provider to use (e.g., azure, google)
+	Provider     string // The identity provider to use (e.g., azure, google, oidc)
 	Id           string // The specific identity (email) to authenticate
 	Alias        string // An optional human-friendly name for this identity
 	ShowToken    bool   // Display the access token after login
@@ -16,7 +16,10 @@ type Options struct {
 	TenantId     string // Azure AD tenant ID (Azure only)
 	ClientId     string // Client ID for the application
 	ClientSecret string // Client secret for the application
+	Issuer       string // OpenID Connect issuer URL (oidc only)
 	Scopes       string // Comma-separated list of scopes to request
+	Interaction  string // How to interact during login (auto, browser, headless, none)
+	Timeout      string // Give up if login has not completed within this duration (e.g. 5m)
 
 	// Stdout receives standard output messages.
 	Stdout io.Writer
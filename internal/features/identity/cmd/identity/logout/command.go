@@ -19,6 +19,7 @@ func CreateLogoutCmd(container di.Container) *cobra.Command {
 		container.Identity(),
 		container.Token(),
 		container.Profile(),
+		container.Mounts(),
 		container.Logger(),
 		l,
 		container.Resolver(),
@@ -26,8 +27,11 @@ func CreateLogoutCmd(container di.Container) *cobra.Command {
 
 	cmd := &cobra.Command{
 		Use:   "logout [flags]",
-		Short: "Sign out from OneDrive",
-		Long:  `Sign out from OneDrive for the active profile by clearing the cached authentication tokens.`,
+		Short: "Sign out and delete stored credentials",
+		Long: `Sign out an identity by revoking its refresh token at the provider where possible and
+deleting its token and identity records. Mounts that use the identity are reported,
+or removed with --remove-mounts.
+`,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			opts.Stdout = cmd.OutOrStdout()
 			opts.Stderr = cmd.ErrOrStderr()
@@ -50,8 +54,10 @@ func CreateLogoutCmd(container di.Container) *cobra.Command {
 			return handler.Finalize(c)
 		},
 	}
-	cmd.Flags().StringVar(&opts.Id, "id", "", "The specific account to logout (optional)")
-	cmd.Flags().BoolVarP(&opts.Force, "force", "f", false, "Clear all cached credentials for the profile")
+	cmd.Flags().StringVar(&opts.Id, "id", "", "The identity to log out (ID, email or display name)")
+	cmd.Flags().BoolVar(&opts.All, "all", false, "Log out every identity")
+	cmd.Flags().BoolVar(&opts.RemoveMounts, "remove-mounts", false, "Remove the mounts that use the identity")
+	cmd.Flags().BoolVar(&opts.RevokeSessions, "revoke-sessions", false, "Also end every sign-in session of a Microsoft account, on all devices")
 
 	return cmd
 }
@@ -1,11 +1,21 @@
 package logout
 
 import (
+	"errors"
 	"fmt"
+
+	"github.com/michaeldcanady/go-onedrive/internal/features/identity"
+	"github.com/michaeldcanady/go-onedrive/internal/features/mount"
 )
 
 // Validate performs initial validation of the command options.
 func (c *Command) Validate(ctx *CommandContext) error {
+	if ctx.Options.Id == "" && !ctx.Options.All {
+		return errors.New("either --id or --all is required")
+	}
+	if ctx.Options.Id != "" && ctx.Options.All {
+		return errors.New("--id and --all cannot be used together")
+	}
 	return nil
 }
 
@@ -16,18 +26,92 @@ func (c *Command) Resolve(ctx *CommandContext) error {
 
 // Execute performs the core business logic of the command.
 func (c *Command) Execute(ctx *CommandContext) error {
-	if ctx.Options.Id == "" {
-		return fmt.Errorf("identity ID is required")
+	var identities []*identity.Identity
+	if ctx.Options.All {
+		all, err := c.identity.List(ctx.Ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list identities: %w", err)
+		}
+		identities = all
+	} else {
+		i, err := c.identity.GetIdentity(ctx.Ctx, ctx.Options.Id)
+		if err != nil {
+			return err
+		}
+		if i == nil {
+			return fmt.Errorf("identity not found: %s", ctx.Options.Id)
+		}
+		identities = append(identities, i)
+	}
+
+	mounts, err := c.mounts.List(ctx.Ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list mounts: %w", err)
+	}
+
+	options := make(map[string]string)
+	if ctx.Options.RevokeSessions {
+		options["revoke_sessions"] = "true"
+	}
+
+	// With --all, one identity failing does not stop the others from being logged out.
+	var errs []error
+	for _, i := range identities {
+		if err := c.logout(ctx, i, mounts, options); err != nil {
+			if !ctx.Options.All {
+				return err
+			}
+			errs = append(errs, fmt.Errorf("%s (%s): %w", displayName(i), i.Provider, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%d of %d identities failed to log out:\n%w", len(errs), len(identities), errors.Join(errs...))
 	}
-	return c.identity.Logout(ctx.Ctx, ctx.Options.Id)
+
+	if len(identities) == 0 {
+		fmt.Fprintln(ctx.Options.Stdout, "No identities to log out")
+	}
+	return nil
 }
 
-// Finalize performs any cleanup or final output formatting.
-func (c *Command) Finalize(ctx *CommandContext) error {
-	if ctx.Options.Id != "" {
-		fmt.Printf("Logged out from account: %s\n", ctx.Options.Id)
+// logout logs i out and removes or warns about the mounts that use it.
+func (c *Command) logout(ctx *CommandContext, i *identity.Identity, mounts []*mount.Mount, options map[string]string) error {
+	revoked, err := c.identity.Logout(ctx.Ctx, i.ID, options)
+	if err != nil {
+		return err
+	}
+	name := displayName(i)
+	if revoked {
+		fmt.Fprintf(ctx.Options.Stdout, "Logged out %s (%s): credentials revoked\n", name, i.Provider)
 	} else {
-		fmt.Println("Logged out from all accounts for the active profile")
+		fmt.Fprintf(ctx.Options.Stdout, "Logged out %s (%s): credentials deleted locally but not revoked at the provider\n", name, i.Provider)
 	}
+
+	for _, m := range mounts {
+		if m.IdentityID != i.ID || (m.IdentityProvider != "" && m.IdentityProvider != i.Provider) {
+			continue
+		}
+		if !ctx.Options.RemoveMounts {
+			fmt.Fprintf(ctx.Options.Stderr, "Warning: mount %s uses %s and will fail until it is removed or you log in again\n", m.Path, name)
+			continue
+		}
+		if err := c.mounts.Remove(ctx.Ctx, m.Path); err != nil {
+			return fmt.Errorf("failed to remove mount %s: %w", m.Path, err)
+		}
+		fmt.Fprintf(ctx.Options.Stdout, "Removed mount %s\n", m.Path)
+	}
+	return nil
+}
+
+// displayName returns the email of i, or its ID when it has none.
+func displayName(i *identity.Identity) string {
+	if i.Email != "" {
+		return i.Email
+	}
+	return i.ID
+}
+
+// Finalize performs any cleanup or final output formatting.
+func (c *Command) Finalize(ctx *CommandContext) error {
 	return nil
 }
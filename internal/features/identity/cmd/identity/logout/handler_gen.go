@@ -7,6 +7,7 @@ import (
 	"github.com/michaeldcanady/go-onedrive/internal/core/logger"
 	"github.com/michaeldcanady/go-onedrive/internal/core/resolver"
 	"github.com/michaeldcanady/go-onedrive/internal/features/identity"
+	"github.com/michaeldcanady/go-onedrive/internal/features/mount"
 	"github.com/michaeldcanady/go-onedrive/internal/features/profile"
 )
 
@@ -36,6 +37,7 @@ type Command struct {
 	identity identity.Service
 	token    identity.TokenService
 	profile  profile.Service
+	mounts   mount.Service
 	logger   logger.Service
 	l        logger.Service
 	resolver resolver.Service
@@ -46,6 +48,7 @@ func NewCommand(
 	identity identity.Service,
 	token identity.TokenService,
 	profile profile.Service,
+	mounts mount.Service,
 	logger logger.Service,
 	l logger.Service,
 	r resolver.Service,
@@ -54,6 +57,7 @@ func NewCommand(
 		identity: identity,
 		token:    token,
 		profile:  profile,
+		mounts:   mounts,
 		logger:   logger,
 		l:        l,
 		resolver: r,
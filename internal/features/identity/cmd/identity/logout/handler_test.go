@@ -0,0 +1,58 @@
+package logout
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/michaeldcanady/go-onedrive/internal/features/identity"
+	"github.com/michaeldcanady/go-onedrive/internal/features/vfs/vfstest"
+)
+
+// fakeIdentities fails Logout for the IDs in fail and records the rest.
+type fakeIdentities struct {
+	identity.Service
+	identities []*identity.Identity
+	fail       map[string]error
+	loggedOut  []string
+}
+
+func (f *fakeIdentities) List(ctx context.Context) ([]*identity.Identity, error) {
+	return f.identities, nil
+}
+
+func (f *fakeIdentities) Logout(ctx context.Context, identityID string, options map[string]string) (bool, error) {
+	if err := f.fail[identityID]; err != nil {
+		return false, err
+	}
+	f.loggedOut = append(f.loggedOut, identityID)
+	return true, nil
+}
+
+func TestCommand_Execute_AllContinuesPastFailures(t *testing.T) {
+	errRevoke := errors.New("provider unreachable")
+	ids := &fakeIdentities{
+		identities: []*identity.Identity{
+			{ID: "a", Email: "a@example.com", Provider: "microsoft"},
+			{ID: "b", Email: "b@example.com", Provider: "google"},
+			{ID: "c", Email: "c@example.com", Provider: "microsoft"},
+		},
+		fail: map[string]error{"b": errRevoke},
+	}
+	c := NewCommand(ids, nil, nil, vfstest.Mounts{}, nil, nil, nil)
+
+	var stdout, stderr bytes.Buffer
+	err := c.Execute(&CommandContext{
+		Ctx:     context.Background(),
+		Options: Options{All: true, Stdout: &stdout, Stderr: &stderr},
+	})
+
+	assert.ErrorIs(t, err, errRevoke)
+	assert.ErrorContains(t, err, "1 of 3 identities failed to log out")
+	assert.ErrorContains(t, err, "b@example.com (google)")
+	assert.Equal(t, []string{"a", "c"}, ids.loggedOut)
+	assert.Contains(t, stdout.String(), "Logged out c@example.com")
+}
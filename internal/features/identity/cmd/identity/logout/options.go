@@ -7,8 +7,10 @@ import (
 
 // Options encapsulates all user-provided arguments and flags for the command.
 type Options struct {
-	Id    string // The specific account to logout (optional)
-	Force bool   // Clear all cached credentials for the profile
+	Id             string // The identity to log out (ID, email or display name)
+	All            bool   // Log out every identity
+	RemoveMounts   bool   // Remove the mounts that use the identity
+	RevokeSessions bool   // Also end every sign-in session of a Microsoft account, on all devices
 
 	// Stdout receives standard output messages.
 	Stdout io.Writer
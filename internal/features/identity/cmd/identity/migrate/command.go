@@ -0,0 +1,56 @@
+// Code generated by spec-gen. DO NOT EDIT.
+package migrate
+
+import (
+	"github.com/michaeldcanady/go-onedrive/internal/core/di"
+	"github.com/spf13/cobra"
+)
+
+// CreateMigrateCmd returns a new [cobra.Command] initialized with dependencies
+// and configured to execute the "migrate" operation.
+func CreateMigrateCmd(container di.Container) *cobra.Command {
+	var opts Options
+	var c *CommandContext
+
+	l := container.Logger().With("command", "identity-migrate")
+
+	// Create the handler using the generated factory
+	var handler Handler = NewCommand(
+		container.Token(),
+		container.Storage(),
+		container.Logger(),
+		l,
+		container.Resolver(),
+	)
+
+	cmd := &cobra.Command{
+		Use:   "migrate [flags]",
+		Short: "Move stored tokens into the configured token store",
+		Long: `Move authentication tokens stored unencrypted into the token store selected by
+identity.token_store, such as the encrypted store or the OS keyring.
+`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			opts.Stdout = cmd.OutOrStdout()
+			opts.Stderr = cmd.ErrOrStderr()
+
+			c = &CommandContext{
+				Ctx:     cmd.Context(),
+				Options: opts,
+			}
+
+			if err := handler.Validate(c); err != nil {
+				return err
+			}
+
+			return handler.Resolve(c)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := handler.Execute(c); err != nil {
+				return err
+			}
+			return handler.Finalize(c)
+		},
+	}
+
+	return cmd
+}
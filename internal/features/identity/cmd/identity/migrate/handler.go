@@ -0,0 +1,38 @@
+package migrate
+
+import (
+	"fmt"
+)
+
+// Validate ensures that the provided options are semantically correct.
+func (c *Command) Validate(ctx *CommandContext) error {
+	return nil
+}
+
+// Resolve translates user input into domain entities using the [resolver.Service].
+func (c *Command) Resolve(ctx *CommandContext) error {
+	return c.BaseResolve(ctx)
+}
+
+// Execute performs the primary business logic of the "migrate" command.
+func (c *Command) Execute(ctx *CommandContext) error {
+	n, err := c.token.MigrateTokens(ctx.Ctx)
+	if err != nil {
+		return err
+	}
+
+	// Deleted plaintext tokens stay in the free pages of state.db until it is rewritten.
+	if err := c.storage.Compact(); err != nil {
+		return fmt.Errorf("failed to compact the state database: %w", err)
+	}
+	fmt.Fprintf(ctx.Options.Stdout, "Migrated %d tokens\n", n)
+	if n > 0 {
+		fmt.Fprintln(ctx.Options.Stdout, "Backups of state.db made before now still contain these tokens in plaintext; delete them")
+	}
+	return nil
+}
+
+// Finalize performs post-execution tasks such as output formatting or resource cleanup.
+func (c *Command) Finalize(ctx *CommandContext) error {
+	return nil
+}
@@ -28,10 +28,14 @@ type Token struct {
 // flows and identity management.
 type Service interface {
 	// Login initiates an interactive authentication flow through a provider-specific plugin.
-	Login(ctx context.Context, provider string, options map[string]string) (*Identity, error)
+	// The interactions the plugin requests are carried out by interactor.
+	Login(ctx context.Context, provider string, options map[string]string, interactor Interactor) (*Identity, error)
 
-	// Logout invalidates the session for the specified identity.
-	Logout(ctx context.Context, identityID string) error
+	// Logout revokes the identity's credentials at the provider where it can, then
+	// deletes its token and record. It reports whether the provider revoked them; a
+	// failed revocation does not stop the local deletion. options are passed to the
+	// identity plugin.
+	Logout(ctx context.Context, identityID string, options map[string]string) (bool, error)
 
 	// List returns all authenticated identities stored in the local cache.
 	List(ctx context.Context) ([]*Identity, error)
@@ -54,6 +58,17 @@ type TokenService interface {
 
 	// RefreshToken forces a token refresh using the stored refresh token.
 	RefreshToken(ctx context.Context, provider string, identityID string) (*Token, error)
+
+	// RevokeToken asks the provider to revoke the stored token, passing options to the
+	// identity plugin. It reports whether the provider revoked it.
+	RevokeToken(ctx context.Context, provider string, identityID string, options map[string]string) (bool, error)
+
+	// DeleteToken removes the stored token for an identity.
+	DeleteToken(ctx context.Context, provider string, identityID string) error
+
+	// MigrateTokens moves tokens stored unencrypted into the configured token store and
+	// returns how many were moved.
+	MigrateTokens(ctx context.Context) (int, error)
 }
 
 // Repository handles the persistent storage of identities and their associated tokens.
@@ -66,6 +81,27 @@ type Repository interface {
 	SaveToken(provider string, identityID string, t *Token) error
 	GetToken(provider string, identityID string) (*Token, error)
 	DeleteToken(provider string, identityID string) error
+	// MigrateTokens moves plaintext tokens into the repository's token store, returning
+	// how many were moved. It is a no-op when tokens are stored in plaintext. The deleted
+	// plaintext copies remain in the database file until it is compacted.
+	MigrateTokens() (int, error)
+}
+
+// loginOptions are the login options recorded in an identity's metadata, so that
+// refreshing and revoking its token use the client it was issued to even when they came
+// from flags. Secrets are never recorded.
+var loginOptions = []string{"issuer", "client_id", "tenant_id", "method", "scopes"}
+
+// recordLoginOptions copies the non-empty [loginOptions] in options to i's metadata.
+func recordLoginOptions(i *Identity, options map[string]string) {
+	for _, name := range loginOptions {
+		if val := options[name]; val != "" {
+			if i.Metadata == nil {
+				i.Metadata = make(map[string]string)
+			}
+			i.Metadata[name] = val
+		}
+	}
 }
 
 func FromProtoIdentity(p *identity_proto.Identity) *Identity {
@@ -0,0 +1,172 @@
+package identity
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+	"github.com/pkg/browser"
+	"github.com/skip2/go-qrcode"
+
+	"github.com/michaeldcanady/go-onedrive/internal/core/logger"
+)
+
+// InteractionMode selects how the host carries out what an identity plugin asks of the
+// user during login.
+type InteractionMode string
+
+const (
+	// InteractionBrowser opens URLs in the default browser.
+	InteractionBrowser InteractionMode = "browser"
+	// InteractionHeadless prints URLs with a QR code instead of opening a browser, for SSH
+	// sessions and machines without a display.
+	InteractionHeadless InteractionMode = "headless"
+	// InteractionNone prints URLs and never waits on a terminal, for scripts. Prompts are
+	// answered from piped input only.
+	InteractionNone InteractionMode = "none"
+)
+
+// ErrInteractionRequired is returned when a plugin asks for input that cannot be given,
+// such as a prompt during non-interactive login.
+var ErrInteractionRequired = errors.New("login requires user input")
+
+// Interactor carries out the interactions an identity plugin requests during login.
+type Interactor interface {
+	// OpenURL sends the user to url.
+	OpenURL(ctx context.Context, url string) error
+	// DisplayMessage shows message to the user.
+	DisplayMessage(ctx context.Context, message string) error
+	// Prompt shows message and returns the line the user enters.
+	Prompt(ctx context.Context, message string) (string, error)
+}
+
+// TerminalInteractor is an [Interactor] that talks to the user through a terminal or, when
+// input is piped, through whatever is on the other end of the pipe.
+type TerminalInteractor struct {
+	in     io.Reader
+	lines  *bufio.Reader
+	out    io.Writer
+	mode   InteractionMode
+	logger logger.Service
+}
+
+// NewTerminalInteractor returns a new [*TerminalInteractor] that reads answers from in
+// and writes to out.
+func NewTerminalInteractor(in io.Reader, out io.Writer, mode InteractionMode, l logger.Service) *TerminalInteractor {
+	return &TerminalInteractor{
+		in:     in,
+		lines:  bufio.NewReader(in),
+		out:    out,
+		mode:   mode,
+		logger: l,
+	}
+}
+
+// DetectInteractionMode returns [InteractionHeadless] inside an SSH session or on a Unix
+// machine without a display, and [InteractionBrowser] otherwise.
+func DetectInteractionMode() InteractionMode {
+	if os.Getenv("SSH_CONNECTION") != "" || os.Getenv("SSH_TTY") != "" {
+		return InteractionHeadless
+	}
+	switch runtime.GOOS {
+	case "windows", "darwin":
+		return InteractionBrowser
+	}
+	if os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == "" {
+		return InteractionHeadless
+	}
+	return InteractionBrowser
+}
+
+// ParseInteractionMode parses the name of an [InteractionMode]. "auto" and the empty
+// string select [DetectInteractionMode].
+func ParseInteractionMode(s string) (InteractionMode, error) {
+	switch m := InteractionMode(s); m {
+	case "", "auto":
+		return DetectInteractionMode(), nil
+	case InteractionBrowser, InteractionHeadless, InteractionNone:
+		return m, nil
+	default:
+		return "", fmt.Errorf("unknown interaction mode %q: must be auto, browser, headless or none", s)
+	}
+}
+
+func (t *TerminalInteractor) OpenURL(ctx context.Context, url string) error {
+	switch t.mode {
+	case InteractionBrowser:
+		fmt.Fprintf(t.out, "Please open your browser at: %s\n", url)
+		err := browser.OpenURL(url)
+		if err == nil {
+			return nil
+		}
+		logger.WithContext(t.logger, ctx).Warn("failed to open browser", "url", url, "error", err)
+		return t.printQR(url)
+	case InteractionHeadless:
+		fmt.Fprintf(t.out, "Open this address in a browser on any device:\n\n  %s\n\n", url)
+		return t.printQR(url)
+	default:
+		_, err := fmt.Fprintf(t.out, "Open this address in a browser: %s\n", url)
+		return err
+	}
+}
+
+func (t *TerminalInteractor) DisplayMessage(ctx context.Context, message string) error {
+	_, err := fmt.Fprintln(t.out, message)
+	return err
+}
+
+// Prompt reads the answer from the input. Without a mode that allows waiting on the user,
+// only piped input is read, so a script never hangs on a terminal.
+func (t *TerminalInteractor) Prompt(ctx context.Context, message string) (string, error) {
+	if t.mode == InteractionNone && isTerminal(t.in) {
+		return "", fmt.Errorf("%w: %s", ErrInteractionRequired, message)
+	}
+	fmt.Fprintf(t.out, "%s ", message)
+
+	type answer struct {
+		line string
+		err  error
+	}
+	ch := make(chan answer, 1)
+	go func() {
+		line, err := t.lines.ReadString('\n')
+		ch <- answer{line, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case a := <-ch:
+		if errors.Is(a.err, io.EOF) && a.line == "" {
+			return "", fmt.Errorf("%w: %s", ErrInteractionRequired, message)
+		}
+		if a.err != nil && !errors.Is(a.err, io.EOF) {
+			return "", a.err
+		}
+		return strings.TrimSpace(a.line), nil
+	}
+}
+
+// printQR draws url as a QR code, so it can be scanned with a phone.
+func (t *TerminalInteractor) printQR(url string) error {
+	qr, err := qrcode.New(url, qrcode.Low)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(t.out, qr.ToSmallString(false))
+	return err
+}
+
+func isTerminal(r io.Reader) bool {
+	f, ok := r.(*os.File)
+	if !ok {
+		return false
+	}
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
@@ -0,0 +1,74 @@
+package identity
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/michaeldcanady/go-onedrive/internal/core/logger/loggertest"
+)
+
+func TestTerminalInteractor(t *testing.T) {
+	t.Run("prompt reads piped input", func(t *testing.T) {
+		var out bytes.Buffer
+		i := NewTerminalInteractor(strings.NewReader("  the-code \nnext\n"), &out, InteractionNone, loggertest.Nop{})
+
+		answer, err := i.Prompt(context.Background(), "Code:")
+		require.NoError(t, err)
+		assert.Equal(t, "the-code", answer)
+		assert.Equal(t, "Code: ", out.String())
+
+		answer, err = i.Prompt(context.Background(), "Again:")
+		require.NoError(t, err)
+		assert.Equal(t, "next", answer)
+	})
+
+	t.Run("prompt without input", func(t *testing.T) {
+		i := NewTerminalInteractor(strings.NewReader(""), io.Discard, InteractionNone, loggertest.Nop{})
+		_, err := i.Prompt(context.Background(), "Code:")
+		assert.ErrorIs(t, err, ErrInteractionRequired)
+	})
+
+	t.Run("prompt gives up when the context ends", func(t *testing.T) {
+		r, w := io.Pipe()
+		t.Cleanup(func() { w.Close() })
+		i := NewTerminalInteractor(r, io.Discard, InteractionHeadless, loggertest.Nop{})
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := i.Prompt(ctx, "Code:")
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("headless prints the address and a QR code", func(t *testing.T) {
+		var out bytes.Buffer
+		i := NewTerminalInteractor(strings.NewReader(""), &out, InteractionHeadless, loggertest.Nop{})
+		require.NoError(t, i.OpenURL(context.Background(), "https://example.com/device"))
+		assert.Contains(t, out.String(), "https://example.com/device")
+		assert.Contains(t, out.String(), "█")
+	})
+
+	t.Run("none prints only the address", func(t *testing.T) {
+		var out bytes.Buffer
+		i := NewTerminalInteractor(strings.NewReader(""), &out, InteractionNone, loggertest.Nop{})
+		require.NoError(t, i.OpenURL(context.Background(), "https://example.com/device"))
+		assert.Equal(t, "Open this address in a browser: https://example.com/device\n", out.String())
+	})
+}
+
+func TestParseInteractionMode(t *testing.T) {
+	mode, err := ParseInteractionMode("headless")
+	require.NoError(t, err)
+	assert.Equal(t, InteractionHeadless, mode)
+
+	mode, err = ParseInteractionMode("auto")
+	require.NoError(t, err)
+	assert.Equal(t, DetectInteractionMode(), mode)
+
+	_, err = ParseInteractionMode("telepathy")
+	assert.Error(t, err)
+}
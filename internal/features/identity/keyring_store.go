@@ -0,0 +1,69 @@
+package identity
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// keyringService is the attribute every odc secret is tagged with in the keyring.
+const keyringService = "odc"
+
+// secretTool runs the secret-tool binary with args, feeding it stdin.
+type secretTool func(stdin []byte, args ...string) ([]byte, error)
+
+type keyringTokenStore struct {
+	run secretTool
+}
+
+// NewKeyringTokenStore returns a [TokenStore] that keeps tokens in the Secret Service
+// keyring (GNOME Keyring, KWallet) through the secret-tool command. It returns
+// [ErrKeyringUnavailable] when secret-tool is not installed.
+func NewKeyringTokenStore() (TokenStore, error) {
+	path, err := exec.LookPath("secret-tool")
+	if err != nil {
+		return nil, fmt.Errorf("%w: secret-tool was not found: %v", ErrKeyringUnavailable, err)
+	}
+	return &keyringTokenStore{run: func(stdin []byte, args ...string) ([]byte, error) {
+		cmd := exec.Command(path, args...)
+		cmd.Stdin = bytes.NewReader(stdin)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil && stderr.Len() > 0 {
+			return out, fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		return out, err
+	}}, nil
+}
+
+// Put passes the secret on stdin so it never appears in the process list.
+func (s *keyringTokenStore) Put(key string, data []byte) error {
+	_, err := s.run(data, "store", "--label", "odc token "+key, "service", keyringService, "account", key)
+	if err != nil {
+		return fmt.Errorf("failed to store token %s in keyring: %w", key, err)
+	}
+	return nil
+}
+
+// Get treats a bare non-zero exit as a missing secret, since secret-tool exits with
+// status 1 and prints nothing when no item matches. Failures that explain themselves
+// on stderr, such as an unreachable keyring, are returned.
+func (s *keyringTokenStore) Get(key string) ([]byte, error) {
+	out, err := s.run(nil, "lookup", "service", keyringService, "account", key)
+	if _, missing := err.(*exec.ExitError); missing || len(out) == 0 && err == nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token %s from keyring: %w", key, err)
+	}
+	return out, nil
+}
+
+func (s *keyringTokenStore) Delete(key string) error {
+	if _, err := s.run(nil, "clear", "service", keyringService, "account", key); err != nil {
+		return fmt.Errorf("failed to remove token %s from keyring: %w", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package identity
+
+import (
+	"sync"
+)
+
+type memoryRepository struct {
+	mu         sync.RWMutex
+	identities map[string]*Identity
+	tokens     map[string]*Token
+}
+
+// NewMemoryRepository returns a [Repository] that lives only as long as the process. The
+// agent uses it so it does not hold state.db open while it runs.
+func NewMemoryRepository() Repository {
+	return &memoryRepository{
+		identities: make(map[string]*Identity),
+		tokens:     make(map[string]*Token),
+	}
+}
+
+func (r *memoryRepository) SaveIdentity(i *Identity) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c := *i
+	r.identities[i.ID] = &c
+	return nil
+}
+
+func (r *memoryRepository) GetIdentity(id string) (*Identity, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	i, ok := r.identities[id]
+	if !ok {
+		return nil, nil
+	}
+	c := *i
+	return &c, nil
+}
+
+func (r *memoryRepository) ListIdentities() ([]*Identity, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var identities []*Identity
+	for _, i := range r.identities {
+		c := *i
+		identities = append(identities, &c)
+	}
+	return identities, nil
+}
+
+func (r *memoryRepository) DeleteIdentity(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.identities, id)
+	return nil
+}
+
+func (r *memoryRepository) SaveToken(provider string, identityID string, t *Token) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c := *t
+	r.tokens[tokenKey(provider, identityID)] = &c
+	return nil
+}
+
+func (r *memoryRepository) GetToken(provider string, identityID string) (*Token, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	t, ok := r.tokens[tokenKey(provider, identityID)]
+	if !ok {
+		return nil, nil
+	}
+	c := *t
+	return &c, nil
+}
+
+func (r *memoryRepository) DeleteToken(provider string, identityID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.tokens, tokenKey(provider, identityID))
+	return nil
+}
+
+func (r *memoryRepository) MigrateTokens() (int, error) {
+	return 0, nil
+}
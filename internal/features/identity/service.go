@@ -7,7 +7,6 @@ import (
 	"github.com/michaeldcanady/go-onedrive/internal/core/logger"
 	"github.com/michaeldcanady/go-onedrive/internal/core/plugins"
 	identity_proto "github.com/michaeldcanady/go-onedrive/internal/features/plugins/proto/identity"
-	"github.com/pkg/browser"
 )
 
 type IdentityService struct {
@@ -28,7 +27,7 @@ func NewIdentityService(repo Repository, pm plugins.Manager, ts TokenService, l
 	}
 }
 
-func (s *IdentityService) Login(ctx context.Context, provider string, options map[string]string) (*Identity, error) {
+func (s *IdentityService) Login(ctx context.Context, provider string, options map[string]string, interactor Interactor) (*Identity, error) {
 	l := logger.WithContext(s.logger, ctx)
 
 	pluginName := fmt.Sprintf("identity-%s", provider)
@@ -37,6 +36,10 @@ func (s *IdentityService) Login(ctx context.Context, provider string, options ma
 		return nil, fmt.Errorf("failed to get identity plugin %s: %w", pluginName, err)
 	}
 
+	// Cancelling the stream stops the plugin's flow when the host gives up early.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	stream, err := client.Login(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open login stream: %w", err)
@@ -58,24 +61,35 @@ func (s *IdentityService) Login(ctx context.Context, provider string, options ma
 	for {
 		resp, err := stream.Recv()
 		if err != nil {
+			if ctx.Err() != nil {
+				return nil, fmt.Errorf("login did not complete: %w", ctx.Err())
+			}
 			return nil, fmt.Errorf("stream receive failed: %w", err)
 		}
 
 		// Handle Interaction Request
 		if req := resp.GetInteractionRequest(); req != nil {
-			if msg := req.GetDisplayMessage(); msg != nil {
-				fmt.Println(msg.Message)
-			} else if open := req.GetOpenUrl(); open != nil {
-				fmt.Printf("Please open your browser at: %s\n", open.Url)
-				if err := browser.OpenURL(open.Url); err != nil {
-					l.Warn("failed to open browser", "url", open.Url, "error", err)
+			answer := &identity_proto.InteractionResponse{}
+			switch {
+			case req.GetDisplayMessage() != nil:
+				err = interactor.DisplayMessage(ctx, req.GetDisplayMessage().Message)
+			case req.GetOpenUrl() != nil:
+				err = interactor.OpenURL(ctx, req.GetOpenUrl().Url)
+			case req.GetPrompt() != nil:
+				answer.Value, err = interactor.Prompt(ctx, req.GetPrompt().Message)
+				if err != nil {
+					return nil, err
 				}
 			}
+			if err != nil {
+				l.Warn("failed to carry out login interaction", "error", err)
+				answer.Error = err.Error()
+			}
 
-			// Send acknowledgement back to plugin
+			// Send the answer, or an acknowledgement, back to the plugin
 			err = stream.Send(&identity_proto.LoginRequest{
 				Payload: &identity_proto.LoginRequest_InteractionResponse{
-					InteractionResponse: &identity_proto.InteractionResponse{},
+					InteractionResponse: answer,
 				},
 			})
 			if err != nil {
@@ -87,6 +101,7 @@ func (s *IdentityService) Login(ctx context.Context, provider string, options ma
 		// Handle Final Result
 		if result := resp.GetResult(); result != nil {
 			identity := FromProtoIdentity(result.Identity)
+			recordLoginOptions(identity, options)
 			token := FromProtoToken(result.Token)
 
 			if err := s.repo.SaveIdentity(identity); err != nil {
@@ -105,34 +120,31 @@ func (s *IdentityService) Login(ctx context.Context, provider string, options ma
 	}
 }
 
-func (s *IdentityService) Logout(ctx context.Context, identityID string) error {
+func (s *IdentityService) Logout(ctx context.Context, identityID string, options map[string]string) (bool, error) {
 	l := logger.WithContext(s.logger, ctx)
 
 	i, err := s.repo.GetIdentity(identityID)
 	if err != nil {
-		return err
+		return false, err
 	}
 	if i == nil {
-		return fmt.Errorf("identity not found: %s", identityID)
+		return false, fmt.Errorf("identity not found: %s", identityID)
 	}
 
-	// Optional: call plugin logout
-	pluginName := fmt.Sprintf("identity-%s", i.Provider)
-	client, err := s.pluginManager.GetIdentityPlugin(pluginName)
-	if err == nil {
-		if _, err := client.Logout(ctx, &identity_proto.LogoutRequest{
-			IdentityId: identityID,
-		}); err != nil {
-			l.Warn("failed to logout from plugin", "plugin", pluginName, "identity", identityID, "error", err)
-		}
+	revoked, err := s.tokenService.RevokeToken(ctx, i.Provider, i.ID, options)
+	if err != nil {
+		l.Warn("failed to revoke credentials at provider", "provider", i.Provider, "identity", i.ID, "error", err)
 	}
 
-	if err := s.tokenService.SaveToken(ctx, i.Provider, identityID, &Token{}); err != nil {
-		return err
+	if err := s.tokenService.DeleteToken(ctx, i.Provider, i.ID); err != nil {
+		return revoked, fmt.Errorf("failed to delete token: %w", err)
+	}
+	if err := s.repo.DeleteIdentity(i.ID); err != nil {
+		return revoked, fmt.Errorf("failed to delete identity: %w", err)
 	}
 
-	l.Info("identity logged out", "identity", identityID)
-	return nil
+	l.Info("identity logged out", "provider", i.Provider, "identity", i.ID, "revoked", revoked)
+	return revoked, nil
 }
 
 func (s *IdentityService) List(ctx context.Context) ([]*Identity, error) {
@@ -0,0 +1,188 @@
+package identity
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/michaeldcanady/go-onedrive/internal/core/logger/loggertest"
+	"github.com/michaeldcanady/go-onedrive/internal/core/plugins"
+	identity_proto "github.com/michaeldcanady/go-onedrive/internal/features/plugins/proto/identity"
+)
+
+// promptingPlugin logs in by asking the host for a code and returning it as the token.
+type promptingPlugin struct {
+	fakeIdentityPlugin
+}
+
+func (p *promptingPlugin) Login(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[identity_proto.LoginRequest, identity_proto.LoginResponse], error) {
+	return &promptStream{ctx: ctx}, nil
+}
+
+// promptStream plays the plugin side of a login: it prompts once and then sends a result
+// built from the answer.
+type promptStream struct {
+	grpc.BidiStreamingClient[identity_proto.LoginRequest, identity_proto.LoginResponse]
+	ctx     context.Context
+	options map[string]string
+	answer  *identity_proto.InteractionResponse
+	sent    int
+}
+
+func (s *promptStream) Send(m *identity_proto.LoginRequest) error {
+	if c := m.GetConfig(); c != nil {
+		s.options = c.Options
+	}
+	if r := m.GetInteractionResponse(); r != nil {
+		s.answer = r
+	}
+	return nil
+}
+
+func (s *promptStream) Recv() (*identity_proto.LoginResponse, error) {
+	s.sent++
+	switch s.sent {
+	case 1:
+		return &identity_proto.LoginResponse{Payload: &identity_proto.LoginResponse_InteractionRequest{InteractionRequest: &identity_proto.InteractionRequest{
+			Action: &identity_proto.InteractionRequest_Prompt{Prompt: &identity_proto.PromptRequest{Message: "Code:"}},
+		}}}, nil
+	case 2:
+		return &identity_proto.LoginResponse{Payload: &identity_proto.LoginResponse_Result{Result: &identity_proto.LoginResult{
+			Token:    &identity_proto.AccessToken{AccessToken: s.answer.Value, ExpiresAt: time.Now().Add(time.Hour).Unix()},
+			Identity: &identity_proto.Identity{Id: "alice", Provider: "azure"},
+		}}}, nil
+	default:
+		<-s.ctx.Done()
+		return nil, io.EOF
+	}
+}
+
+func TestIdentityService_Login(t *testing.T) {
+	repo := NewMemoryRepository()
+	plugin := &promptingPlugin{}
+	ts := newTestTokenService(t, repo, &plugin.fakeIdentityPlugin)
+	s := NewIdentityService(repo, promptingPlugins{plugin: plugin}, ts, loggertest.Nop{})
+
+	t.Run("prompt answered from input", func(t *testing.T) {
+		interactor := NewTerminalInteractor(strings.NewReader("pasted-code\n"), io.Discard, InteractionNone, loggertest.Nop{})
+		i, err := s.Login(context.Background(), "azure", nil, interactor)
+		require.NoError(t, err)
+		assert.Equal(t, "alice", i.ID)
+
+		token, err := repo.GetToken("azure", "alice")
+		require.NoError(t, err)
+		assert.Equal(t, "pasted-code", token.AccessToken)
+	})
+
+	t.Run("login options are kept for refresh and logout", func(t *testing.T) {
+		require.NoError(t, ts.config.Set("identity.azure.client_id", "configured-client"))
+		require.NoError(t, ts.config.Set("identity.azure.client_secret", "configured-secret"))
+
+		interactor := NewTerminalInteractor(strings.NewReader("pasted-code\n"), io.Discard, InteractionNone, loggertest.Nop{})
+		options := map[string]string{"client_id": "flag-client", "tenant_id": "flag-tenant", "client_secret": "flag-secret", "headless": "true"}
+		_, err := s.Login(context.Background(), "azure", options, interactor)
+		require.NoError(t, err)
+
+		i, err := repo.GetIdentity("alice")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"client_id": "flag-client", "tenant_id": "flag-tenant"}, i.Metadata, "secrets are not recorded")
+
+		require.NoError(t, repo.SaveToken("azure", "alice", &Token{AccessToken: "a", RefreshToken: "r", ExpiresAt: time.Now()}))
+		_, err = ts.RefreshToken(context.Background(), "azure", "alice")
+		require.NoError(t, err)
+		require.Len(t, plugin.refreshOptions, 1)
+		assert.Equal(t, "flag-client", plugin.refreshOptions[0]["client_id"])
+		assert.Equal(t, "flag-tenant", plugin.refreshOptions[0]["tenant_id"])
+		assert.Equal(t, "configured-secret", plugin.refreshOptions[0]["client_secret"])
+
+		_, err = s.Logout(context.Background(), "alice", nil)
+		require.NoError(t, err)
+		require.Len(t, plugin.logouts, 1)
+		assert.Equal(t, "flag-client", plugin.logouts[0].Options["client_id"])
+	})
+
+	t.Run("prompt without input", func(t *testing.T) {
+		interactor := NewTerminalInteractor(strings.NewReader(""), io.Discard, InteractionNone, loggertest.Nop{})
+		_, err := s.Login(context.Background(), "azure", nil, interactor)
+		assert.ErrorIs(t, err, ErrInteractionRequired)
+	})
+}
+
+// promptingPlugins is a [plugins.Manager] that only serves a [promptingPlugin].
+type promptingPlugins struct {
+	plugins.Manager
+	plugin *promptingPlugin
+}
+
+func (f promptingPlugins) GetIdentityPlugin(name string) (identity_proto.IdentityPluginClient, error) {
+	return f.plugin, nil
+}
+
+func TestIdentityService_Logout(t *testing.T) {
+	tests := []struct {
+		name      string
+		logoutErr error
+		revoked   bool
+	}{
+		{"revoked at the provider", nil, true},
+		{"revocation fails", errors.New("provider unreachable"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMemoryRepository()
+			saveTestToken(t, repo, "alice", time.Hour)
+			plugin := &fakeIdentityPlugin{logoutErr: tt.logoutErr}
+			ts := newTestTokenService(t, repo, plugin)
+			s := NewIdentityService(repo, fakePlugins{identity: plugin}, ts, loggertest.Nop{})
+
+			revoked, err := s.Logout(context.Background(), "alice", map[string]string{"revoke_sessions": "true"})
+			require.NoError(t, err)
+			assert.Equal(t, tt.revoked, revoked)
+
+			require.Len(t, plugin.logouts, 1)
+			assert.Equal(t, "old-refresh", plugin.logouts[0].Options["refresh_token"])
+			assert.Equal(t, "true", plugin.logouts[0].Options["revoke_sessions"])
+
+			token, err := repo.GetToken("azure", "alice")
+			require.NoError(t, err)
+			assert.Nil(t, token, "the token is deleted even when revocation fails")
+			i, err := repo.GetIdentity("alice")
+			require.NoError(t, err)
+			assert.Nil(t, i)
+		})
+	}
+
+	t.Run("unknown identity", func(t *testing.T) {
+		repo := NewMemoryRepository()
+		s := NewIdentityService(repo, fakePlugins{}, newTestTokenService(t, repo, &fakeIdentityPlugin{}), loggertest.Nop{})
+		_, err := s.Logout(context.Background(), "nobody", nil)
+		assert.ErrorContains(t, err, "identity not found")
+	})
+}
+
+func TestTokenService_RefreshWithoutRefreshToken(t *testing.T) {
+	repo := NewMemoryRepository()
+	plugin := &fakeIdentityPlugin{}
+	ts := newTestTokenService(t, repo, plugin)
+	require.NoError(t, repo.SaveIdentity(&Identity{ID: "user", Provider: "oidc"}))
+	require.NoError(t, repo.SaveToken("oidc", "user", &Token{AccessToken: "a", ExpiresAt: time.Now()}))
+	require.NoError(t, repo.SaveIdentity(&Identity{ID: "client", Provider: "oidc", Metadata: map[string]string{"method": "client-secret"}}))
+	require.NoError(t, repo.SaveToken("oidc", "client", &Token{AccessToken: "a", ExpiresAt: time.Now()}))
+
+	_, err := ts.RefreshToken(context.Background(), "oidc", "user")
+	assert.ErrorContains(t, err, "no refresh token found")
+	assert.Zero(t, plugin.refreshes.Load())
+
+	token, err := ts.RefreshToken(context.Background(), "oidc", "client")
+	require.NoError(t, err)
+	assert.Equal(t, "access-1", token.AccessToken, "the plugin renews tokens from a recorded login method")
+	require.Len(t, plugin.refreshOptions, 1)
+	assert.Equal(t, "client-secret", plugin.refreshOptions[0]["method"])
+}
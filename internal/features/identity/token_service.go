@@ -3,6 +3,8 @@ package identity
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/michaeldcanady/go-onedrive/internal/core/logger"
@@ -16,6 +18,17 @@ type DefaultTokenService struct {
 	pluginManager plugins.Manager
 	config        config.Service
 	logger        logger.Service
+
+	mu         sync.Mutex
+	refreshing map[string]*refreshCall
+}
+
+// refreshCall is a refresh in flight that concurrent callers for the same token wait on
+// rather than starting their own.
+type refreshCall struct {
+	done  chan struct{}
+	token *Token
+	err   error
 }
 
 // NewTokenService returns a new [*DefaultTokenService] initialized with required dependencies.
@@ -26,6 +39,7 @@ func NewTokenService(repo Repository, pm plugins.Manager, cs config.Service, l l
 		pluginManager: pm,
 		config:        cs,
 		logger:        l,
+		refreshing:    make(map[string]*refreshCall),
 	}
 }
 
@@ -53,35 +67,56 @@ func (s *DefaultTokenService) SaveToken(ctx context.Context, provider string, id
 	return s.repo.SaveToken(provider, identityID, token)
 }
 
+// RefreshToken refreshes at most once at a time per token. Callers that arrive while a
+// refresh is in flight share its result, so a rotated refresh token is never redeemed
+// twice.
 func (s *DefaultTokenService) RefreshToken(ctx context.Context, provider string, identityID string) (*Token, error) {
+	key := tokenKey(provider, identityID)
+
+	s.mu.Lock()
+	if call, ok := s.refreshing[key]; ok {
+		s.mu.Unlock()
+		select {
+		case <-call.done:
+			return call.token, call.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	call := &refreshCall{done: make(chan struct{})}
+	s.refreshing[key] = call
+	s.mu.Unlock()
+
+	call.token, call.err = s.refresh(ctx, provider, identityID)
+
+	s.mu.Lock()
+	delete(s.refreshing, key)
+	s.mu.Unlock()
+	close(call.done)
+
+	return call.token, call.err
+}
+
+func (s *DefaultTokenService) refresh(ctx context.Context, provider string, identityID string) (*Token, error) {
 	token, err := s.repo.GetToken(provider, identityID)
 	if err != nil {
 		return nil, err
 	}
-	if token == nil || token.RefreshToken == "" {
+	// Without a refresh token, the plugin may still renew the token by repeating the
+	// login method recorded on the identity, such as a client-credentials grant.
+	opts := s.clientOptions(provider, identityID)
+	if token == nil || (token.RefreshToken == "" && opts["method"] == "") {
 		return nil, fmt.Errorf("cannot refresh: no refresh token found for %s:%s. Please run 'odc identity login --provider %s'", provider, identityID, provider)
 	}
 
-	options := make(map[string]string)
-	clientIDKey := fmt.Sprintf("identity.%s.client_id", provider)
-	clientSecretKey := fmt.Sprintf("identity.%s.client_secret", provider)
-
-	if val, err := s.config.Get(clientIDKey); err == nil && val != nil {
-		options["client_id"] = fmt.Sprintf("%v", val)
-	}
-	if val, err := s.config.Get(clientSecretKey); err == nil && val != nil {
-		options["client_secret"] = fmt.Sprintf("%v", val)
-	}
-
-	pluginName := fmt.Sprintf("identity-%s", provider)
-	client, err := s.pluginManager.GetIdentityPlugin(pluginName)
+	client, err := s.plugin(provider)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get identity plugin %s: %w", pluginName, err)
+		return nil, err
 	}
 
 	resp, err := client.Refresh(ctx, &identity_proto.RefreshRequest{
 		RefreshToken: token.RefreshToken,
-		Options:      options,
+		Options:      opts,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("token refresh failed: %w. Your session may have expired, please run 'odc identity login --provider %s' to re-authenticate", err, provider)
@@ -99,3 +134,79 @@ func (s *DefaultTokenService) RefreshToken(ctx context.Context, provider string,
 
 	return newToken, nil
 }
+
+// RevokeToken passes the stored token to the provider's plugin for revocation. Tokens
+// that are already gone count as revoked.
+func (s *DefaultTokenService) RevokeToken(ctx context.Context, provider string, identityID string, options map[string]string) (bool, error) {
+	token, err := s.repo.GetToken(provider, identityID)
+	if err != nil {
+		return false, err
+	}
+	if token == nil {
+		return true, nil
+	}
+
+	client, err := s.plugin(provider)
+	if err != nil {
+		return false, err
+	}
+
+	opts := s.clientOptions(provider, identityID)
+	for k, v := range options {
+		opts[k] = v
+	}
+	opts["access_token"] = token.AccessToken
+	opts["refresh_token"] = token.RefreshToken
+	opts["expires_at"] = strconv.FormatInt(token.ExpiresAt.Unix(), 10)
+
+	resp, err := client.Logout(ctx, &identity_proto.LogoutRequest{
+		IdentityId: identityID,
+		Options:    opts,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke token for %s:%s: %w", provider, identityID, err)
+	}
+	return resp.Success, nil
+}
+
+func (s *DefaultTokenService) DeleteToken(ctx context.Context, provider string, identityID string) error {
+	return s.repo.DeleteToken(provider, identityID)
+}
+
+// plugin returns the identity plugin for provider.
+func (s *DefaultTokenService) plugin(provider string) (identity_proto.IdentityPluginClient, error) {
+	pluginName := fmt.Sprintf("identity-%s", provider)
+	client, err := s.pluginManager.GetIdentityPlugin(pluginName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get identity plugin %s: %w", pluginName, err)
+	}
+	return client, nil
+}
+
+// clientOptions returns the OAuth client the identity's token was issued to: the login
+// options recorded on the identity, falling back to those configured for provider.
+func (s *DefaultTokenService) clientOptions(provider string, identityID string) map[string]string {
+	options := make(map[string]string)
+	for _, name := range []string{"client_id", "client_secret", "tenant_id", "issuer"} {
+		if val, err := s.config.Get(fmt.Sprintf("identity.%s.%s", provider, name)); err == nil && val != nil {
+			options[name] = fmt.Sprintf("%v", val)
+		}
+	}
+	if i, err := s.repo.GetIdentity(identityID); err == nil && i != nil {
+		for _, name := range loginOptions {
+			if val := i.Metadata[name]; val != "" {
+				options[name] = val
+			}
+		}
+	}
+	return options
+}
+
+func (s *DefaultTokenService) MigrateTokens(ctx context.Context) (int, error) {
+	n, err := s.repo.MigrateTokens()
+	if err != nil {
+		return n, fmt.Errorf("failed to migrate tokens: %w", err)
+	}
+	logger.WithContext(s.logger, ctx).Info("migrated plaintext tokens", "count", n)
+	return n, nil
+}
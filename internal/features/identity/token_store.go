@@ -0,0 +1,230 @@
+package identity
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sync"
+
+	"go.etcd.io/bbolt"
+)
+
+var (
+	encryptedTokensBucket = []byte("encrypted_tokens")
+	tokenKeyBucket        = []byte("token_key")
+
+	saltKey  = []byte("salt")
+	checkKey = []byte("check")
+)
+
+const (
+	// encryptedTokenVersion prefixes every sealed value so the format can change later.
+	encryptedTokenVersion byte = 1
+	// keyIterations is the PBKDF2-SHA256 work factor used to derive the encryption key.
+	keyIterations = 600_000
+	// checkValue is sealed with the derived key so a wrong passphrase is detected up front
+	// rather than reported as corrupted tokens.
+	checkValue = "odc-token-store"
+)
+
+var (
+	// ErrInvalidTokenKey is returned when the passphrase or key file does not match the
+	// one the stored tokens were encrypted with.
+	ErrInvalidTokenKey = errors.New("token store key does not match the key the tokens were encrypted with")
+	// ErrKeyringUnavailable is returned when no supported OS keyring can be reached.
+	ErrKeyringUnavailable = errors.New("no supported keyring is available")
+)
+
+// TokenStore persists serialized tokens by key. Implementations decide how the bytes
+// are protected at rest.
+type TokenStore interface {
+	// Put stores data under key, replacing any existing value.
+	Put(key string, data []byte) error
+	// Get returns the data stored under key, or nil when there is none.
+	Get(key string) ([]byte, error)
+	// Delete removes the value stored under key. Deleting a missing key is not an error.
+	Delete(key string) error
+}
+
+type plainTokenStore struct {
+	db *bbolt.DB
+}
+
+// NewPlainTokenStore returns a [TokenStore] that keeps tokens unencrypted in the tokens
+// bucket of db.
+func NewPlainTokenStore(db *bbolt.DB) (TokenStore, error) {
+	err := db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(tokensBucket)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize token bucket: %w", err)
+	}
+	return &plainTokenStore{db: db}, nil
+}
+
+func (s *plainTokenStore) Put(key string, data []byte) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(tokensBucket).Put([]byte(key), data)
+	})
+}
+
+func (s *plainTokenStore) Get(key string) ([]byte, error) {
+	var data []byte
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		if v := tx.Bucket(tokensBucket).Get([]byte(key)); v != nil {
+			data = append([]byte(nil), v...)
+		}
+		return nil
+	})
+	return data, err
+}
+
+func (s *plainTokenStore) Delete(key string) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(tokensBucket).Delete([]byte(key))
+	})
+}
+
+type encryptedTokenStore struct {
+	db     *bbolt.DB
+	secret []byte
+
+	once sync.Once
+	aead cipher.AEAD
+	err  error
+}
+
+// NewEncryptedTokenStore returns a [TokenStore] that seals tokens with AES-256-GCM before
+// writing them to db. The key is derived from secret, a passphrase or the contents of a
+// key file, with a random salt kept alongside the tokens. Derivation is deferred until a
+// token is first read or written, so commands that never touch tokens do not pay for it.
+func NewEncryptedTokenStore(db *bbolt.DB, secret []byte) (TokenStore, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("an encrypted token store requires a passphrase or key file")
+	}
+	err := db.Update(func(tx *bbolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(encryptedTokensBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(tokenKeyBucket)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize encrypted token buckets: %w", err)
+	}
+	return &encryptedTokenStore{db: db, secret: secret}, nil
+}
+
+// derive builds the cipher on first use, creating the salt and check value for a new store
+// and verifying the key against the check value of an existing one.
+func (s *encryptedTokenStore) derive() (cipher.AEAD, error) {
+	s.once.Do(func() {
+		s.err = s.db.Update(func(tx *bbolt.Tx) error {
+			b := tx.Bucket(tokenKeyBucket)
+			salt := b.Get(saltKey)
+			if salt == nil {
+				salt = make([]byte, 16)
+				if _, err := rand.Read(salt); err != nil {
+					return err
+				}
+				if err := b.Put(saltKey, salt); err != nil {
+					return err
+				}
+			}
+			key, err := pbkdf2.Key(sha256.New, string(s.secret), salt, keyIterations, 32)
+			if err != nil {
+				return err
+			}
+			block, err := aes.NewCipher(key)
+			if err != nil {
+				return err
+			}
+			aead, err := cipher.NewGCM(block)
+			if err != nil {
+				return err
+			}
+
+			check := b.Get(checkKey)
+			if check == nil {
+				sealed, err := sealToken(aead, checkKey, []byte(checkValue))
+				if err != nil {
+					return err
+				}
+				if err := b.Put(checkKey, sealed); err != nil {
+					return err
+				}
+			} else if plain, err := openToken(aead, checkKey, check); err != nil || string(plain) != checkValue {
+				return ErrInvalidTokenKey
+			}
+			s.aead = aead
+			return nil
+		})
+	})
+	return s.aead, s.err
+}
+
+func (s *encryptedTokenStore) Put(key string, data []byte) error {
+	aead, err := s.derive()
+	if err != nil {
+		return err
+	}
+	sealed, err := sealToken(aead, []byte(key), data)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(encryptedTokensBucket).Put([]byte(key), sealed)
+	})
+}
+
+func (s *encryptedTokenStore) Get(key string) ([]byte, error) {
+	aead, err := s.derive()
+	if err != nil {
+		return nil, err
+	}
+	var sealed []byte
+	err = s.db.View(func(tx *bbolt.Tx) error {
+		if v := tx.Bucket(encryptedTokensBucket).Get([]byte(key)); v != nil {
+			sealed = append([]byte(nil), v...)
+		}
+		return nil
+	})
+	if err != nil || sealed == nil {
+		return nil, err
+	}
+	data, err := openToken(aead, []byte(key), sealed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt token %s: %w", key, err)
+	}
+	return data, nil
+}
+
+func (s *encryptedTokenStore) Delete(key string) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(encryptedTokensBucket).Delete([]byte(key))
+	})
+}
+
+// sealToken encrypts data as a version byte, a random nonce and the ciphertext. The key the
+// value is stored under is authenticated too, so sealed values cannot be swapped.
+func sealToken(aead cipher.AEAD, key, data []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	out := append([]byte{encryptedTokenVersion}, nonce...)
+	return aead.Seal(out, nonce, data, key), nil
+}
+
+func openToken(aead cipher.AEAD, key, sealed []byte) ([]byte, error) {
+	if len(sealed) < 1+aead.NonceSize() || sealed[0] != encryptedTokenVersion {
+		return nil, errors.New("unsupported encrypted token format")
+	}
+	nonce, ciphertext := sealed[1:1+aead.NonceSize()], sealed[1+aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, key)
+}
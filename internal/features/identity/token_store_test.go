@@ -0,0 +1,146 @@
+package identity
+
+import (
+	"bytes"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+)
+
+func openTestDB(t *testing.T) *bbolt.DB {
+	t.Helper()
+	db, err := bbolt.Open(filepath.Join(t.TempDir(), "state.db"), 0600, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestEncryptedTokenStore(t *testing.T) {
+	db := openTestDB(t)
+	store, err := NewEncryptedTokenStore(db, []byte("correct horse"))
+	require.NoError(t, err)
+
+	secret := []byte(`{"access_token":"secret-access-token"}`)
+	require.NoError(t, store.Put("azure:alice", secret))
+
+	got, err := store.Get("azure:alice")
+	require.NoError(t, err)
+	assert.Equal(t, secret, got)
+
+	missing, err := store.Get("azure:bob")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	t.Run("ciphertext at rest", func(t *testing.T) {
+		require.NoError(t, db.View(func(tx *bbolt.Tx) error {
+			raw := tx.Bucket(encryptedTokensBucket).Get([]byte("azure:alice"))
+			require.NotNil(t, raw)
+			assert.False(t, bytes.Contains(raw, []byte("secret-access-token")))
+			return nil
+		}))
+	})
+
+	t.Run("same passphrase reopens", func(t *testing.T) {
+		reopened, err := NewEncryptedTokenStore(db, []byte("correct horse"))
+		require.NoError(t, err)
+		got, err := reopened.Get("azure:alice")
+		require.NoError(t, err)
+		assert.Equal(t, secret, got)
+	})
+
+	t.Run("wrong passphrase", func(t *testing.T) {
+		wrong, err := NewEncryptedTokenStore(db, []byte("battery staple"))
+		require.NoError(t, err)
+		_, err = wrong.Get("azure:alice")
+		assert.ErrorIs(t, err, ErrInvalidTokenKey)
+		assert.ErrorIs(t, wrong.Put("azure:bob", secret), ErrInvalidTokenKey)
+	})
+
+	t.Run("values are bound to their key", func(t *testing.T) {
+		require.NoError(t, db.Update(func(tx *bbolt.Tx) error {
+			b := tx.Bucket(encryptedTokensBucket)
+			return b.Put([]byte("azure:mallory"), b.Get([]byte("azure:alice")))
+		}))
+		_, err := store.Get("azure:mallory")
+		assert.Error(t, err)
+	})
+
+	_, err = NewEncryptedTokenStore(db, nil)
+	assert.Error(t, err, "an empty secret is refused")
+}
+
+func TestBoltRepository_MigrateTokens(t *testing.T) {
+	db := openTestDB(t)
+	token := &Token{AccessToken: "access", RefreshToken: "refresh", ExpiresAt: time.Now().Add(time.Hour).UTC()}
+
+	plain, err := NewBoltRepository(db)
+	require.NoError(t, err)
+	require.NoError(t, plain.SaveToken("azure", "alice", token))
+	n, err := plain.MigrateTokens()
+	require.NoError(t, err)
+	assert.Zero(t, n, "the plaintext repository has nothing to migrate to")
+
+	store, err := NewEncryptedTokenStore(db, []byte("passphrase"))
+	require.NoError(t, err)
+	repo, err := NewBoltRepositoryWithTokenStore(db, store)
+	require.NoError(t, err)
+
+	_, err = repo.GetToken("azure", "alice")
+	assert.ErrorContains(t, err, "odc identity migrate")
+
+	n, err = repo.MigrateTokens()
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	got, err := repo.GetToken("azure", "alice")
+	require.NoError(t, err)
+	assert.Equal(t, token.AccessToken, got.AccessToken)
+	assert.Equal(t, token.RefreshToken, got.RefreshToken)
+
+	legacy, err := plain.GetToken("azure", "alice")
+	require.NoError(t, err)
+	assert.Nil(t, legacy, "the plaintext copy is removed")
+
+	require.NoError(t, repo.DeleteToken("azure", "alice"))
+	got, err = repo.GetToken("azure", "alice")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+func TestKeyringTokenStore(t *testing.T) {
+	secrets := make(map[string][]byte)
+	var calls [][]string
+	store := &keyringTokenStore{run: func(stdin []byte, args ...string) ([]byte, error) {
+		calls = append(calls, args)
+		account := args[len(args)-1]
+		switch args[0] {
+		case "store":
+			secrets[account] = stdin
+		case "lookup":
+			if secrets[account] == nil {
+				return nil, &exec.ExitError{}
+			}
+			return secrets[account], nil
+		case "clear":
+			delete(secrets, account)
+		}
+		return nil, nil
+	}}
+
+	require.NoError(t, store.Put("azure:alice", []byte("token")))
+	assert.NotContains(t, calls[0], "token", "secrets are passed on stdin, not as arguments")
+
+	got, err := store.Get("azure:alice")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("token"), got)
+
+	require.NoError(t, store.Delete("azure:alice"))
+	got, err = store.Get("azure:alice")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
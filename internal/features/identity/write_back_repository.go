@@ -0,0 +1,83 @@
+package identity
+
+import (
+	"sync"
+
+	"github.com/michaeldcanady/go-onedrive/internal/core/logger"
+)
+
+// WriteBackRepository is a [Repository] that serves everything from memory and writes
+// the tokens saved to it back to state.db. Long-running commands use it so that refresh
+// tokens rotated while they run are not lost, without holding state.db open: the
+// database is only opened, by persist, for the duration of each write.
+type WriteBackRepository struct {
+	Repository
+	persist func(fn func(Repository) error) error
+	logger  logger.Service
+
+	mu      sync.Mutex
+	pending map[string]pendingToken
+}
+
+// pendingToken is a token that has not been written back yet.
+type pendingToken struct {
+	provider   string
+	identityID string
+	token      *Token
+}
+
+// NewWriteBackRepository returns a [*WriteBackRepository] serving cache. persist calls
+// its argument with the repository backed by state.db.
+func NewWriteBackRepository(cache Repository, persist func(fn func(Repository) error) error, l logger.Service) *WriteBackRepository {
+	return &WriteBackRepository{
+		Repository: cache,
+		persist:    persist,
+		logger:     l,
+		pending:    make(map[string]pendingToken),
+	}
+}
+
+// SaveToken saves t in memory and writes it back. A token that cannot be written back,
+// for example because another command holds state.db, is kept and written with the
+// next token saved or by [WriteBackRepository.Flush].
+func (r *WriteBackRepository) SaveToken(provider string, identityID string, t *Token) error {
+	if err := r.Repository.SaveToken(provider, identityID, t); err != nil {
+		return err
+	}
+	c := *t
+	r.mu.Lock()
+	r.pending[tokenKey(provider, identityID)] = pendingToken{provider: provider, identityID: identityID, token: &c}
+	r.mu.Unlock()
+
+	if err := r.Flush(); err != nil {
+		r.logger.Warn("failed to write token back to state.db, will retry", "provider", provider, "identity", identityID, "error", err)
+	}
+	return nil
+}
+
+// DeleteToken forgets the token in memory, along with any copy still waiting to be
+// written back. The copy in state.db is left alone.
+func (r *WriteBackRepository) DeleteToken(provider string, identityID string) error {
+	r.mu.Lock()
+	delete(r.pending, tokenKey(provider, identityID))
+	r.mu.Unlock()
+	return r.Repository.DeleteToken(provider, identityID)
+}
+
+// Flush writes back the tokens that have not been written yet.
+func (r *WriteBackRepository) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.pending) == 0 {
+		return nil
+	}
+	return r.persist(func(repo Repository) error {
+		for key, p := range r.pending {
+			if err := repo.SaveToken(p.provider, p.identityID, p.token); err != nil {
+				return err
+			}
+			delete(r.pending, key)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,51 @@
+package identity
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/michaeldcanady/go-onedrive/internal/core/logger/loggertest"
+)
+
+func TestWriteBackRepository(t *testing.T) {
+	stored := NewMemoryRepository()
+	locked := true
+	persist := func(fn func(Repository) error) error {
+		if locked {
+			return errors.New("database in use")
+		}
+		return fn(stored)
+	}
+	repo := NewWriteBackRepository(NewMemoryRepository(), persist, loggertest.Nop{})
+
+	require.NoError(t, repo.SaveToken("azure", "alice", &Token{RefreshToken: "r1"}), "a failed write-back does not fail the save")
+	token, err := repo.GetToken("azure", "alice")
+	require.NoError(t, err)
+	assert.Equal(t, "r1", token.RefreshToken)
+	token, err = stored.GetToken("azure", "alice")
+	require.NoError(t, err)
+	assert.Nil(t, token)
+
+	locked = false
+	require.NoError(t, repo.Flush())
+	token, err = stored.GetToken("azure", "alice")
+	require.NoError(t, err)
+	assert.Equal(t, "r1", token.RefreshToken, "pending tokens are written on flush")
+
+	require.NoError(t, repo.SaveToken("azure", "alice", &Token{RefreshToken: "r2"}))
+	token, err = stored.GetToken("azure", "alice")
+	require.NoError(t, err)
+	assert.Equal(t, "r2", token.RefreshToken, "tokens are written back as they are saved")
+
+	locked = true
+	require.NoError(t, repo.SaveToken("azure", "bob", &Token{RefreshToken: "b1"}))
+	require.NoError(t, repo.DeleteToken("azure", "bob"))
+	locked = false
+	require.NoError(t, repo.Flush())
+	token, err = stored.GetToken("azure", "bob")
+	require.NoError(t, err)
+	assert.Nil(t, token, "deleted tokens are not written back")
+}
@@ -3,12 +3,14 @@ package mount
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"go.etcd.io/bbolt"
 )
 
 var (
-	mountsBucket = []byte("mounts")
+	mountsBucket  = []byte("mounts")
+	cursorsBucket = []byte("cursors")
 )
 
 type boltRepository struct {
@@ -18,7 +20,10 @@ type boltRepository struct {
 // NewBoltRepository creates a new bbolt-based mount repository.
 func NewBoltRepository(db *bbolt.DB) (Repository, error) {
 	err := db.Update(func(tx *bbolt.Tx) error {
-		_, err := tx.CreateBucketIfNotExists(mountsBucket)
+		if _, err := tx.CreateBucketIfNotExists(mountsBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(cursorsBucket)
 		return err
 	})
 	if err != nil {
@@ -57,8 +62,24 @@ func (r *boltRepository) List() ([]*Mount, error) {
 
 func (r *boltRepository) Delete(path string) error {
 	return r.db.Update(func(tx *bbolt.Tx) error {
-		b := tx.Bucket(mountsBucket)
-		return b.Delete([]byte(path))
+		if err := tx.Bucket(mountsBucket).Delete([]byte(path)); err != nil {
+			return err
+		}
+
+		cursors := tx.Bucket(cursorsBucket)
+		var stale [][]byte
+		c := cursors.Cursor()
+		for k, _ := c.Seek([]byte(path)); k != nil && strings.HasPrefix(string(k), path); k, _ = c.Next() {
+			if p := string(k); p == path || strings.HasPrefix(p, path+"/") {
+				stale = append(stale, k)
+			}
+		}
+		for _, k := range stale {
+			if err := cursors.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
 	})
 }
 
@@ -77,3 +98,18 @@ func (r *boltRepository) Get(path string) (*Mount, error) {
 	}
 	return &m, err
 }
+
+func (r *boltRepository) GetCursor(path string) (string, error) {
+	var cursor string
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		cursor = string(tx.Bucket(cursorsBucket).Get([]byte(path)))
+		return nil
+	})
+	return cursor, err
+}
+
+func (r *boltRepository) SaveCursor(path, cursor string) error {
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(cursorsBucket).Put([]byte(path), []byte(cursor))
+	})
+}
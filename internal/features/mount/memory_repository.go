@@ -0,0 +1,78 @@
+package mount
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+type memoryRepository struct {
+	mu      sync.RWMutex
+	mounts  map[string]*Mount
+	cursors map[string]string
+}
+
+// NewMemoryRepository returns a [Repository] that lives only as long as the process.
+// Long-running commands use it so they do not hold state.db open while they run.
+func NewMemoryRepository() Repository {
+	return &memoryRepository{
+		mounts:  make(map[string]*Mount),
+		cursors: make(map[string]string),
+	}
+}
+
+func (r *memoryRepository) Save(m *Mount) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c := *m
+	r.mounts[m.Path] = &c
+	return nil
+}
+
+func (r *memoryRepository) List() ([]*Mount, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	mounts := make([]*Mount, 0, len(r.mounts))
+	for _, m := range r.mounts {
+		c := *m
+		mounts = append(mounts, &c)
+	}
+	sort.Slice(mounts, func(i, j int) bool { return mounts[i].Path < mounts[j].Path })
+	return mounts, nil
+}
+
+func (r *memoryRepository) Delete(path string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.mounts, path)
+	for p := range r.cursors {
+		if p == path || strings.HasPrefix(p, path+"/") {
+			delete(r.cursors, p)
+		}
+	}
+	return nil
+}
+
+func (r *memoryRepository) Get(path string) (*Mount, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	m, ok := r.mounts[path]
+	if !ok {
+		return nil, nil
+	}
+	c := *m
+	return &c, nil
+}
+
+func (r *memoryRepository) GetCursor(path string) (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cursors[path], nil
+}
+
+func (r *memoryRepository) SaveCursor(path, cursor string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cursors[path] = cursor
+	return nil
+}
@@ -25,6 +25,13 @@ type Service interface {
 
 	// Get retrieves the mount point configuration for the specified path.
 	Get(ctx context.Context, path string) (*Mount, error)
+
+	// Cursor returns the change cursor last recorded for the VFS path, or an empty
+	// string if none has been recorded.
+	Cursor(ctx context.Context, path string) (string, error)
+
+	// SaveCursor records the change cursor for the VFS path.
+	SaveCursor(ctx context.Context, path, cursor string) error
 }
 
 // Repository handles the low-level persistence of [Mount] configurations.
@@ -35,9 +42,16 @@ type Repository interface {
 	// List retrieves all stored mount configurations.
 	List() ([]*Mount, error)
 
-	// Delete removes a mount configuration by its logical path.
+	// Delete removes a mount configuration by its logical path, along with any
+	// change cursors recorded beneath it.
 	Delete(path string) error
 
 	// Get retrieves a single mount configuration by its logical path.
 	Get(path string) (*Mount, error)
+
+	// GetCursor retrieves the change cursor stored for a VFS path.
+	GetCursor(path string) (string, error)
+
+	// SaveCursor persists the change cursor for a VFS path.
+	SaveCursor(path, cursor string) error
 }
@@ -43,3 +43,14 @@ func (s *mountService) Remove(ctx context.Context, path string) error {
 func (s *mountService) Get(ctx context.Context, path string) (*Mount, error) {
 	return s.repo.Get(path)
 }
+
+func (s *mountService) Cursor(ctx context.Context, path string) (string, error) {
+	return s.repo.GetCursor(path)
+}
+
+func (s *mountService) SaveCursor(ctx context.Context, path, cursor string) error {
+	if err := s.repo.SaveCursor(path, cursor); err != nil {
+		return fmt.Errorf("failed to save cursor: %w", err)
+	}
+	return nil
+}
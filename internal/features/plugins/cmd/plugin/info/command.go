@@ -0,0 +1,59 @@
+// Code generated by spec-gen. DO NOT EDIT.
+package info
+
+import (
+	"github.com/michaeldcanady/go-onedrive/internal/core/di"
+	"github.com/spf13/cobra"
+)
+
+// CreateInfoCmd returns a new [cobra.Command] initialized with dependencies
+// and configured to execute the "info" operation.
+func CreateInfoCmd(container di.Container) *cobra.Command {
+	var opts Options
+	var c *CommandContext
+
+	l := container.Logger().With("command", "plugin-info")
+
+	// Create the handler using the generated factory
+	var handler Handler = NewCommand(
+		container.PluginManager(),
+		container.Formatter(),
+		container.Logger(),
+		l,
+		container.Resolver(),
+	)
+
+	cmd := &cobra.Command{
+		Use:   "info <name> [flags]",
+		Short: "Show a plugin's metadata and capabilities",
+		Long:  `Display the metadata a plugin reports about itself, including the optional operations a storage plugin implements natively.`,
+		Args:  cobra.ExactArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				opts.Name = args[0]
+			}
+			opts.Stdout = cmd.OutOrStdout()
+			opts.Stderr = cmd.ErrOrStderr()
+
+			c = &CommandContext{
+				Ctx:     cmd.Context(),
+				Options: opts,
+			}
+
+			if err := handler.Validate(c); err != nil {
+				return err
+			}
+
+			return handler.Resolve(c)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := handler.Execute(c); err != nil {
+				return err
+			}
+			return handler.Finalize(c)
+		},
+	}
+	cmd.Flags().StringVarP(&opts.Format, "format", "o", "table", "Output format (table, json, yaml)")
+
+	return cmd
+}
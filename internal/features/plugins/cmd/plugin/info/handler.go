@@ -0,0 +1,102 @@
+package info
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/michaeldcanady/go-onedrive/internal/core/plugins"
+	"github.com/michaeldcanady/go-onedrive/pkg/format"
+)
+
+// PluginInfo is the output of the info command. It implements format.Tabular as a
+// property/value listing.
+type PluginInfo struct {
+	Name               string                `json:"name" yaml:"name"`
+	Type               string                `json:"type" yaml:"type"`
+	Path               string                `json:"path" yaml:"path"`
+	Version            string                `json:"version" yaml:"version"`
+	Checksum           string                `json:"checksum" yaml:"checksum"`
+	Builtin            bool                  `json:"builtin" yaml:"builtin"`
+	SupportedProviders []string              `json:"supported_providers" yaml:"supported_providers"`
+	Capabilities       *plugins.Capabilities `json:"capabilities,omitempty" yaml:"capabilities,omitempty"`
+}
+
+// TableHeaders returns the headers for the table output.
+func (i PluginInfo) TableHeaders() []string {
+	return []string{"PROPERTY", "VALUE"}
+}
+
+// TableRows returns the rows for the table output.
+func (i PluginInfo) TableRows() [][]string {
+	rows := [][]string{
+		{"Name", i.Name},
+		{"Type", i.Type},
+		{"Path", i.Path},
+		{"Version", i.Version},
+		{"Checksum", i.Checksum},
+		{"Built-in", strconv.FormatBool(i.Builtin)},
+		{"Providers", strings.Join(i.SupportedProviders, ", ")},
+	}
+	if i.Type != plugins.PluginTypeStorage {
+		return rows
+	}
+
+	caps := i.Capabilities
+	flag := func(supported func(*plugins.Capabilities) bool) string {
+		switch {
+		case caps == nil:
+			return "unknown"
+		case supported(caps):
+			return "yes"
+		default:
+			return "no"
+		}
+	}
+	hashes := "unknown"
+	if caps != nil {
+		hashes = strings.Join(caps.Hashes, ", ")
+	}
+	return append(rows,
+		[]string{"Move", flag(func(c *plugins.Capabilities) bool { return c.Move })},
+		[]string{"Copy", flag(func(c *plugins.Capabilities) bool { return c.Copy })},
+		[]string{"Ranged read", flag(func(c *plugins.Capabilities) bool { return c.RangedRead })},
+		[]string{"ETag concurrency", flag(func(c *plugins.Capabilities) bool { return c.ETagConcurrency })},
+		[]string{"Delta", flag(func(c *plugins.Capabilities) bool { return c.Delta })},
+		[]string{"Hashes", hashes},
+	)
+}
+
+// Validate ensures that the provided options are semantically correct.
+func (c *Command) Validate(ctx *CommandContext) error {
+	return nil
+}
+
+// Resolve translates user input into domain entities using the [resolver.Service].
+func (c *Command) Resolve(ctx *CommandContext) error {
+	return c.BaseResolve(ctx)
+}
+
+// Execute performs the primary business logic of the "info" command.
+func (c *Command) Execute(ctx *CommandContext) error {
+	meta, err := c.pluginManager.GetMetadata(ctx.Ctx, ctx.Options.Name)
+	if err != nil {
+		return err
+	}
+
+	info := PluginInfo{
+		Name:               meta.Name,
+		Type:               meta.Type,
+		Path:               meta.PluginPath,
+		Version:            meta.Version,
+		Checksum:           meta.Checksum,
+		Builtin:            meta.Builtin,
+		SupportedProviders: meta.SupportedProviders,
+		Capabilities:       meta.Capabilities,
+	}
+	return c.formatter.Get(format.Format(ctx.Options.Format)).Format(ctx.Options.Stdout, info)
+}
+
+// Finalize performs post-execution tasks such as output formatting or resource cleanup.
+func (c *Command) Finalize(ctx *CommandContext) error {
+	return nil
+}
@@ -0,0 +1,17 @@
+// Code generated by spec-gen. DO NOT EDIT.
+package info
+
+import (
+	"io"
+)
+
+// Options encapsulates all user-provided arguments and flags for the command.
+type Options struct {
+	Name   string // The plugin executable name, e.g. storage-onedrive
+	Format string // Output format (table, json, yaml)
+
+	// Stdout receives standard output messages.
+	Stdout io.Writer
+	// Stderr receives error and diagnostic messages.
+	Stderr io.Writer
+}
@@ -0,0 +1,57 @@
+// Code generated by spec-gen. DO NOT EDIT.
+package install
+
+import (
+	"github.com/michaeldcanady/go-onedrive/internal/core/di"
+	"github.com/spf13/cobra"
+)
+
+// CreateInstallCmd returns a new [cobra.Command] initialized with dependencies
+// and configured to execute the "install" operation.
+func CreateInstallCmd(container di.Container) *cobra.Command {
+	var opts Options
+	var c *CommandContext
+
+	l := container.Logger().With("command", "plugin-install")
+
+	// Create the handler using the generated factory
+	var handler Handler = NewCommand(
+		container.PluginManager(),
+		container.Logger(),
+		l,
+		container.Resolver(),
+	)
+
+	cmd := &cobra.Command{
+		Use:   "install <source> [flags]",
+		Short: "Install a plugin from a binary or tarball",
+		Long:  `Copy a plugin binary, or the single executable in a .tar.gz archive, into the plugin directory after verifying that it is a working odc plugin.`,
+		Args:  cobra.ExactArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				opts.Source = args[0]
+			}
+			opts.Stdout = cmd.OutOrStdout()
+			opts.Stderr = cmd.ErrOrStderr()
+
+			c = &CommandContext{
+				Ctx:     cmd.Context(),
+				Options: opts,
+			}
+
+			if err := handler.Validate(c); err != nil {
+				return err
+			}
+
+			return handler.Resolve(c)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := handler.Execute(c); err != nil {
+				return err
+			}
+			return handler.Finalize(c)
+		},
+	}
+
+	return cmd
+}
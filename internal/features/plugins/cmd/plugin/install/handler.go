@@ -0,0 +1,42 @@
+package install
+
+import (
+	"fmt"
+)
+
+// Validate ensures that the provided options are semantically correct.
+func (c *Command) Validate(ctx *CommandContext) error {
+	if ctx.Options.Source == "" {
+		return fmt.Errorf("source is required")
+	}
+	return nil
+}
+
+// Resolve translates user input into domain entities using the [resolver.Service].
+func (c *Command) Resolve(ctx *CommandContext) error {
+	return c.BaseResolve(ctx)
+}
+
+// Execute performs the primary business logic of the "install" command.
+func (c *Command) Execute(ctx *CommandContext) error {
+	meta, err := c.pluginManager.Install(ctx.Ctx, ctx.Options.Source)
+	if err != nil {
+		return err
+	}
+
+	version := meta.Version
+	if version == "" {
+		version = "unknown version"
+	}
+	signed := ""
+	if meta.Signature != "" {
+		signed = ", signed"
+	}
+	fmt.Fprintf(ctx.Options.Stdout, "Installed %s plugin %s (%s, sha256 %s%s)\n", meta.Type, meta.PluginPath, version, meta.Checksum, signed)
+	return nil
+}
+
+// Finalize performs post-execution tasks such as output formatting or resource cleanup.
+func (c *Command) Finalize(ctx *CommandContext) error {
+	return nil
+}
@@ -0,0 +1,16 @@
+// Code generated by spec-gen. DO NOT EDIT.
+package install
+
+import (
+	"io"
+)
+
+// Options encapsulates all user-provided arguments and flags for the command.
+type Options struct {
+	Source string // Path to the plugin binary or a .tar.gz archive containing it
+
+	// Stdout receives standard output messages.
+	Stdout io.Writer
+	// Stderr receives error and diagnostic messages.
+	Stderr io.Writer
+}
@@ -0,0 +1,80 @@
+package list
+
+import (
+	"strings"
+
+	"github.com/michaeldcanady/go-onedrive/pkg/format"
+)
+
+// PluginListItem represents a single row in the plugin list output.
+type PluginListItem struct {
+	Name      string   `json:"name" yaml:"name"`
+	Type      string   `json:"type" yaml:"type"`
+	Version   string   `json:"version" yaml:"version"`
+	Providers []string `json:"supported_providers" yaml:"supported_providers"`
+	Checksum  string   `json:"checksum" yaml:"checksum"`
+	Builtin   bool     `json:"builtin" yaml:"builtin"`
+}
+
+// PluginList is a collection of PluginListItem that implements format.Tabular.
+type PluginList []PluginListItem
+
+// TableHeaders returns the headers for the table output.
+func (l PluginList) TableHeaders() []string {
+	return []string{"NAME", "TYPE", "VERSION", "PROVIDERS", "CHECKSUM"}
+}
+
+// TableRows returns the rows for the table output. Checksums are shortened to their
+// first 12 hex digits; built-in plugins have none.
+func (l PluginList) TableRows() [][]string {
+	rows := make([][]string, len(l))
+	for i, item := range l {
+		checksum := item.Checksum
+		if len(checksum) > 12 {
+			checksum = checksum[:12]
+		}
+		if item.Builtin {
+			checksum = "(built-in)"
+		}
+		rows[i] = []string{item.Name, item.Type, item.Version, strings.Join(item.Providers, ", "), checksum}
+	}
+	return rows
+}
+
+// Validate performs initial validation of the command options.
+func (c *Command) Validate(ctx *CommandContext) error {
+	return nil
+}
+
+// Resolve performs argument resolution.
+func (c *Command) Resolve(ctx *CommandContext) error {
+	return c.BaseResolve(ctx)
+}
+
+// Execute performs the core business logic of the command.
+func (c *Command) Execute(ctx *CommandContext) error {
+	metas, err := c.pluginManager.ListPlugins(ctx.Ctx)
+	if err != nil {
+		return err
+	}
+
+	list := PluginList{}
+	for _, m := range metas {
+		list = append(list, PluginListItem{
+			Name:      m.PluginPath,
+			Type:      m.Type,
+			Version:   m.Version,
+			Providers: m.SupportedProviders,
+			Checksum:  m.Checksum,
+			Builtin:   m.Builtin,
+		})
+	}
+
+	f := c.formatter.Get(format.Format(ctx.Options.Format))
+	return f.Format(ctx.Options.Stdout, list)
+}
+
+// Finalize performs any cleanup or final output formatting.
+func (c *Command) Finalize(ctx *CommandContext) error {
+	return nil
+}
@@ -0,0 +1,52 @@
+// Code generated by spec-gen. DO NOT EDIT.
+package refresh
+
+import (
+	"github.com/michaeldcanady/go-onedrive/internal/core/di"
+	"github.com/spf13/cobra"
+)
+
+// CreateRefreshCmd returns a new [cobra.Command] initialized with dependencies
+// and configured to execute the "refresh" operation.
+func CreateRefreshCmd(container di.Container) *cobra.Command {
+	var opts Options
+	var c *CommandContext
+
+	l := container.Logger().With("command", "plugin-refresh")
+
+	// Create the handler using the generated factory
+	var handler Handler = NewCommand(
+		container.PluginManager(),
+		container.Logger(),
+		l,
+		container.Resolver(),
+	)
+
+	cmd := &cobra.Command{
+		Use:   "refresh [flags]",
+		Short: "Refresh the plugin metadata cache",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			opts.Stdout = cmd.OutOrStdout()
+			opts.Stderr = cmd.ErrOrStderr()
+
+			c = &CommandContext{
+				Ctx:     cmd.Context(),
+				Options: opts,
+			}
+
+			if err := handler.Validate(c); err != nil {
+				return err
+			}
+
+			return handler.Resolve(c)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := handler.Execute(c); err != nil {
+				return err
+			}
+			return handler.Finalize(c)
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,40 @@
+package refresh
+
+import (
+	"fmt"
+)
+
+// Validate ensures that the provided options are semantically correct.
+func (c *Command) Validate(ctx *CommandContext) error {
+	return nil
+}
+
+// Resolve translates user input into domain entities using the [resolver.Service].
+func (c *Command) Resolve(ctx *CommandContext) error {
+	return c.BaseResolve(ctx)
+}
+
+// Execute performs the primary business logic of the "refresh" command.
+func (c *Command) Execute(ctx *CommandContext) error {
+	result, err := c.pluginManager.Refresh(ctx.Ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range result.Updated {
+		fmt.Fprintf(ctx.Options.Stdout, "Updated %s\n", name)
+	}
+	for _, name := range result.Removed {
+		fmt.Fprintf(ctx.Options.Stdout, "Removed stale entry %s\n", name)
+	}
+	for _, name := range result.Modified {
+		fmt.Fprintf(ctx.Options.Stdout, "Modified %s: reinstall it, or refresh with --skip-plugin-verification to accept the change\n", name)
+	}
+	fmt.Fprintf(ctx.Options.Stdout, "%d plugins cached, %d updated, %d removed, %d modified\n", len(result.Plugins), len(result.Updated), len(result.Removed), len(result.Modified))
+	return nil
+}
+
+// Finalize performs post-execution tasks such as output formatting or resource cleanup.
+func (c *Command) Finalize(ctx *CommandContext) error {
+	return nil
+}
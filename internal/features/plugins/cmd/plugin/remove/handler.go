@@ -0,0 +1,29 @@
+package remove
+
+import (
+	"fmt"
+)
+
+// Validate ensures that the provided options are semantically correct.
+func (c *Command) Validate(ctx *CommandContext) error {
+	if ctx.Options.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	return nil
+}
+
+// Resolve translates user input into domain entities using the [resolver.Service].
+func (c *Command) Resolve(ctx *CommandContext) error {
+	return c.BaseResolve(ctx)
+}
+
+// Execute performs the primary business logic of the "remove" command.
+func (c *Command) Execute(ctx *CommandContext) error {
+	return c.pluginManager.Remove(ctx.Ctx, ctx.Options.Name)
+}
+
+// Finalize performs post-execution tasks such as output formatting or resource cleanup.
+func (c *Command) Finalize(ctx *CommandContext) error {
+	fmt.Fprintf(ctx.Options.Stdout, "Removed plugin %s\n", ctx.Options.Name)
+	return nil
+}
@@ -0,0 +1,16 @@
+// Code generated by spec-gen. DO NOT EDIT.
+package remove
+
+import (
+	"io"
+)
+
+// Options encapsulates all user-provided arguments and flags for the command.
+type Options struct {
+	Name string // The plugin executable name, e.g. storage-onedrive
+
+	// Stdout receives standard output messages.
+	Stdout io.Writer
+	// Stderr receives error and diagnostic messages.
+	Stderr io.Writer
+}
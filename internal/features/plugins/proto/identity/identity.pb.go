@@ -62,6 +62,7 @@ type MetadataResponse struct {
 	Name               string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
 	Type               string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
 	SupportedProviders []string               `protobuf:"bytes,3,rep,name=supported_providers,json=supportedProviders,proto3" json:"supported_providers,omitempty"`
+	Version            string                 `protobuf:"bytes,4,opt,name=version,proto3" json:"version,omitempty"`
 	unknownFields      protoimpl.UnknownFields
 	sizeCache          protoimpl.SizeCache
 }
@@ -117,6 +118,13 @@ func (x *MetadataResponse) GetSupportedProviders() []string {
 	return nil
 }
 
+func (x *MetadataResponse) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
 type LoginRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Types that are valid to be assigned to Payload:
@@ -383,6 +391,7 @@ type InteractionRequest struct {
 	//
 	//	*InteractionRequest_OpenUrl
 	//	*InteractionRequest_DisplayMessage
+	//	*InteractionRequest_Prompt
 	Action        isInteractionRequest_Action `protobuf_oneof:"action"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
@@ -443,6 +452,15 @@ func (x *InteractionRequest) GetDisplayMessage() *DisplayMessageRequest {
 	return nil
 }
 
+func (x *InteractionRequest) GetPrompt() *PromptRequest {
+	if x != nil {
+		if x, ok := x.Action.(*InteractionRequest_Prompt); ok {
+			return x.Prompt
+		}
+	}
+	return nil
+}
+
 type isInteractionRequest_Action interface {
 	isInteractionRequest_Action()
 }
@@ -455,10 +473,16 @@ type InteractionRequest_DisplayMessage struct {
 	DisplayMessage *DisplayMessageRequest `protobuf:"bytes,2,opt,name=display_message,json=displayMessage,proto3,oneof"`
 }
 
+type InteractionRequest_Prompt struct {
+	Prompt *PromptRequest `protobuf:"bytes,3,opt,name=prompt,proto3,oneof"`
+}
+
 func (*InteractionRequest_OpenUrl) isInteractionRequest_Action() {}
 
 func (*InteractionRequest_DisplayMessage) isInteractionRequest_Action() {}
 
+func (*InteractionRequest_Prompt) isInteractionRequest_Action() {}
+
 type OpenUrlRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
@@ -547,16 +571,61 @@ func (x *DisplayMessageRequest) GetMessage() string {
 	return ""
 }
 
+type PromptRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PromptRequest) Reset() {
+	*x = PromptRequest{}
+	mi := &file_identity_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PromptRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PromptRequest) ProtoMessage() {}
+
+func (x *PromptRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_identity_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PromptRequest.ProtoReflect.Descriptor instead.
+func (*PromptRequest) Descriptor() ([]byte, []int) {
+	return file_identity_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *PromptRequest) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
 type InteractionResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Error         string                 `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
+	Value         string                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *InteractionResponse) Reset() {
 	*x = InteractionResponse{}
-	mi := &file_identity_proto_msgTypes[9]
+	mi := &file_identity_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -568,7 +637,7 @@ func (x *InteractionResponse) String() string {
 func (*InteractionResponse) ProtoMessage() {}
 
 func (x *InteractionResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_identity_proto_msgTypes[9]
+	mi := &file_identity_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -581,7 +650,7 @@ func (x *InteractionResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use InteractionResponse.ProtoReflect.Descriptor instead.
 func (*InteractionResponse) Descriptor() ([]byte, []int) {
-	return file_identity_proto_rawDescGZIP(), []int{9}
+	return file_identity_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *InteractionResponse) GetError() string {
@@ -591,6 +660,13 @@ func (x *InteractionResponse) GetError() string {
 	return ""
 }
 
+func (x *InteractionResponse) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
 type RefreshRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	RefreshToken  string                 `protobuf:"bytes,1,opt,name=refresh_token,json=refreshToken,proto3" json:"refresh_token,omitempty"`
@@ -601,7 +677,7 @@ type RefreshRequest struct {
 
 func (x *RefreshRequest) Reset() {
 	*x = RefreshRequest{}
-	mi := &file_identity_proto_msgTypes[10]
+	mi := &file_identity_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -613,7 +689,7 @@ func (x *RefreshRequest) String() string {
 func (*RefreshRequest) ProtoMessage() {}
 
 func (x *RefreshRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_identity_proto_msgTypes[10]
+	mi := &file_identity_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -626,7 +702,7 @@ func (x *RefreshRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RefreshRequest.ProtoReflect.Descriptor instead.
 func (*RefreshRequest) Descriptor() ([]byte, []int) {
-	return file_identity_proto_rawDescGZIP(), []int{10}
+	return file_identity_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *RefreshRequest) GetRefreshToken() string {
@@ -652,7 +728,7 @@ type RefreshResponse struct {
 
 func (x *RefreshResponse) Reset() {
 	*x = RefreshResponse{}
-	mi := &file_identity_proto_msgTypes[11]
+	mi := &file_identity_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -664,7 +740,7 @@ func (x *RefreshResponse) String() string {
 func (*RefreshResponse) ProtoMessage() {}
 
 func (x *RefreshResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_identity_proto_msgTypes[11]
+	mi := &file_identity_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -677,7 +753,7 @@ func (x *RefreshResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RefreshResponse.ProtoReflect.Descriptor instead.
 func (*RefreshResponse) Descriptor() ([]byte, []int) {
-	return file_identity_proto_rawDescGZIP(), []int{11}
+	return file_identity_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *RefreshResponse) GetToken() *AccessToken {
@@ -696,7 +772,7 @@ type ListIdentitiesRequest struct {
 
 func (x *ListIdentitiesRequest) Reset() {
 	*x = ListIdentitiesRequest{}
-	mi := &file_identity_proto_msgTypes[12]
+	mi := &file_identity_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -708,7 +784,7 @@ func (x *ListIdentitiesRequest) String() string {
 func (*ListIdentitiesRequest) ProtoMessage() {}
 
 func (x *ListIdentitiesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_identity_proto_msgTypes[12]
+	mi := &file_identity_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -721,7 +797,7 @@ func (x *ListIdentitiesRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListIdentitiesRequest.ProtoReflect.Descriptor instead.
 func (*ListIdentitiesRequest) Descriptor() ([]byte, []int) {
-	return file_identity_proto_rawDescGZIP(), []int{12}
+	return file_identity_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *ListIdentitiesRequest) GetOptions() map[string]string {
@@ -740,7 +816,7 @@ type ListIdentitiesResponse struct {
 
 func (x *ListIdentitiesResponse) Reset() {
 	*x = ListIdentitiesResponse{}
-	mi := &file_identity_proto_msgTypes[13]
+	mi := &file_identity_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -752,7 +828,7 @@ func (x *ListIdentitiesResponse) String() string {
 func (*ListIdentitiesResponse) ProtoMessage() {}
 
 func (x *ListIdentitiesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_identity_proto_msgTypes[13]
+	mi := &file_identity_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -765,7 +841,7 @@ func (x *ListIdentitiesResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListIdentitiesResponse.ProtoReflect.Descriptor instead.
 func (*ListIdentitiesResponse) Descriptor() ([]byte, []int) {
-	return file_identity_proto_rawDescGZIP(), []int{13}
+	return file_identity_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *ListIdentitiesResponse) GetIdentities() []*Identity {
@@ -785,7 +861,7 @@ type LogoutRequest struct {
 
 func (x *LogoutRequest) Reset() {
 	*x = LogoutRequest{}
-	mi := &file_identity_proto_msgTypes[14]
+	mi := &file_identity_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -797,7 +873,7 @@ func (x *LogoutRequest) String() string {
 func (*LogoutRequest) ProtoMessage() {}
 
 func (x *LogoutRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_identity_proto_msgTypes[14]
+	mi := &file_identity_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -810,7 +886,7 @@ func (x *LogoutRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use LogoutRequest.ProtoReflect.Descriptor instead.
 func (*LogoutRequest) Descriptor() ([]byte, []int) {
-	return file_identity_proto_rawDescGZIP(), []int{14}
+	return file_identity_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *LogoutRequest) GetIdentityId() string {
@@ -836,7 +912,7 @@ type LogoutResponse struct {
 
 func (x *LogoutResponse) Reset() {
 	*x = LogoutResponse{}
-	mi := &file_identity_proto_msgTypes[15]
+	mi := &file_identity_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -848,7 +924,7 @@ func (x *LogoutResponse) String() string {
 func (*LogoutResponse) ProtoMessage() {}
 
 func (x *LogoutResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_identity_proto_msgTypes[15]
+	mi := &file_identity_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -861,7 +937,7 @@ func (x *LogoutResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use LogoutResponse.ProtoReflect.Descriptor instead.
 func (*LogoutResponse) Descriptor() ([]byte, []int) {
-	return file_identity_proto_rawDescGZIP(), []int{15}
+	return file_identity_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *LogoutResponse) GetSuccess() bool {
@@ -883,7 +959,7 @@ type AccessToken struct {
 
 func (x *AccessToken) Reset() {
 	*x = AccessToken{}
-	mi := &file_identity_proto_msgTypes[16]
+	mi := &file_identity_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -895,7 +971,7 @@ func (x *AccessToken) String() string {
 func (*AccessToken) ProtoMessage() {}
 
 func (x *AccessToken) ProtoReflect() protoreflect.Message {
-	mi := &file_identity_proto_msgTypes[16]
+	mi := &file_identity_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -908,7 +984,7 @@ func (x *AccessToken) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AccessToken.ProtoReflect.Descriptor instead.
 func (*AccessToken) Descriptor() ([]byte, []int) {
-	return file_identity_proto_rawDescGZIP(), []int{16}
+	return file_identity_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *AccessToken) GetAccessToken() string {
@@ -952,7 +1028,7 @@ type Identity struct {
 
 func (x *Identity) Reset() {
 	*x = Identity{}
-	mi := &file_identity_proto_msgTypes[17]
+	mi := &file_identity_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -964,7 +1040,7 @@ func (x *Identity) String() string {
 func (*Identity) ProtoMessage() {}
 
 func (x *Identity) ProtoReflect() protoreflect.Message {
-	mi := &file_identity_proto_msgTypes[17]
+	mi := &file_identity_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -977,7 +1053,7 @@ func (x *Identity) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Identity.ProtoReflect.Descriptor instead.
 func (*Identity) Descriptor() ([]byte, []int) {
-	return file_identity_proto_rawDescGZIP(), []int{17}
+	return file_identity_proto_rawDescGZIP(), []int{18}
 }
 
 func (x *Identity) GetId() string {
@@ -1020,11 +1096,12 @@ var File_identity_proto protoreflect.FileDescriptor
 const file_identity_proto_rawDesc = "" +
 	"\n" +
 	"\x0eidentity.proto\x12\bidentity\"\x11\n" +
-	"\x0fMetadataRequest\"k\n" +
+	"\x0fMetadataRequest\"\x85\x01\n" +
 	"\x10MetadataResponse\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
 	"\x04type\x18\x02 \x01(\tR\x04type\x12/\n" +
-	"\x13supported_providers\x18\x03 \x03(\tR\x12supportedProviders\"\x99\x01\n" +
+	"\x13supported_providers\x18\x03 \x03(\tR\x12supportedProviders\x12\x18\n" +
+	"\aversion\x18\x04 \x01(\tR\aversion\"\x99\x01\n" +
 	"\fLoginRequest\x12*\n" +
 	"\x06config\x18\x01 \x01(\v2\x10.identity.ConfigH\x00R\x06config\x12R\n" +
 	"\x14interaction_response\x18\x02 \x01(\v2\x1d.identity.InteractionResponseH\x00R\x13interactionResponseB\t\n" +
@@ -1040,17 +1117,21 @@ const file_identity_proto_rawDesc = "" +
 	"\apayload\"j\n" +
 	"\vLoginResult\x12+\n" +
 	"\x05token\x18\x01 \x01(\v2\x15.identity.AccessTokenR\x05token\x12.\n" +
-	"\bidentity\x18\x02 \x01(\v2\x12.identity.IdentityR\bidentity\"\xa1\x01\n" +
+	"\bidentity\x18\x02 \x01(\v2\x12.identity.IdentityR\bidentity\"\xd4\x01\n" +
 	"\x12InteractionRequest\x125\n" +
 	"\bopen_url\x18\x01 \x01(\v2\x18.identity.OpenUrlRequestH\x00R\aopenUrl\x12J\n" +
-	"\x0fdisplay_message\x18\x02 \x01(\v2\x1f.identity.DisplayMessageRequestH\x00R\x0edisplayMessageB\b\n" +
+	"\x0fdisplay_message\x18\x02 \x01(\v2\x1f.identity.DisplayMessageRequestH\x00R\x0edisplayMessage\x121\n" +
+	"\x06prompt\x18\x03 \x01(\v2\x17.identity.PromptRequestH\x00R\x06promptB\b\n" +
 	"\x06action\"\"\n" +
 	"\x0eOpenUrlRequest\x12\x10\n" +
 	"\x03url\x18\x01 \x01(\tR\x03url\"1\n" +
 	"\x15DisplayMessageRequest\x12\x18\n" +
-	"\amessage\x18\x01 \x01(\tR\amessage\"+\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\")\n" +
+	"\rPromptRequest\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\"A\n" +
 	"\x13InteractionResponse\x12\x14\n" +
-	"\x05error\x18\x01 \x01(\tR\x05error\"\xb2\x01\n" +
+	"\x05error\x18\x01 \x01(\tR\x05error\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value\"\xb2\x01\n" +
 	"\x0eRefreshRequest\x12#\n" +
 	"\rrefresh_token\x18\x01 \x01(\tR\frefreshToken\x12?\n" +
 	"\aoptions\x18\x02 \x03(\v2%.identity.RefreshRequest.OptionsEntryR\aoptions\x1a:\n" +
@@ -1111,7 +1192,7 @@ func file_identity_proto_rawDescGZIP() []byte {
 	return file_identity_proto_rawDescData
 }
 
-var file_identity_proto_msgTypes = make([]protoimpl.MessageInfo, 23)
+var file_identity_proto_msgTypes = make([]protoimpl.MessageInfo, 24)
 var file_identity_proto_goTypes = []any{
 	(*MetadataRequest)(nil),        // 0: identity.MetadataRequest
 	(*MetadataResponse)(nil),       // 1: identity.MetadataResponse
@@ -1122,52 +1203,54 @@ var file_identity_proto_goTypes = []any{
 	(*InteractionRequest)(nil),     // 6: identity.InteractionRequest
 	(*OpenUrlRequest)(nil),         // 7: identity.OpenUrlRequest
 	(*DisplayMessageRequest)(nil),  // 8: identity.DisplayMessageRequest
-	(*InteractionResponse)(nil),    // 9: identity.InteractionResponse
-	(*RefreshRequest)(nil),         // 10: identity.RefreshRequest
-	(*RefreshResponse)(nil),        // 11: identity.RefreshResponse
-	(*ListIdentitiesRequest)(nil),  // 12: identity.ListIdentitiesRequest
-	(*ListIdentitiesResponse)(nil), // 13: identity.ListIdentitiesResponse
-	(*LogoutRequest)(nil),          // 14: identity.LogoutRequest
-	(*LogoutResponse)(nil),         // 15: identity.LogoutResponse
-	(*AccessToken)(nil),            // 16: identity.AccessToken
-	(*Identity)(nil),               // 17: identity.Identity
-	nil,                            // 18: identity.Config.OptionsEntry
-	nil,                            // 19: identity.RefreshRequest.OptionsEntry
-	nil,                            // 20: identity.ListIdentitiesRequest.OptionsEntry
-	nil,                            // 21: identity.LogoutRequest.OptionsEntry
-	nil,                            // 22: identity.Identity.MetadataEntry
+	(*PromptRequest)(nil),          // 9: identity.PromptRequest
+	(*InteractionResponse)(nil),    // 10: identity.InteractionResponse
+	(*RefreshRequest)(nil),         // 11: identity.RefreshRequest
+	(*RefreshResponse)(nil),        // 12: identity.RefreshResponse
+	(*ListIdentitiesRequest)(nil),  // 13: identity.ListIdentitiesRequest
+	(*ListIdentitiesResponse)(nil), // 14: identity.ListIdentitiesResponse
+	(*LogoutRequest)(nil),          // 15: identity.LogoutRequest
+	(*LogoutResponse)(nil),         // 16: identity.LogoutResponse
+	(*AccessToken)(nil),            // 17: identity.AccessToken
+	(*Identity)(nil),               // 18: identity.Identity
+	nil,                            // 19: identity.Config.OptionsEntry
+	nil,                            // 20: identity.RefreshRequest.OptionsEntry
+	nil,                            // 21: identity.ListIdentitiesRequest.OptionsEntry
+	nil,                            // 22: identity.LogoutRequest.OptionsEntry
+	nil,                            // 23: identity.Identity.MetadataEntry
 }
 var file_identity_proto_depIdxs = []int32{
 	3,  // 0: identity.LoginRequest.config:type_name -> identity.Config
-	9,  // 1: identity.LoginRequest.interaction_response:type_name -> identity.InteractionResponse
-	18, // 2: identity.Config.options:type_name -> identity.Config.OptionsEntry
+	10, // 1: identity.LoginRequest.interaction_response:type_name -> identity.InteractionResponse
+	19, // 2: identity.Config.options:type_name -> identity.Config.OptionsEntry
 	6,  // 3: identity.LoginResponse.interaction_request:type_name -> identity.InteractionRequest
 	5,  // 4: identity.LoginResponse.result:type_name -> identity.LoginResult
-	16, // 5: identity.LoginResult.token:type_name -> identity.AccessToken
-	17, // 6: identity.LoginResult.identity:type_name -> identity.Identity
+	17, // 5: identity.LoginResult.token:type_name -> identity.AccessToken
+	18, // 6: identity.LoginResult.identity:type_name -> identity.Identity
 	7,  // 7: identity.InteractionRequest.open_url:type_name -> identity.OpenUrlRequest
 	8,  // 8: identity.InteractionRequest.display_message:type_name -> identity.DisplayMessageRequest
-	19, // 9: identity.RefreshRequest.options:type_name -> identity.RefreshRequest.OptionsEntry
-	16, // 10: identity.RefreshResponse.token:type_name -> identity.AccessToken
-	20, // 11: identity.ListIdentitiesRequest.options:type_name -> identity.ListIdentitiesRequest.OptionsEntry
-	17, // 12: identity.ListIdentitiesResponse.identities:type_name -> identity.Identity
-	21, // 13: identity.LogoutRequest.options:type_name -> identity.LogoutRequest.OptionsEntry
-	22, // 14: identity.Identity.metadata:type_name -> identity.Identity.MetadataEntry
-	2,  // 15: identity.IdentityPlugin.Login:input_type -> identity.LoginRequest
-	10, // 16: identity.IdentityPlugin.Refresh:input_type -> identity.RefreshRequest
-	12, // 17: identity.IdentityPlugin.ListIdentities:input_type -> identity.ListIdentitiesRequest
-	14, // 18: identity.IdentityPlugin.Logout:input_type -> identity.LogoutRequest
-	0,  // 19: identity.IdentityPlugin.GetMetadata:input_type -> identity.MetadataRequest
-	4,  // 20: identity.IdentityPlugin.Login:output_type -> identity.LoginResponse
-	11, // 21: identity.IdentityPlugin.Refresh:output_type -> identity.RefreshResponse
-	13, // 22: identity.IdentityPlugin.ListIdentities:output_type -> identity.ListIdentitiesResponse
-	15, // 23: identity.IdentityPlugin.Logout:output_type -> identity.LogoutResponse
-	1,  // 24: identity.IdentityPlugin.GetMetadata:output_type -> identity.MetadataResponse
-	20, // [20:25] is the sub-list for method output_type
-	15, // [15:20] is the sub-list for method input_type
-	15, // [15:15] is the sub-list for extension type_name
-	15, // [15:15] is the sub-list for extension extendee
-	0,  // [0:15] is the sub-list for field type_name
+	9,  // 9: identity.InteractionRequest.prompt:type_name -> identity.PromptRequest
+	20, // 10: identity.RefreshRequest.options:type_name -> identity.RefreshRequest.OptionsEntry
+	17, // 11: identity.RefreshResponse.token:type_name -> identity.AccessToken
+	21, // 12: identity.ListIdentitiesRequest.options:type_name -> identity.ListIdentitiesRequest.OptionsEntry
+	18, // 13: identity.ListIdentitiesResponse.identities:type_name -> identity.Identity
+	22, // 14: identity.LogoutRequest.options:type_name -> identity.LogoutRequest.OptionsEntry
+	23, // 15: identity.Identity.metadata:type_name -> identity.Identity.MetadataEntry
+	2,  // 16: identity.IdentityPlugin.Login:input_type -> identity.LoginRequest
+	11, // 17: identity.IdentityPlugin.Refresh:input_type -> identity.RefreshRequest
+	13, // 18: identity.IdentityPlugin.ListIdentities:input_type -> identity.ListIdentitiesRequest
+	15, // 19: identity.IdentityPlugin.Logout:input_type -> identity.LogoutRequest
+	0,  // 20: identity.IdentityPlugin.GetMetadata:input_type -> identity.MetadataRequest
+	4,  // 21: identity.IdentityPlugin.Login:output_type -> identity.LoginResponse
+	12, // 22: identity.IdentityPlugin.Refresh:output_type -> identity.RefreshResponse
+	14, // 23: identity.IdentityPlugin.ListIdentities:output_type -> identity.ListIdentitiesResponse
+	16, // 24: identity.IdentityPlugin.Logout:output_type -> identity.LogoutResponse
+	1,  // 25: identity.IdentityPlugin.GetMetadata:output_type -> identity.MetadataResponse
+	21, // [21:26] is the sub-list for method output_type
+	16, // [16:21] is the sub-list for method input_type
+	16, // [16:16] is the sub-list for extension type_name
+	16, // [16:16] is the sub-list for extension extendee
+	0,  // [0:16] is the sub-list for field type_name
 }
 
 func init() { file_identity_proto_init() }
@@ -1186,6 +1269,7 @@ func file_identity_proto_init() {
 	file_identity_proto_msgTypes[6].OneofWrappers = []any{
 		(*InteractionRequest_OpenUrl)(nil),
 		(*InteractionRequest_DisplayMessage)(nil),
+		(*InteractionRequest_Prompt)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -1193,7 +1277,7 @@ func file_identity_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_identity_proto_rawDesc), len(file_identity_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   23,
+			NumMessages:   24,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -108,6 +108,8 @@ type MetadataResponse struct {
 	Name               string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
 	Type               string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
 	SupportedProviders []string               `protobuf:"bytes,3,rep,name=supported_providers,json=supportedProviders,proto3" json:"supported_providers,omitempty"`
+	Capabilities       *Capabilities          `protobuf:"bytes,4,opt,name=capabilities,proto3" json:"capabilities,omitempty"`
+	Version            string                 `protobuf:"bytes,5,opt,name=version,proto3" json:"version,omitempty"`
 	unknownFields      protoimpl.UnknownFields
 	sizeCache          protoimpl.SizeCache
 }
@@ -163,6 +165,104 @@ func (x *MetadataResponse) GetSupportedProviders() []string {
 	return nil
 }
 
+func (x *MetadataResponse) GetCapabilities() *Capabilities {
+	if x != nil {
+		return x.Capabilities
+	}
+	return nil
+}
+
+func (x *MetadataResponse) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+type Capabilities struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Move            bool                   `protobuf:"varint,1,opt,name=move,proto3" json:"move,omitempty"`
+	Copy            bool                   `protobuf:"varint,2,opt,name=copy,proto3" json:"copy,omitempty"`
+	RangedRead      bool                   `protobuf:"varint,3,opt,name=ranged_read,json=rangedRead,proto3" json:"ranged_read,omitempty"`
+	EtagConcurrency bool                   `protobuf:"varint,4,opt,name=etag_concurrency,json=etagConcurrency,proto3" json:"etag_concurrency,omitempty"`
+	Delta           bool                   `protobuf:"varint,5,opt,name=delta,proto3" json:"delta,omitempty"`
+	Hashes          []string               `protobuf:"bytes,6,rep,name=hashes,proto3" json:"hashes,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Capabilities) Reset() {
+	*x = Capabilities{}
+	mi := &file_storage_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Capabilities) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Capabilities) ProtoMessage() {}
+
+func (x *Capabilities) ProtoReflect() protoreflect.Message {
+	mi := &file_storage_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Capabilities.ProtoReflect.Descriptor instead.
+func (*Capabilities) Descriptor() ([]byte, []int) {
+	return file_storage_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Capabilities) GetMove() bool {
+	if x != nil {
+		return x.Move
+	}
+	return false
+}
+
+func (x *Capabilities) GetCopy() bool {
+	if x != nil {
+		return x.Copy
+	}
+	return false
+}
+
+func (x *Capabilities) GetRangedRead() bool {
+	if x != nil {
+		return x.RangedRead
+	}
+	return false
+}
+
+func (x *Capabilities) GetEtagConcurrency() bool {
+	if x != nil {
+		return x.EtagConcurrency
+	}
+	return false
+}
+
+func (x *Capabilities) GetDelta() bool {
+	if x != nil {
+		return x.Delta
+	}
+	return false
+}
+
+func (x *Capabilities) GetHashes() []string {
+	if x != nil {
+		return x.Hashes
+	}
+	return nil
+}
+
 type ListDrivesRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Options       map[string]string      `protobuf:"bytes,1,rep,name=options,proto3" json:"options,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
@@ -172,7 +272,7 @@ type ListDrivesRequest struct {
 
 func (x *ListDrivesRequest) Reset() {
 	*x = ListDrivesRequest{}
-	mi := &file_storage_proto_msgTypes[2]
+	mi := &file_storage_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -184,7 +284,7 @@ func (x *ListDrivesRequest) String() string {
 func (*ListDrivesRequest) ProtoMessage() {}
 
 func (x *ListDrivesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_storage_proto_msgTypes[2]
+	mi := &file_storage_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -197,7 +297,7 @@ func (x *ListDrivesRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListDrivesRequest.ProtoReflect.Descriptor instead.
 func (*ListDrivesRequest) Descriptor() ([]byte, []int) {
-	return file_storage_proto_rawDescGZIP(), []int{2}
+	return file_storage_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *ListDrivesRequest) GetOptions() map[string]string {
@@ -216,7 +316,7 @@ type ListDrivesResponse struct {
 
 func (x *ListDrivesResponse) Reset() {
 	*x = ListDrivesResponse{}
-	mi := &file_storage_proto_msgTypes[3]
+	mi := &file_storage_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -228,7 +328,7 @@ func (x *ListDrivesResponse) String() string {
 func (*ListDrivesResponse) ProtoMessage() {}
 
 func (x *ListDrivesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_storage_proto_msgTypes[3]
+	mi := &file_storage_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -241,7 +341,7 @@ func (x *ListDrivesResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListDrivesResponse.ProtoReflect.Descriptor instead.
 func (*ListDrivesResponse) Descriptor() ([]byte, []int) {
-	return file_storage_proto_rawDescGZIP(), []int{3}
+	return file_storage_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *ListDrivesResponse) GetDrives() []*Drive {
@@ -261,7 +361,7 @@ type GetDriveRequest struct {
 
 func (x *GetDriveRequest) Reset() {
 	*x = GetDriveRequest{}
-	mi := &file_storage_proto_msgTypes[4]
+	mi := &file_storage_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -273,7 +373,7 @@ func (x *GetDriveRequest) String() string {
 func (*GetDriveRequest) ProtoMessage() {}
 
 func (x *GetDriveRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_storage_proto_msgTypes[4]
+	mi := &file_storage_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -286,7 +386,7 @@ func (x *GetDriveRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetDriveRequest.ProtoReflect.Descriptor instead.
 func (*GetDriveRequest) Descriptor() ([]byte, []int) {
-	return file_storage_proto_rawDescGZIP(), []int{4}
+	return file_storage_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *GetDriveRequest) GetDriveId() string {
@@ -312,7 +412,7 @@ type GetDriveResponse struct {
 
 func (x *GetDriveResponse) Reset() {
 	*x = GetDriveResponse{}
-	mi := &file_storage_proto_msgTypes[5]
+	mi := &file_storage_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -324,7 +424,7 @@ func (x *GetDriveResponse) String() string {
 func (*GetDriveResponse) ProtoMessage() {}
 
 func (x *GetDriveResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_storage_proto_msgTypes[5]
+	mi := &file_storage_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -337,7 +437,7 @@ func (x *GetDriveResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetDriveResponse.ProtoReflect.Descriptor instead.
 func (*GetDriveResponse) Descriptor() ([]byte, []int) {
-	return file_storage_proto_rawDescGZIP(), []int{5}
+	return file_storage_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *GetDriveResponse) GetDrive() *Drive {
@@ -358,7 +458,7 @@ type Drive struct {
 
 func (x *Drive) Reset() {
 	*x = Drive{}
-	mi := &file_storage_proto_msgTypes[6]
+	mi := &file_storage_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -370,7 +470,7 @@ func (x *Drive) String() string {
 func (*Drive) ProtoMessage() {}
 
 func (x *Drive) ProtoReflect() protoreflect.Message {
-	mi := &file_storage_proto_msgTypes[6]
+	mi := &file_storage_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -383,7 +483,7 @@ func (x *Drive) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Drive.ProtoReflect.Descriptor instead.
 func (*Drive) Descriptor() ([]byte, []int) {
-	return file_storage_proto_rawDescGZIP(), []int{6}
+	return file_storage_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *Drive) GetId() string {
@@ -417,7 +517,7 @@ type StatRequest struct {
 
 func (x *StatRequest) Reset() {
 	*x = StatRequest{}
-	mi := &file_storage_proto_msgTypes[7]
+	mi := &file_storage_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -429,7 +529,7 @@ func (x *StatRequest) String() string {
 func (*StatRequest) ProtoMessage() {}
 
 func (x *StatRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_storage_proto_msgTypes[7]
+	mi := &file_storage_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -442,7 +542,7 @@ func (x *StatRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StatRequest.ProtoReflect.Descriptor instead.
 func (*StatRequest) Descriptor() ([]byte, []int) {
-	return file_storage_proto_rawDescGZIP(), []int{7}
+	return file_storage_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *StatRequest) GetPath() string {
@@ -468,7 +568,7 @@ type StatResponse struct {
 
 func (x *StatResponse) Reset() {
 	*x = StatResponse{}
-	mi := &file_storage_proto_msgTypes[8]
+	mi := &file_storage_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -480,7 +580,7 @@ func (x *StatResponse) String() string {
 func (*StatResponse) ProtoMessage() {}
 
 func (x *StatResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_storage_proto_msgTypes[8]
+	mi := &file_storage_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -493,7 +593,7 @@ func (x *StatResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StatResponse.ProtoReflect.Descriptor instead.
 func (*StatResponse) Descriptor() ([]byte, []int) {
-	return file_storage_proto_rawDescGZIP(), []int{8}
+	return file_storage_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *StatResponse) GetNode() *Node {
@@ -513,7 +613,7 @@ type MkdirRequest struct {
 
 func (x *MkdirRequest) Reset() {
 	*x = MkdirRequest{}
-	mi := &file_storage_proto_msgTypes[9]
+	mi := &file_storage_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -525,7 +625,7 @@ func (x *MkdirRequest) String() string {
 func (*MkdirRequest) ProtoMessage() {}
 
 func (x *MkdirRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_storage_proto_msgTypes[9]
+	mi := &file_storage_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -538,7 +638,7 @@ func (x *MkdirRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MkdirRequest.ProtoReflect.Descriptor instead.
 func (*MkdirRequest) Descriptor() ([]byte, []int) {
-	return file_storage_proto_rawDescGZIP(), []int{9}
+	return file_storage_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *MkdirRequest) GetPath() string {
@@ -564,7 +664,7 @@ type MkdirResponse struct {
 
 func (x *MkdirResponse) Reset() {
 	*x = MkdirResponse{}
-	mi := &file_storage_proto_msgTypes[10]
+	mi := &file_storage_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -576,7 +676,7 @@ func (x *MkdirResponse) String() string {
 func (*MkdirResponse) ProtoMessage() {}
 
 func (x *MkdirResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_storage_proto_msgTypes[10]
+	mi := &file_storage_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -589,7 +689,7 @@ func (x *MkdirResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MkdirResponse.ProtoReflect.Descriptor instead.
 func (*MkdirResponse) Descriptor() ([]byte, []int) {
-	return file_storage_proto_rawDescGZIP(), []int{10}
+	return file_storage_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *MkdirResponse) GetNode() *Node {
@@ -609,7 +709,7 @@ type ListRequest struct {
 
 func (x *ListRequest) Reset() {
 	*x = ListRequest{}
-	mi := &file_storage_proto_msgTypes[11]
+	mi := &file_storage_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -621,7 +721,7 @@ func (x *ListRequest) String() string {
 func (*ListRequest) ProtoMessage() {}
 
 func (x *ListRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_storage_proto_msgTypes[11]
+	mi := &file_storage_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -634,7 +734,7 @@ func (x *ListRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListRequest.ProtoReflect.Descriptor instead.
 func (*ListRequest) Descriptor() ([]byte, []int) {
-	return file_storage_proto_rawDescGZIP(), []int{11}
+	return file_storage_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *ListRequest) GetPath() string {
@@ -660,7 +760,7 @@ type ListResponse struct {
 
 func (x *ListResponse) Reset() {
 	*x = ListResponse{}
-	mi := &file_storage_proto_msgTypes[12]
+	mi := &file_storage_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -672,7 +772,7 @@ func (x *ListResponse) String() string {
 func (*ListResponse) ProtoMessage() {}
 
 func (x *ListResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_storage_proto_msgTypes[12]
+	mi := &file_storage_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -685,7 +785,7 @@ func (x *ListResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListResponse.ProtoReflect.Descriptor instead.
 func (*ListResponse) Descriptor() ([]byte, []int) {
-	return file_storage_proto_rawDescGZIP(), []int{12}
+	return file_storage_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *ListResponse) GetNodes() []*Node {
@@ -699,13 +799,15 @@ type ReadRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
 	Options       map[string]string      `protobuf:"bytes,2,rep,name=options,proto3" json:"options,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Offset        int64                  `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
+	Length        int64                  `protobuf:"varint,4,opt,name=length,proto3" json:"length,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ReadRequest) Reset() {
 	*x = ReadRequest{}
-	mi := &file_storage_proto_msgTypes[13]
+	mi := &file_storage_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -717,7 +819,7 @@ func (x *ReadRequest) String() string {
 func (*ReadRequest) ProtoMessage() {}
 
 func (x *ReadRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_storage_proto_msgTypes[13]
+	mi := &file_storage_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -730,7 +832,7 @@ func (x *ReadRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ReadRequest.ProtoReflect.Descriptor instead.
 func (*ReadRequest) Descriptor() ([]byte, []int) {
-	return file_storage_proto_rawDescGZIP(), []int{13}
+	return file_storage_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *ReadRequest) GetPath() string {
@@ -747,6 +849,20 @@ func (x *ReadRequest) GetOptions() map[string]string {
 	return nil
 }
 
+func (x *ReadRequest) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *ReadRequest) GetLength() int64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
 type ReadResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Chunk         []byte                 `protobuf:"bytes,1,opt,name=chunk,proto3" json:"chunk,omitempty"`
@@ -756,7 +872,7 @@ type ReadResponse struct {
 
 func (x *ReadResponse) Reset() {
 	*x = ReadResponse{}
-	mi := &file_storage_proto_msgTypes[14]
+	mi := &file_storage_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -768,7 +884,7 @@ func (x *ReadResponse) String() string {
 func (*ReadResponse) ProtoMessage() {}
 
 func (x *ReadResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_storage_proto_msgTypes[14]
+	mi := &file_storage_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -781,7 +897,7 @@ func (x *ReadResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ReadResponse.ProtoReflect.Descriptor instead.
 func (*ReadResponse) Descriptor() ([]byte, []int) {
-	return file_storage_proto_rawDescGZIP(), []int{14}
+	return file_storage_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *ReadResponse) GetChunk() []byte {
@@ -802,7 +918,7 @@ type WriteRequest struct {
 
 func (x *WriteRequest) Reset() {
 	*x = WriteRequest{}
-	mi := &file_storage_proto_msgTypes[15]
+	mi := &file_storage_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -814,7 +930,7 @@ func (x *WriteRequest) String() string {
 func (*WriteRequest) ProtoMessage() {}
 
 func (x *WriteRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_storage_proto_msgTypes[15]
+	mi := &file_storage_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -827,7 +943,7 @@ func (x *WriteRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use WriteRequest.ProtoReflect.Descriptor instead.
 func (*WriteRequest) Descriptor() ([]byte, []int) {
-	return file_storage_proto_rawDescGZIP(), []int{15}
+	return file_storage_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *WriteRequest) GetPath() string {
@@ -860,7 +976,7 @@ type WriteResponse struct {
 
 func (x *WriteResponse) Reset() {
 	*x = WriteResponse{}
-	mi := &file_storage_proto_msgTypes[16]
+	mi := &file_storage_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -872,7 +988,7 @@ func (x *WriteResponse) String() string {
 func (*WriteResponse) ProtoMessage() {}
 
 func (x *WriteResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_storage_proto_msgTypes[16]
+	mi := &file_storage_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -885,7 +1001,7 @@ func (x *WriteResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use WriteResponse.ProtoReflect.Descriptor instead.
 func (*WriteResponse) Descriptor() ([]byte, []int) {
-	return file_storage_proto_rawDescGZIP(), []int{16}
+	return file_storage_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *WriteResponse) GetNode() *Node {
@@ -905,7 +1021,7 @@ type DeleteRequest struct {
 
 func (x *DeleteRequest) Reset() {
 	*x = DeleteRequest{}
-	mi := &file_storage_proto_msgTypes[17]
+	mi := &file_storage_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -917,7 +1033,7 @@ func (x *DeleteRequest) String() string {
 func (*DeleteRequest) ProtoMessage() {}
 
 func (x *DeleteRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_storage_proto_msgTypes[17]
+	mi := &file_storage_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -930,7 +1046,7 @@ func (x *DeleteRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteRequest.ProtoReflect.Descriptor instead.
 func (*DeleteRequest) Descriptor() ([]byte, []int) {
-	return file_storage_proto_rawDescGZIP(), []int{17}
+	return file_storage_proto_rawDescGZIP(), []int{18}
 }
 
 func (x *DeleteRequest) GetPath() string {
@@ -956,7 +1072,7 @@ type DeleteResponse struct {
 
 func (x *DeleteResponse) Reset() {
 	*x = DeleteResponse{}
-	mi := &file_storage_proto_msgTypes[18]
+	mi := &file_storage_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -968,7 +1084,7 @@ func (x *DeleteResponse) String() string {
 func (*DeleteResponse) ProtoMessage() {}
 
 func (x *DeleteResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_storage_proto_msgTypes[18]
+	mi := &file_storage_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -981,7 +1097,7 @@ func (x *DeleteResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteResponse.ProtoReflect.Descriptor instead.
 func (*DeleteResponse) Descriptor() ([]byte, []int) {
-	return file_storage_proto_rawDescGZIP(), []int{18}
+	return file_storage_proto_rawDescGZIP(), []int{19}
 }
 
 func (x *DeleteResponse) GetSuccess() bool {
@@ -1002,7 +1118,7 @@ type MoveRequest struct {
 
 func (x *MoveRequest) Reset() {
 	*x = MoveRequest{}
-	mi := &file_storage_proto_msgTypes[19]
+	mi := &file_storage_proto_msgTypes[20]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1014,7 +1130,7 @@ func (x *MoveRequest) String() string {
 func (*MoveRequest) ProtoMessage() {}
 
 func (x *MoveRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_storage_proto_msgTypes[19]
+	mi := &file_storage_proto_msgTypes[20]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1027,7 +1143,7 @@ func (x *MoveRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MoveRequest.ProtoReflect.Descriptor instead.
 func (*MoveRequest) Descriptor() ([]byte, []int) {
-	return file_storage_proto_rawDescGZIP(), []int{19}
+	return file_storage_proto_rawDescGZIP(), []int{20}
 }
 
 func (x *MoveRequest) GetSource() string {
@@ -1060,7 +1176,7 @@ type MoveResponse struct {
 
 func (x *MoveResponse) Reset() {
 	*x = MoveResponse{}
-	mi := &file_storage_proto_msgTypes[20]
+	mi := &file_storage_proto_msgTypes[21]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1072,7 +1188,7 @@ func (x *MoveResponse) String() string {
 func (*MoveResponse) ProtoMessage() {}
 
 func (x *MoveResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_storage_proto_msgTypes[20]
+	mi := &file_storage_proto_msgTypes[21]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1085,7 +1201,7 @@ func (x *MoveResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MoveResponse.ProtoReflect.Descriptor instead.
 func (*MoveResponse) Descriptor() ([]byte, []int) {
-	return file_storage_proto_rawDescGZIP(), []int{20}
+	return file_storage_proto_rawDescGZIP(), []int{21}
 }
 
 func (x *MoveResponse) GetNode() *Node {
@@ -1095,6 +1211,274 @@ func (x *MoveResponse) GetNode() *Node {
 	return nil
 }
 
+type CopyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Source        string                 `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	Destination   string                 `protobuf:"bytes,2,opt,name=destination,proto3" json:"destination,omitempty"`
+	Options       map[string]string      `protobuf:"bytes,3,rep,name=options,proto3" json:"options,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CopyRequest) Reset() {
+	*x = CopyRequest{}
+	mi := &file_storage_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CopyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CopyRequest) ProtoMessage() {}
+
+func (x *CopyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_storage_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CopyRequest.ProtoReflect.Descriptor instead.
+func (*CopyRequest) Descriptor() ([]byte, []int) {
+	return file_storage_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *CopyRequest) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *CopyRequest) GetDestination() string {
+	if x != nil {
+		return x.Destination
+	}
+	return ""
+}
+
+func (x *CopyRequest) GetOptions() map[string]string {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+type CopyResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Node          *Node                  `protobuf:"bytes,1,opt,name=node,proto3" json:"node,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CopyResponse) Reset() {
+	*x = CopyResponse{}
+	mi := &file_storage_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CopyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CopyResponse) ProtoMessage() {}
+
+func (x *CopyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_storage_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CopyResponse.ProtoReflect.Descriptor instead.
+func (*CopyResponse) Descriptor() ([]byte, []int) {
+	return file_storage_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *CopyResponse) GetNode() *Node {
+	if x != nil {
+		return x.Node
+	}
+	return nil
+}
+
+type DeltaRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Options       map[string]string      `protobuf:"bytes,2,rep,name=options,proto3" json:"options,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Cursor        string                 `protobuf:"bytes,3,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeltaRequest) Reset() {
+	*x = DeltaRequest{}
+	mi := &file_storage_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeltaRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeltaRequest) ProtoMessage() {}
+
+func (x *DeltaRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_storage_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeltaRequest.ProtoReflect.Descriptor instead.
+func (*DeltaRequest) Descriptor() ([]byte, []int) {
+	return file_storage_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *DeltaRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *DeltaRequest) GetOptions() map[string]string {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+func (x *DeltaRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+type DeltaResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Changes       []*Change              `protobuf:"bytes,1,rep,name=changes,proto3" json:"changes,omitempty"`
+	Cursor        string                 `protobuf:"bytes,2,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeltaResponse) Reset() {
+	*x = DeltaResponse{}
+	mi := &file_storage_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeltaResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeltaResponse) ProtoMessage() {}
+
+func (x *DeltaResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_storage_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeltaResponse.ProtoReflect.Descriptor instead.
+func (*DeltaResponse) Descriptor() ([]byte, []int) {
+	return file_storage_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *DeltaResponse) GetChanges() []*Change {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+func (x *DeltaResponse) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+type Change struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Node          *Node                  `protobuf:"bytes,1,opt,name=node,proto3" json:"node,omitempty"`
+	Deleted       bool                   `protobuf:"varint,2,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Change) Reset() {
+	*x = Change{}
+	mi := &file_storage_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Change) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Change) ProtoMessage() {}
+
+func (x *Change) ProtoReflect() protoreflect.Message {
+	mi := &file_storage_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Change.ProtoReflect.Descriptor instead.
+func (*Change) Descriptor() ([]byte, []int) {
+	return file_storage_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *Change) GetNode() *Node {
+	if x != nil {
+		return x.Node
+	}
+	return nil
+}
+
+func (x *Change) GetDeleted() bool {
+	if x != nil {
+		return x.Deleted
+	}
+	return false
+}
+
 type Node struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -1105,13 +1489,14 @@ type Node struct {
 	ModifiedAt    int64                  `protobuf:"varint,6,opt,name=modified_at,json=modifiedAt,proto3" json:"modified_at,omitempty"`
 	Etag          string                 `protobuf:"bytes,7,opt,name=etag,proto3" json:"etag,omitempty"`
 	Ctag          string                 `protobuf:"bytes,8,opt,name=ctag,proto3" json:"ctag,omitempty"`
+	Hashes        map[string]string      `protobuf:"bytes,9,rep,name=hashes,proto3" json:"hashes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Node) Reset() {
 	*x = Node{}
-	mi := &file_storage_proto_msgTypes[21]
+	mi := &file_storage_proto_msgTypes[27]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1123,7 +1508,7 @@ func (x *Node) String() string {
 func (*Node) ProtoMessage() {}
 
 func (x *Node) ProtoReflect() protoreflect.Message {
-	mi := &file_storage_proto_msgTypes[21]
+	mi := &file_storage_proto_msgTypes[27]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1136,7 +1521,7 @@ func (x *Node) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Node.ProtoReflect.Descriptor instead.
 func (*Node) Descriptor() ([]byte, []int) {
-	return file_storage_proto_rawDescGZIP(), []int{21}
+	return file_storage_proto_rawDescGZIP(), []int{27}
 }
 
 func (x *Node) GetId() string {
@@ -1195,16 +1580,33 @@ func (x *Node) GetCtag() string {
 	return ""
 }
 
+func (x *Node) GetHashes() map[string]string {
+	if x != nil {
+		return x.Hashes
+	}
+	return nil
+}
+
 var File_storage_proto protoreflect.FileDescriptor
 
 const file_storage_proto_rawDesc = "" +
 	"\n" +
 	"\rstorage.proto\x12\astorage\"\x11\n" +
-	"\x0fMetadataRequest\"k\n" +
+	"\x0fMetadataRequest\"\xc0\x01\n" +
 	"\x10MetadataResponse\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
 	"\x04type\x18\x02 \x01(\tR\x04type\x12/\n" +
-	"\x13supported_providers\x18\x03 \x03(\tR\x12supportedProviders\"\x92\x01\n" +
+	"\x13supported_providers\x18\x03 \x03(\tR\x12supportedProviders\x129\n" +
+	"\fcapabilities\x18\x04 \x01(\v2\x15.storage.CapabilitiesR\fcapabilities\x12\x18\n" +
+	"\aversion\x18\x05 \x01(\tR\aversion\"\xb0\x01\n" +
+	"\fCapabilities\x12\x12\n" +
+	"\x04move\x18\x01 \x01(\bR\x04move\x12\x12\n" +
+	"\x04copy\x18\x02 \x01(\bR\x04copy\x12\x1f\n" +
+	"\vranged_read\x18\x03 \x01(\bR\n" +
+	"rangedRead\x12)\n" +
+	"\x10etag_concurrency\x18\x04 \x01(\bR\x0fetagConcurrency\x12\x14\n" +
+	"\x05delta\x18\x05 \x01(\bR\x05delta\x12\x16\n" +
+	"\x06hashes\x18\x06 \x03(\tR\x06hashes\"\x92\x01\n" +
 	"\x11ListDrivesRequest\x12A\n" +
 	"\aoptions\x18\x01 \x03(\v2'.storage.ListDrivesRequest.OptionsEntryR\aoptions\x1a:\n" +
 	"\fOptionsEntry\x12\x10\n" +
@@ -1247,10 +1649,12 @@ const file_storage_proto_rawDesc = "" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"3\n" +
 	"\fListResponse\x12#\n" +
-	"\x05nodes\x18\x01 \x03(\v2\r.storage.NodeR\x05nodes\"\x9a\x01\n" +
+	"\x05nodes\x18\x01 \x03(\v2\r.storage.NodeR\x05nodes\"\xca\x01\n" +
 	"\vReadRequest\x12\x12\n" +
 	"\x04path\x18\x01 \x01(\tR\x04path\x12;\n" +
-	"\aoptions\x18\x02 \x03(\v2!.storage.ReadRequest.OptionsEntryR\aoptions\x1a:\n" +
+	"\aoptions\x18\x02 \x03(\v2!.storage.ReadRequest.OptionsEntryR\aoptions\x12\x16\n" +
+	"\x06offset\x18\x03 \x01(\x03R\x06offset\x12\x16\n" +
+	"\x06length\x18\x04 \x01(\x03R\x06length\x1a:\n" +
 	"\fOptionsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"$\n" +
@@ -1281,7 +1685,29 @@ const file_storage_proto_rawDesc = "" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"1\n" +
 	"\fMoveResponse\x12!\n" +
-	"\x04node\x18\x01 \x01(\v2\r.storage.NodeR\x04node\"\xc2\x01\n" +
+	"\x04node\x18\x01 \x01(\v2\r.storage.NodeR\x04node\"\xc0\x01\n" +
+	"\vCopyRequest\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\x12 \n" +
+	"\vdestination\x18\x02 \x01(\tR\vdestination\x12;\n" +
+	"\aoptions\x18\x03 \x03(\v2!.storage.CopyRequest.OptionsEntryR\aoptions\x1a:\n" +
+	"\fOptionsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"1\n" +
+	"\fCopyResponse\x12!\n" +
+	"\x04node\x18\x01 \x01(\v2\r.storage.NodeR\x04node\"\xb4\x01\n" +
+	"\fDeltaRequest\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12<\n" +
+	"\aoptions\x18\x02 \x03(\v2\".storage.DeltaRequest.OptionsEntryR\aoptions\x12\x16\n" +
+	"\x06cursor\x18\x03 \x01(\tR\x06cursor\x1a:\n" +
+	"\fOptionsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"R\n" +
+	"\rDeltaResponse\x12)\n" +
+	"\achanges\x18\x01 \x03(\v2\x0f.storage.ChangeR\achanges\x12\x16\n" +
+	"\x06cursor\x18\x02 \x01(\tR\x06cursor\"E\n" +
+	"\x06Change\x12!\n" +
+	"\x04node\x18\x01 \x01(\v2\r.storage.NodeR\x04node\x12\x18\n" +
+	"\adeleted\x18\x02 \x01(\bR\adeleted\"\xb0\x02\n" +
 	"\x04Node\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
@@ -1291,10 +1717,14 @@ const file_storage_proto_rawDesc = "" +
 	"\vmodified_at\x18\x06 \x01(\x03R\n" +
 	"modifiedAt\x12\x12\n" +
 	"\x04etag\x18\a \x01(\tR\x04etag\x12\x12\n" +
-	"\x04ctag\x18\b \x01(\tR\x04ctag*#\n" +
+	"\x04ctag\x18\b \x01(\tR\x04ctag\x121\n" +
+	"\x06hashes\x18\t \x03(\v2\x19.storage.Node.HashesEntryR\x06hashes\x1a9\n" +
+	"\vHashesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01*#\n" +
 	"\bNodeType\x12\b\n" +
 	"\x04FILE\x10\x00\x12\r\n" +
-	"\tDIRECTORY\x10\x012\xdf\x04\n" +
+	"\tDIRECTORY\x10\x012\xce\x05\n" +
 	"\x0eStorageService\x123\n" +
 	"\x04List\x12\x14.storage.ListRequest\x1a\x15.storage.ListResponse\x123\n" +
 	"\x04Stat\x12\x14.storage.StatRequest\x1a\x15.storage.StatResponse\x126\n" +
@@ -1302,11 +1732,13 @@ const file_storage_proto_rawDesc = "" +
 	"\x04Read\x12\x14.storage.ReadRequest\x1a\x15.storage.ReadResponse0\x01\x128\n" +
 	"\x05Write\x12\x15.storage.WriteRequest\x1a\x16.storage.WriteResponse(\x01\x129\n" +
 	"\x06Delete\x12\x16.storage.DeleteRequest\x1a\x17.storage.DeleteResponse\x123\n" +
-	"\x04Move\x12\x14.storage.MoveRequest\x1a\x15.storage.MoveResponse\x12E\n" +
+	"\x04Move\x12\x14.storage.MoveRequest\x1a\x15.storage.MoveResponse\x123\n" +
+	"\x04Copy\x12\x14.storage.CopyRequest\x1a\x15.storage.CopyResponse\x12E\n" +
 	"\n" +
 	"ListDrives\x12\x1a.storage.ListDrivesRequest\x1a\x1b.storage.ListDrivesResponse\x12?\n" +
 	"\bGetDrive\x12\x18.storage.GetDriveRequest\x1a\x19.storage.GetDriveResponse\x12B\n" +
-	"\vGetMetadata\x12\x18.storage.MetadataRequest\x1a\x19.storage.MetadataResponseBOZMgithub.com/michaeldcanady/go-onedrive/internal/features/plugins/proto/storageb\x06proto3"
+	"\vGetMetadata\x12\x18.storage.MetadataRequest\x1a\x19.storage.MetadataResponse\x128\n" +
+	"\x05Delta\x12\x15.storage.DeltaRequest\x1a\x16.storage.DeltaResponse0\x01BOZMgithub.com/michaeldcanady/go-onedrive/internal/features/plugins/proto/storageb\x06proto3"
 
 var (
 	file_storage_proto_rawDescOnce sync.Once
@@ -1321,84 +1753,104 @@ func file_storage_proto_rawDescGZIP() []byte {
 }
 
 var file_storage_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_storage_proto_msgTypes = make([]protoimpl.MessageInfo, 31)
+var file_storage_proto_msgTypes = make([]protoimpl.MessageInfo, 40)
 var file_storage_proto_goTypes = []any{
 	(NodeType)(0),              // 0: storage.NodeType
 	(*MetadataRequest)(nil),    // 1: storage.MetadataRequest
 	(*MetadataResponse)(nil),   // 2: storage.MetadataResponse
-	(*ListDrivesRequest)(nil),  // 3: storage.ListDrivesRequest
-	(*ListDrivesResponse)(nil), // 4: storage.ListDrivesResponse
-	(*GetDriveRequest)(nil),    // 5: storage.GetDriveRequest
-	(*GetDriveResponse)(nil),   // 6: storage.GetDriveResponse
-	(*Drive)(nil),              // 7: storage.Drive
-	(*StatRequest)(nil),        // 8: storage.StatRequest
-	(*StatResponse)(nil),       // 9: storage.StatResponse
-	(*MkdirRequest)(nil),       // 10: storage.MkdirRequest
-	(*MkdirResponse)(nil),      // 11: storage.MkdirResponse
-	(*ListRequest)(nil),        // 12: storage.ListRequest
-	(*ListResponse)(nil),       // 13: storage.ListResponse
-	(*ReadRequest)(nil),        // 14: storage.ReadRequest
-	(*ReadResponse)(nil),       // 15: storage.ReadResponse
-	(*WriteRequest)(nil),       // 16: storage.WriteRequest
-	(*WriteResponse)(nil),      // 17: storage.WriteResponse
-	(*DeleteRequest)(nil),      // 18: storage.DeleteRequest
-	(*DeleteResponse)(nil),     // 19: storage.DeleteResponse
-	(*MoveRequest)(nil),        // 20: storage.MoveRequest
-	(*MoveResponse)(nil),       // 21: storage.MoveResponse
-	(*Node)(nil),               // 22: storage.Node
-	nil,                        // 23: storage.ListDrivesRequest.OptionsEntry
-	nil,                        // 24: storage.GetDriveRequest.OptionsEntry
-	nil,                        // 25: storage.StatRequest.OptionsEntry
-	nil,                        // 26: storage.MkdirRequest.OptionsEntry
-	nil,                        // 27: storage.ListRequest.OptionsEntry
-	nil,                        // 28: storage.ReadRequest.OptionsEntry
-	nil,                        // 29: storage.WriteRequest.OptionsEntry
-	nil,                        // 30: storage.DeleteRequest.OptionsEntry
-	nil,                        // 31: storage.MoveRequest.OptionsEntry
+	(*Capabilities)(nil),       // 3: storage.Capabilities
+	(*ListDrivesRequest)(nil),  // 4: storage.ListDrivesRequest
+	(*ListDrivesResponse)(nil), // 5: storage.ListDrivesResponse
+	(*GetDriveRequest)(nil),    // 6: storage.GetDriveRequest
+	(*GetDriveResponse)(nil),   // 7: storage.GetDriveResponse
+	(*Drive)(nil),              // 8: storage.Drive
+	(*StatRequest)(nil),        // 9: storage.StatRequest
+	(*StatResponse)(nil),       // 10: storage.StatResponse
+	(*MkdirRequest)(nil),       // 11: storage.MkdirRequest
+	(*MkdirResponse)(nil),      // 12: storage.MkdirResponse
+	(*ListRequest)(nil),        // 13: storage.ListRequest
+	(*ListResponse)(nil),       // 14: storage.ListResponse
+	(*ReadRequest)(nil),        // 15: storage.ReadRequest
+	(*ReadResponse)(nil),       // 16: storage.ReadResponse
+	(*WriteRequest)(nil),       // 17: storage.WriteRequest
+	(*WriteResponse)(nil),      // 18: storage.WriteResponse
+	(*DeleteRequest)(nil),      // 19: storage.DeleteRequest
+	(*DeleteResponse)(nil),     // 20: storage.DeleteResponse
+	(*MoveRequest)(nil),        // 21: storage.MoveRequest
+	(*MoveResponse)(nil),       // 22: storage.MoveResponse
+	(*CopyRequest)(nil),        // 23: storage.CopyRequest
+	(*CopyResponse)(nil),       // 24: storage.CopyResponse
+	(*DeltaRequest)(nil),       // 25: storage.DeltaRequest
+	(*DeltaResponse)(nil),      // 26: storage.DeltaResponse
+	(*Change)(nil),             // 27: storage.Change
+	(*Node)(nil),               // 28: storage.Node
+	nil,                        // 29: storage.ListDrivesRequest.OptionsEntry
+	nil,                        // 30: storage.GetDriveRequest.OptionsEntry
+	nil,                        // 31: storage.StatRequest.OptionsEntry
+	nil,                        // 32: storage.MkdirRequest.OptionsEntry
+	nil,                        // 33: storage.ListRequest.OptionsEntry
+	nil,                        // 34: storage.ReadRequest.OptionsEntry
+	nil,                        // 35: storage.WriteRequest.OptionsEntry
+	nil,                        // 36: storage.DeleteRequest.OptionsEntry
+	nil,                        // 37: storage.MoveRequest.OptionsEntry
+	nil,                        // 38: storage.CopyRequest.OptionsEntry
+	nil,                        // 39: storage.DeltaRequest.OptionsEntry
+	nil,                        // 40: storage.Node.HashesEntry
 }
 var file_storage_proto_depIdxs = []int32{
-	23, // 0: storage.ListDrivesRequest.options:type_name -> storage.ListDrivesRequest.OptionsEntry
-	7,  // 1: storage.ListDrivesResponse.drives:type_name -> storage.Drive
-	24, // 2: storage.GetDriveRequest.options:type_name -> storage.GetDriveRequest.OptionsEntry
-	7,  // 3: storage.GetDriveResponse.drive:type_name -> storage.Drive
-	25, // 4: storage.StatRequest.options:type_name -> storage.StatRequest.OptionsEntry
-	22, // 5: storage.StatResponse.node:type_name -> storage.Node
-	26, // 6: storage.MkdirRequest.options:type_name -> storage.MkdirRequest.OptionsEntry
-	22, // 7: storage.MkdirResponse.node:type_name -> storage.Node
-	27, // 8: storage.ListRequest.options:type_name -> storage.ListRequest.OptionsEntry
-	22, // 9: storage.ListResponse.nodes:type_name -> storage.Node
-	28, // 10: storage.ReadRequest.options:type_name -> storage.ReadRequest.OptionsEntry
-	29, // 11: storage.WriteRequest.options:type_name -> storage.WriteRequest.OptionsEntry
-	22, // 12: storage.WriteResponse.node:type_name -> storage.Node
-	30, // 13: storage.DeleteRequest.options:type_name -> storage.DeleteRequest.OptionsEntry
-	31, // 14: storage.MoveRequest.options:type_name -> storage.MoveRequest.OptionsEntry
-	22, // 15: storage.MoveResponse.node:type_name -> storage.Node
-	0,  // 16: storage.Node.type:type_name -> storage.NodeType
-	12, // 17: storage.StorageService.List:input_type -> storage.ListRequest
-	8,  // 18: storage.StorageService.Stat:input_type -> storage.StatRequest
-	10, // 19: storage.StorageService.Mkdir:input_type -> storage.MkdirRequest
-	14, // 20: storage.StorageService.Read:input_type -> storage.ReadRequest
-	16, // 21: storage.StorageService.Write:input_type -> storage.WriteRequest
-	18, // 22: storage.StorageService.Delete:input_type -> storage.DeleteRequest
-	20, // 23: storage.StorageService.Move:input_type -> storage.MoveRequest
-	3,  // 24: storage.StorageService.ListDrives:input_type -> storage.ListDrivesRequest
-	5,  // 25: storage.StorageService.GetDrive:input_type -> storage.GetDriveRequest
-	1,  // 26: storage.StorageService.GetMetadata:input_type -> storage.MetadataRequest
-	13, // 27: storage.StorageService.List:output_type -> storage.ListResponse
-	9,  // 28: storage.StorageService.Stat:output_type -> storage.StatResponse
-	11, // 29: storage.StorageService.Mkdir:output_type -> storage.MkdirResponse
-	15, // 30: storage.StorageService.Read:output_type -> storage.ReadResponse
-	17, // 31: storage.StorageService.Write:output_type -> storage.WriteResponse
-	19, // 32: storage.StorageService.Delete:output_type -> storage.DeleteResponse
-	21, // 33: storage.StorageService.Move:output_type -> storage.MoveResponse
-	4,  // 34: storage.StorageService.ListDrives:output_type -> storage.ListDrivesResponse
-	6,  // 35: storage.StorageService.GetDrive:output_type -> storage.GetDriveResponse
-	2,  // 36: storage.StorageService.GetMetadata:output_type -> storage.MetadataResponse
-	27, // [27:37] is the sub-list for method output_type
-	17, // [17:27] is the sub-list for method input_type
-	17, // [17:17] is the sub-list for extension type_name
-	17, // [17:17] is the sub-list for extension extendee
-	0,  // [0:17] is the sub-list for field type_name
+	3,  // 0: storage.MetadataResponse.capabilities:type_name -> storage.Capabilities
+	29, // 1: storage.ListDrivesRequest.options:type_name -> storage.ListDrivesRequest.OptionsEntry
+	8,  // 2: storage.ListDrivesResponse.drives:type_name -> storage.Drive
+	30, // 3: storage.GetDriveRequest.options:type_name -> storage.GetDriveRequest.OptionsEntry
+	8,  // 4: storage.GetDriveResponse.drive:type_name -> storage.Drive
+	31, // 5: storage.StatRequest.options:type_name -> storage.StatRequest.OptionsEntry
+	28, // 6: storage.StatResponse.node:type_name -> storage.Node
+	32, // 7: storage.MkdirRequest.options:type_name -> storage.MkdirRequest.OptionsEntry
+	28, // 8: storage.MkdirResponse.node:type_name -> storage.Node
+	33, // 9: storage.ListRequest.options:type_name -> storage.ListRequest.OptionsEntry
+	28, // 10: storage.ListResponse.nodes:type_name -> storage.Node
+	34, // 11: storage.ReadRequest.options:type_name -> storage.ReadRequest.OptionsEntry
+	35, // 12: storage.WriteRequest.options:type_name -> storage.WriteRequest.OptionsEntry
+	28, // 13: storage.WriteResponse.node:type_name -> storage.Node
+	36, // 14: storage.DeleteRequest.options:type_name -> storage.DeleteRequest.OptionsEntry
+	37, // 15: storage.MoveRequest.options:type_name -> storage.MoveRequest.OptionsEntry
+	28, // 16: storage.MoveResponse.node:type_name -> storage.Node
+	38, // 17: storage.CopyRequest.options:type_name -> storage.CopyRequest.OptionsEntry
+	28, // 18: storage.CopyResponse.node:type_name -> storage.Node
+	39, // 19: storage.DeltaRequest.options:type_name -> storage.DeltaRequest.OptionsEntry
+	27, // 20: storage.DeltaResponse.changes:type_name -> storage.Change
+	28, // 21: storage.Change.node:type_name -> storage.Node
+	0,  // 22: storage.Node.type:type_name -> storage.NodeType
+	40, // 23: storage.Node.hashes:type_name -> storage.Node.HashesEntry
+	13, // 24: storage.StorageService.List:input_type -> storage.ListRequest
+	9,  // 25: storage.StorageService.Stat:input_type -> storage.StatRequest
+	11, // 26: storage.StorageService.Mkdir:input_type -> storage.MkdirRequest
+	15, // 27: storage.StorageService.Read:input_type -> storage.ReadRequest
+	17, // 28: storage.StorageService.Write:input_type -> storage.WriteRequest
+	19, // 29: storage.StorageService.Delete:input_type -> storage.DeleteRequest
+	21, // 30: storage.StorageService.Move:input_type -> storage.MoveRequest
+	23, // 31: storage.StorageService.Copy:input_type -> storage.CopyRequest
+	4,  // 32: storage.StorageService.ListDrives:input_type -> storage.ListDrivesRequest
+	6,  // 33: storage.StorageService.GetDrive:input_type -> storage.GetDriveRequest
+	1,  // 34: storage.StorageService.GetMetadata:input_type -> storage.MetadataRequest
+	25, // 35: storage.StorageService.Delta:input_type -> storage.DeltaRequest
+	14, // 36: storage.StorageService.List:output_type -> storage.ListResponse
+	10, // 37: storage.StorageService.Stat:output_type -> storage.StatResponse
+	12, // 38: storage.StorageService.Mkdir:output_type -> storage.MkdirResponse
+	16, // 39: storage.StorageService.Read:output_type -> storage.ReadResponse
+	18, // 40: storage.StorageService.Write:output_type -> storage.WriteResponse
+	20, // 41: storage.StorageService.Delete:output_type -> storage.DeleteResponse
+	22, // 42: storage.StorageService.Move:output_type -> storage.MoveResponse
+	24, // 43: storage.StorageService.Copy:output_type -> storage.CopyResponse
+	5,  // 44: storage.StorageService.ListDrives:output_type -> storage.ListDrivesResponse
+	7,  // 45: storage.StorageService.GetDrive:output_type -> storage.GetDriveResponse
+	2,  // 46: storage.StorageService.GetMetadata:output_type -> storage.MetadataResponse
+	26, // 47: storage.StorageService.Delta:output_type -> storage.DeltaResponse
+	36, // [36:48] is the sub-list for method output_type
+	24, // [24:36] is the sub-list for method input_type
+	24, // [24:24] is the sub-list for extension type_name
+	24, // [24:24] is the sub-list for extension extendee
+	0,  // [0:24] is the sub-list for field type_name
 }
 
 func init() { file_storage_proto_init() }
@@ -1412,7 +1864,7 @@ func file_storage_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_storage_proto_rawDesc), len(file_storage_proto_rawDesc)),
 			NumEnums:      1,
-			NumMessages:   31,
+			NumMessages:   40,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
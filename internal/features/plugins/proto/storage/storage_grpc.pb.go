@@ -26,9 +26,11 @@ const (
 	StorageService_Write_FullMethodName       = "/storage.StorageService/Write"
 	StorageService_Delete_FullMethodName      = "/storage.StorageService/Delete"
 	StorageService_Move_FullMethodName        = "/storage.StorageService/Move"
+	StorageService_Copy_FullMethodName        = "/storage.StorageService/Copy"
 	StorageService_ListDrives_FullMethodName  = "/storage.StorageService/ListDrives"
 	StorageService_GetDrive_FullMethodName    = "/storage.StorageService/GetDrive"
 	StorageService_GetMetadata_FullMethodName = "/storage.StorageService/GetMetadata"
+	StorageService_Delta_FullMethodName       = "/storage.StorageService/Delta"
 )
 
 // StorageServiceClient is the client API for StorageService service.
@@ -42,9 +44,11 @@ type StorageServiceClient interface {
 	Write(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[WriteRequest, WriteResponse], error)
 	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
 	Move(ctx context.Context, in *MoveRequest, opts ...grpc.CallOption) (*MoveResponse, error)
+	Copy(ctx context.Context, in *CopyRequest, opts ...grpc.CallOption) (*CopyResponse, error)
 	ListDrives(ctx context.Context, in *ListDrivesRequest, opts ...grpc.CallOption) (*ListDrivesResponse, error)
 	GetDrive(ctx context.Context, in *GetDriveRequest, opts ...grpc.CallOption) (*GetDriveResponse, error)
 	GetMetadata(ctx context.Context, in *MetadataRequest, opts ...grpc.CallOption) (*MetadataResponse, error)
+	Delta(ctx context.Context, in *DeltaRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DeltaResponse], error)
 }
 
 type storageServiceClient struct {
@@ -137,6 +141,16 @@ func (c *storageServiceClient) Move(ctx context.Context, in *MoveRequest, opts .
 	return out, nil
 }
 
+func (c *storageServiceClient) Copy(ctx context.Context, in *CopyRequest, opts ...grpc.CallOption) (*CopyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CopyResponse)
+	err := c.cc.Invoke(ctx, StorageService_Copy_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *storageServiceClient) ListDrives(ctx context.Context, in *ListDrivesRequest, opts ...grpc.CallOption) (*ListDrivesResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(ListDrivesResponse)
@@ -167,6 +181,25 @@ func (c *storageServiceClient) GetMetadata(ctx context.Context, in *MetadataRequ
 	return out, nil
 }
 
+func (c *storageServiceClient) Delta(ctx context.Context, in *DeltaRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DeltaResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &StorageService_ServiceDesc.Streams[2], StorageService_Delta_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[DeltaRequest, DeltaResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type StorageService_DeltaClient = grpc.ServerStreamingClient[DeltaResponse]
+
 // StorageServiceServer is the server API for StorageService service.
 // All implementations must embed UnimplementedStorageServiceServer
 // for forward compatibility.
@@ -178,9 +211,11 @@ type StorageServiceServer interface {
 	Write(grpc.ClientStreamingServer[WriteRequest, WriteResponse]) error
 	Delete(context.Context, *DeleteRequest) (*DeleteResponse, error)
 	Move(context.Context, *MoveRequest) (*MoveResponse, error)
+	Copy(context.Context, *CopyRequest) (*CopyResponse, error)
 	ListDrives(context.Context, *ListDrivesRequest) (*ListDrivesResponse, error)
 	GetDrive(context.Context, *GetDriveRequest) (*GetDriveResponse, error)
 	GetMetadata(context.Context, *MetadataRequest) (*MetadataResponse, error)
+	Delta(*DeltaRequest, grpc.ServerStreamingServer[DeltaResponse]) error
 	mustEmbedUnimplementedStorageServiceServer()
 }
 
@@ -212,6 +247,9 @@ func (UnimplementedStorageServiceServer) Delete(context.Context, *DeleteRequest)
 func (UnimplementedStorageServiceServer) Move(context.Context, *MoveRequest) (*MoveResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method Move not implemented")
 }
+func (UnimplementedStorageServiceServer) Copy(context.Context, *CopyRequest) (*CopyResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Copy not implemented")
+}
 func (UnimplementedStorageServiceServer) ListDrives(context.Context, *ListDrivesRequest) (*ListDrivesResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method ListDrives not implemented")
 }
@@ -221,6 +259,9 @@ func (UnimplementedStorageServiceServer) GetDrive(context.Context, *GetDriveRequ
 func (UnimplementedStorageServiceServer) GetMetadata(context.Context, *MetadataRequest) (*MetadataResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetMetadata not implemented")
 }
+func (UnimplementedStorageServiceServer) Delta(*DeltaRequest, grpc.ServerStreamingServer[DeltaResponse]) error {
+	return status.Error(codes.Unimplemented, "method Delta not implemented")
+}
 func (UnimplementedStorageServiceServer) mustEmbedUnimplementedStorageServiceServer() {}
 func (UnimplementedStorageServiceServer) testEmbeddedByValue()                        {}
 
@@ -350,6 +391,24 @@ func _StorageService_Move_Handler(srv interface{}, ctx context.Context, dec func
 	return interceptor(ctx, in, info, handler)
 }
 
+func _StorageService_Copy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CopyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServiceServer).Copy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StorageService_Copy_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServiceServer).Copy(ctx, req.(*CopyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _StorageService_ListDrives_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ListDrivesRequest)
 	if err := dec(in); err != nil {
@@ -404,6 +463,17 @@ func _StorageService_GetMetadata_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _StorageService_Delta_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DeltaRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(StorageServiceServer).Delta(m, &grpc.GenericServerStream[DeltaRequest, DeltaResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type StorageService_DeltaServer = grpc.ServerStreamingServer[DeltaResponse]
+
 // StorageService_ServiceDesc is the grpc.ServiceDesc for StorageService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -431,6 +501,10 @@ var StorageService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Move",
 			Handler:    _StorageService_Move_Handler,
 		},
+		{
+			MethodName: "Copy",
+			Handler:    _StorageService_Copy_Handler,
+		},
 		{
 			MethodName: "ListDrives",
 			Handler:    _StorageService_ListDrives_Handler,
@@ -455,6 +529,11 @@ var StorageService_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _StorageService_Write_Handler,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "Delta",
+			Handler:       _StorageService_Delta_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "storage.proto",
 }
@@ -0,0 +1,251 @@
+package googledrive
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/michaeldcanady/go-onedrive/internal/core/plugins"
+	"github.com/michaeldcanady/go-onedrive/internal/features/plugins/storage/storagetest"
+)
+
+func TestConformance(t *testing.T) {
+	srv := httptest.NewServer(newFakeDrive())
+	t.Cleanup(srv.Close)
+
+	p := &StoragePlugin{baseURL: srv.URL + "/drive/v3/"}
+	client := storagetest.Serve(t, p, plugins.CustomGRPCServerWithErrors(ToStatus))
+	storagetest.Run(t, client, map[string]string{"token": "t"})
+}
+
+// childQuery matches the searches the plugin runs: the children of a folder, optionally
+// with a given name.
+var childQuery = regexp.MustCompile(`^(?:name = '((?:[^'\\]|\\.)*)' and )?'([^']*)' in parents and trashed = false$`)
+
+// fakeFile is a file or folder held by fakeDrive.
+type fakeFile struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	MimeType     string    `json:"mimeType"`
+	Parents      []string  `json:"parents,omitempty"`
+	Size         string    `json:"size,omitempty"`
+	ModifiedTime time.Time `json:"modifiedTime"`
+	Trashed      bool      `json:"trashed,omitempty"`
+	content      []byte
+}
+
+// fakeChange is an entry in the change log kept by fakeDrive.
+type fakeChange struct {
+	FileID  string    `json:"fileId"`
+	Removed bool      `json:"removed,omitempty"`
+	File    *fakeFile `json:"file,omitempty"`
+}
+
+// changePageSize is the number of changes fakeDrive returns per page, kept small so
+// that paging is exercised.
+const changePageSize = 2
+
+// fakeDrive emulates the subset of the Drive v3 API the plugin uses. Like Drive, it
+// allows several files with the same name in a folder. Page tokens for the changes API
+// are offsets into its change log.
+type fakeDrive struct {
+	mu      sync.Mutex
+	files   map[string]*fakeFile
+	changes []fakeChange
+	nextID  int
+}
+
+func newFakeDrive() *fakeDrive {
+	return &fakeDrive{files: map[string]*fakeFile{"root": {ID: "root", MimeType: folderMimeType}}}
+}
+
+func (d *fakeDrive) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	p := r.URL.Path
+	id, action, _ := strings.Cut(strings.TrimPrefix(strings.TrimPrefix(p, "/upload"), "/drive/v3/files/"), "/")
+	switch {
+	case r.Method == http.MethodGet && p == "/drive/v3/changes/startPageToken":
+		writeJSON(w, http.StatusOK, map[string]any{"startPageToken": strconv.Itoa(len(d.changes))})
+	case r.Method == http.MethodGet && p == "/drive/v3/changes":
+		d.listChanges(w, r.URL.Query().Get("pageToken"))
+	case r.Method == http.MethodGet && p == "/drive/v3/files":
+		d.list(w, r.URL.Query().Get("q"))
+	case r.Method == http.MethodPost && p == "/drive/v3/files":
+		var f fakeFile
+		json.NewDecoder(r.Body).Decode(&f)
+		writeJSON(w, http.StatusOK, d.create(&f, nil))
+	case r.Method == http.MethodPost && p == "/upload/drive/v3/files":
+		f, content, err := readMultipart(r)
+		if err != nil {
+			driveError(w, http.StatusBadRequest, "badRequest")
+			return
+		}
+		writeJSON(w, http.StatusOK, d.create(f, content))
+	case !strings.HasPrefix(p, "/drive/v3/files/") && !strings.HasPrefix(p, "/upload/drive/v3/files/"):
+		driveError(w, http.StatusNotFound, "notFound")
+	case d.files[id] == nil:
+		driveError(w, http.StatusNotFound, "notFound")
+	case r.Method == http.MethodGet && r.URL.Query().Get("alt") == "media":
+		d.download(w, r, d.files[id])
+	case r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, d.files[id])
+	case r.Method == http.MethodPatch && strings.HasPrefix(p, "/upload/"):
+		_, content, err := readMultipart(r)
+		if err != nil {
+			driveError(w, http.StatusBadRequest, "badRequest")
+			return
+		}
+		f := d.files[id]
+		f.content, f.Size, f.ModifiedTime = content, strconv.Itoa(len(content)), time.Now().UTC()
+		d.changed(f)
+		writeJSON(w, http.StatusOK, f)
+	case r.Method == http.MethodDelete:
+		d.remove(id)
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPost && action == "copy":
+		var f fakeFile
+		json.NewDecoder(r.Body).Decode(&f)
+		src := d.files[id]
+		f.MimeType = src.MimeType
+		writeJSON(w, http.StatusOK, d.create(&f, src.content))
+	default:
+		driveError(w, http.StatusBadRequest, "badRequest")
+	}
+}
+
+// list answers a search for the children of a folder.
+func (d *fakeDrive) list(w http.ResponseWriter, q string) {
+	m := childQuery.FindStringSubmatch(q)
+	if m == nil {
+		driveError(w, http.StatusBadRequest, "invalidQuery")
+		return
+	}
+	name := strings.NewReplacer(`\\`, `\`, `\'`, `'`).Replace(m[1])
+	files := []*fakeFile{}
+	for _, f := range d.files {
+		if len(f.Parents) > 0 && f.Parents[0] == m[2] && (m[1] == "" || f.Name == name) {
+			files = append(files, f)
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"files": files})
+}
+
+func (d *fakeDrive) create(f *fakeFile, content []byte) *fakeFile {
+	d.nextID++
+	f.ID = "file-" + strconv.Itoa(d.nextID)
+	f.ModifiedTime = time.Now().UTC()
+	if f.MimeType == "" {
+		f.MimeType = "application/octet-stream"
+	}
+	if f.MimeType != folderMimeType {
+		f.content, f.Size = content, strconv.Itoa(len(content))
+	}
+	d.files[f.ID] = f
+	d.changed(f)
+	return f
+}
+
+// changed appends the current state of f to the change log.
+func (d *fakeDrive) changed(f *fakeFile) {
+	snapshot := *f
+	d.changes = append(d.changes, fakeChange{FileID: f.ID, File: &snapshot})
+}
+
+// listChanges answers a changes.list request for the page starting at token.
+func (d *fakeDrive) listChanges(w http.ResponseWriter, token string) {
+	start, err := strconv.Atoi(token)
+	if err != nil || start < 0 || start > len(d.changes) {
+		driveError(w, http.StatusBadRequest, "invalidPageToken")
+		return
+	}
+	end := min(start+changePageSize, len(d.changes))
+	res := map[string]any{"changes": d.changes[start:end]}
+	if end < len(d.changes) {
+		res["nextPageToken"] = strconv.Itoa(end)
+	} else {
+		res["newStartPageToken"] = strconv.Itoa(end)
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// remove deletes the file with the given ID and, for folders, everything beneath it.
+func (d *fakeDrive) remove(id string) {
+	delete(d.files, id)
+	d.changes = append(d.changes, fakeChange{FileID: id, Removed: true})
+	for childID, f := range d.files {
+		if len(f.Parents) > 0 && f.Parents[0] == id {
+			d.remove(childID)
+		}
+	}
+}
+
+// download serves file content, honoring single byte ranges.
+func (d *fakeDrive) download(w http.ResponseWriter, r *http.Request, f *fakeFile) {
+	rng := r.Header.Get("Range")
+	if rng == "" {
+		w.Write(f.content)
+		return
+	}
+	var start, end int64
+	end = int64(len(f.content)) - 1
+	if _, err := fmt.Sscanf(rng, "bytes=%d-%d", &start, &end); err != nil && !strings.HasSuffix(rng, "-") {
+		driveError(w, http.StatusBadRequest, "badRequest")
+		return
+	}
+	if start >= int64(len(f.content)) {
+		driveError(w, http.StatusRequestedRangeNotSatisfiable, "requestedRangeNotSatisfiable")
+		return
+	}
+	end = min(end, int64(len(f.content))-1)
+	w.WriteHeader(http.StatusPartialContent)
+	w.Write(f.content[start : end+1])
+}
+
+// readMultipart reads the metadata and content of a multipart upload.
+func readMultipart(r *http.Request) (*fakeFile, []byte, error) {
+	_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, nil, err
+	}
+	mr := multipart.NewReader(r.Body, params["boundary"])
+	part, err := mr.NextPart()
+	if err != nil {
+		return nil, nil, err
+	}
+	var f fakeFile
+	if err := json.NewDecoder(part).Decode(&f); err != nil {
+		return nil, nil, err
+	}
+	part, err = mr.NextPart()
+	if err != nil {
+		return nil, nil, err
+	}
+	content, err := io.ReadAll(part)
+	return &f, content, err
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+func driveError(w http.ResponseWriter, code int, reason string) {
+	writeJSON(w, code, map[string]any{"error": map[string]any{
+		"code":    code,
+		"message": reason,
+		"errors":  []any{map[string]any{"reason": reason, "message": reason}},
+	}})
+}
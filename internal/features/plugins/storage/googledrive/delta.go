@@ -0,0 +1,152 @@
+package googledrive
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	storage_proto "github.com/michaeldcanady/go-onedrive/internal/features/plugins/proto/storage"
+)
+
+const (
+	folderMimeType = "application/vnd.google-apps.folder"
+	nodeFields     = "id, name, mimeType, size, modifiedTime, md5Checksum, sha1Checksum, sha256Checksum"
+	changeFields   = "nextPageToken, newStartPageToken, changes(fileId, removed, file(" + nodeFields + ", parents, trashed))"
+)
+
+// Delta reports changes beneath req.Path using the Drive changes API, whose page token
+// is the cursor. Without a cursor the subtree is enumerated in full. Changes to files
+// outside the subtree are dropped, except permanent removals: Drive no longer knows
+// where those files lived, so they are reported by ID alone.
+func (p *StoragePlugin) Delta(req *storage_proto.DeltaRequest, stream storage_proto.StorageService_DeltaServer) error {
+	ctx := stream.Context()
+	srv, err := p.getService(ctx, req.Options)
+	if err != nil {
+		return err
+	}
+	id, err := p.resolvePath(srv, req.Path)
+	if err != nil {
+		return err
+	}
+	// Parents reference the real folder ID rather than the "root" alias.
+	root, err := srv.Files.Get(id).Fields("id").Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+
+	if req.Cursor == "" {
+		// Take the token before walking so that nothing changed during the walk is missed.
+		start, err := srv.Changes.GetStartPageToken().Context(ctx).Do()
+		if err != nil {
+			return err
+		}
+		if err := p.enumerate(ctx, srv, root.Id, req.Path, stream); err != nil {
+			return err
+		}
+		return stream.Send(&storage_proto.DeltaResponse{Cursor: start.StartPageToken})
+	}
+
+	paths := &pathResolver{srv: srv, ctx: ctx, known: map[string]resolved{root.Id: {req.Path, true}}}
+	token := req.Cursor
+	for {
+		res, err := srv.Changes.List(token).Fields(changeFields).Context(ctx).Do()
+		if err != nil {
+			if e, ok := err.(*googleapi.Error); ok && (e.Code == http.StatusBadRequest || e.Code == http.StatusGone) {
+				return status.Error(codes.FailedPrecondition, "cursor expired: "+e.Message)
+			}
+			return err
+		}
+
+		changes := make([]*storage_proto.Change, 0, len(res.Changes))
+		for _, c := range res.Changes {
+			if c.Removed || c.File == nil {
+				changes = append(changes, &storage_proto.Change{Node: &storage_proto.Node{Id: c.FileId}, Deleted: true})
+				continue
+			}
+			if len(c.File.Parents) == 0 {
+				continue
+			}
+			dir, ok := paths.dir(c.File.Parents[0])
+			if !ok {
+				continue
+			}
+			changes = append(changes, &storage_proto.Change{
+				Node:    p.toProtoNode(c.File, path.Join(dir, c.File.Name)),
+				Deleted: c.File.Trashed,
+			})
+		}
+
+		if res.NextPageToken != "" {
+			if err := stream.Send(&storage_proto.DeltaResponse{Changes: changes}); err != nil {
+				return err
+			}
+			token = res.NextPageToken
+			continue
+		}
+		return stream.Send(&storage_proto.DeltaResponse{Changes: changes, Cursor: res.NewStartPageToken})
+	}
+}
+
+// enumerate sends every node beneath the folder id, one message per listing page.
+func (p *StoragePlugin) enumerate(ctx context.Context, srv *drive.Service, id, dir string, stream storage_proto.StorageService_DeltaServer) error {
+	var folders []*drive.File
+	err := srv.Files.List().
+		Q(fmt.Sprintf("'%s' in parents and trashed = false", id)).
+		Fields("nextPageToken, files("+nodeFields+")").
+		Pages(ctx, func(page *drive.FileList) error {
+			changes := make([]*storage_proto.Change, len(page.Files))
+			for i, f := range page.Files {
+				changes[i] = &storage_proto.Change{Node: p.toProtoNode(f, path.Join(dir, f.Name))}
+				if f.MimeType == folderMimeType {
+					folders = append(folders, f)
+				}
+			}
+			return stream.Send(&storage_proto.DeltaResponse{Changes: changes})
+		})
+	if err != nil {
+		return err
+	}
+
+	for _, f := range folders {
+		if err := p.enumerate(ctx, srv, f.Id, path.Join(dir, f.Name), stream); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type resolved struct {
+	path string
+	ok   bool
+}
+
+// pathResolver maps folder IDs to paths beneath the delta root by walking parents,
+// caching every folder it visits.
+type pathResolver struct {
+	srv   *drive.Service
+	ctx   context.Context
+	known map[string]resolved
+}
+
+// dir returns the path of the folder id, or false when it lies outside the delta root.
+func (r *pathResolver) dir(id string) (string, bool) {
+	if res, ok := r.known[id]; ok {
+		return res.path, res.ok
+	}
+
+	res := resolved{}
+	f, err := r.srv.Files.Get(id).Fields("id, name, parents").Context(r.ctx).Do()
+	if err == nil && len(f.Parents) > 0 {
+		if parent, ok := r.dir(f.Parents[0]); ok {
+			res = resolved{path.Join(parent, f.Name), true}
+		}
+	}
+	r.known[id] = res
+	return res.path, res.ok
+}
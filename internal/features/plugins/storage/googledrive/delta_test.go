@@ -0,0 +1,122 @@
+package googledrive
+
+import (
+	"context"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	storage_proto "github.com/michaeldcanady/go-onedrive/internal/features/plugins/proto/storage"
+)
+
+type fakeDeltaStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	msgs []*storage_proto.DeltaResponse
+}
+
+func (s *fakeDeltaStream) Context() context.Context { return s.ctx }
+
+func (s *fakeDeltaStream) Send(resp *storage_proto.DeltaResponse) error {
+	s.msgs = append(s.msgs, resp)
+	return nil
+}
+
+func TestStoragePlugin_Delta(t *testing.T) {
+	opts := map[string]string{"token": "t"}
+
+	// setup serves a drive holding /a.txt, /docs/b.txt and /other/c.txt.
+	setup := func(t *testing.T) (*StoragePlugin, *fakeDrive, map[string]*fakeFile) {
+		d := newFakeDrive()
+		srv := httptest.NewServer(d)
+		t.Cleanup(srv.Close)
+
+		files := map[string]*fakeFile{}
+		files["/docs"] = d.create(&fakeFile{Name: "docs", MimeType: folderMimeType, Parents: []string{"root"}}, nil)
+		files["/other"] = d.create(&fakeFile{Name: "other", MimeType: folderMimeType, Parents: []string{"root"}}, nil)
+		files["/a.txt"] = d.create(&fakeFile{Name: "a.txt", Parents: []string{"root"}}, []byte("a"))
+		files["/docs/b.txt"] = d.create(&fakeFile{Name: "b.txt", Parents: []string{files["/docs"].ID}}, []byte("b"))
+		files["/other/c.txt"] = d.create(&fakeFile{Name: "c.txt", Parents: []string{files["/other"].ID}}, []byte("c"))
+		return &StoragePlugin{baseURL: srv.URL + "/drive/v3/"}, d, files
+	}
+
+	t.Run("without a cursor the subtree is enumerated", func(t *testing.T) {
+		p, d, _ := setup(t)
+		stream := &fakeDeltaStream{ctx: context.Background()}
+		require.NoError(t, p.Delta(&storage_proto.DeltaRequest{Path: "/", Options: opts}, stream))
+
+		var paths []string
+		for _, m := range stream.msgs {
+			for _, c := range m.Changes {
+				paths = append(paths, c.Node.Path)
+			}
+		}
+		assert.ElementsMatch(t, []string{"/docs", "/other", "/a.txt", "/docs/b.txt", "/other/c.txt"}, paths)
+		require.NotEmpty(t, stream.msgs)
+		assert.Equal(t, strconv.Itoa(len(d.changes)), stream.msgs[len(stream.msgs)-1].Cursor)
+	})
+
+	t.Run("follows pages and returns the new start token", func(t *testing.T) {
+		p, d, files := setup(t)
+		cursor := strconv.Itoa(len(d.changes))
+
+		d.create(&fakeFile{Name: "new.txt", Parents: []string{files["/docs"].ID}}, []byte("new"))
+		d.remove(files["/docs/b.txt"].ID)
+		files["/a.txt"].Trashed = true
+		d.changed(files["/a.txt"])
+		d.create(&fakeFile{Name: "d.txt", Parents: []string{files["/other"].ID}}, []byte("d"))
+
+		stream := &fakeDeltaStream{ctx: context.Background()}
+		require.NoError(t, p.Delta(&storage_proto.DeltaRequest{Path: "/", Options: opts, Cursor: cursor}, stream))
+		require.Len(t, stream.msgs, 2)
+		assert.Empty(t, stream.msgs[0].Cursor)
+		assert.Equal(t, strconv.Itoa(len(d.changes)), stream.msgs[1].Cursor)
+
+		var paths, deleted []string
+		for _, m := range stream.msgs {
+			for _, c := range m.Changes {
+				paths = append(paths, c.Node.Path)
+				if c.Deleted {
+					deleted = append(deleted, c.Node.Path+"#"+c.Node.Id)
+				}
+			}
+		}
+		assert.Equal(t, []string{"/docs/new.txt", "", "/a.txt", "/other/d.txt"}, paths)
+		assert.Equal(t, []string{"#" + files["/docs/b.txt"].ID, "/a.txt#" + files["/a.txt"].ID}, deleted)
+	})
+
+	t.Run("changes outside the subtree are dropped", func(t *testing.T) {
+		p, d, files := setup(t)
+		cursor := strconv.Itoa(len(d.changes))
+
+		d.create(&fakeFile{Name: "new.txt", Parents: []string{files["/docs"].ID}}, []byte("new"))
+		d.changed(files["/a.txt"])
+		d.changed(files["/other/c.txt"])
+
+		stream := &fakeDeltaStream{ctx: context.Background()}
+		require.NoError(t, p.Delta(&storage_proto.DeltaRequest{Path: "/docs", Options: opts, Cursor: cursor}, stream))
+
+		var paths []string
+		for _, m := range stream.msgs {
+			for _, c := range m.Changes {
+				paths = append(paths, c.Node.Path)
+			}
+		}
+		assert.Equal(t, []string{"/docs/new.txt"}, paths)
+	})
+
+	t.Run("expired cursor", func(t *testing.T) {
+		p, _, _ := setup(t)
+		stream := &fakeDeltaStream{ctx: context.Background()}
+		err := p.Delta(&storage_proto.DeltaRequest{Path: "/", Options: opts, Cursor: "bogus"}, stream)
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+		assert.Contains(t, err.Error(), "cursor expired")
+		assert.Empty(t, stream.msgs)
+	})
+}
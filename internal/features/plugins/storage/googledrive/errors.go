@@ -0,0 +1,54 @@
+package googledrive
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"google.golang.org/api/googleapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/michaeldcanady/go-onedrive/internal/core/plugins"
+)
+
+// ToStatus translates Drive rate limiting and transient server failures into retryable
+// gRPC statuses carrying the Retry-After hint, and missing items and denied access into
+// their codes. Other errors are returned unchanged.
+func ToStatus(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	var e *googleapi.Error
+	if !errors.As(err, &e) {
+		return err
+	}
+
+	var retryAfter time.Duration
+	if secs, perr := strconv.Atoi(e.Header.Get("Retry-After")); perr == nil {
+		retryAfter = time.Duration(secs) * time.Second
+	}
+
+	switch {
+	case e.Code == http.StatusTooManyRequests, e.Code == http.StatusForbidden && rateLimited(e):
+		return plugins.RetryableError(codes.ResourceExhausted, e.Error(), retryAfter)
+	case e.Code == http.StatusServiceUnavailable, e.Code == http.StatusBadGateway, e.Code == http.StatusGatewayTimeout:
+		return plugins.RetryableError(codes.Unavailable, e.Error(), retryAfter)
+	case e.Code == http.StatusNotFound:
+		return status.Error(codes.NotFound, e.Error())
+	case e.Code == http.StatusUnauthorized, e.Code == http.StatusForbidden:
+		return status.Error(codes.PermissionDenied, e.Error())
+	}
+	return err
+}
+
+// rateLimited reports whether a 403 is Drive's rate limit rather than a permission error.
+func rateLimited(e *googleapi.Error) bool {
+	for _, item := range e.Errors {
+		if item.Reason == "rateLimitExceeded" || item.Reason == "userRateLimitExceeded" {
+			return true
+		}
+	}
+	return false
+}
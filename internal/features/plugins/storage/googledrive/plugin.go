@@ -0,0 +1,356 @@
+// Package googledrive is the storage plugin for Google Drive. odc serves it
+// in-process, and cmd/storage-plugin-googledrive serves it as a plugin binary.
+package googledrive
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/michaeldcanady/go-onedrive/internal/core/plugins"
+	storage_proto "github.com/michaeldcanady/go-onedrive/internal/features/plugins/proto/storage"
+	"github.com/michaeldcanady/go-onedrive/pkg/pluginsdk"
+)
+
+// Version is reported through GetMetadata.
+var Version = "dev"
+
+// StoragePlugin implements the storage service for Google Drive.
+type StoragePlugin struct {
+	storage_proto.UnimplementedStorageServiceServer
+
+	// baseURL overrides the Drive API endpoint when set.
+	baseURL string
+}
+
+// NewStoragePlugin returns a new [*StoragePlugin].
+func NewStoragePlugin() *StoragePlugin {
+	return &StoragePlugin{}
+}
+
+func (p *StoragePlugin) ListDrives(ctx context.Context, req *storage_proto.ListDrivesRequest) (*storage_proto.ListDrivesResponse, error) {
+	srv, err := p.getService(ctx, req.Options)
+	if err != nil {
+		return nil, err
+	}
+	res, err := srv.About.Get().Fields("user").Do()
+	if err != nil {
+		return nil, err
+	}
+	return &storage_proto.ListDrivesResponse{Drives: []*storage_proto.Drive{{Id: "root", Name: res.User.DisplayName + "'s Drive", Type: "personal"}}}, nil
+}
+
+func (p *StoragePlugin) GetMetadata(ctx context.Context, req *storage_proto.MetadataRequest) (*storage_proto.MetadataResponse, error) {
+	return &storage_proto.MetadataResponse{
+		Name:               "googledrive",
+		Version:            Version,
+		Type:               "storage",
+		SupportedProviders: []string{"google"},
+		Capabilities: &storage_proto.Capabilities{
+			Copy:       true,
+			RangedRead: true,
+			Delta:      true,
+			Hashes:     []string{"md5", "sha1", "sha256"},
+		},
+	}, nil
+}
+
+func (p *StoragePlugin) List(ctx context.Context, req *storage_proto.ListRequest) (*storage_proto.ListResponse, error) {
+	srv, err := p.getService(ctx, req.Options)
+	if err != nil {
+		return nil, err
+	}
+	id, err := p.resolvePath(srv, req.Path)
+	if err != nil {
+		return nil, err
+	}
+	res, err := srv.Files.List().Q(fmt.Sprintf("'%s' in parents and trashed = false", id)).Fields("files(" + nodeFields + ")").Do()
+	if err != nil {
+		return nil, err
+	}
+	nodes := make([]*storage_proto.Node, len(res.Files))
+	for i, f := range res.Files {
+		nodes[i] = p.toProtoNode(f, filepath.Join(req.Path, f.Name))
+	}
+	return &storage_proto.ListResponse{Nodes: nodes}, nil
+}
+
+func (p *StoragePlugin) Stat(ctx context.Context, req *storage_proto.StatRequest) (*storage_proto.StatResponse, error) {
+	srv, err := p.getService(ctx, req.Options)
+	if err != nil {
+		return nil, err
+	}
+	id, err := p.resolvePath(srv, req.Path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := srv.Files.Get(id).Fields(nodeFields).Do()
+	if err != nil {
+		return nil, err
+	}
+	return &storage_proto.StatResponse{Node: p.toProtoNode(f, req.Path)}, nil
+}
+
+// Mkdir creates the folder and any missing parents. Drive allows several files with one
+// name, so an existing item is looked up first and reported as already existing.
+func (p *StoragePlugin) Mkdir(ctx context.Context, req *storage_proto.MkdirRequest) (*storage_proto.MkdirResponse, error) {
+	srv, err := p.getService(ctx, req.Options)
+	if err != nil {
+		return nil, err
+	}
+	parent, err := p.ensureFolder(srv, filepath.Dir(req.Path))
+	if err != nil {
+		return nil, err
+	}
+	name := filepath.Base(req.Path)
+	existing, err := p.findChild(srv, parent, name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, status.Errorf(codes.AlreadyExists, "%s already exists", req.Path)
+	}
+	f, err := srv.Files.Create(&drive.File{Name: name, MimeType: folderMimeType, Parents: []string{parent}}).Fields(nodeFields).Do()
+	if err != nil {
+		return nil, err
+	}
+	return &storage_proto.MkdirResponse{Node: p.toProtoNode(f, req.Path)}, nil
+}
+
+func (p *StoragePlugin) Read(req *storage_proto.ReadRequest, stream storage_proto.StorageService_ReadServer) error {
+	srv, err := p.getService(stream.Context(), req.Options)
+	if err != nil {
+		return err
+	}
+	id, err := p.resolvePath(srv, req.Path)
+	if err != nil {
+		return err
+	}
+	call := srv.Files.Get(id)
+	if req.Offset > 0 || req.Length > 0 {
+		rng := fmt.Sprintf("bytes=%d-", req.Offset)
+		if req.Length > 0 {
+			rng = fmt.Sprintf("bytes=%d-%d", req.Offset, req.Offset+req.Length-1)
+		}
+		call.Header().Set("Range", rng)
+	}
+	res, err := call.Download()
+	if err != nil {
+		if e, ok := err.(*googleapi.Error); ok && e.Code == http.StatusRequestedRangeNotSatisfiable {
+			return nil
+		}
+		return err
+	}
+	defer res.Body.Close()
+	return pluginsdk.SendChunks(res.Body, func(chunk []byte) error {
+		return stream.Send(&storage_proto.ReadResponse{Chunk: chunk})
+	})
+}
+
+func (p *StoragePlugin) Write(stream storage_proto.StorageService_WriteServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	srv, err := p.getService(stream.Context(), req.Options)
+	if err != nil {
+		return err
+	}
+	parent, err := p.ensureFolder(srv, filepath.Dir(req.Path))
+	if err != nil {
+		return err
+	}
+	name := filepath.Base(req.Path)
+	existing, err := p.findChild(srv, parent, name)
+	if err != nil {
+		return err
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		defer pw.Close()
+		if _, err := pw.Write(req.Chunk); err != nil {
+			return
+		}
+		for {
+			m, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return
+			}
+			if _, err := pw.Write(m.Chunk); err != nil {
+				return
+			}
+		}
+	}()
+
+	var f *drive.File
+	if existing != nil {
+		f, err = srv.Files.Update(existing.Id, nil).Media(pr).Fields(nodeFields).Do()
+	} else {
+		f, err = srv.Files.Create(&drive.File{Name: name, Parents: []string{parent}}).Media(pr).Fields(nodeFields).Do()
+	}
+	if err != nil {
+		return err
+	}
+	return stream.SendAndClose(&storage_proto.WriteResponse{Node: p.toProtoNode(f, req.Path)})
+}
+
+func (p *StoragePlugin) Delete(ctx context.Context, req *storage_proto.DeleteRequest) (*storage_proto.DeleteResponse, error) {
+	srv, err := p.getService(ctx, req.Options)
+	if err != nil {
+		return nil, err
+	}
+	id, err := p.resolvePath(srv, req.Path)
+	if err != nil {
+		return nil, err
+	}
+	if err := srv.Files.Delete(id).Do(); err != nil {
+		return nil, err
+	}
+	return &storage_proto.DeleteResponse{Success: true}, nil
+}
+
+// Copy duplicates a file with files.copy, so the content never leaves Drive. Drive allows
+// several files with one name, so an existing destination is removed once the copy exists.
+func (p *StoragePlugin) Copy(ctx context.Context, req *storage_proto.CopyRequest) (*storage_proto.CopyResponse, error) {
+	srv, err := p.getService(ctx, req.Options)
+	if err != nil {
+		return nil, err
+	}
+	id, err := p.resolvePath(srv, req.Source)
+	if err != nil {
+		return nil, err
+	}
+	src, err := srv.Files.Get(id).Fields("id, mimeType").Do()
+	if err != nil {
+		return nil, err
+	}
+	if src.MimeType == folderMimeType {
+		return nil, status.Errorf(codes.InvalidArgument, "%s: folders cannot be copied", req.Source)
+	}
+	parent, err := p.resolvePath(srv, filepath.Dir(req.Destination))
+	if err != nil {
+		return nil, err
+	}
+
+	name := filepath.Base(req.Destination)
+	existing, err := srv.Files.List().Q(fmt.Sprintf("name = '%s' and '%s' in parents and trashed = false", escapeQuery(name), parent)).Fields("files(id)").Do()
+	if err != nil {
+		return nil, err
+	}
+	f, err := srv.Files.Copy(id, &drive.File{Name: name, Parents: []string{parent}}).Fields(nodeFields).Do()
+	if err != nil {
+		return nil, err
+	}
+	for _, old := range existing.Files {
+		if old.Id == id {
+			continue
+		}
+		if err := srv.Files.Delete(old.Id).Do(); err != nil {
+			return nil, err
+		}
+	}
+	return &storage_proto.CopyResponse{Node: p.toProtoNode(f, req.Destination)}, nil
+}
+
+func (p *StoragePlugin) getService(ctx context.Context, opts map[string]string) (*drive.Service, error) {
+	t := opts["token"]
+	if t == "" {
+		return nil, fmt.Errorf("missing token")
+	}
+	clientOpts := []option.ClientOption{option.WithHTTPClient(&http.Client{Transport: &plugins.TokenTransport{Token: t}})}
+	if p.baseURL != "" {
+		clientOpts = append(clientOpts, option.WithEndpoint(p.baseURL))
+	}
+	return drive.NewService(ctx, clientOpts...)
+}
+
+// resolvePath returns the ID of the file at path by looking up each segment in turn.
+func (p *StoragePlugin) resolvePath(srv *drive.Service, path string) (string, error) {
+	id := "root"
+	for _, part := range strings.Split(strings.Trim(path, "/"), "/") {
+		if part == "" {
+			continue
+		}
+		f, err := p.findChild(srv, id, part)
+		if err != nil {
+			return "", err
+		}
+		if f == nil {
+			return "", status.Errorf(codes.NotFound, "%s not found", path)
+		}
+		id = f.Id
+	}
+	return id, nil
+}
+
+// ensureFolder returns the ID of the folder at path, creating it and any missing parents.
+func (p *StoragePlugin) ensureFolder(srv *drive.Service, path string) (string, error) {
+	id := "root"
+	for _, part := range strings.Split(strings.Trim(path, "/"), "/") {
+		if part == "" || part == "." {
+			continue
+		}
+		f, err := p.findChild(srv, id, part)
+		if err != nil {
+			return "", err
+		}
+		if f == nil {
+			f, err = srv.Files.Create(&drive.File{Name: part, MimeType: folderMimeType, Parents: []string{id}}).Fields("id").Do()
+			if err != nil {
+				return "", err
+			}
+		}
+		id = f.Id
+	}
+	return id, nil
+}
+
+// findChild returns the file named name in the folder parent, or nil when there is none.
+func (p *StoragePlugin) findChild(srv *drive.Service, parent, name string) (*drive.File, error) {
+	res, err := srv.Files.List().Q(fmt.Sprintf("name = '%s' and '%s' in parents and trashed = false", escapeQuery(name), parent)).Fields("files(id)").Do()
+	if err != nil {
+		return nil, err
+	}
+	if len(res.Files) == 0 {
+		return nil, nil
+	}
+	return res.Files[0], nil
+}
+
+// escapeQuery escapes a value for use inside a quoted string in a Drive search query.
+func escapeQuery(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(v)
+}
+
+func (p *StoragePlugin) toProtoNode(f *drive.File, path string) *storage_proto.Node {
+	t := storage_proto.NodeType_FILE
+	if f.MimeType == folderMimeType {
+		t = storage_proto.NodeType_DIRECTORY
+	}
+	mod, _ := time.Parse(time.RFC3339, f.ModifiedTime)
+	node := &storage_proto.Node{Id: f.Id, Name: f.Name, Path: path, Type: t, Size: f.Size, ModifiedAt: mod.Unix()}
+	for name, sum := range map[string]string{"md5": f.Md5Checksum, "sha1": f.Sha1Checksum, "sha256": f.Sha256Checksum} {
+		if sum == "" {
+			continue
+		}
+		if node.Hashes == nil {
+			node.Hashes = make(map[string]string)
+		}
+		node.Hashes[name] = sum
+	}
+	return node
+}
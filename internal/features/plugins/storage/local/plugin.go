@@ -0,0 +1,322 @@
+// Package local is the storage plugin for the local host filesystem. odc serves it
+// in-process, and cmd/storage-plugin-local serves it as a plugin binary.
+package local
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	storage_proto "github.com/michaeldcanady/go-onedrive/internal/features/plugins/proto/storage"
+)
+
+// Version is reported through GetMetadata.
+var Version = "dev"
+
+const (
+	// deltaBatchSize bounds the number of changes sent in a single Delta message.
+	deltaBatchSize = 256
+	// cursorPrefix marks Delta cursors, which record when the previous scan started.
+	cursorPrefix = "mtime:"
+)
+
+// StoragePlugin implements the storage service for the local host filesystem.
+type StoragePlugin struct {
+	storage_proto.UnimplementedStorageServiceServer
+}
+
+// NewStoragePlugin returns a new [*StoragePlugin].
+func NewStoragePlugin() *StoragePlugin {
+	return &StoragePlugin{}
+}
+
+func (p *StoragePlugin) getPath(opts map[string]string, path string) string {
+	root := opts["root_path"]
+	if root == "" {
+		root = "."
+	}
+	return filepath.Join(root, path)
+}
+
+func (p *StoragePlugin) List(ctx context.Context, req *storage_proto.ListRequest) (*storage_proto.ListResponse, error) {
+	es, err := os.ReadDir(p.getPath(req.Options, req.Path))
+	if err != nil {
+		return nil, err
+	}
+	nodes := make([]*storage_proto.Node, 0, len(es))
+	for _, e := range es {
+		if info, err := e.Info(); err == nil {
+			nodes = append(nodes, p.toProtoNode(info, filepath.Join(req.Path, e.Name())))
+		}
+	}
+	return &storage_proto.ListResponse{Nodes: nodes}, nil
+}
+
+func (p *StoragePlugin) Stat(ctx context.Context, req *storage_proto.StatRequest) (*storage_proto.StatResponse, error) {
+	info, err := os.Stat(p.getPath(req.Options, req.Path))
+	if err != nil {
+		return nil, err
+	}
+	return &storage_proto.StatResponse{Node: p.toProtoNode(info, req.Path)}, nil
+}
+
+// Mkdir creates the directory and any missing parents. An existing item at the path is
+// reported as already existing.
+func (p *StoragePlugin) Mkdir(ctx context.Context, req *storage_proto.MkdirRequest) (*storage_proto.MkdirResponse, error) {
+	full := p.getPath(req.Options, req.Path)
+	if _, err := os.Lstat(full); err == nil {
+		return nil, &fs.PathError{Op: "mkdir", Path: req.Path, Err: fs.ErrExist}
+	}
+	if err := os.MkdirAll(full, 0755); err != nil {
+		return nil, err
+	}
+	info, _ := os.Stat(full)
+	return &storage_proto.MkdirResponse{Node: p.toProtoNode(info, req.Path)}, nil
+}
+
+func (p *StoragePlugin) Read(req *storage_proto.ReadRequest, stream storage_proto.StorageService_ReadServer) error {
+	f, err := os.Open(p.getPath(req.Options, req.Path))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if req.Offset > 0 {
+		if _, err := f.Seek(req.Offset, io.SeekStart); err != nil {
+			return err
+		}
+	}
+	var r io.Reader = f
+	if req.Length > 0 {
+		r = io.LimitReader(f, req.Length)
+	}
+
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := r.Read(buf)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(&storage_proto.ReadResponse{Chunk: buf[:n]}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *StoragePlugin) Write(stream storage_proto.StorageService_WriteServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	full := p.getPath(req.Options, req.Path)
+	if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(full)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.Write(req.Chunk); err != nil {
+		return err
+	}
+	for {
+		m, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(m.Chunk); err != nil {
+			return err
+		}
+	}
+	info, _ := os.Stat(full)
+	return stream.SendAndClose(&storage_proto.WriteResponse{Node: p.toProtoNode(info, req.Path)})
+}
+
+func (p *StoragePlugin) Delete(ctx context.Context, req *storage_proto.DeleteRequest) (*storage_proto.DeleteResponse, error) {
+	full := p.getPath(req.Options, req.Path)
+	// RemoveAll succeeds on a missing path.
+	if _, err := os.Lstat(full); err != nil {
+		return nil, err
+	}
+	if err := os.RemoveAll(full); err != nil {
+		return nil, err
+	}
+	return &storage_proto.DeleteResponse{Success: true}, nil
+}
+
+func (p *StoragePlugin) Move(ctx context.Context, req *storage_proto.MoveRequest) (*storage_proto.MoveResponse, error) {
+	src, dst := p.getPath(req.Options, req.Source), p.getPath(req.Options, req.Destination)
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(src, dst); err != nil {
+		return nil, err
+	}
+	info, _ := os.Stat(dst)
+	return &storage_proto.MoveResponse{Node: p.toProtoNode(info, req.Destination)}, nil
+}
+
+// Copy duplicates a file, or a directory and everything beneath it, replacing any files
+// already at the destination.
+func (p *StoragePlugin) Copy(ctx context.Context, req *storage_proto.CopyRequest) (*storage_proto.CopyResponse, error) {
+	src, dst := p.getPath(req.Options, req.Source), p.getPath(req.Options, req.Destination)
+	if src == dst || strings.HasPrefix(dst, src+string(filepath.Separator)) {
+		return nil, status.Errorf(codes.InvalidArgument, "cannot copy %s into itself", req.Source)
+	}
+	err := filepath.WalkDir(src, func(full string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, full)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		return copyFile(full, target)
+	})
+	if err != nil {
+		return nil, err
+	}
+	info, _ := os.Stat(dst)
+	return &storage_proto.CopyResponse{Node: p.toProtoNode(info, req.Destination)}, nil
+}
+
+// copyFile copies the content of src to dst, creating dst's parent directories.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func (p *StoragePlugin) ListDrives(ctx context.Context, req *storage_proto.ListDrivesRequest) (*storage_proto.ListDrivesResponse, error) {
+	return &storage_proto.ListDrivesResponse{
+		Drives: []*storage_proto.Drive{
+			{Id: "/", Name: "Local Filesystem", Type: "local"},
+		},
+	}, nil
+}
+
+func (p *StoragePlugin) GetMetadata(ctx context.Context, req *storage_proto.MetadataRequest) (*storage_proto.MetadataResponse, error) {
+	return &storage_proto.MetadataResponse{
+		Name:               "local",
+		Version:            Version,
+		Type:               "storage",
+		SupportedProviders: []string{},
+		Capabilities: &storage_proto.Capabilities{
+			Move:       true,
+			Copy:       true,
+			RangedRead: true,
+			Delta:      true,
+		},
+	}, nil
+}
+
+// Delta walks the tree and reports every entry modified after the cursor was issued. The
+// filesystem keeps no record of deletions, so a removed entry surfaces only as a change
+// to its parent directory.
+func (p *StoragePlugin) Delta(req *storage_proto.DeltaRequest, stream storage_proto.StorageService_DeltaServer) error {
+	var since time.Time
+	if req.Cursor != "" {
+		v, ok := strings.CutPrefix(req.Cursor, cursorPrefix)
+		n, err := strconv.ParseInt(v, 10, 64)
+		if !ok || err != nil {
+			return status.Error(codes.FailedPrecondition, "cursor expired: unrecognized cursor")
+		}
+		since = time.Unix(0, n)
+	}
+
+	start := time.Now()
+	root := p.getPath(req.Options, req.Path)
+	batch := make([]*storage_proto.Change, 0, deltaBatchSize)
+
+	err := filepath.WalkDir(root, func(full string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := stream.Context().Err(); err != nil {
+			return err
+		}
+		if full == root {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			// Removed since it was listed.
+			return nil
+		}
+		if !info.ModTime().After(since) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, full)
+		if err != nil {
+			return err
+		}
+		batch = append(batch, &storage_proto.Change{Node: p.toProtoNode(info, filepath.Join(req.Path, rel))})
+		if len(batch) == deltaBatchSize {
+			if err := stream.Send(&storage_proto.DeltaResponse{Changes: batch}); err != nil {
+				return err
+			}
+			batch = make([]*storage_proto.Change, 0, deltaBatchSize)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return stream.Send(&storage_proto.DeltaResponse{
+		Changes: batch,
+		Cursor:  cursorPrefix + strconv.FormatInt(start.UnixNano(), 10),
+	})
+}
+
+func (p *StoragePlugin) toProtoNode(info os.FileInfo, path string) *storage_proto.Node {
+	t := storage_proto.NodeType_FILE
+	if info.IsDir() {
+		t = storage_proto.NodeType_DIRECTORY
+	}
+	node := &storage_proto.Node{Name: info.Name(), Path: path, Type: t, Size: info.Size(), ModifiedAt: info.ModTime().Unix()}
+	if !info.IsDir() {
+		// The filesystem keeps no version, so a file's ETag changes with its
+		// modification time or size.
+		node.Etag = strconv.FormatInt(info.ModTime().UnixNano(), 10) + "-" + strconv.FormatInt(info.Size(), 10)
+	}
+	return node
+}
@@ -0,0 +1,131 @@
+package local
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	coreerrors "github.com/michaeldcanady/go-onedrive/internal/core/errors"
+	"github.com/michaeldcanady/go-onedrive/internal/core/plugins"
+	storage_proto "github.com/michaeldcanady/go-onedrive/internal/features/plugins/proto/storage"
+	"github.com/michaeldcanady/go-onedrive/internal/features/plugins/storage/storagetest"
+	"github.com/michaeldcanady/go-onedrive/pkg/pluginsdk"
+)
+
+func TestConformance(t *testing.T) {
+	client := storagetest.Serve(t, &StoragePlugin{}, plugins.CustomGRPCServerWithErrors(pluginsdk.ToStatus))
+	storagetest.Run(t, client, map[string]string{"root_path": t.TempDir()})
+}
+
+type fakeDeltaStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	msgs []*storage_proto.DeltaResponse
+}
+
+func (s *fakeDeltaStream) Context() context.Context { return s.ctx }
+
+func (s *fakeDeltaStream) Send(resp *storage_proto.DeltaResponse) error {
+	s.msgs = append(s.msgs, resp)
+	return nil
+}
+
+// paths returns the path of every change sent on s.
+func (s *fakeDeltaStream) paths() []string {
+	var paths []string
+	for _, m := range s.msgs {
+		for _, c := range m.Changes {
+			paths = append(paths, c.Node.Path)
+		}
+	}
+	return paths
+}
+
+// cursor returns the cursor sent with the final message on s.
+func (s *fakeDeltaStream) cursor() string {
+	if len(s.msgs) == 0 {
+		return ""
+	}
+	return s.msgs[len(s.msgs)-1].Cursor
+}
+
+func TestStoragePlugin_Delta(t *testing.T) {
+	p := NewStoragePlugin()
+
+	// setup creates a tree whose entries were all last modified an hour ago.
+	setup := func(t *testing.T) (string, map[string]string) {
+		root := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(root, "docs"), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(root, "a.txt"), []byte("a"), 0o644))
+		require.NoError(t, os.WriteFile(filepath.Join(root, "docs", "b.txt"), []byte("b"), 0o644))
+
+		old := time.Now().Add(-time.Hour)
+		for _, name := range []string{"docs/b.txt", "docs", "a.txt"} {
+			require.NoError(t, os.Chtimes(filepath.Join(root, name), old, old))
+		}
+		return root, map[string]string{"root_path": root}
+	}
+
+	t.Run("without a cursor every entry is reported", func(t *testing.T) {
+		_, opts := setup(t)
+		stream := &fakeDeltaStream{ctx: context.Background()}
+		require.NoError(t, p.Delta(&storage_proto.DeltaRequest{Path: "/", Options: opts}, stream))
+
+		assert.ElementsMatch(t, []string{"/a.txt", "/docs", "/docs/b.txt"}, stream.paths())
+		assert.Regexp(t, `^mtime:\d+$`, stream.cursor())
+	})
+
+	t.Run("the returned cursor reports nothing until entries change", func(t *testing.T) {
+		_, opts := setup(t)
+		first := &fakeDeltaStream{ctx: context.Background()}
+		require.NoError(t, p.Delta(&storage_proto.DeltaRequest{Path: "/", Options: opts}, first))
+
+		second := &fakeDeltaStream{ctx: context.Background()}
+		require.NoError(t, p.Delta(&storage_proto.DeltaRequest{Path: "/", Options: opts, Cursor: first.cursor()}, second))
+		assert.Empty(t, second.paths())
+		assert.NotEmpty(t, second.cursor())
+	})
+
+	t.Run("entries modified after the cursor are reported", func(t *testing.T) {
+		root, opts := setup(t)
+		cursor := cursorPrefix + strconv.FormatInt(time.Now().Add(-time.Minute).UnixNano(), 10)
+
+		now := time.Now()
+		require.NoError(t, os.Chtimes(filepath.Join(root, "a.txt"), now, now))
+		require.NoError(t, os.Remove(filepath.Join(root, "docs", "b.txt")))
+
+		stream := &fakeDeltaStream{ctx: context.Background()}
+		require.NoError(t, p.Delta(&storage_proto.DeltaRequest{Path: "/", Options: opts, Cursor: cursor}, stream))
+
+		// The removal surfaces as a change to its parent directory.
+		assert.ElementsMatch(t, []string{"/a.txt", "/docs"}, stream.paths())
+	})
+
+	t.Run("paths are relative to the mount", func(t *testing.T) {
+		_, opts := setup(t)
+		stream := &fakeDeltaStream{ctx: context.Background()}
+		require.NoError(t, p.Delta(&storage_proto.DeltaRequest{Path: "/docs", Options: opts}, stream))
+
+		assert.Equal(t, []string{"/docs/b.txt"}, stream.paths())
+	})
+
+	t.Run("expired cursor", func(t *testing.T) {
+		_, opts := setup(t)
+		for _, cursor := range []string{"bogus", cursorPrefix + "bogus"} {
+			stream := &fakeDeltaStream{ctx: context.Background()}
+			err := p.Delta(&storage_proto.DeltaRequest{Path: "/", Options: opts, Cursor: cursor}, stream)
+			assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+			assert.ErrorIs(t, plugins.FromGRPC(err), coreerrors.ErrCursorExpired)
+			assert.Empty(t, stream.msgs)
+		}
+	})
+}
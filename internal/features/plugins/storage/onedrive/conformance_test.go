@@ -0,0 +1,345 @@
+package onedrive
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/michaeldcanady/go-onedrive/internal/core/plugins"
+	"github.com/michaeldcanady/go-onedrive/internal/features/plugins/storage/storagetest"
+)
+
+func TestConformance(t *testing.T) {
+	graph := newFakeGraph()
+	srv := httptest.NewServer(graph)
+	t.Cleanup(srv.Close)
+	graph.baseURL = srv.URL
+
+	p := &StoragePlugin{baseURL: srv.URL, httpClient: srv.Client(), copyPollInterval: time.Millisecond}
+	client := storagetest.Serve(t, p, plugins.CustomGRPCServerWithErrors(ToStatus))
+	storagetest.Run(t, client, map[string]string{"token": "t"})
+}
+
+// fakeItem is a drive item held by fakeGraph.
+type fakeItem struct {
+	id       string
+	dir      bool
+	content  []byte
+	version  int
+	modified time.Time
+}
+
+// fakeGraph emulates the subset of the Graph drive API the plugin uses against a single
+// in-memory drive, addressing items by path ("root:/a/b:") or by ID.
+type fakeGraph struct {
+	baseURL string
+
+	mu       sync.Mutex
+	items    map[string]*fakeItem // by cleaned path
+	sessions map[string]string    // upload session ID to item path
+	uploads  map[string][]byte    // upload session ID to the bytes received
+	nextID   int
+}
+
+func newFakeGraph() *fakeGraph {
+	return &fakeGraph{
+		items:    map[string]*fakeItem{"/": {id: "root", dir: true, modified: time.Now()}},
+		sessions: make(map[string]string),
+		uploads:  make(map[string][]byte),
+	}
+}
+
+func (g *fakeGraph) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/drives/root/items/"):
+		g.serveItem(w, r, strings.TrimPrefix(r.URL.Path, "/drives/root/items/"))
+	case strings.HasPrefix(r.URL.Path, "/upload/"):
+		g.serveUpload(w, r, strings.TrimPrefix(r.URL.Path, "/upload/"))
+	case strings.HasPrefix(r.URL.Path, "/download/"):
+		g.serveDownload(w, r, strings.TrimPrefix(r.URL.Path, "/download/"))
+	case strings.HasPrefix(r.URL.Path, "/monitor/"):
+		writeJSON(w, http.StatusOK, map[string]any{"status": "completed", "resourceId": strings.TrimPrefix(r.URL.Path, "/monitor/")})
+	default:
+		graphError(w, http.StatusNotFound, "itemNotFound")
+	}
+}
+
+// serveItem handles requests for an item reference, which is "root", "root:/<path>:" or
+// an item ID, optionally followed by an action.
+func (g *fakeGraph) serveItem(w http.ResponseWriter, r *http.Request, ref string) {
+	var p, action string
+	if rest, ok := strings.CutPrefix(ref, "root:"); ok {
+		end := strings.Index(rest, ":")
+		if end < 0 {
+			graphError(w, http.StatusBadRequest, "invalidRequest")
+			return
+		}
+		p, action = path.Clean("/"+rest[:end]), strings.TrimPrefix(rest[end+1:], "/")
+	} else {
+		id, rest, _ := strings.Cut(ref, "/")
+		p, action = g.pathOf(id), rest
+	}
+
+	item := g.items[p]
+	switch {
+	case r.Method == http.MethodGet && action == "":
+		if item == nil {
+			graphError(w, http.StatusNotFound, "itemNotFound")
+			return
+		}
+		writeJSON(w, http.StatusOK, g.itemJSON(p, item))
+	case r.Method == http.MethodGet && action == "children":
+		if item == nil || !item.dir {
+			graphError(w, http.StatusNotFound, "itemNotFound")
+			return
+		}
+		var children []any
+		for _, child := range g.children(p) {
+			children = append(children, g.itemJSON(child, g.items[child]))
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"value": children})
+	case r.Method == http.MethodPost && action == "children":
+		var body struct {
+			Name     string `json:"name"`
+			Conflict string `json:"@microsoft.graph.conflictBehavior"`
+		}
+		decodeBody(r, &body)
+		if item == nil || !item.dir {
+			graphError(w, http.StatusNotFound, "itemNotFound")
+			return
+		}
+		child := path.Join(p, body.Name)
+		if g.items[child] != nil && body.Conflict == "fail" {
+			graphError(w, http.StatusConflict, "nameAlreadyExists")
+			return
+		}
+		writeJSON(w, http.StatusCreated, g.itemJSON(child, g.put(child, true, nil)))
+	case r.Method == http.MethodDelete && action == "":
+		if item == nil {
+			graphError(w, http.StatusNotFound, "itemNotFound")
+			return
+		}
+		for _, p := range g.tree(p) {
+			delete(g.items, p)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut && action == "content":
+		if !g.matches(w, r, item) {
+			return
+		}
+		content, _ := io.ReadAll(r.Body)
+		writeJSON(w, http.StatusCreated, g.itemJSON(p, g.put(p, false, content)))
+	case r.Method == http.MethodPost && action == "createUploadSession":
+		if !g.matches(w, r, item) {
+			return
+		}
+		id := g.newID()
+		g.sessions[id] = p
+		writeJSON(w, http.StatusOK, map[string]any{"uploadUrl": g.baseURL + "/upload/" + id})
+	case r.Method == http.MethodPost && action == "copy":
+		var body struct {
+			Name   string `json:"name"`
+			Parent struct {
+				ID string `json:"id"`
+			} `json:"parentReference"`
+		}
+		decodeBody(r, &body)
+		if item == nil || g.items[g.pathOf(body.Parent.ID)] == nil {
+			graphError(w, http.StatusNotFound, "itemNotFound")
+			return
+		}
+		dst := path.Join(g.pathOf(body.Parent.ID), body.Name)
+		for _, old := range g.tree(dst) {
+			delete(g.items, old)
+		}
+		for _, src := range g.tree(p) {
+			from := g.items[src]
+			g.put(dst+strings.TrimPrefix(src, p), from.dir, from.content)
+		}
+		w.Header().Set("Location", g.baseURL+"/monitor/"+g.items[dst].id)
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		graphError(w, http.StatusBadRequest, "invalidRequest")
+	}
+}
+
+// matches checks the If-Match header against the item's ETag.
+func (g *fakeGraph) matches(w http.ResponseWriter, r *http.Request, item *fakeItem) bool {
+	if etag := r.Header.Get("If-Match"); etag != "" && (item == nil || etag != item.etag()) {
+		graphError(w, http.StatusPreconditionFailed, "resourceModified")
+		return false
+	}
+	return true
+}
+
+// serveUpload accepts the chunks of an upload session, creating the file once every
+// byte has arrived.
+func (g *fakeGraph) serveUpload(w http.ResponseWriter, r *http.Request, id string) {
+	p, ok := g.sessions[id]
+	if !ok {
+		graphError(w, http.StatusNotFound, "itemNotFound")
+		return
+	}
+	if r.Method == http.MethodDelete {
+		delete(g.sessions, id)
+		delete(g.uploads, id)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	var start, end, size int64
+	if _, err := fmt.Sscanf(r.Header.Get("Content-Range"), "bytes %d-%d/%d", &start, &end, &size); err != nil || start != int64(len(g.uploads[id])) {
+		graphError(w, http.StatusRequestedRangeNotSatisfiable, "invalidRange")
+		return
+	}
+	chunk, _ := io.ReadAll(r.Body)
+	g.uploads[id] = append(g.uploads[id], chunk...)
+	if received := int64(len(g.uploads[id])); received < size {
+		writeJSON(w, http.StatusAccepted, map[string]any{"nextExpectedRanges": []string{strconv.FormatInt(received, 10) + "-"}})
+		return
+	}
+	item := g.put(p, false, g.uploads[id])
+	delete(g.sessions, id)
+	delete(g.uploads, id)
+	writeJSON(w, http.StatusCreated, g.itemJSON(p, item))
+}
+
+// serveDownload serves file content, honoring single byte ranges.
+func (g *fakeGraph) serveDownload(w http.ResponseWriter, r *http.Request, id string) {
+	item := g.items[g.pathOf(id)]
+	if item == nil {
+		graphError(w, http.StatusNotFound, "itemNotFound")
+		return
+	}
+	content := item.content
+	rng := r.Header.Get("Range")
+	if rng == "" {
+		w.Write(content)
+		return
+	}
+	var start, end int64
+	end = int64(len(content)) - 1
+	if _, err := fmt.Sscanf(rng, "bytes=%d-%d", &start, &end); err != nil && !strings.HasSuffix(rng, "-") {
+		graphError(w, http.StatusBadRequest, "invalidRange")
+		return
+	}
+	if start >= int64(len(content)) {
+		w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+		return
+	}
+	end = min(end, int64(len(content))-1)
+	w.WriteHeader(http.StatusPartialContent)
+	w.Write(content[start : end+1])
+}
+
+// put creates or replaces the item at p, creating missing parents as Graph does for
+// uploads addressed by path.
+func (g *fakeGraph) put(p string, dir bool, content []byte) *fakeItem {
+	if parent := path.Dir(p); g.items[parent] == nil {
+		g.put(parent, true, nil)
+	}
+	item := g.items[p]
+	if item == nil {
+		item = &fakeItem{id: g.newID()}
+		g.items[p] = item
+	}
+	item.dir, item.content, item.modified = dir, content, time.Now()
+	item.version++
+	return item
+}
+
+func (g *fakeGraph) newID() string {
+	g.nextID++
+	return "item-" + strconv.Itoa(g.nextID)
+}
+
+func (g *fakeGraph) pathOf(id string) string {
+	for p, item := range g.items {
+		if item.id == id {
+			return p
+		}
+	}
+	return ""
+}
+
+// children returns the paths of the direct children of p, sorted.
+func (g *fakeGraph) children(p string) []string {
+	var children []string
+	for child := range g.items {
+		if child != "/" && path.Dir(child) == p {
+			children = append(children, child)
+		}
+	}
+	sort.Strings(children)
+	return children
+}
+
+// tree returns p and the paths of everything beneath it.
+func (g *fakeGraph) tree(p string) []string {
+	if g.items[p] == nil {
+		return nil
+	}
+	paths := []string{p}
+	for _, child := range g.children(p) {
+		paths = append(paths, g.tree(child)...)
+	}
+	return paths
+}
+
+func (i *fakeItem) etag() string {
+	return fmt.Sprintf(`"{%s},%d"`, i.id, i.version)
+}
+
+func (g *fakeGraph) itemJSON(p string, item *fakeItem) map[string]any {
+	v := map[string]any{
+		"id":                   item.id,
+		"eTag":                 item.etag(),
+		"cTag":                 item.etag(),
+		"lastModifiedDateTime": item.modified.UTC().Format(time.RFC3339),
+		"parentReference":      map[string]any{"driveId": "drive-id"},
+	}
+	if p != "/" {
+		v["name"] = path.Base(p)
+		v["parentReference"] = map[string]any{"driveId": "drive-id", "id": g.items[path.Dir(p)].id}
+	}
+	if item.dir {
+		v["folder"] = map[string]any{"childCount": len(g.children(p))}
+	} else {
+		v["file"] = map[string]any{}
+		v["size"] = len(item.content)
+		v["@microsoft.graph.downloadUrl"] = g.baseURL + "/download/" + item.id
+	}
+	return v
+}
+
+// decodeBody decodes a JSON request body, which Graph clients may gzip.
+func decodeBody(r *http.Request, v any) {
+	var body io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		body, _ = gzip.NewReader(r.Body)
+	}
+	json.NewDecoder(body).Decode(v)
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+func graphError(w http.ResponseWriter, code int, errCode string) {
+	writeJSON(w, code, map[string]any{"error": map[string]any{"code": errCode, "message": errCode}})
+}
@@ -0,0 +1,148 @@
+package onedrive
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"time"
+
+	abstractions "github.com/microsoft/kiota-abstractions-go"
+	nethttplibrary "github.com/microsoft/kiota-http-go"
+	msgraphdrives "github.com/microsoftgraph/msgraph-sdk-go/drives"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+
+	storage_proto "github.com/michaeldcanady/go-onedrive/internal/features/plugins/proto/storage"
+)
+
+// defaultCopyPollInterval is the delay between checks of an asynchronous copy's monitor URL.
+const defaultCopyPollInterval = time.Second
+
+// copyStatus is the body returned by a copy monitor URL.
+type copyStatus struct {
+	Status     string `json:"status"`
+	ResourceID string `json:"resourceId"`
+	Error      *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Copy duplicates an item with the Graph copy action, so the content never leaves
+// OneDrive. Graph copies asynchronously; the returned monitor URL is polled until the
+// copy completes. An existing item at the destination is replaced.
+func (p *StoragePlugin) Copy(ctx context.Context, req *storage_proto.CopyRequest) (*storage_proto.CopyResponse, error) {
+	c, err := p.getClient(req.Options)
+	if err != nil {
+		return nil, err
+	}
+	items := c.Drives().ByDriveId(p.getDriveID(req.Options)).Items()
+
+	parent, err := items.ByDriveItemId(p.resolvePath(path.Dir(req.Destination))).Get(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	ref := models.NewItemReference()
+	ref.SetId(parent.GetId())
+	if pr := parent.GetParentReference(); pr != nil {
+		ref.SetDriveId(pr.GetDriveId())
+	}
+	body := msgraphdrives.NewItemItemsItemCopyPostRequestBody()
+	body.SetParentReference(ref)
+	name := path.Base(req.Destination)
+	body.SetName(&name)
+
+	builder := items.ByDriveItemId(p.resolvePath(req.Source)).Copy()
+	info, err := builder.ToPostRequestInformation(ctx, body, nil)
+	if err != nil {
+		return nil, err
+	}
+	u, err := info.GetUri()
+	if err != nil {
+		return nil, err
+	}
+	q := u.Query()
+	q.Set("@microsoft.graph.conflictBehavior", "replace")
+	u.RawQuery = q.Encode()
+
+	// The monitor URL is only returned in the Location header.
+	inspect := nethttplibrary.NewHeadersInspectionOptions()
+	inspect.InspectResponseHeaders = true
+	cfg := &msgraphdrives.ItemItemsItemCopyRequestBuilderPostRequestConfiguration{Options: []abstractions.RequestOption{inspect}}
+	if _, err := builder.WithUrl(u.String()).Post(ctx, body, cfg); err != nil {
+		return nil, err
+	}
+
+	if location := inspect.GetResponseHeaders().Get("Location"); len(location) > 0 {
+		if err := p.waitForCopy(ctx, location[0]); err != nil {
+			return nil, fmt.Errorf("copy %s to %s: %w", req.Source, req.Destination, err)
+		}
+	}
+
+	item, err := items.ByDriveItemId(p.resolvePath(req.Destination)).Get(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &storage_proto.CopyResponse{Node: p.toProtoNode(item, req.Destination)}, nil
+}
+
+// waitForCopy polls a pre-authenticated copy monitor URL until the copy completes or
+// fails. A redirect to the new item also signals completion.
+func (p *StoragePlugin) waitForCopy(ctx context.Context, monitorURL string) error {
+	client := http.Client{Transport: http.DefaultTransport}
+	if p.httpClient != nil {
+		client = *p.httpClient
+	}
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	interval := p.copyPollInterval
+	if interval <= 0 {
+		interval = defaultCopyPollInterval
+	}
+
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, monitorURL, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+
+		var st copyStatus
+		switch resp.StatusCode {
+		case http.StatusSeeOther:
+			resp.Body.Close()
+			return nil
+		case http.StatusOK, http.StatusAccepted:
+			err = json.NewDecoder(resp.Body).Decode(&st)
+			resp.Body.Close()
+			if err != nil {
+				return fmt.Errorf("invalid copy status: %w", err)
+			}
+		default:
+			resp.Body.Close()
+			return newStatusError(resp)
+		}
+
+		switch st.Status {
+		case "completed":
+			return nil
+		case "failed", "cancelled":
+			if st.Error != nil {
+				return fmt.Errorf("%s: %s", st.Error.Code, st.Error.Message)
+			}
+			return errors.New("copy " + st.Status)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package onedrive
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	storage_proto "github.com/michaeldcanady/go-onedrive/internal/features/plugins/proto/storage"
+)
+
+// fakeCopyServer emulates the Graph copy action and its monitor URL.
+type fakeCopyServer struct {
+	mu      sync.Mutex
+	body    map[string]any
+	query   string
+	polls   int
+	pending int    // polls answered with inProgress before the final status
+	final   string // "completed", "redirect" or "failed"
+	// monitorAuth records any Authorization header sent to the monitor URL.
+	monitorAuth string
+}
+
+func (f *fakeCopyServer) handler(baseURL func() string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/drives/root/items/root:/dst:":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"id":"parent-id","name":"dst","folder":{},"parentReference":{"driveId":"drive-id"}}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/drives/root/items/root:/src.txt:/copy":
+			f.query = r.URL.RawQuery
+			var body io.Reader = r.Body
+			if r.Header.Get("Content-Encoding") == "gzip" {
+				body, _ = gzip.NewReader(r.Body)
+			}
+			json.NewDecoder(body).Decode(&f.body)
+			w.Header().Set("Location", baseURL()+"/monitor")
+			w.WriteHeader(http.StatusAccepted)
+		case r.Method == http.MethodGet && r.URL.Path == "/monitor":
+			f.monitorAuth += r.Header.Get("Authorization")
+			f.polls++
+			w.Header().Set("Content-Type", "application/json")
+			switch {
+			case f.polls <= f.pending:
+				w.WriteHeader(http.StatusAccepted)
+				fmt.Fprint(w, `{"status":"inProgress","percentageComplete":50}`)
+			case f.final == "redirect":
+				w.Header().Set("Location", baseURL()+"/drives/root/items/copy-id")
+				w.WriteHeader(http.StatusSeeOther)
+			case f.final == "failed":
+				fmt.Fprint(w, `{"status":"failed","error":{"code":"nameAlreadyExists","message":"name conflict"}}`)
+			default:
+				fmt.Fprint(w, `{"status":"completed","resourceId":"copy-id"}`)
+			}
+		case r.Method == http.MethodGet && r.URL.Path == "/drives/root/items/root:/dst/copy.txt:":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"id":"copy-id","name":"copy.txt","size":5,"eTag":"e1"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+}
+
+func TestOneDriveStoragePlugin_Copy(t *testing.T) {
+	cases := []struct {
+		name      string
+		pending   int
+		final     string
+		wantErr   string
+		wantPolls int
+	}{
+		{name: "completes after polling", pending: 2, final: "completed", wantPolls: 3},
+		{name: "redirect signals completion", pending: 1, final: "redirect", wantPolls: 2},
+		{name: "failed copy", final: "failed", wantErr: "nameAlreadyExists: name conflict", wantPolls: 1},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeCopyServer{pending: tt.pending, final: tt.final}
+			var srv *httptest.Server
+			srv = httptest.NewServer(fake.handler(func() string { return srv.URL }))
+			defer srv.Close()
+
+			p := &StoragePlugin{baseURL: srv.URL, httpClient: srv.Client(), copyPollInterval: time.Millisecond}
+			resp, err := p.Copy(context.Background(), &storage_proto.CopyRequest{
+				Source:      "/src.txt",
+				Destination: "/dst/copy.txt",
+				Options:     map[string]string{"token": "t"},
+			})
+
+			assert.Equal(t, tt.wantPolls, fake.polls)
+			assert.Empty(t, fake.monitorAuth, "monitor URLs are pre-authenticated")
+			assert.Equal(t, "%40microsoft.graph.conflictBehavior=replace", fake.query)
+			assert.Equal(t, "copy.txt", fake.body["name"])
+			assert.Equal(t, map[string]any{"driveId": "drive-id", "id": "parent-id"}, fake.body["parentReference"])
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.True(t, strings.Contains(err.Error(), tt.wantErr), err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "copy.txt", resp.Node.Name)
+			assert.Equal(t, "/dst/copy.txt", resp.Node.Path)
+			assert.Equal(t, "e1", resp.Node.Etag)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package onedrive
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	storage_proto "github.com/michaeldcanady/go-onedrive/internal/features/plugins/proto/storage"
+)
+
+// Delta follows the Graph delta feed for the item at req.Path. Each page is sent as it
+// arrives, and the final message carries the delta link as the cursor.
+func (p *StoragePlugin) Delta(req *storage_proto.DeltaRequest, stream storage_proto.StorageService_DeltaServer) error {
+	c, err := p.getClient(req.Options)
+	if err != nil {
+		return err
+	}
+
+	builder := c.Drives().ByDriveId(p.getDriveID(req.Options)).Items().ByDriveItemId(p.resolvePath(req.Path)).Delta()
+	if req.Cursor != "" {
+		builder = builder.WithUrl(req.Cursor)
+	}
+
+	for {
+		page, err := builder.GetAsDeltaGetResponse(stream.Context(), nil)
+		if err != nil {
+			if isGone(err) {
+				return status.Error(codes.FailedPrecondition, "cursor expired: resync required")
+			}
+			return err
+		}
+
+		changes := make([]*storage_proto.Change, 0, len(page.GetValue()))
+		for _, item := range page.GetValue() {
+			changes = append(changes, &storage_proto.Change{
+				Node:    p.toProtoNode(item, itemPath(item)),
+				Deleted: item.GetDeleted() != nil,
+			})
+		}
+
+		if next := page.GetOdataNextLink(); next != nil && *next != "" {
+			if err := stream.Send(&storage_proto.DeltaResponse{Changes: changes}); err != nil {
+				return err
+			}
+			builder = builder.WithUrl(*next)
+			continue
+		}
+
+		link := page.GetOdataDeltaLink()
+		if link == nil || *link == "" {
+			return fmt.Errorf("delta response for %s has no next or delta link", req.Path)
+		}
+		return stream.Send(&storage_proto.DeltaResponse{Changes: changes, Cursor: *link})
+	}
+}
+
+// itemPath derives an item's drive-relative path from its parent reference, which has
+// the form "/drive/root:/parent". Deleted items may omit the reference, in which case
+// the path is empty.
+func itemPath(item models.DriveItemable) string {
+	if item.GetRoot() != nil {
+		return "/"
+	}
+	ref := item.GetParentReference()
+	if ref == nil || ref.GetPath() == nil || item.GetName() == nil {
+		return ""
+	}
+	_, parent, ok := strings.Cut(*ref.GetPath(), "root:")
+	if !ok {
+		return ""
+	}
+	return path.Join("/", parent, *item.GetName())
+}
+
+// isGone reports whether Graph rejected a delta link as expired.
+func isGone(err error) bool {
+	var apiErr interface{ GetStatusCode() int }
+	return errors.As(err, &apiErr) && apiErr.GetStatusCode() == http.StatusGone
+}
@@ -0,0 +1,89 @@
+package onedrive
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	storage_proto "github.com/michaeldcanady/go-onedrive/internal/features/plugins/proto/storage"
+)
+
+type fakeDeltaStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	msgs []*storage_proto.DeltaResponse
+}
+
+func (s *fakeDeltaStream) Context() context.Context { return s.ctx }
+
+func (s *fakeDeltaStream) Send(resp *storage_proto.DeltaResponse) error {
+	s.msgs = append(s.msgs, resp)
+	return nil
+}
+
+func TestOneDriveStoragePlugin_Delta(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/delta()") || strings.HasSuffix(r.URL.Path, "/delta"):
+			fmt.Fprintf(w, `{"value":[
+				{"id":"root","name":"root","root":{},"folder":{}},
+				{"id":"1","name":"a.txt","size":3,"cTag":"c1","parentReference":{"path":"/drive/root:"}}
+			],"@odata.nextLink":%q}`, srv.URL+"/page2")
+		case r.URL.Path == "/page2":
+			fmt.Fprintf(w, `{"value":[
+				{"id":"2","name":"b.txt","parentReference":{"path":"/drive/root:/docs"}},
+				{"id":"3","deleted":{"state":"deleted"}}
+			],"@odata.deltaLink":%q}`, srv.URL+"/next-delta")
+		case r.URL.Path == "/expired":
+			w.WriteHeader(http.StatusGone)
+			fmt.Fprint(w, `{"error":{"code":"resyncRequired","message":"resync required"}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	p := &StoragePlugin{baseURL: srv.URL, httpClient: srv.Client()}
+
+	t.Run("follows pages and returns the delta link", func(t *testing.T) {
+		stream := &fakeDeltaStream{ctx: context.Background()}
+		err := p.Delta(&storage_proto.DeltaRequest{Path: "/", Options: map[string]string{"token": "t"}}, stream)
+		assert.NoError(t, err)
+		if !assert.Len(t, stream.msgs, 2) {
+			return
+		}
+
+		assert.Empty(t, stream.msgs[0].Cursor)
+		assert.Equal(t, srv.URL+"/next-delta", stream.msgs[1].Cursor)
+
+		var paths []string
+		var deleted []string
+		for _, m := range stream.msgs {
+			for _, c := range m.Changes {
+				paths = append(paths, c.Node.Path)
+				if c.Deleted {
+					deleted = append(deleted, c.Node.Id)
+				}
+			}
+		}
+		assert.Equal(t, []string{"/", "/a.txt", "/docs/b.txt", ""}, paths)
+		assert.Equal(t, []string{"3"}, deleted)
+	})
+
+	t.Run("expired cursor", func(t *testing.T) {
+		stream := &fakeDeltaStream{ctx: context.Background()}
+		err := p.Delta(&storage_proto.DeltaRequest{Path: "/", Options: map[string]string{"token": "t"}, Cursor: srv.URL + "/expired"}, stream)
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+		assert.Contains(t, err.Error(), "cursor expired")
+	})
+}
@@ -0,0 +1,117 @@
+package onedrive
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	coreerrors "github.com/michaeldcanady/go-onedrive/internal/core/errors"
+	"github.com/michaeldcanady/go-onedrive/internal/core/plugins"
+	storage_proto "github.com/michaeldcanady/go-onedrive/internal/features/plugins/proto/storage"
+)
+
+type fakeReadStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	chunks [][]byte
+}
+
+func (s *fakeReadStream) Context() context.Context { return s.ctx }
+
+func (s *fakeReadStream) Send(resp *storage_proto.ReadResponse) error {
+	s.chunks = append(s.chunks, append([]byte(nil), resp.Chunk...))
+	return nil
+}
+
+func TestOneDriveStoragePlugin_Read(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), readChunkSize/5)
+
+	tests := []struct {
+		name   string
+		offset int64
+		length int64
+		want   []byte
+	}{
+		{
+			name: "streams whole file in bounded chunks",
+			want: content,
+		},
+		{
+			name:   "offset only",
+			offset: 5,
+			want:   content[5:],
+		},
+		{
+			name:   "offset and length",
+			offset: 10,
+			length: 20,
+			want:   content[10:30],
+		},
+		{
+			name:   "offset past end of file",
+			offset: int64(len(content)) + 10,
+			want:   []byte{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var srv *httptest.Server
+			srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.URL.Path == "/download/file.bin":
+					http.ServeContent(w, r, "file.bin", time.Time{}, bytes.NewReader(content))
+				case strings.Contains(r.URL.Path, "/items/"):
+					w.Header().Set("Content-Type", "application/json")
+					fmt.Fprintf(w, `{"id":"1","name":"file.bin","size":%d,"@microsoft.graph.downloadUrl":%q}`, len(content), srv.URL+"/download/file.bin")
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer srv.Close()
+
+			p := &StoragePlugin{baseURL: srv.URL, httpClient: srv.Client()}
+			stream := &fakeReadStream{ctx: context.Background()}
+
+			err := p.Read(&storage_proto.ReadRequest{
+				Path:    "/file.bin",
+				Options: map[string]string{"token": "t"},
+				Offset:  tt.offset,
+				Length:  tt.length,
+			}, stream)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, bytes.Join(stream.chunks, nil))
+			for _, c := range stream.chunks {
+				assert.LessOrEqual(t, len(c), readChunkSize)
+			}
+		})
+	}
+}
+
+func TestOneDriveStoragePlugin_Read_Folder(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":"1","name":"docs","folder":{"childCount":2}}`)
+	}))
+	defer srv.Close()
+
+	p := &StoragePlugin{baseURL: srv.URL, httpClient: srv.Client()}
+	err := p.Read(&storage_proto.ReadRequest{
+		Path:    "/docs",
+		Options: map[string]string{"token": "t"},
+	}, &fakeReadStream{ctx: context.Background()})
+
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	assert.ErrorIs(t, plugins.FromGRPC(err), coreerrors.ErrIsADirectory)
+}
@@ -0,0 +1,104 @@
+package onedrive
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	abstractions "github.com/microsoft/kiota-abstractions-go"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/michaeldcanady/go-onedrive/internal/core/plugins"
+)
+
+// statusError is an unexpected HTTP response from a pre-authenticated URL.
+type statusError struct {
+	statusCode int
+	status     string
+	retryAfter time.Duration
+}
+
+func newStatusError(resp *http.Response) *statusError {
+	return &statusError{
+		statusCode: resp.StatusCode,
+		status:     resp.Status,
+		retryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+	}
+}
+
+func (e *statusError) Error() string {
+	return "request returned " + e.status
+}
+
+// ToStatus translates Graph errors into the gRPC statuses the host understands: missing
+// items, name conflicts and denied access map to their codes, throttling and transient
+// server failures to retryable statuses carrying Graph's Retry-After hint, and failed
+// If-Match checks to [codes.Aborted]. Other errors are returned unchanged.
+func ToStatus(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	code, retryAfter, ok := httpStatus(err)
+	if !ok {
+		return err
+	}
+
+	switch {
+	case code == http.StatusNotFound:
+		return status.Error(codes.NotFound, err.Error())
+	case code == http.StatusConflict:
+		return status.Error(codes.AlreadyExists, err.Error())
+	case code == http.StatusUnauthorized, code == http.StatusForbidden:
+		return status.Error(codes.PermissionDenied, err.Error())
+	case code == http.StatusTooManyRequests:
+		return plugins.RetryableError(codes.ResourceExhausted, err.Error(), retryAfter)
+	case code == http.StatusServiceUnavailable, code == http.StatusBadGateway, code == http.StatusGatewayTimeout:
+		return plugins.RetryableError(codes.Unavailable, err.Error(), retryAfter)
+	case code == http.StatusPreconditionFailed:
+		return status.Error(codes.Aborted, err.Error())
+	}
+	return err
+}
+
+// httpStatus returns the HTTP status code, and any Retry-After hint, of an error response
+// from Graph or from a pre-authenticated URL.
+func httpStatus(err error) (int, time.Duration, bool) {
+	var apiErr interface {
+		GetStatusCode() int
+		GetResponseHeaders() *abstractions.ResponseHeaders
+	}
+	var se *statusError
+	var ue *uploadError
+	switch {
+	case errors.As(err, &apiErr):
+		var retryAfter time.Duration
+		if h := apiErr.GetResponseHeaders(); h != nil {
+			if v := h.Get("Retry-After"); len(v) > 0 {
+				retryAfter = parseRetryAfter(v[0])
+			}
+		}
+		return apiErr.GetStatusCode(), retryAfter, true
+	case errors.As(err, &se):
+		return se.statusCode, se.retryAfter, true
+	case errors.As(err, &ue):
+		return ue.statusCode, ue.retryAfter, true
+	}
+	return 0, 0, false
+}
+
+// parseRetryAfter reads a Retry-After header given either in seconds or as an HTTP date.
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(time.Until(t), 0)
+	}
+	return 0
+}
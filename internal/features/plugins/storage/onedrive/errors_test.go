@@ -0,0 +1,84 @@
+package onedrive
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	coreerrors "github.com/michaeldcanady/go-onedrive/internal/core/errors"
+	"github.com/michaeldcanady/go-onedrive/internal/core/plugins"
+)
+
+func TestToStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantCode   codes.Code
+		wantErr    error
+		retryAfter time.Duration
+	}{
+		{
+			name:       "throttled",
+			err:        &statusError{statusCode: http.StatusTooManyRequests, status: "429 Too Many Requests", retryAfter: 5 * time.Second},
+			wantCode:   codes.ResourceExhausted,
+			wantErr:    coreerrors.ErrThrottled,
+			retryAfter: 5 * time.Second,
+		},
+		{
+			name:     "unavailable without hint",
+			err:      &uploadError{statusCode: http.StatusServiceUnavailable, status: "503 Service Unavailable"},
+			wantCode: codes.Unavailable,
+			wantErr:  coreerrors.ErrUnavailable,
+		},
+		{
+			name:     "etag mismatch",
+			err:      &statusError{statusCode: http.StatusPreconditionFailed, status: "412 Precondition Failed"},
+			wantCode: codes.Aborted,
+			wantErr:  coreerrors.ErrPrecondition,
+		},
+		{
+			name:     "not found",
+			err:      &statusError{statusCode: http.StatusNotFound, status: "404 Not Found"},
+			wantCode: codes.NotFound,
+			wantErr:  coreerrors.ErrNotFound,
+		},
+		{
+			name:     "name conflict",
+			err:      &statusError{statusCode: http.StatusConflict, status: "409 Conflict"},
+			wantCode: codes.AlreadyExists,
+			wantErr:  coreerrors.ErrAlreadyExists,
+		},
+		{
+			name:     "other errors pass through",
+			err:      &statusError{statusCode: http.StatusBadRequest, status: "400 Bad Request"},
+			wantCode: codes.Unknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ToStatus(tt.err)
+			assert.Equal(t, tt.wantCode, status.Code(got))
+			if tt.wantErr == nil {
+				return
+			}
+
+			host := plugins.FromGRPC(got)
+			assert.True(t, errors.Is(host, tt.wantErr))
+			retryAfter, ok := coreerrors.RetryAfter(host)
+			assert.Equal(t, tt.retryAfter > 0, ok)
+			assert.Equal(t, tt.retryAfter, retryAfter)
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	assert.Equal(t, 7*time.Second, parseRetryAfter("7"))
+	assert.Equal(t, time.Duration(0), parseRetryAfter(""))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("Wed, 21 Oct 2015 07:28:00 GMT"))
+}
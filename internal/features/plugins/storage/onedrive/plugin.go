@@ -0,0 +1,380 @@
+// Package onedrive is the storage plugin for Microsoft OneDrive. odc serves it
+// in-process, and cmd/storage-plugin-onedrive serves it as a plugin binary.
+package onedrive
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	abstractions "github.com/microsoft/kiota-abstractions-go"
+	msgraph "github.com/microsoftgraph/msgraph-sdk-go"
+	msgraphdrives "github.com/microsoftgraph/msgraph-sdk-go/drives"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/michaeldcanady/go-onedrive/internal/core/plugins"
+	storage_proto "github.com/michaeldcanady/go-onedrive/internal/features/plugins/proto/storage"
+)
+
+// Version is reported through GetMetadata.
+var Version = "dev"
+
+// StoragePlugin implements the storage service for Microsoft OneDrive.
+type StoragePlugin struct {
+	storage_proto.UnimplementedStorageServiceServer
+
+	// baseURL overrides the Microsoft Graph endpoint when set.
+	baseURL string
+	// httpClient performs requests against pre-authenticated download, upload session
+	// and copy monitor URLs.
+	httpClient *http.Client
+	// chunkSize is the number of bytes sent per upload session request.
+	chunkSize int64
+	// copyPollInterval is the delay between checks on an asynchronous copy.
+	copyPollInterval time.Duration
+}
+
+// NewStoragePlugin returns a new [*StoragePlugin].
+func NewStoragePlugin() *StoragePlugin {
+	return &StoragePlugin{}
+}
+
+func (p *StoragePlugin) List(ctx context.Context, req *storage_proto.ListRequest) (*storage_proto.ListResponse, error) {
+	c, err := p.getClient(req.Options)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.Drives().ByDriveId(p.getDriveID(req.Options)).Items().ByDriveItemId(p.resolvePath(req.Path)).Children().Get(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make([]*storage_proto.Node, 0)
+	for _, item := range res.GetValue() {
+		name := ""
+		if item.GetName() != nil {
+			name = *item.GetName()
+		}
+		nodes = append(nodes, p.toProtoNode(item, filepath.Join(req.Path, name)))
+	}
+	return &storage_proto.ListResponse{Nodes: nodes}, nil
+}
+
+func (p *StoragePlugin) Stat(ctx context.Context, req *storage_proto.StatRequest) (*storage_proto.StatResponse, error) {
+	c, err := p.getClient(req.Options)
+	if err != nil {
+		return nil, err
+	}
+	item, err := c.Drives().ByDriveId(p.getDriveID(req.Options)).Items().ByDriveItemId(p.resolvePath(req.Path)).Get(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &storage_proto.StatResponse{Node: p.toProtoNode(item, req.Path)}, nil
+}
+
+// Mkdir creates the folder and any missing parents. Graph refuses to create a folder
+// over an existing item, which is reported as already existing.
+func (p *StoragePlugin) Mkdir(ctx context.Context, req *storage_proto.MkdirRequest) (*storage_proto.MkdirResponse, error) {
+	c, err := p.getClient(req.Options)
+	if err != nil {
+		return nil, err
+	}
+	item, err := p.createFolder(ctx, c, req.Options, path.Clean("/"+req.Path))
+	if err != nil {
+		return nil, err
+	}
+	return &storage_proto.MkdirResponse{Node: p.toProtoNode(item, req.Path)}, nil
+}
+
+// createFolder creates the folder at dir. When its parent is missing, the parent is
+// created first.
+func (p *StoragePlugin) createFolder(ctx context.Context, c *msgraph.GraphServiceClient, opts map[string]string, dir string) (models.DriveItemable, error) {
+	parent, name := path.Dir(dir), path.Base(dir)
+	f := models.NewDriveItem()
+	f.SetName(&name)
+	f.SetFolder(models.NewFolder())
+	f.SetAdditionalData(map[string]any{"@microsoft.graph.conflictBehavior": "fail"})
+	children := c.Drives().ByDriveId(p.getDriveID(opts)).Items().ByDriveItemId(p.resolvePath(parent)).Children()
+
+	item, err := children.Post(ctx, f, nil)
+	if code, _, _ := httpStatus(err); code == http.StatusNotFound && parent != "/" {
+		if _, err := p.createFolder(ctx, c, opts, parent); err != nil {
+			if code, _, _ := httpStatus(err); code != http.StatusConflict {
+				return nil, err
+			}
+		}
+		item, err = children.Post(ctx, f, nil)
+	}
+	return item, err
+}
+
+func (p *StoragePlugin) Read(req *storage_proto.ReadRequest, stream storage_proto.StorageService_ReadServer) error {
+	c, err := p.getClient(req.Options)
+	if err != nil {
+		return err
+	}
+	item, err := c.Drives().ByDriveId(p.getDriveID(req.Options)).Items().ByDriveItemId(p.resolvePath(req.Path)).Get(stream.Context(), nil)
+	if err != nil {
+		return err
+	}
+	if item.GetFolder() != nil {
+		return status.Error(codes.FailedPrecondition, req.Path+": is a directory")
+	}
+	url, ok := downloadURL(item)
+	if !ok {
+		return fmt.Errorf("no download url for %s", req.Path)
+	}
+
+	body, err := p.download(stream.Context(), url, req.Offset, req.Length)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	return sendChunks(stream, body)
+}
+
+func (p *StoragePlugin) Write(stream storage_proto.StorageService_WriteServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	c, err := p.getClient(req.Options)
+	if err != nil {
+		return err
+	}
+	chunkSize, err := p.uploadChunkSize(req.Options)
+	if err != nil {
+		return err
+	}
+
+	// Spool the stream to disk so memory use stays bounded and any range the
+	// upload session asks for again can be re-read.
+	f, err := os.CreateTemp("", "odc-upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	size, err := f.Write(req.Chunk)
+	if err != nil {
+		return err
+	}
+	total := int64(size)
+	for {
+		m, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		n, err := f.Write(m.Chunk)
+		if err != nil {
+			return err
+		}
+		total += int64(n)
+	}
+
+	var item models.DriveItemable
+	if total == 0 {
+		// Upload sessions cannot create empty files.
+		item, err = p.putEmpty(stream.Context(), c, req)
+	} else {
+		item, err = p.uploadInSession(stream.Context(), c, req, f, total, chunkSize)
+	}
+	if err != nil {
+		return err
+	}
+	return stream.SendAndClose(&storage_proto.WriteResponse{Node: p.toProtoNode(item, req.Path)})
+}
+
+func (p *StoragePlugin) putEmpty(ctx context.Context, c *msgraph.GraphServiceClient, req *storage_proto.WriteRequest) (models.DriveItemable, error) {
+	var cfg *msgraphdrives.ItemItemsItemContentRequestBuilderPutRequestConfiguration
+	if etag := req.Options["if_match"]; etag != "" {
+		cfg = &msgraphdrives.ItemItemsItemContentRequestBuilderPutRequestConfiguration{Headers: abstractions.NewRequestHeaders()}
+		cfg.Headers.Add("If-Match", etag)
+	}
+	return c.Drives().ByDriveId(p.getDriveID(req.Options)).Items().ByDriveItemId(p.resolvePath(req.Path)).Content().Put(ctx, []byte{}, cfg)
+}
+
+func (p *StoragePlugin) uploadInSession(ctx context.Context, c *msgraph.GraphServiceClient, req *storage_proto.WriteRequest, content io.ReaderAt, size, chunkSize int64) (models.DriveItemable, error) {
+	props := models.NewDriveItemUploadableProperties()
+	props.SetAdditionalData(map[string]any{"@microsoft.graph.conflictBehavior": "replace"})
+	body := msgraphdrives.NewItemItemsItemCreateUploadSessionPostRequestBody()
+	body.SetItem(props)
+
+	// The ETag is checked when the session is created; Graph rejects the
+	// session with 412 if the item has changed since it was read.
+	var cfg *msgraphdrives.ItemItemsItemCreateUploadSessionRequestBuilderPostRequestConfiguration
+	if etag := req.Options["if_match"]; etag != "" {
+		cfg = &msgraphdrives.ItemItemsItemCreateUploadSessionRequestBuilderPostRequestConfiguration{Headers: abstractions.NewRequestHeaders()}
+		cfg.Headers.Add("If-Match", etag)
+	}
+
+	session, err := c.Drives().ByDriveId(p.getDriveID(req.Options)).Items().ByDriveItemId(p.resolvePath(req.Path)).CreateUploadSession().Post(ctx, body, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if session.GetUploadUrl() == nil {
+		return nil, fmt.Errorf("upload session for %s has no upload url", req.Path)
+	}
+
+	u := &uploadSession{
+		client:     p.httpClient,
+		url:        *session.GetUploadUrl(),
+		content:    content,
+		size:       size,
+		chunkSize:  chunkSize,
+		maxRetries: defaultUploadMaxRetries,
+		backoff:    defaultUploadBackoff,
+	}
+	if u.client == nil {
+		u.client = http.DefaultClient
+	}
+
+	item, err := u.upload(ctx)
+	if err != nil {
+		if cerr := u.cancel(context.WithoutCancel(ctx)); cerr != nil {
+			return nil, fmt.Errorf("%w (failed to cancel upload session: %v)", err, cerr)
+		}
+		return nil, err
+	}
+	return item, nil
+}
+
+func (p *StoragePlugin) Delete(ctx context.Context, req *storage_proto.DeleteRequest) (*storage_proto.DeleteResponse, error) {
+	c, err := p.getClient(req.Options)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Drives().ByDriveId(p.getDriveID(req.Options)).Items().ByDriveItemId(p.resolvePath(req.Path)).Delete(ctx, nil); err != nil {
+		return nil, err
+	}
+	return &storage_proto.DeleteResponse{Success: true}, nil
+}
+
+func (p *StoragePlugin) ListDrives(ctx context.Context, req *storage_proto.ListDrivesRequest) (*storage_proto.ListDrivesResponse, error) {
+	c, err := p.getClient(req.Options)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.Me().Drives().Get(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	drives := make([]*storage_proto.Drive, 0)
+	for _, d := range res.GetValue() {
+		drives = append(drives, &storage_proto.Drive{Id: *d.GetId(), Name: *d.GetName(), Type: *d.GetDriveType()})
+	}
+	return &storage_proto.ListDrivesResponse{Drives: drives}, nil
+}
+
+func (p *StoragePlugin) GetMetadata(ctx context.Context, req *storage_proto.MetadataRequest) (*storage_proto.MetadataResponse, error) {
+	return &storage_proto.MetadataResponse{
+		Name:               "onedrive",
+		Version:            Version,
+		Type:               "storage",
+		SupportedProviders: []string{"azure"},
+		Capabilities: &storage_proto.Capabilities{
+			Copy:            true,
+			RangedRead:      true,
+			EtagConcurrency: true,
+			Delta:           true,
+			Hashes:          []string{"quickXorHash", "sha1", "sha256"},
+		},
+	}, nil
+}
+
+func (p *StoragePlugin) getClient(opts map[string]string) (*msgraph.GraphServiceClient, error) {
+	t := opts["token"]
+	if t == "" {
+		return nil, fmt.Errorf("missing token")
+	}
+	tp := &plugins.TokenTransport{Token: t}
+	adapter, err := msgraph.NewGraphRequestAdapter(&authProvider{tp})
+	if err != nil {
+		return nil, err
+	}
+	if p.baseURL != "" {
+		adapter.SetBaseUrl(p.baseURL)
+	}
+	return msgraph.NewGraphServiceClient(adapter), nil
+}
+
+type authProvider struct{ tp *plugins.TokenTransport }
+
+func (a *authProvider) AuthenticateRequest(ctx context.Context, req *abstractions.RequestInformation, _ map[string]any) error {
+	if req.Headers == nil {
+		req.Headers = abstractions.NewRequestHeaders()
+	}
+	req.Headers.Add("Authorization", "Bearer "+a.tp.Token)
+	return nil
+}
+
+func (p *StoragePlugin) getDriveID(opts map[string]string) string {
+	if id := opts["drive_id"]; id != "" {
+		return id
+	}
+	return "root"
+}
+
+func (p *StoragePlugin) resolvePath(path string) string {
+	if path == "" || path == "/" {
+		return "root"
+	}
+	return "root:/" + strings.TrimPrefix(path, "/") + ":"
+}
+
+func (p *StoragePlugin) toProtoNode(item models.DriveItemable, path string) *storage_proto.Node {
+	node := &storage_proto.Node{Path: path, Type: storage_proto.NodeType_FILE}
+	if item.GetFolder() != nil {
+		node.Type = storage_proto.NodeType_DIRECTORY
+	}
+	if s := item.GetSize(); s != nil {
+		node.Size = *s
+	}
+	if t := item.GetLastModifiedDateTime(); t != nil {
+		node.ModifiedAt = t.Unix()
+	}
+	if e := item.GetETag(); e != nil {
+		node.Etag = *e
+	}
+	if c := item.GetCTag(); c != nil {
+		node.Ctag = *c
+	}
+	if i := item.GetId(); i != nil {
+		node.Id = *i
+	}
+	if n := item.GetName(); n != nil {
+		node.Name = *n
+	}
+	if f := item.GetFile(); f != nil && f.GetHashes() != nil {
+		node.Hashes = fileHashes(f.GetHashes())
+	}
+	return node
+}
+
+// fileHashes collects the content hashes Graph reports for a file. Personal drives only
+// report SHA-1 and SHA-256; business drives only report quickXorHash.
+func fileHashes(h models.Hashesable) map[string]string {
+	hashes := make(map[string]string)
+	for name, sum := range map[string]*string{"quickXorHash": h.GetQuickXorHash(), "sha1": h.GetSha1Hash(), "sha256": h.GetSha256Hash()} {
+		if sum != nil && *sum != "" {
+			hashes[name] = *sum
+		}
+	}
+	if len(hashes) == 0 {
+		return nil
+	}
+	return hashes
+}
@@ -0,0 +1,271 @@
+package onedrive
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	jsonserialization "github.com/microsoft/kiota-serialization-json-go"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// defaultUploadChunkSize is the size of each ranged PUT. Graph requires
+	// chunks to be a multiple of 320 KiB.
+	defaultUploadChunkSize = 32 * uploadChunkMultiple
+	// uploadChunkMultiple is the granularity Graph requires of every chunk but the last.
+	uploadChunkMultiple = 320 * 1024
+	// maxUploadChunkSize is the largest chunk Graph accepts in a single request.
+	maxUploadChunkSize = 60 * 1024 * 1024
+	// defaultUploadMaxRetries bounds the number of consecutive failed attempts for a single chunk.
+	defaultUploadMaxRetries = 5
+	// defaultUploadBackoff is the initial delay between chunk retries; it doubles on each attempt.
+	defaultUploadBackoff = 500 * time.Millisecond
+)
+
+// uploadSession drives a Graph resumable upload against a pre-authenticated upload URL.
+// The content is read through an [io.ReaderAt] so that any range requested by the
+// server can be re-sent after a failure.
+type uploadSession struct {
+	client     *http.Client
+	url        string
+	content    io.ReaderAt
+	size       int64
+	chunkSize  int64
+	maxRetries int
+	backoff    time.Duration
+}
+
+// upload sends the content in ranged chunks and returns the resulting drive item.
+// Failed chunks are retried with exponential backoff, resuming from the ranges
+// the server reports as still missing.
+func (s *uploadSession) upload(ctx context.Context) (models.DriveItemable, error) {
+	var offset int64
+	failures := 0
+
+	for {
+		end := offset + s.chunkSize
+		if end > s.size {
+			end = s.size
+		}
+
+		item, next, err := s.putChunk(ctx, offset, end)
+		if err == nil {
+			if item != nil {
+				return item, nil
+			}
+			failures = 0
+			offset = next
+			continue
+		}
+
+		delay, ok := retryDelay(ctx, err)
+		if !ok {
+			return nil, err
+		}
+
+		failures++
+		if failures > s.maxRetries {
+			return nil, fmt.Errorf("upload chunk %d-%d failed after %d attempts: %w", offset, end-1, failures, err)
+		}
+
+		if backoff := s.backoff << (failures - 1); backoff > delay {
+			delay = backoff
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+
+		// Ask the server what it already has so that the next attempt resumes
+		// from the first missing byte instead of the failed chunk.
+		if next, err := s.status(ctx); err == nil {
+			offset = next
+		}
+	}
+}
+
+// retryDelay reports whether a failed chunk may succeed if sent again, and the delay the
+// server asked for before retrying. Error responses are classified by their status and
+// transport failures are transient; anything else, such as a cancelled context, an
+// unreadable spool file or an unparsable response, is final.
+func retryDelay(ctx context.Context, err error) (time.Duration, bool) {
+	var ue *uploadError
+	if errors.As(err, &ue) {
+		return ue.retryAfter, ue.retryable()
+	}
+	if ctx.Err() != nil {
+		return 0, false
+	}
+	var ne net.Error
+	return 0, errors.As(err, &ne) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// putChunk uploads the bytes in [start, end). It returns the finished drive item once the
+// server has received every byte, or the next offset the server expects otherwise.
+func (s *uploadSession) putChunk(ctx context.Context, start, end int64) (models.DriveItemable, int64, error) {
+	buf := make([]byte, end-start)
+	if _, err := s.content.ReadAt(buf, start); err != nil && err != io.EOF {
+		return nil, 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.url, bytes.NewReader(buf))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.ContentLength = int64(len(buf))
+	req.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end-1, s.size))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated:
+		item, err := parseDriveItem(body)
+		return item, 0, err
+	case http.StatusAccepted:
+		next, err := parseNextExpectedRanges(body, end)
+		return nil, next, err
+	default:
+		return nil, 0, newUploadError(resp, body)
+	}
+}
+
+// status queries the upload session for the next byte offset the server expects.
+func (s *uploadSession) status(ctx context.Context) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("upload session status returned %s", resp.Status)
+	}
+	return parseNextExpectedRanges(body, 0)
+}
+
+// cancel deletes the upload session so the server can discard any partially uploaded bytes.
+func (s *uploadSession) cancel(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// uploadChunkSize returns the chunk size for an upload: the chunk_size option when set,
+// otherwise the plugin's own size or the default. A chunk_size Graph would reject is
+// reported as [codes.InvalidArgument] before anything is uploaded.
+func (p *StoragePlugin) uploadChunkSize(options map[string]string) (int64, error) {
+	v := options["chunk_size"]
+	if v == "" {
+		if p.chunkSize > 0 {
+			return p.chunkSize, nil
+		}
+		return defaultUploadChunkSize, nil
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n <= 0 || n%uploadChunkMultiple != 0 || n > maxUploadChunkSize {
+		return 0, status.Errorf(codes.InvalidArgument, "chunk_size %q must be a multiple of %d bytes no larger than %d", v, uploadChunkMultiple, maxUploadChunkSize)
+	}
+	return n, nil
+}
+
+// parseNextExpectedRanges returns the start of the first range in a Graph upload session
+// status body (e.g. {"nextExpectedRanges": ["26-"]}). If no range is reported, fallback is returned.
+func parseNextExpectedRanges(body []byte, fallback int64) (int64, error) {
+	var status struct {
+		NextExpectedRanges []string `json:"nextExpectedRanges"`
+	}
+	if err := json.Unmarshal(body, &status); err != nil {
+		return 0, fmt.Errorf("invalid upload session status: %w", err)
+	}
+	if len(status.NextExpectedRanges) == 0 {
+		return fallback, nil
+	}
+	start, _, _ := strings.Cut(status.NextExpectedRanges[0], "-")
+	return strconv.ParseInt(start, 10, 64)
+}
+
+func parseDriveItem(body []byte) (models.DriveItemable, error) {
+	node, err := jsonserialization.NewJsonParseNode(body)
+	if err != nil {
+		return nil, err
+	}
+	v, err := node.GetObjectValue(models.CreateDriveItemFromDiscriminatorValue)
+	if err != nil {
+		return nil, err
+	}
+	item, ok := v.(models.DriveItemable)
+	if !ok {
+		return nil, fmt.Errorf("unexpected upload session response")
+	}
+	return item, nil
+}
+
+// uploadError describes a non-success response to a chunk upload.
+type uploadError struct {
+	statusCode int
+	status     string
+	message    string
+	retryAfter time.Duration
+}
+
+func newUploadError(resp *http.Response, body []byte) *uploadError {
+	e := &uploadError{
+		statusCode: resp.StatusCode,
+		status:     resp.Status,
+		message:    strings.TrimSpace(string(body)),
+	}
+	if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		e.retryAfter = time.Duration(secs) * time.Second
+	}
+	return e
+}
+
+func (e *uploadError) Error() string {
+	return fmt.Sprintf("upload session returned %s: %s", e.status, e.message)
+}
+
+// retryable reports whether the chunk may succeed if sent again. Server errors and
+// throttling are transient; 416 means the server already holds part of the range and
+// the upload must resume from the reported offset.
+func (e *uploadError) retryable() bool {
+	switch e.statusCode {
+	case http.StatusRequestTimeout, http.StatusRequestedRangeNotSatisfiable, http.StatusTooManyRequests:
+		return true
+	}
+	return e.statusCode >= http.StatusInternalServerError
+}
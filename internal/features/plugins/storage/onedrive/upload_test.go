@@ -0,0 +1,234 @@
+package onedrive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+
+	storage_proto "github.com/michaeldcanady/go-onedrive/internal/features/plugins/proto/storage"
+)
+
+// fakeUploadServer emulates the Graph createUploadSession and upload URL endpoints.
+type fakeUploadServer struct {
+	mu       sync.Mutex
+	etag     string
+	received []byte
+	puts     int
+	// failPut, when non-zero, makes the n-th chunk PUT store only half of its
+	// bytes and then respond with 503.
+	failPut int
+}
+
+func (f *fakeUploadServer) handler(baseURL func() string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		switch {
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/createUploadSession"):
+			if m := r.Header.Get("If-Match"); m != "" && m != f.etag {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusPreconditionFailed)
+				fmt.Fprint(w, `{"error":{"code":"resourceModified","message":"etag mismatch"}}`)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"uploadUrl":%q,"nextExpectedRanges":["0-"]}`, baseURL()+"/upload/session")
+		case r.Method == http.MethodPut && strings.HasSuffix(r.URL.Path, "/content"):
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"id":"empty","name":"empty.txt","size":0,"eTag":"e1"}`)
+		case r.Method == http.MethodPut && r.URL.Path == "/upload/session":
+			f.puts++
+			var start, end, total int
+			if _, err := fmt.Sscanf(r.Header.Get("Content-Range"), "bytes %d-%d/%d", &start, &end, &total); err != nil || start != len(f.received) {
+				w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+				return
+			}
+			chunk, _ := io.ReadAll(r.Body)
+			if f.puts == f.failPut {
+				f.received = append(f.received, chunk[:len(chunk)/2]...)
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			f.received = append(f.received, chunk...)
+			if len(f.received) < total {
+				w.WriteHeader(http.StatusAccepted)
+				fmt.Fprintf(w, `{"nextExpectedRanges":["%d-"]}`, len(f.received))
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"id":"item","name":"file.txt","size":%d,"eTag":"e2"}`, len(f.received))
+		case r.Method == http.MethodGet && r.URL.Path == "/upload/session":
+			fmt.Fprintf(w, `{"nextExpectedRanges":["%d-"]}`, len(f.received))
+		case r.Method == http.MethodDelete && r.URL.Path == "/upload/session":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+}
+
+type fakeWriteStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	reqs []*storage_proto.WriteRequest
+	resp *storage_proto.WriteResponse
+}
+
+func (s *fakeWriteStream) Context() context.Context { return s.ctx }
+
+func (s *fakeWriteStream) Recv() (*storage_proto.WriteRequest, error) {
+	if len(s.reqs) == 0 {
+		return nil, io.EOF
+	}
+	r := s.reqs[0]
+	s.reqs = s.reqs[1:]
+	return r, nil
+}
+
+func (s *fakeWriteStream) SendAndClose(resp *storage_proto.WriteResponse) error {
+	s.resp = resp
+	return nil
+}
+
+func TestOneDriveStoragePlugin_Write(t *testing.T) {
+	tests := []struct {
+		name      string
+		path      string
+		chunks    []string
+		ifMatch   string
+		chunkSize string
+		failPut   int
+		wantErr   bool
+		wantName  string
+		wantPuts  int
+	}{
+		{
+			name:     "uploads in ranged chunks",
+			path:     "/file.txt",
+			chunks:   []string{"hello ", "world"},
+			wantName: "file.txt",
+			wantPuts: 3,
+		},
+		{
+			name:     "resumes from next expected range after failure",
+			path:     "/file.txt",
+			chunks:   []string{"hello world"},
+			failPut:  2,
+			wantName: "file.txt",
+			wantPuts: 4,
+		},
+		{
+			name:     "matching etag",
+			path:     "/file.txt",
+			chunks:   []string{"data"},
+			ifMatch:  "current",
+			wantName: "file.txt",
+			wantPuts: 1,
+		},
+		{
+			name:    "stale etag",
+			path:    "/file.txt",
+			chunks:  []string{"data"},
+			ifMatch: "stale",
+			wantErr: true,
+		},
+		{
+			name:      "chunk size not a multiple of 320 KiB",
+			path:      "/file.txt",
+			chunks:    []string{"data"},
+			chunkSize: "1000",
+			wantErr:   true,
+		},
+		{
+			name:      "chunk size over 60 MiB",
+			path:      "/file.txt",
+			chunks:    []string{"data"},
+			chunkSize: "63242240",
+			wantErr:   true,
+		},
+		{
+			name:     "empty file",
+			path:     "/empty.txt",
+			chunks:   []string{""},
+			wantName: "empty.txt",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeUploadServer{etag: "current", failPut: tt.failPut}
+			var srv *httptest.Server
+			srv = httptest.NewServer(fake.handler(func() string { return srv.URL }))
+			defer srv.Close()
+
+			p := &StoragePlugin{baseURL: srv.URL, httpClient: srv.Client(), chunkSize: 4}
+
+			opts := map[string]string{"token": "t"}
+			if tt.ifMatch != "" {
+				opts["if_match"] = tt.ifMatch
+			}
+			if tt.chunkSize != "" {
+				opts["chunk_size"] = tt.chunkSize
+			}
+			stream := &fakeWriteStream{ctx: context.Background()}
+			for _, c := range tt.chunks {
+				stream.reqs = append(stream.reqs, &storage_proto.WriteRequest{Path: tt.path, Chunk: []byte(c), Options: opts})
+			}
+
+			err := p.Write(stream)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Zero(t, fake.puts)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantName, stream.resp.Node.Name)
+			assert.Equal(t, tt.path, stream.resp.Node.Path)
+			assert.Equal(t, strings.Join(tt.chunks, ""), string(fake.received))
+			assert.Equal(t, tt.wantPuts, fake.puts)
+		})
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		err       error
+		wantDelay time.Duration
+		wantRetry bool
+	}{
+		{name: "throttled", ctx: context.Background(), err: &uploadError{statusCode: http.StatusTooManyRequests, retryAfter: time.Second}, wantDelay: time.Second, wantRetry: true},
+		{name: "server error", ctx: context.Background(), err: &uploadError{statusCode: http.StatusBadGateway}, wantRetry: true},
+		{name: "client error", ctx: context.Background(), err: &uploadError{statusCode: http.StatusBadRequest}},
+		{name: "transport failure", ctx: context.Background(), err: &url.Error{Op: "Put", URL: "https://upload", Err: io.ErrUnexpectedEOF}, wantRetry: true},
+		{name: "truncated response", ctx: context.Background(), err: io.ErrUnexpectedEOF, wantRetry: true},
+		{name: "cancelled", ctx: canceled, err: &url.Error{Op: "Put", URL: "https://upload", Err: context.Canceled}},
+		{name: "unparsable response", ctx: context.Background(), err: fmt.Errorf("invalid upload session status: %w", errors.New("bad json"))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delay, retry := retryDelay(tt.ctx, tt.err)
+			assert.Equal(t, tt.wantRetry, retry)
+			assert.Equal(t, tt.wantDelay, delay)
+		})
+	}
+}
@@ -0,0 +1,407 @@
+// Package storagetest checks that a storage plugin behaves the way the odc host
+// expects. The suite drives the plugin through a table of scenarios, including the edge
+// cases where backends tend to differ, and asserts the node fields and gRPC status codes
+// the host relies on. The bundled plugins run it directly; pluginsdktest runs it against
+// SDK backends.
+package storagetest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	coreerrors "github.com/michaeldcanady/go-onedrive/internal/core/errors"
+	"github.com/michaeldcanady/go-onedrive/internal/core/plugins"
+	storage_proto "github.com/michaeldcanady/go-onedrive/internal/features/plugins/proto/storage"
+	"github.com/michaeldcanady/go-onedrive/pkg/pluginsdk"
+)
+
+// Serve starts impl on an in-memory listener and returns a client for it. The server is
+// created by newServer, such as [plugins.CustomGRPCServerWithErrors], when it is not
+// nil, so errors reach the client the way they do from the plugin binary.
+func Serve(t *testing.T, impl storage_proto.StorageServiceServer, newServer func([]grpc.ServerOption) *grpc.Server) storage_proto.StorageServiceClient {
+	t.Helper()
+	return ServeFunc(t, func(s grpc.ServiceRegistrar) { storage_proto.RegisterStorageServiceServer(s, impl) }, newServer)
+}
+
+// ServeFunc is [Serve] for a storage service registered by register, such as the
+// Register method of a [pluginsdk.Handler].
+func ServeFunc(t *testing.T, register func(grpc.ServiceRegistrar), newServer func([]grpc.ServerOption) *grpc.Server) storage_proto.StorageServiceClient {
+	t.Helper()
+	if newServer == nil {
+		newServer = func(opts []grpc.ServerOption) *grpc.Server { return grpc.NewServer(opts...) }
+	}
+	lis := bufconn.Listen(1 << 20)
+	server := newServer(nil)
+	register(server)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///conformance",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return storage_proto.NewStorageServiceClient(conn)
+}
+
+// suite holds what every scenario needs.
+type suite struct {
+	client storage_proto.StorageServiceClient
+	opts   map[string]string
+	caps   *storage_proto.Capabilities
+}
+
+// Run runs the conformance suite against a storage plugin client, passing opts with
+// every request. Each scenario works in its own directory beneath the root of the
+// plugin; the plugin should start empty. Scenarios that need a capability the plugin
+// does not report are skipped.
+func Run(t *testing.T, client storage_proto.StorageServiceClient, opts map[string]string) {
+	t.Helper()
+	ctx := context.Background()
+	meta, err := client.GetMetadata(ctx, &storage_proto.MetadataRequest{})
+	require.NoError(t, err, "GetMetadata")
+	require.NotEmpty(t, meta.Name, "plugins report a name")
+	require.Equal(t, plugins.PluginTypeStorage, meta.Type)
+
+	s := &suite{client: client, opts: opts, caps: meta.Capabilities}
+	if s.caps == nil {
+		s.caps = &storage_proto.Capabilities{}
+	}
+
+	for _, sc := range scenarios {
+		t.Run(sc.name, func(t *testing.T) {
+			if sc.requires != nil && !sc.requires(s.caps) {
+				t.Skip("not supported by the plugin")
+			}
+			dir := "/conformance-" + sc.slug
+			_, err := client.Mkdir(ctx, &storage_proto.MkdirRequest{Path: dir, Options: opts})
+			require.NoError(t, err, "Mkdir %s", dir)
+			t.Cleanup(func() {
+				client.Delete(context.Background(), &storage_proto.DeleteRequest{Path: dir, Options: opts})
+			})
+			sc.run(t, s, dir)
+		})
+	}
+}
+
+type scenario struct {
+	name     string
+	slug     string
+	requires func(*storage_proto.Capabilities) bool
+	run      func(t *testing.T, s *suite, dir string)
+}
+
+var scenarios = []scenario{
+	{name: "Mkdir creates directories and their parents", slug: "mkdir", run: testMkdir},
+	{name: "Mkdir on an existing path already exists", slug: "mkdir-exists", run: testMkdirExists},
+	{name: "Write and Read round-trip content", slug: "write-read", run: testWriteRead},
+	{name: "Write creates empty files", slug: "empty", run: testEmpty},
+	{name: "Write replaces existing content", slug: "overwrite", run: testOverwrite},
+	{name: "Read applies ranges", slug: "range", run: testRange},
+	{name: "List joins child paths", slug: "list", run: testList},
+	{name: "Names with spaces and unicode round-trip", slug: "names", run: testNames},
+	{name: "Missing items are not found", slug: "not-found", run: testNotFound},
+	{name: "Delete removes files and non-empty directories", slug: "delete", run: testDelete},
+	{
+		name: "Move relocates items", slug: "move", run: testMove,
+		requires: func(c *storage_proto.Capabilities) bool { return c.Move },
+	},
+	{
+		name: "Copy duplicates files", slug: "copy", run: testCopy,
+		requires: func(c *storage_proto.Capabilities) bool { return c.Copy },
+	},
+	{
+		name: "Write with a stale if_match fails", slug: "if-match", run: testIfMatch,
+		requires: func(c *storage_proto.Capabilities) bool { return c.EtagConcurrency },
+	},
+}
+
+func testMkdir(t *testing.T, s *suite, dir string) {
+	p := path.Join(dir, "a", "b")
+	assertNode(t, s.mkdir(t, p), p, storage_proto.NodeType_DIRECTORY)
+	assertNode(t, s.stat(t, p), p, storage_proto.NodeType_DIRECTORY)
+	assertNode(t, s.stat(t, path.Join(dir, "a")), path.Join(dir, "a"), storage_proto.NodeType_DIRECTORY)
+}
+
+func testMkdirExists(t *testing.T, s *suite, dir string) {
+	ctx := context.Background()
+	sub, file := path.Join(dir, "sub"), path.Join(dir, "file.txt")
+	s.mkdir(t, sub)
+	s.write(t, file, []byte("x"), nil)
+
+	for _, p := range []string{sub, file} {
+		_, err := s.client.Mkdir(ctx, &storage_proto.MkdirRequest{Path: p, Options: s.opts})
+		assert.Equal(t, codes.AlreadyExists, status.Code(err), "Mkdir %s: %v", p, err)
+	}
+	assert.Equal(t, storage_proto.NodeType_FILE, s.stat(t, file).Type, "the file is left alone")
+}
+
+func testWriteRead(t *testing.T, s *suite, dir string) {
+	// More than one chunk, and not a multiple of the chunk size.
+	content := bytes.Repeat([]byte("0123456789"), pluginsdk.ChunkSize/4)
+	p := path.Join(dir, "sub", "file.bin")
+
+	node := s.write(t, p, content, nil)
+	assertNode(t, node, p, storage_proto.NodeType_FILE)
+	assert.Equal(t, int64(len(content)), node.Size)
+
+	assert.Equal(t, content, s.read(t, p, 0, 0))
+	stat := s.stat(t, p)
+	assertNode(t, stat, p, storage_proto.NodeType_FILE)
+	assert.Equal(t, int64(len(content)), stat.Size)
+}
+
+func testEmpty(t *testing.T, s *suite, dir string) {
+	p := path.Join(dir, "empty.txt")
+	node := s.write(t, p, nil, nil)
+	assertNode(t, node, p, storage_proto.NodeType_FILE)
+	assert.Zero(t, node.Size)
+	assert.Empty(t, s.read(t, p, 0, 0))
+}
+
+func testOverwrite(t *testing.T, s *suite, dir string) {
+	p := path.Join(dir, "file.txt")
+	s.write(t, p, []byte("a longer first version"), nil)
+	s.write(t, p, []byte("second"), nil)
+	assert.Equal(t, "second", string(s.read(t, p, 0, 0)))
+	assert.Equal(t, int64(len("second")), s.stat(t, p).Size)
+	assert.Len(t, s.list(t, dir), 1, "the file is replaced, not duplicated")
+}
+
+func testRange(t *testing.T, s *suite, dir string) {
+	p := path.Join(dir, "file.txt")
+	s.write(t, p, []byte("0123456789"), nil)
+	assert.Equal(t, "3456", string(s.read(t, p, 3, 4)))
+	assert.Equal(t, "789", string(s.read(t, p, 7, 0)), "a zero length reads to the end")
+}
+
+func testList(t *testing.T, s *suite, dir string) {
+	s.write(t, path.Join(dir, "file.txt"), []byte("x"), nil)
+	s.mkdir(t, path.Join(dir, "child"))
+
+	got := s.list(t, dir)
+	require.Len(t, got, 2)
+	require.Contains(t, got, "file.txt")
+	require.Contains(t, got, "child")
+	assertNode(t, got["file.txt"], path.Join(dir, "file.txt"), storage_proto.NodeType_FILE)
+	assert.Equal(t, int64(1), got["file.txt"].Size)
+	assertNode(t, got["child"], path.Join(dir, "child"), storage_proto.NodeType_DIRECTORY)
+
+	root := s.list(t, "/")
+	name := path.Base(dir)
+	require.Contains(t, root, name, "List /")
+	assertNode(t, root[name], dir, storage_proto.NodeType_DIRECTORY)
+}
+
+func testNames(t *testing.T, s *suite, dir string) {
+	sub := path.Join(dir, "with space", "Ünïcödé dir")
+	file := path.Join(sub, "it's naïve 文件 🙂.txt")
+	assertNode(t, s.mkdir(t, sub), sub, storage_proto.NodeType_DIRECTORY)
+	assertNode(t, s.write(t, file, []byte("content"), nil), file, storage_proto.NodeType_FILE)
+
+	assertNode(t, s.stat(t, file), file, storage_proto.NodeType_FILE)
+	assert.Equal(t, "content", string(s.read(t, file, 0, 0)))
+
+	got := s.list(t, sub)
+	require.Contains(t, got, path.Base(file))
+	assertNode(t, got[path.Base(file)], file, storage_proto.NodeType_FILE)
+	parent := s.list(t, path.Join(dir, "with space"))
+	require.Contains(t, parent, path.Base(sub))
+	assertNode(t, parent[path.Base(sub)], sub, storage_proto.NodeType_DIRECTORY)
+}
+
+func testNotFound(t *testing.T, s *suite, dir string) {
+	ctx := context.Background()
+	missing := path.Join(dir, "missing")
+
+	_, err := s.client.Stat(ctx, &storage_proto.StatRequest{Path: missing, Options: s.opts})
+	assert.Equal(t, codes.NotFound, status.Code(err), "Stat: %v", err)
+
+	_, err = s.client.List(ctx, &storage_proto.ListRequest{Path: missing, Options: s.opts})
+	assert.Equal(t, codes.NotFound, status.Code(err), "List: %v", err)
+
+	stream, err := s.client.Read(ctx, &storage_proto.ReadRequest{Path: missing, Options: s.opts})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	assert.Equal(t, codes.NotFound, status.Code(err), "Read: %v", err)
+
+	_, err = s.client.Delete(ctx, &storage_proto.DeleteRequest{Path: missing, Options: s.opts})
+	assert.Equal(t, codes.NotFound, status.Code(err), "Delete: %v", err)
+}
+
+func testDelete(t *testing.T, s *suite, dir string) {
+	ctx := context.Background()
+	file := path.Join(dir, "file.txt")
+	tree := path.Join(dir, "tree")
+	s.write(t, file, []byte("x"), nil)
+	s.write(t, path.Join(tree, "nested", "file.txt"), []byte("x"), nil)
+
+	for _, p := range []string{file, tree} {
+		_, err := s.client.Delete(ctx, &storage_proto.DeleteRequest{Path: p, Options: s.opts})
+		require.NoError(t, err, "Delete %s", p)
+		_, err = s.client.Stat(ctx, &storage_proto.StatRequest{Path: p, Options: s.opts})
+		assert.Equal(t, codes.NotFound, status.Code(err), "Stat %s after Delete: %v", p, err)
+	}
+	assert.Empty(t, s.list(t, dir))
+}
+
+func testMove(t *testing.T, s *suite, dir string) {
+	src, dst := path.Join(dir, "src.txt"), path.Join(dir, "moved", "dst.txt")
+	s.write(t, src, []byte("content"), nil)
+
+	resp, err := s.client.Move(context.Background(), &storage_proto.MoveRequest{Source: src, Destination: dst, Options: s.opts})
+	require.NoError(t, err)
+	assertNode(t, resp.Node, dst, storage_proto.NodeType_FILE)
+
+	_, err = s.client.Stat(context.Background(), &storage_proto.StatRequest{Path: src, Options: s.opts})
+	assert.Equal(t, codes.NotFound, status.Code(err), "the source is gone: %v", err)
+	assert.Equal(t, "content", string(s.read(t, dst, 0, 0)))
+}
+
+func testCopy(t *testing.T, s *suite, dir string) {
+	src, dst := path.Join(dir, "src.txt"), path.Join(dir, "dst.txt")
+	s.write(t, src, []byte("content"), nil)
+	s.write(t, dst, []byte("replaced"), nil)
+
+	resp, err := s.client.Copy(context.Background(), &storage_proto.CopyRequest{Source: src, Destination: dst, Options: s.opts})
+	require.NoError(t, err)
+	assertNode(t, resp.Node, dst, storage_proto.NodeType_FILE)
+	assert.Equal(t, "content", string(s.read(t, src, 0, 0)), "the source remains")
+	assert.Equal(t, "content", string(s.read(t, dst, 0, 0)))
+	assert.Len(t, s.list(t, dir), 2, "an existing destination is replaced")
+}
+
+func testIfMatch(t *testing.T, s *suite, dir string) {
+	p := path.Join(dir, "file.txt")
+	first := s.write(t, p, []byte("first"), nil)
+	require.NotEmpty(t, first.Etag, "plugins with ETag concurrency report ETags")
+	s.write(t, p, []byte("second"), nil)
+
+	_, err := s.tryWrite(p, []byte("third"), map[string]string{pluginsdk.OptionIfMatch: first.Etag})
+	assert.Equal(t, codes.Aborted, status.Code(err), "%v", err)
+	assert.ErrorIs(t, plugins.FromGRPC(err), coreerrors.ErrPrecondition)
+	assert.Equal(t, "second", string(s.read(t, p, 0, 0)), "the file is unchanged")
+
+	current := s.stat(t, p)
+	s.write(t, p, []byte("third"), map[string]string{pluginsdk.OptionIfMatch: current.Etag})
+	assert.Equal(t, "third", string(s.read(t, p, 0, 0)))
+}
+
+// assertNode checks the fields every node describing p must carry.
+func assertNode(t *testing.T, n *storage_proto.Node, p string, typ storage_proto.NodeType) {
+	t.Helper()
+	if !assert.NotNil(t, n, "node for %s", p) {
+		return
+	}
+	assert.Equal(t, p, n.Path, "path of %s", p)
+	assert.Equal(t, path.Base(p), n.Name, "name of %s", p)
+	assert.Equal(t, typ, n.Type, "type of %s", p)
+}
+
+func (s *suite) with(extra map[string]string) map[string]string {
+	if len(extra) == 0 {
+		return s.opts
+	}
+	opts := make(map[string]string, len(s.opts)+len(extra))
+	for k, v := range s.opts {
+		opts[k] = v
+	}
+	for k, v := range extra {
+		opts[k] = v
+	}
+	return opts
+}
+
+func (s *suite) mkdir(t *testing.T, p string) *storage_proto.Node {
+	t.Helper()
+	resp, err := s.client.Mkdir(context.Background(), &storage_proto.MkdirRequest{Path: p, Options: s.opts})
+	require.NoError(t, err, "Mkdir %s", p)
+	return resp.Node
+}
+
+func (s *suite) stat(t *testing.T, p string) *storage_proto.Node {
+	t.Helper()
+	resp, err := s.client.Stat(context.Background(), &storage_proto.StatRequest{Path: p, Options: s.opts})
+	require.NoError(t, err, "Stat %s", p)
+	return resp.Node
+}
+
+// list returns the children of p by name.
+func (s *suite) list(t *testing.T, p string) map[string]*storage_proto.Node {
+	t.Helper()
+	resp, err := s.client.List(context.Background(), &storage_proto.ListRequest{Path: p, Options: s.opts})
+	require.NoError(t, err, "List %s", p)
+	nodes := make(map[string]*storage_proto.Node, len(resp.Nodes))
+	for _, n := range resp.Nodes {
+		nodes[n.Name] = n
+	}
+	return nodes
+}
+
+func (s *suite) write(t *testing.T, p string, content []byte, extra map[string]string) *storage_proto.Node {
+	t.Helper()
+	node, err := s.tryWrite(p, content, extra)
+	require.NoError(t, err, "Write %s", p)
+	return node
+}
+
+func (s *suite) tryWrite(p string, content []byte, extra map[string]string) (*storage_proto.Node, error) {
+	stream, err := s.client.Write(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	opts := s.with(extra)
+	first := true
+	err = pluginsdk.SendChunks(bytes.NewReader(content), func(chunk []byte) error {
+		req := &storage_proto.WriteRequest{Chunk: chunk}
+		if first {
+			req.Path, req.Options, first = p, opts, false
+		}
+		return stream.Send(req)
+	})
+	if first && err == nil {
+		// Empty content still names the file.
+		err = stream.Send(&storage_proto.WriteRequest{Path: p, Options: opts})
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return nil, err
+	}
+	return resp.Node, nil
+}
+
+func (s *suite) read(t *testing.T, p string, offset, length int64) []byte {
+	t.Helper()
+	stream, err := s.client.Read(context.Background(), &storage_proto.ReadRequest{Path: p, Options: s.opts, Offset: offset, Length: length})
+	require.NoError(t, err, "Read %s", p)
+	var buf bytes.Buffer
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err, "Read %s", p)
+		buf.Write(resp.Chunk)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,57 @@
+// Code generated by spec-gen. DO NOT EDIT.
+package s3
+
+import (
+	"github.com/michaeldcanady/go-onedrive/internal/core/di"
+	"github.com/spf13/cobra"
+)
+
+// CreateS3Cmd returns a new [cobra.Command] initialized with dependencies
+// and configured to execute the "s3" operation.
+func CreateS3Cmd(container di.Container) *cobra.Command {
+	var opts Options
+	var c *CommandContext
+
+	l := container.Logger().With("command", "serve-s3")
+
+	// Create the handler using the generated factory
+	var handler Handler = NewCommand(
+		container.Snapshot(),
+		container.Logger(),
+		l,
+		container.Resolver(),
+	)
+
+	cmd := &cobra.Command{
+		Use:   "s3 [flags]",
+		Short: "Serve the virtual file system over the S3 API",
+		Long:  `Serve every top-level mount as an S3 bucket so that S3 tools and SDKs can read and write OneDrive and other backends through one local endpoint.`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			opts.Stdout = cmd.OutOrStdout()
+			opts.Stderr = cmd.ErrOrStderr()
+
+			c = &CommandContext{
+				Ctx:     cmd.Context(),
+				Options: opts,
+			}
+
+			if err := handler.Validate(c); err != nil {
+				return err
+			}
+
+			return handler.Resolve(c)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := handler.Execute(c); err != nil {
+				return err
+			}
+			return handler.Finalize(c)
+		},
+	}
+	cmd.Flags().StringVar(&opts.Addr, "addr", "127.0.0.1:9000", "The address to listen on")
+	cmd.Flags().StringVar(&opts.AccessKey, "access-key", "", "Require requests signed with this access key (defaults to $ODC_S3_ACCESS_KEY)")
+	cmd.Flags().StringVar(&opts.SecretKey, "secret-key", "", "The secret key requests are signed with (defaults to $ODC_S3_SECRET_KEY)")
+	cmd.Flags().BoolVar(&opts.ReadOnly, "read-only", false, "Reject every request that would change an object")
+
+	return cmd
+}
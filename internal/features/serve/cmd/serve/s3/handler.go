@@ -0,0 +1,107 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/michaeldcanady/go-onedrive/internal/features/serve"
+)
+
+// The credentials can be supplied through the environment so that the secret key does
+// not appear in the process list.
+const (
+	accessKeyEnv = "ODC_S3_ACCESS_KEY"
+	secretKeyEnv = "ODC_S3_SECRET_KEY"
+)
+
+// shutdownTimeout bounds how long in-flight requests may run after an interrupt.
+const shutdownTimeout = 10 * time.Second
+
+// Validate ensures that the provided options are semantically correct.
+func (c *Command) Validate(ctx *CommandContext) error {
+	if ctx.Options.AccessKey == "" {
+		ctx.Options.AccessKey = os.Getenv(accessKeyEnv)
+	}
+	if ctx.Options.SecretKey == "" {
+		ctx.Options.SecretKey = os.Getenv(secretKeyEnv)
+	}
+	if ctx.Options.AccessKey == "" {
+		if !serve.IsLoopback(ctx.Options.Addr) {
+			return fmt.Errorf("refusing to serve on %s without authentication: use a loopback address or set --access-key", ctx.Options.Addr)
+		}
+		return nil
+	}
+	if ctx.Options.SecretKey == "" {
+		return fmt.Errorf("a secret key is required with --access-key: set --secret-key or $%s", secretKeyEnv)
+	}
+	return nil
+}
+
+// Resolve translates user input into domain entities using the [resolver.Service].
+func (c *Command) Resolve(ctx *CommandContext) error {
+	return c.BaseResolve(ctx)
+}
+
+// Execute performs the primary business logic of the "s3" command.
+func (c *Command) Execute(ctx *CommandContext) error {
+	snap, err := c.snapshot.Take(ctx.Ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := snap.Close(context.Background()); err != nil {
+			c.l.Warn("failed to write tokens back to state.db", "error", err)
+		}
+	}()
+
+	gateway := serve.NewS3Gateway(snap.VFS, snap.Mounts, serve.S3Options{
+		ReadOnly:  ctx.Options.ReadOnly,
+		AccessKey: ctx.Options.AccessKey,
+		SecretKey: ctx.Options.SecretKey,
+	}, c.l)
+	defer gateway.Close()
+
+	listener, err := net.Listen("tcp", ctx.Options.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", ctx.Options.Addr, err)
+	}
+	server := &http.Server{Handler: gateway, ReadHeaderTimeout: 30 * time.Second}
+	fmt.Fprintf(ctx.Options.Stdout, "Serving S3 on http://%s (press Ctrl+C to stop)\n", listener.Addr())
+
+	sigCtx, stop := signal.NotifyContext(ctx.Ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.Serve(listener)
+	}()
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("s3 server failed: %w", err)
+	case <-sigCtx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to stop s3 server: %w", err)
+	}
+	if err := <-errCh; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("s3 server failed: %w", err)
+	}
+	return nil
+}
+
+// Finalize performs post-execution tasks such as output formatting or resource cleanup.
+func (c *Command) Finalize(ctx *CommandContext) error {
+	fmt.Fprintln(ctx.Options.Stdout, "Stopped S3 server")
+	return nil
+}
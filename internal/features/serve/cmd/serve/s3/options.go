@@ -0,0 +1,19 @@
+// Code generated by spec-gen. DO NOT EDIT.
+package s3
+
+import (
+	"io"
+)
+
+// Options encapsulates all user-provided arguments and flags for the command.
+type Options struct {
+	Addr      string // The address to listen on
+	AccessKey string // Require requests signed with this access key (defaults to $ODC_S3_ACCESS_KEY)
+	SecretKey string // The secret key requests are signed with (defaults to $ODC_S3_SECRET_KEY)
+	ReadOnly  bool   // Reject every request that would change an object
+
+	// Stdout receives standard output messages.
+	Stdout io.Writer
+	// Stderr receives error and diagnostic messages.
+	Stderr io.Writer
+}
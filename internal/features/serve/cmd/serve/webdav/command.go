@@ -0,0 +1,57 @@
+// Code generated by spec-gen. DO NOT EDIT.
+package webdav
+
+import (
+	"github.com/michaeldcanady/go-onedrive/internal/core/di"
+	"github.com/spf13/cobra"
+)
+
+// CreateWebdavCmd returns a new [cobra.Command] initialized with dependencies
+// and configured to execute the "webdav" operation.
+func CreateWebdavCmd(container di.Container) *cobra.Command {
+	var opts Options
+	var c *CommandContext
+
+	l := container.Logger().With("command", "serve-webdav")
+
+	// Create the handler using the generated factory
+	var handler Handler = NewCommand(
+		container.Snapshot(),
+		container.Logger(),
+		l,
+		container.Resolver(),
+	)
+
+	cmd := &cobra.Command{
+		Use:   "webdav [flags]",
+		Short: "Serve the virtual file system over WebDAV",
+		Long:  `Serve every mount over WebDAV so that file managers, backup software and other WebDAV clients can use them through one local endpoint.`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			opts.Stdout = cmd.OutOrStdout()
+			opts.Stderr = cmd.ErrOrStderr()
+
+			c = &CommandContext{
+				Ctx:     cmd.Context(),
+				Options: opts,
+			}
+
+			if err := handler.Validate(c); err != nil {
+				return err
+			}
+
+			return handler.Resolve(c)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := handler.Execute(c); err != nil {
+				return err
+			}
+			return handler.Finalize(c)
+		},
+	}
+	cmd.Flags().StringVar(&opts.Addr, "addr", "127.0.0.1:8080", "The address to listen on")
+	cmd.Flags().StringVar(&opts.Username, "username", "", "Require HTTP basic authentication with this user name")
+	cmd.Flags().StringVar(&opts.Password, "password", "", "The basic authentication password (defaults to $ODC_SERVE_PASSWORD)")
+	cmd.Flags().BoolVar(&opts.ReadOnly, "read-only", false, "Reject every request that would change a file")
+
+	return cmd
+}
@@ -0,0 +1,99 @@
+package webdav
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/michaeldcanady/go-onedrive/internal/features/serve"
+)
+
+// passwordEnv supplies the basic authentication password without exposing it in the
+// process list.
+const passwordEnv = "ODC_SERVE_PASSWORD"
+
+// shutdownTimeout bounds how long in-flight requests may run after an interrupt.
+const shutdownTimeout = 10 * time.Second
+
+// Validate ensures that the provided options are semantically correct.
+func (c *Command) Validate(ctx *CommandContext) error {
+	if ctx.Options.Password == "" {
+		ctx.Options.Password = os.Getenv(passwordEnv)
+	}
+	if ctx.Options.Username == "" {
+		if !serve.IsLoopback(ctx.Options.Addr) {
+			return fmt.Errorf("refusing to serve on %s without authentication: use a loopback address or set --username", ctx.Options.Addr)
+		}
+		return nil
+	}
+	if ctx.Options.Password == "" {
+		return fmt.Errorf("a password is required with --username: set --password or $%s", passwordEnv)
+	}
+	return nil
+}
+
+// Resolve translates user input into domain entities using the [resolver.Service].
+func (c *Command) Resolve(ctx *CommandContext) error {
+	return c.BaseResolve(ctx)
+}
+
+// Execute performs the primary business logic of the "webdav" command.
+func (c *Command) Execute(ctx *CommandContext) error {
+	snap, err := c.snapshot.Take(ctx.Ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := snap.Close(context.Background()); err != nil {
+			c.l.Warn("failed to write tokens back to state.db", "error", err)
+		}
+	}()
+
+	handler := serve.NewWebDAVHandler(snap.VFS, serve.WebDAVOptions{ReadOnly: ctx.Options.ReadOnly}, c.l)
+	if ctx.Options.Username != "" {
+		handler = serve.BasicAuth(handler, ctx.Options.Username, ctx.Options.Password)
+	}
+
+	listener, err := net.Listen("tcp", ctx.Options.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", ctx.Options.Addr, err)
+	}
+	server := &http.Server{Handler: handler, ReadHeaderTimeout: 30 * time.Second}
+	fmt.Fprintf(ctx.Options.Stdout, "Serving WebDAV on http://%s (press Ctrl+C to stop)\n", listener.Addr())
+
+	sigCtx, stop := signal.NotifyContext(ctx.Ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.Serve(listener)
+	}()
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("webdav server failed: %w", err)
+	case <-sigCtx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to stop webdav server: %w", err)
+	}
+	if err := <-errCh; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("webdav server failed: %w", err)
+	}
+	return nil
+}
+
+// Finalize performs post-execution tasks such as output formatting or resource cleanup.
+func (c *Command) Finalize(ctx *CommandContext) error {
+	fmt.Fprintln(ctx.Options.Stdout, "Stopped WebDAV server")
+	return nil
+}
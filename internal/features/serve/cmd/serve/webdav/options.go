@@ -0,0 +1,19 @@
+// Code generated by spec-gen. DO NOT EDIT.
+package webdav
+
+import (
+	"io"
+)
+
+// Options encapsulates all user-provided arguments and flags for the command.
+type Options struct {
+	Addr     string // The address to listen on
+	Username string // Require HTTP basic authentication with this user name
+	Password string // The basic authentication password (defaults to $ODC_SERVE_PASSWORD)
+	ReadOnly bool   // Reject every request that would change a file
+
+	// Stdout receives standard output messages.
+	Stdout io.Writer
+	// Stderr receives error and diagnostic messages.
+	Stderr io.Writer
+}
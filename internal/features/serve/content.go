@@ -0,0 +1,49 @@
+package serve
+
+import (
+	"fmt"
+	"mime"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/michaeldcanady/go-onedrive/internal/features/vfs"
+)
+
+// etagOf returns the HTTP entity tag of n. Backends without ETags get one derived from
+// the modification time and size, as the webdav package does for plain files.
+func etagOf(n *vfs.Node) string {
+	switch {
+	case n.ETag == "":
+		return fmt.Sprintf(`"%x%x"`, time.Unix(n.ModifiedAt, 0).UnixNano(), n.Size)
+	case strings.HasPrefix(n.ETag, `"`), strings.HasPrefix(n.ETag, `W/"`):
+		return n.ETag
+	default:
+		return `"` + n.ETag + `"`
+	}
+}
+
+// matchETag reports whether the If-Match or If-None-Match header value list names etag.
+func matchETag(list, etag string) bool {
+	for _, candidate := range strings.Split(list, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// contentType guesses a MIME type from the file extension.
+func contentType(name string) string {
+	if t := mime.TypeByExtension(path.Ext(name)); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
+
+// retryAfterSeconds formats d for a Retry-After header.
+func retryAfterSeconds(d time.Duration) string {
+	return strconv.Itoa(int(d.Round(time.Second).Seconds()))
+}
@@ -0,0 +1,8 @@
+// Package serve exposes the virtual file system to other programs over network
+// protocols, so that tools without native support for a backend can still reach every
+// mount through a single local endpoint.
+//
+// [NewWebDAVHandler] serves the VFS over WebDAV: PROPFIND is answered from List and
+// Stat, GET streams ranged reads, PUT writes with the request's If-Match condition, and
+// MKCOL, MOVE, COPY and DELETE map to the matching VFS operations.
+package serve
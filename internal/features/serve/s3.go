@@ -0,0 +1,515 @@
+package serve
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	coreerrors "github.com/michaeldcanady/go-onedrive/internal/core/errors"
+	"github.com/michaeldcanady/go-onedrive/internal/core/logger"
+	"github.com/michaeldcanady/go-onedrive/internal/features/mount"
+	"github.com/michaeldcanady/go-onedrive/internal/features/vfs"
+)
+
+const (
+	s3Namespace  = "http://s3.amazonaws.com/doc/2006-03-01/"
+	s3TimeFormat = "2006-01-02T15:04:05.000Z"
+)
+
+// S3Options configures an S3 gateway.
+type S3Options struct {
+	// ReadOnly rejects every request that would modify the VFS.
+	ReadOnly bool
+	// AccessKey and SecretKey, when set, require every request to be signed with AWS
+	// Signature Version 4 using these credentials.
+	AccessKey string
+	SecretKey string
+}
+
+// S3Gateway serves the VFS through a subset of the Amazon S3 REST API using path-style
+// addressing. Every top-level mount is a bucket, and object keys are paths relative to
+// it. Supported operations are ListBuckets, HeadBucket, ListObjects (V1 and V2),
+// GetObject, HeadObject, PutObject, CopyObject, DeleteObject, DeleteObjects and
+// multipart uploads.
+type S3Gateway struct {
+	vfs     vfs.VFS
+	mounts  mount.Service
+	opts    S3Options
+	logger  logger.Service
+	uploads *multipartUploads
+	now     func() time.Time
+}
+
+// NewS3Gateway returns an S3 gateway over v. The caller must [S3Gateway.Close] it to
+// discard incomplete multipart uploads.
+func NewS3Gateway(v vfs.VFS, ms mount.Service, opts S3Options, l logger.Service) *S3Gateway {
+	return &S3Gateway{
+		vfs:     v,
+		mounts:  ms,
+		opts:    opts,
+		logger:  l,
+		uploads: newMultipartUploads(),
+		now:     time.Now,
+	}
+}
+
+// Close discards the parts of every multipart upload that was not completed.
+func (g *S3Gateway) Close() error {
+	return g.uploads.close()
+}
+
+func (g *S3Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	err := g.serve(w, r)
+	l := logger.WithContext(g.logger, r.Context())
+	if err == nil {
+		l.Debug("s3 request", "method", r.Method, "path", r.URL.Path)
+		return
+	}
+	l.Debug("s3 request failed", "method", r.Method, "path", r.URL.Path, "error", err)
+
+	e := toS3Error(err)
+	if d, ok := coreerrors.RetryAfter(err); ok {
+		w.Header().Set("Retry-After", retryAfterSeconds(d))
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(e.status)
+	if r.Method != http.MethodHead {
+		writeXML(w, s3ErrorResponse{Code: e.code, Message: e.message, Resource: r.URL.Path})
+	}
+}
+
+func (g *S3Gateway) serve(w http.ResponseWriter, r *http.Request) error {
+	var sig *sigV4
+	if g.opts.AccessKey != "" {
+		var err error
+		if sig, err = verifySigV4(r, g.opts.AccessKey, g.opts.SecretKey, g.now()); err != nil {
+			if errors.Is(err, errSignatureMismatch) {
+				return &s3Error{http.StatusForbidden, "SignatureDoesNotMatch", err.Error()}
+			}
+			return &s3Error{http.StatusForbidden, "AccessDenied", err.Error()}
+		}
+	}
+	body, err := verifiedBody(r, sig)
+	if err != nil {
+		return &s3Error{http.StatusBadRequest, "InvalidRequest", err.Error()}
+	}
+	r.Body = body
+	if g.opts.ReadOnly && r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return &s3Error{http.StatusForbidden, "AccessDenied", "server is read-only"}
+	}
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	query := r.URL.Query()
+	ctx := r.Context()
+
+	if bucket == "" {
+		if r.Method != http.MethodGet {
+			return errS3MethodNotAllowed
+		}
+		return g.listBuckets(ctx, w)
+	}
+
+	if err := g.checkBucket(ctx, bucket); err != nil {
+		if r.Method == http.MethodPut && key == "" && errors.Is(err, errNoSuchBucket) {
+			return &s3Error{http.StatusNotImplemented, "NotImplemented", "buckets are created by adding a top-level mount"}
+		}
+		return err
+	}
+
+	if key == "" {
+		switch r.Method {
+		case http.MethodHead:
+			return nil
+		case http.MethodGet:
+			switch {
+			case query.Has("location"):
+				writeXML(w, locationConstraint{Xmlns: s3Namespace})
+				return nil
+			case query.Has("uploads"), query.Has("versions"), query.Has("acl"), query.Has("policy"):
+				return errS3NotImplemented
+			}
+			return g.listObjects(ctx, w, bucket, query)
+		case http.MethodPut:
+			return &s3Error{http.StatusConflict, "BucketAlreadyOwnedByYou", "buckets are the top-level mounts"}
+		case http.MethodPost:
+			if query.Has("delete") {
+				return g.deleteObjects(ctx, w, r, bucket)
+			}
+		}
+		return errS3NotImplemented
+	}
+
+	p, err := objectPath(bucket, key)
+	if err != nil {
+		return err
+	}
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		if query.Has("uploadId") {
+			return errS3NotImplemented
+		}
+		return g.getObject(w, r, p)
+	case http.MethodPut:
+		switch {
+		case query.Has("uploadId"):
+			if r.Header.Get("X-Amz-Copy-Source") != "" {
+				return errS3NotImplemented
+			}
+			return g.uploadPart(w, r, query)
+		case r.Header.Get("X-Amz-Copy-Source") != "":
+			return g.copyObject(ctx, w, r, p)
+		default:
+			return g.putObject(w, r, p, strings.HasSuffix(key, "/"))
+		}
+	case http.MethodDelete:
+		if query.Has("uploadId") {
+			return g.abortMultipartUpload(w, query)
+		}
+		return g.deleteObject(ctx, w, p)
+	case http.MethodPost:
+		switch {
+		case query.Has("uploads"):
+			return g.createMultipartUpload(w, bucket, key, p)
+		case query.Has("uploadId"):
+			return g.completeMultipartUpload(w, r, query)
+		}
+		return errS3NotImplemented
+	default:
+		return errS3MethodNotAllowed
+	}
+}
+
+// checkBucket ensures a top-level mount named bucket exists.
+func (g *S3Gateway) checkBucket(ctx context.Context, bucket string) error {
+	mounts, err := g.mounts.List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, m := range mounts {
+		if path.Clean(m.Path) == "/"+bucket {
+			return nil
+		}
+	}
+	return errNoSuchBucket
+}
+
+func (g *S3Gateway) listBuckets(ctx context.Context, w http.ResponseWriter) error {
+	mounts, err := g.mounts.List(ctx)
+	if err != nil {
+		return err
+	}
+	result := listAllMyBucketsResult{Xmlns: s3Namespace, Owner: s3Owner{ID: "odc", DisplayName: "odc"}}
+	for _, m := range mounts {
+		name := strings.TrimPrefix(path.Clean(m.Path), "/")
+		if name == "" || strings.Contains(name, "/") {
+			continue
+		}
+		// Mounts do not record when they were added.
+		result.Buckets = append(result.Buckets, s3Bucket{Name: name, CreationDate: time.Unix(0, 0).UTC().Format(s3TimeFormat)})
+	}
+	writeXML(w, result)
+	return nil
+}
+
+// getObject serves GetObject and HeadObject, including Range and conditional requests.
+func (g *S3Gateway) getObject(w http.ResponseWriter, r *http.Request, p string) error {
+	ctx := r.Context()
+	node, err := g.vfs.Stat(ctx, p)
+	if err != nil {
+		return err
+	}
+	if node.Type == vfs.DirectoryType {
+		return errNoSuchKey
+	}
+
+	f := &vfsFile{ctx: ctx, vfs: g.vfs, path: p, node: node}
+	defer f.Close()
+	w.Header().Set("ETag", etagOf(node))
+	w.Header().Set("Content-Type", contentType(p))
+	http.ServeContent(w, r, "", time.Unix(node.ModifiedAt, 0), f)
+	return nil
+}
+
+// putObject writes the request body to p. Keys ending in a slash create a directory.
+// If-Match and If-None-Match are honoured as for WebDAV.
+func (g *S3Gateway) putObject(w http.ResponseWriter, r *http.Request, p string, dir bool) error {
+	ctx := r.Context()
+	body := r.Body
+	if dir {
+		if _, err := io.Copy(io.Discard, body); err != nil {
+			return err
+		}
+		if err := g.mkdirAll(ctx, p); err != nil {
+			return err
+		}
+		w.Header().Set("ETag", `"d41d8cd98f00b204e9800998ecf8427e"`)
+		return nil
+	}
+
+	node, err := g.vfs.Stat(ctx, p)
+	exists := err == nil
+	switch {
+	case errors.Is(err, vfs.ErrNotFound):
+	case err != nil:
+		return err
+	case node.Type == vfs.DirectoryType:
+		return &s3Error{http.StatusConflict, "InvalidRequest", "a directory exists at this key"}
+	}
+
+	var opts []vfs.WriteOption
+	if v := r.Header.Get("If-Match"); v != "" {
+		if !exists || !matchETag(v, etagOf(node)) {
+			return vfs.ErrPrecondition
+		}
+		opts = append(opts, vfs.WithIfMatch(node.ETag))
+	}
+	if v := r.Header.Get("If-None-Match"); v != "" && exists && matchETag(v, etagOf(node)) {
+		return vfs.ErrPrecondition
+	}
+
+	if !exists {
+		if err := g.mkdirAll(ctx, path.Dir(p)); err != nil {
+			return err
+		}
+	}
+	if err := g.vfs.Write(ctx, p, body, opts...); err != nil {
+		return err
+	}
+	if node, err := g.vfs.Stat(ctx, p); err == nil {
+		w.Header().Set("ETag", etagOf(node))
+	}
+	return nil
+}
+
+// copyObject copies the object named by the X-Amz-Copy-Source header to p.
+func (g *S3Gateway) copyObject(ctx context.Context, w http.ResponseWriter, r *http.Request, p string) error {
+	source, _, _ := strings.Cut(r.Header.Get("X-Amz-Copy-Source"), "?")
+	source, err := url.PathUnescape(source)
+	if err != nil {
+		return &s3Error{http.StatusBadRequest, "InvalidArgument", "invalid copy source"}
+	}
+	srcBucket, srcKey, _ := strings.Cut(strings.TrimPrefix(source, "/"), "/")
+	if err := g.checkBucket(ctx, srcBucket); err != nil {
+		return err
+	}
+	src, err := objectPath(srcBucket, srcKey)
+	if err != nil {
+		return err
+	}
+
+	node, err := g.vfs.Stat(ctx, src)
+	if err != nil {
+		return err
+	}
+	if node.Type == vfs.DirectoryType {
+		return errNoSuchKey
+	}
+	// Copying an object onto itself only replaces metadata, which the VFS does not store.
+	if src != p {
+		if err := g.mkdirAll(ctx, path.Dir(p)); err != nil {
+			return err
+		}
+		if _, err := g.vfs.Copy(ctx, src, p); err != nil {
+			return err
+		}
+		if node, err = g.vfs.Stat(ctx, p); err != nil {
+			return err
+		}
+	}
+	writeXML(w, copyObjectResult{Xmlns: s3Namespace, ETag: etagOf(node), LastModified: time.Unix(node.ModifiedAt, 0).UTC().Format(s3TimeFormat)})
+	return nil
+}
+
+// deleteObject removes p. Missing keys succeed, as in S3, and directories are only
+// removed once empty.
+func (g *S3Gateway) deleteObject(ctx context.Context, w http.ResponseWriter, p string) error {
+	if err := g.remove(ctx, p); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (g *S3Gateway) remove(ctx context.Context, p string) error {
+	_, err := g.vfs.Remove(ctx, p)
+	if errors.Is(err, vfs.ErrNotFound) || errors.Is(err, vfs.ErrNotEmpty) {
+		return nil
+	}
+	return err
+}
+
+func (g *S3Gateway) deleteObjects(ctx context.Context, w http.ResponseWriter, r *http.Request, bucket string) error {
+	var req deleteRequest
+	if err := xml.NewDecoder(r.Body).Decode(&req); err != nil {
+		return errMalformedXML
+	}
+	result := deleteResult{Xmlns: s3Namespace}
+	for _, obj := range req.Objects {
+		p, err := objectPath(bucket, obj.Key)
+		if err == nil {
+			err = g.remove(ctx, p)
+		}
+		if err != nil {
+			e := toS3Error(err)
+			result.Errors = append(result.Errors, deleteError{Key: obj.Key, Code: e.code, Message: e.message})
+			continue
+		}
+		if !req.Quiet {
+			result.Deleted = append(result.Deleted, deletedObject{Key: obj.Key})
+		}
+	}
+	writeXML(w, result)
+	return nil
+}
+
+// mkdirAll creates p and any missing parents, since S3 keys imply their prefixes.
+func (g *S3Gateway) mkdirAll(ctx context.Context, p string) error {
+	node, err := g.vfs.Stat(ctx, p)
+	switch {
+	case err == nil:
+		if node.Type != vfs.DirectoryType {
+			return vfs.ErrNotADirectory
+		}
+		return nil
+	case !errors.Is(err, vfs.ErrNotFound):
+		return err
+	}
+	if err := g.mkdirAll(ctx, path.Dir(p)); err != nil {
+		return err
+	}
+	if err := g.vfs.Mkdir(ctx, p); err != nil && !errors.Is(err, vfs.ErrAlreadyExists) {
+		return err
+	}
+	return nil
+}
+
+// objectPath returns the VFS path of key in bucket. Keys that would not survive path
+// cleaning, such as those with empty or dot segments, are rejected.
+func objectPath(bucket, key string) (string, error) {
+	p := "/" + bucket + "/" + key
+	if key == "" || path.Clean(p) != strings.TrimSuffix(p, "/") {
+		return "", &s3Error{http.StatusBadRequest, "InvalidArgument", "unsupported object key"}
+	}
+	return path.Clean(p), nil
+}
+
+func writeXML(w http.ResponseWriter, v any) {
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "application/xml")
+	}
+	io.WriteString(w, xml.Header)
+	xml.NewEncoder(w).Encode(v)
+}
+
+// s3Error is an error response in the S3 format.
+type s3Error struct {
+	status  int
+	code    string
+	message string
+}
+
+func (e *s3Error) Error() string {
+	return e.code + ": " + e.message
+}
+
+var (
+	errNoSuchBucket       = &s3Error{http.StatusNotFound, "NoSuchBucket", "the specified bucket does not exist"}
+	errNoSuchKey          = &s3Error{http.StatusNotFound, "NoSuchKey", "the specified key does not exist"}
+	errNoSuchUpload       = &s3Error{http.StatusNotFound, "NoSuchUpload", "the specified multipart upload does not exist"}
+	errMalformedXML       = &s3Error{http.StatusBadRequest, "MalformedXML", "the XML provided was not well-formed"}
+	errS3NotImplemented   = &s3Error{http.StatusNotImplemented, "NotImplemented", "this operation is not supported"}
+	errS3MethodNotAllowed = &s3Error{http.StatusMethodNotAllowed, "MethodNotAllowed", "the method is not allowed against this resource"}
+)
+
+// toS3Error maps VFS errors onto S3 error codes.
+func toS3Error(err error) *s3Error {
+	var e *s3Error
+	switch {
+	case errors.As(err, &e):
+		return e
+	case errors.Is(err, errSignatureMismatch):
+		return &s3Error{http.StatusForbidden, "SignatureDoesNotMatch", err.Error()}
+	case errors.Is(err, errPayloadMismatch):
+		return &s3Error{http.StatusBadRequest, "XAmzContentSHA256Mismatch", err.Error()}
+	case errors.Is(err, vfs.ErrNotFound):
+		return errNoSuchKey
+	case errors.Is(err, vfs.ErrPermissionDenied):
+		return &s3Error{http.StatusForbidden, "AccessDenied", err.Error()}
+	case errors.Is(err, vfs.ErrPrecondition):
+		return &s3Error{http.StatusPreconditionFailed, "PreconditionFailed", "at least one of the preconditions did not hold"}
+	case errors.Is(err, vfs.ErrInvalidPath), errors.Is(err, vfs.ErrNotADirectory), errors.Is(err, vfs.ErrIsADirectory):
+		return &s3Error{http.StatusBadRequest, "InvalidArgument", err.Error()}
+	case errors.Is(err, vfs.ErrThrottled):
+		return &s3Error{http.StatusServiceUnavailable, "SlowDown", err.Error()}
+	case errors.Is(err, vfs.ErrUnavailable):
+		return &s3Error{http.StatusServiceUnavailable, "ServiceUnavailable", err.Error()}
+	default:
+		return &s3Error{http.StatusInternalServerError, "InternalError", err.Error()}
+	}
+}
+
+type s3ErrorResponse struct {
+	XMLName  xml.Name `xml:"Error"`
+	Code     string   `xml:"Code"`
+	Message  string   `xml:"Message"`
+	Resource string   `xml:"Resource"`
+}
+
+type s3Owner struct {
+	ID          string `xml:"ID"`
+	DisplayName string `xml:"DisplayName"`
+}
+
+type s3Bucket struct {
+	Name         string `xml:"Name"`
+	CreationDate string `xml:"CreationDate"`
+}
+
+type listAllMyBucketsResult struct {
+	XMLName xml.Name   `xml:"ListAllMyBucketsResult"`
+	Xmlns   string     `xml:"xmlns,attr"`
+	Owner   s3Owner    `xml:"Owner"`
+	Buckets []s3Bucket `xml:"Buckets>Bucket"`
+}
+
+type locationConstraint struct {
+	XMLName xml.Name `xml:"LocationConstraint"`
+	Xmlns   string   `xml:"xmlns,attr"`
+}
+
+type copyObjectResult struct {
+	XMLName      xml.Name `xml:"CopyObjectResult"`
+	Xmlns        string   `xml:"xmlns,attr"`
+	ETag         string   `xml:"ETag"`
+	LastModified string   `xml:"LastModified"`
+}
+
+type deleteRequest struct {
+	Quiet   bool `xml:"Quiet"`
+	Objects []struct {
+		Key string `xml:"Key"`
+	} `xml:"Object"`
+}
+
+type deletedObject struct {
+	Key string `xml:"Key"`
+}
+
+type deleteError struct {
+	Key     string `xml:"Key"`
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+type deleteResult struct {
+	XMLName xml.Name        `xml:"DeleteResult"`
+	Xmlns   string          `xml:"xmlns,attr"`
+	Deleted []deletedObject `xml:"Deleted"`
+	Errors  []deleteError   `xml:"Error"`
+}
@@ -0,0 +1,401 @@
+package serve
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	sigV4Algorithm   = "AWS4-HMAC-SHA256"
+	sigV4TimeFormat  = "20060102T150405Z"
+	maxSigV4Skew     = 15 * time.Minute
+	maxPresignExpiry = 7 * 24 * time.Hour
+)
+
+// Values of X-Amz-Content-Sha256 other than the hex SHA-256 of the body.
+const (
+	unsignedPayload          = "UNSIGNED-PAYLOAD"
+	streamingUnsignedTrailer = "STREAMING-UNSIGNED-PAYLOAD-TRAILER"
+	streamingSignedPayload   = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD"
+	streamingSignedTrailer   = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD-TRAILER"
+)
+
+// emptySHA256 is the hex SHA-256 of no bytes.
+const emptySHA256 = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+var (
+	errMissingAuth       = errors.New("request is not signed")
+	errUnknownAccessKey  = errors.New("unknown access key")
+	errSignatureMismatch = errors.New("signature does not match")
+	errRequestExpired    = errors.New("request has expired")
+	errPayloadMismatch   = errors.New("the body does not match X-Amz-Content-Sha256")
+)
+
+// sigV4 holds the parts of an AWS Signature Version 4 authorization.
+type sigV4 struct {
+	accessKey     string
+	scope         string
+	signedHeaders []string
+	signature     string
+	amzDate       time.Time
+	payloadHash   string
+	// key is the signing key derived from the secret key and scope.
+	key []byte
+}
+
+// verifySigV4 checks that r was signed with secretKey for accessKey, either in the
+// Authorization header or as a presigned URL. The body is not read: [verifiedBody]
+// checks it against the payload hash as it is streamed.
+func verifySigV4(r *http.Request, accessKey, secretKey string, now time.Time) (*sigV4, error) {
+	var (
+		sig *sigV4
+		err error
+	)
+	query := r.URL.Query()
+	switch {
+	case strings.HasPrefix(r.Header.Get("Authorization"), sigV4Algorithm+" "):
+		sig, err = parseSigV4Header(r, now)
+	case query.Get("X-Amz-Algorithm") == sigV4Algorithm:
+		sig, err = parseSigV4Query(query, now)
+	default:
+		return nil, errMissingAuth
+	}
+	if err != nil {
+		return nil, err
+	}
+	if sig.accessKey != accessKey {
+		return nil, errUnknownAccessKey
+	}
+
+	canonical := canonicalRequest(r, sig)
+	hash := sha256.Sum256([]byte(canonical))
+	stringToSign := strings.Join([]string{
+		sigV4Algorithm,
+		sig.amzDate.Format(sigV4TimeFormat),
+		sig.scope,
+		hex.EncodeToString(hash[:]),
+	}, "\n")
+
+	key := []byte("AWS4" + secretKey)
+	for _, part := range strings.Split(sig.scope, "/") {
+		key = hmacSHA256(key, part)
+	}
+	want := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	if !hmac.Equal([]byte(want), []byte(sig.signature)) {
+		return nil, errSignatureMismatch
+	}
+	sig.key = key
+	return sig, nil
+}
+
+// verifiedBody returns the payload of r, decoding the aws-chunked encoding if used. When
+// sig is set, the payload is checked against what it signed as it is read: the SHA-256
+// in X-Amz-Content-Sha256, or the signature of every chunk of a streaming upload. A
+// payload that does not match fails on a read before io.EOF, so it is never written in
+// full. Unsigned payloads are accepted as sent.
+func verifiedBody(r *http.Request, sig *sigV4) (io.ReadCloser, error) {
+	var body io.Reader = r.Body
+	if isAWSChunked(r) {
+		chunked := newAWSChunkedReader(r.Body)
+		if sig != nil && (sig.payloadHash == streamingSignedPayload || sig.payloadHash == streamingSignedTrailer) {
+			chunked.signer = &chunkSigner{key: sig.key, date: sig.amzDate.Format(sigV4TimeFormat), scope: sig.scope, prev: sig.signature}
+		}
+		body = chunked
+	}
+
+	switch {
+	case sig == nil:
+	case sig.payloadHash == unsignedPayload, sig.payloadHash == streamingUnsignedTrailer:
+	case sig.payloadHash == streamingSignedPayload, sig.payloadHash == streamingSignedTrailer:
+		if !isAWSChunked(r) {
+			return nil, fmt.Errorf("%s requires the aws-chunked encoding", sig.payloadHash)
+		}
+	case isSHA256Hex(sig.payloadHash):
+		body = &sha256Reader{r: body, h: sha256.New(), want: strings.ToLower(sig.payloadHash)}
+	default:
+		return nil, fmt.Errorf("unsupported X-Amz-Content-Sha256 %q", sig.payloadHash)
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{body, r.Body}, nil
+}
+
+func isSHA256Hex(s string) bool {
+	b, err := hex.DecodeString(s)
+	return err == nil && len(b) == sha256.Size
+}
+
+// sha256Reader returns [errPayloadMismatch] in place of io.EOF when what it read does not
+// hash to want.
+type sha256Reader struct {
+	r    io.Reader
+	h    hash.Hash
+	want string
+}
+
+func (s *sha256Reader) Read(b []byte) (int, error) {
+	n, err := s.r.Read(b)
+	s.h.Write(b[:n])
+	if err == io.EOF && hex.EncodeToString(s.h.Sum(nil)) != s.want {
+		return n, errPayloadMismatch
+	}
+	return n, err
+}
+
+// chunkSigner verifies the chained signatures of a STREAMING-AWS4-HMAC-SHA256-PAYLOAD
+// upload, each covering one chunk and the signature before it.
+type chunkSigner struct {
+	key   []byte
+	date  string
+	scope string
+	// prev is the signature of the previous chunk, or of the request for the first one.
+	prev string
+}
+
+// verify checks signature against the SHA-256 of a chunk's data.
+func (s *chunkSigner) verify(signature string, sum []byte) error {
+	stringToSign := strings.Join([]string{
+		sigV4Algorithm + "-PAYLOAD",
+		s.date,
+		s.scope,
+		s.prev,
+		emptySHA256,
+		hex.EncodeToString(sum),
+	}, "\n")
+	want := hex.EncodeToString(hmacSHA256(s.key, stringToSign))
+	if !hmac.Equal([]byte(want), []byte(signature)) {
+		return errSignatureMismatch
+	}
+	s.prev = signature
+	return nil
+}
+
+func parseSigV4Header(r *http.Request, now time.Time) (*sigV4, error) {
+	fields := map[string]string{}
+	for _, part := range strings.Split(strings.TrimPrefix(r.Header.Get("Authorization"), sigV4Algorithm+" "), ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		fields[k] = v
+	}
+	sig, err := newSigV4(fields["Credential"], fields["SignedHeaders"], fields["Signature"], r.Header.Get("X-Amz-Date"))
+	if err != nil {
+		return nil, err
+	}
+	if d := now.Sub(sig.amzDate); d > maxSigV4Skew || d < -maxSigV4Skew {
+		return nil, errRequestExpired
+	}
+	sig.payloadHash = r.Header.Get("X-Amz-Content-Sha256")
+	if sig.payloadHash == "" {
+		sig.payloadHash = unsignedPayload
+	}
+	return sig, nil
+}
+
+func parseSigV4Query(query url.Values, now time.Time) (*sigV4, error) {
+	sig, err := newSigV4(query.Get("X-Amz-Credential"), query.Get("X-Amz-SignedHeaders"), query.Get("X-Amz-Signature"), query.Get("X-Amz-Date"))
+	if err != nil {
+		return nil, err
+	}
+	expires, err := strconv.Atoi(query.Get("X-Amz-Expires"))
+	if err != nil || expires < 0 || time.Duration(expires)*time.Second > maxPresignExpiry {
+		return nil, fmt.Errorf("invalid X-Amz-Expires")
+	}
+	if now.After(sig.amzDate.Add(time.Duration(expires) * time.Second)) {
+		return nil, errRequestExpired
+	}
+	sig.payloadHash = unsignedPayload
+	return sig, nil
+}
+
+func newSigV4(credential, signedHeaders, signature, amzDate string) (*sigV4, error) {
+	// The credential is access-key/date/region/service/aws4_request.
+	parts := strings.SplitN(credential, "/", 2)
+	if len(parts) != 2 || signedHeaders == "" || signature == "" {
+		return nil, fmt.Errorf("malformed authorization")
+	}
+	t, err := time.Parse(sigV4TimeFormat, amzDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid X-Amz-Date: %w", err)
+	}
+	return &sigV4{
+		accessKey:     parts[0],
+		scope:         parts[1],
+		signedHeaders: strings.Split(signedHeaders, ";"),
+		signature:     signature,
+		amzDate:       t,
+	}, nil
+}
+
+// canonicalRequest builds the SigV4 canonical form of r.
+func canonicalRequest(r *http.Request, sig *sigV4) string {
+	var headers strings.Builder
+	for _, name := range sig.signedHeaders {
+		var value string
+		switch name {
+		case "host":
+			value = r.Host
+		case "content-length":
+			value = r.Header.Get("Content-Length")
+			if value == "" && r.ContentLength >= 0 {
+				value = strconv.FormatInt(r.ContentLength, 10)
+			}
+		default:
+			value = strings.Join(r.Header.Values(name), ",")
+		}
+		headers.WriteString(name + ":" + strings.Join(strings.Fields(value), " ") + "\n")
+	}
+
+	return strings.Join([]string{
+		r.Method,
+		r.URL.EscapedPath(),
+		canonicalQuery(r.URL.RawQuery),
+		headers.String(),
+		strings.Join(sig.signedHeaders, ";"),
+		sig.payloadHash,
+	}, "\n")
+}
+
+// canonicalQuery sorts and re-encodes the query string, leaving out the signature of a
+// presigned URL.
+func canonicalQuery(raw string) string {
+	var pairs []string
+	for _, part := range strings.Split(raw, "&") {
+		if part == "" {
+			continue
+		}
+		k, v, _ := strings.Cut(part, "=")
+		k, _ = url.QueryUnescape(k)
+		v, _ = url.QueryUnescape(v)
+		if k == "X-Amz-Signature" {
+			continue
+		}
+		pairs = append(pairs, awsURIEncode(k)+"="+awsURIEncode(v))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// awsURIEncode percent-encodes everything but the unreserved characters, as SigV4
+// requires.
+func awsURIEncode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// isAWSChunked reports whether the body of r uses the aws-chunked encoding, which S3
+// clients use to stream payloads with per-chunk signatures or trailing checksums.
+func isAWSChunked(r *http.Request) bool {
+	return strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") ||
+		strings.Contains(r.Header.Get("Content-Encoding"), "aws-chunked")
+}
+
+// awsChunkedReader decodes an aws-chunked body. Chunk signatures are verified when signer
+// is set. Trailing checksums are skipped.
+type awsChunkedReader struct {
+	r         *bufio.Reader
+	remaining int64
+	done      bool
+	signer    *chunkSigner
+	// signature and hash belong to the chunk being read.
+	signature string
+	hash      hash.Hash
+}
+
+func newAWSChunkedReader(r io.Reader) *awsChunkedReader {
+	return &awsChunkedReader{r: bufio.NewReader(r)}
+}
+
+func (c *awsChunkedReader) Read(b []byte) (int, error) {
+	for c.remaining == 0 {
+		if c.done {
+			return 0, io.EOF
+		}
+		if err := c.nextChunk(); err != nil {
+			return 0, err
+		}
+	}
+	if int64(len(b)) > c.remaining {
+		b = b[:c.remaining]
+	}
+	n, err := c.r.Read(b)
+	c.remaining -= int64(n)
+	if c.hash != nil {
+		c.hash.Write(b[:n])
+	}
+	if c.remaining == 0 && err == nil {
+		if err = c.verifyChunk(); err == nil {
+			err = c.skipLine()
+		}
+	}
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+// nextChunk reads a chunk header of the form "size[;chunk-signature=...]". A zero size
+// ends the body; any trailers that follow are discarded.
+func (c *awsChunkedReader) nextChunk() error {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return io.ErrUnexpectedEOF
+	}
+	sizeHex, ext, _ := strings.Cut(strings.TrimSpace(line), ";")
+	size, err := strconv.ParseInt(sizeHex, 16, 64)
+	if err != nil || size < 0 {
+		return fmt.Errorf("invalid aws-chunked chunk size %q", sizeHex)
+	}
+	if c.signer != nil {
+		c.signature, _ = strings.CutPrefix(ext, "chunk-signature=")
+		c.hash = sha256.New()
+	}
+	if size == 0 {
+		if err := c.verifyChunk(); err != nil {
+			return err
+		}
+		c.done = true
+		_, err := io.Copy(io.Discard, c.r)
+		return err
+	}
+	c.remaining = size
+	return nil
+}
+
+// verifyChunk checks the signature of the chunk that was just read in full.
+func (c *awsChunkedReader) verifyChunk() error {
+	if c.signer == nil {
+		return nil
+	}
+	return c.signer.verify(c.signature, c.hash.Sum(nil))
+}
+
+func (c *awsChunkedReader) skipLine() error {
+	_, err := c.r.ReadString('\n')
+	return err
+}
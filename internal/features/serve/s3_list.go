@@ -0,0 +1,221 @@
+package serve
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/michaeldcanady/go-onedrive/internal/features/vfs"
+)
+
+const maxListKeys = 1000
+
+// listEntry is an object or common prefix in a listing.
+type listEntry struct {
+	key    string
+	node   *vfs.Node
+	prefix bool
+}
+
+// listObjects serves ListObjectsV2 when list-type=2 is given, and ListObjects otherwise.
+func (g *S3Gateway) listObjects(ctx context.Context, w http.ResponseWriter, bucket string, query url.Values) error {
+	v2 := query.Get("list-type") == "2"
+	prefix, delimiter := query.Get("prefix"), query.Get("delimiter")
+
+	maxKeys := maxListKeys
+	if v := query.Get("max-keys"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return &s3Error{http.StatusBadRequest, "InvalidArgument", "invalid max-keys"}
+		}
+		maxKeys = min(n, maxListKeys)
+	}
+
+	after := query.Get("marker")
+	if v2 {
+		after = query.Get("start-after")
+		if token := query.Get("continuation-token"); token != "" {
+			decoded, err := base64.RawURLEncoding.DecodeString(token)
+			if err != nil {
+				return &s3Error{http.StatusBadRequest, "InvalidArgument", "invalid continuation token"}
+			}
+			after = string(decoded)
+		}
+	}
+
+	entries, err := g.collect(ctx, bucket, prefix, delimiter)
+	if err != nil {
+		return err
+	}
+	page, truncated := paginate(entries, after, maxKeys)
+
+	encode := func(s string) string { return s }
+	if query.Get("encoding-type") == "url" {
+		encode = url.QueryEscape
+	}
+	result := listBucketResult{
+		Xmlns:        s3Namespace,
+		Name:         bucket,
+		Prefix:       encode(prefix),
+		Delimiter:    encode(delimiter),
+		MaxKeys:      maxKeys,
+		IsTruncated:  truncated,
+		EncodingType: query.Get("encoding-type"),
+	}
+	for _, e := range page {
+		if e.prefix {
+			result.CommonPrefixes = append(result.CommonPrefixes, commonPrefix{Prefix: encode(e.key)})
+			continue
+		}
+		result.Contents = append(result.Contents, s3Object{
+			Key:          encode(e.key),
+			LastModified: time.Unix(e.node.ModifiedAt, 0).UTC().Format(s3TimeFormat),
+			ETag:         etagOf(e.node),
+			Size:         e.node.Size,
+			StorageClass: "STANDARD",
+		})
+	}
+
+	var last string
+	if len(page) > 0 {
+		last = page[len(page)-1].key
+	}
+	if v2 {
+		keyCount := len(page)
+		result.KeyCount = &keyCount
+		result.StartAfter = encode(query.Get("start-after"))
+		result.ContinuationToken = query.Get("continuation-token")
+		if truncated {
+			result.NextContinuationToken = base64.RawURLEncoding.EncodeToString([]byte(last))
+		}
+	} else {
+		marker := encode(query.Get("marker"))
+		result.Marker = &marker
+		if truncated && delimiter != "" {
+			result.NextMarker = encode(last)
+		}
+	}
+	writeXML(w, result)
+	return nil
+}
+
+// collect walks the bucket for keys beginning with prefix, sorted as S3 orders them.
+// Only directories that can contain matching keys are listed, and keys containing the
+// delimiter after the prefix are rolled up into common prefixes without descending
+// further.
+func (g *S3Gateway) collect(ctx context.Context, bucket, prefix, delimiter string) ([]listEntry, error) {
+	var entries []listEntry
+	prefixes := map[string]bool{}
+
+	rollUp := func(key string) bool {
+		if delimiter == "" {
+			return false
+		}
+		i := strings.Index(key[len(prefix):], delimiter)
+		if i < 0 {
+			return false
+		}
+		prefixes[key[:len(prefix)+i+len(delimiter)]] = true
+		return true
+	}
+
+	var walk func(dir string) error
+	walk = func(dir string) error {
+		nodes, err := g.vfs.List(ctx, path.Join("/", bucket, dir))
+		if errors.Is(err, vfs.ErrNotFound) || errors.Is(err, vfs.ErrNotADirectory) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for _, n := range nodes {
+			key := dir + n.Name
+			if n.Type == vfs.DirectoryType {
+				key += "/"
+				switch {
+				case strings.HasPrefix(key, prefix):
+					if !rollUp(key) {
+						if err := walk(key); err != nil {
+							return err
+						}
+					}
+				case strings.HasPrefix(prefix, key):
+					if err := walk(key); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			if strings.HasPrefix(key, prefix) && !rollUp(key) {
+				entries = append(entries, listEntry{key: key, node: n})
+			}
+		}
+		return nil
+	}
+
+	// Start from the deepest directory the prefix names.
+	if err := walk(prefix[:strings.LastIndex(prefix, "/")+1]); err != nil {
+		return nil, err
+	}
+	for p := range prefixes {
+		entries = append(entries, listEntry{key: p, prefix: true})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].key < entries[j].key })
+	return entries, nil
+}
+
+// paginate returns up to maxKeys entries following the key after, and whether more
+// remain. A common prefix that after falls within was already returned in full.
+func paginate(entries []listEntry, after string, maxKeys int) ([]listEntry, bool) {
+	start := 0
+	if after != "" {
+		start = sort.Search(len(entries), func(i int) bool { return entries[i].key > after })
+		for start < len(entries) && entries[start].prefix && strings.HasPrefix(after, entries[start].key) {
+			start++
+		}
+	}
+	entries = entries[start:]
+	if len(entries) > maxKeys {
+		return entries[:maxKeys], true
+	}
+	return entries, false
+}
+
+type s3Object struct {
+	Key          string `xml:"Key"`
+	LastModified string `xml:"LastModified"`
+	ETag         string `xml:"ETag"`
+	Size         int64  `xml:"Size"`
+	StorageClass string `xml:"StorageClass"`
+}
+
+type commonPrefix struct {
+	Prefix string `xml:"Prefix"`
+}
+
+type listBucketResult struct {
+	XMLName               xml.Name       `xml:"ListBucketResult"`
+	Xmlns                 string         `xml:"xmlns,attr"`
+	Name                  string         `xml:"Name"`
+	Prefix                string         `xml:"Prefix"`
+	Delimiter             string         `xml:"Delimiter,omitempty"`
+	Marker                *string        `xml:"Marker"`
+	NextMarker            string         `xml:"NextMarker,omitempty"`
+	StartAfter            string         `xml:"StartAfter,omitempty"`
+	ContinuationToken     string         `xml:"ContinuationToken,omitempty"`
+	NextContinuationToken string         `xml:"NextContinuationToken,omitempty"`
+	KeyCount              *int           `xml:"KeyCount"`
+	MaxKeys               int            `xml:"MaxKeys"`
+	EncodingType          string         `xml:"EncodingType,omitempty"`
+	IsTruncated           bool           `xml:"IsTruncated"`
+	Contents              []s3Object     `xml:"Contents"`
+	CommonPrefixes        []commonPrefix `xml:"CommonPrefixes"`
+}
@@ -0,0 +1,242 @@
+package serve
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const maxPartNumber = 10000
+
+var (
+	errInvalidPart      = &s3Error{http.StatusBadRequest, "InvalidPart", "one or more of the specified parts could not be found"}
+	errInvalidPartOrder = &s3Error{http.StatusBadRequest, "InvalidPartOrder", "the list of parts was not in ascending order"}
+)
+
+// multipartUpload is an upload in progress. Parts are kept in a temporary directory
+// until the upload is completed, when they are joined and written to the VFS at once.
+type multipartUpload struct {
+	bucket, key, path string
+	dir               string
+
+	mu    sync.Mutex
+	parts map[int]string // part number to ETag
+}
+
+// multipartUploads tracks the uploads in progress. They live only as long as the
+// gateway.
+type multipartUploads struct {
+	mu      sync.Mutex
+	uploads map[string]*multipartUpload
+}
+
+func newMultipartUploads() *multipartUploads {
+	return &multipartUploads{uploads: map[string]*multipartUpload{}}
+}
+
+func (u *multipartUploads) create(bucket, key, p string) (string, error) {
+	dir, err := os.MkdirTemp("", "odc-s3-upload-*")
+	if err != nil {
+		return "", err
+	}
+	id := make([]byte, 16)
+	rand.Read(id)
+	uploadID := hex.EncodeToString(id)
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.uploads[uploadID] = &multipartUpload{bucket: bucket, key: key, path: p, dir: dir, parts: map[int]string{}}
+	return uploadID, nil
+}
+
+func (u *multipartUploads) get(uploadID string) (*multipartUpload, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	upload, ok := u.uploads[uploadID]
+	if !ok {
+		return nil, errNoSuchUpload
+	}
+	return upload, nil
+}
+
+// remove forgets the upload and deletes its parts.
+func (u *multipartUploads) remove(uploadID string) error {
+	u.mu.Lock()
+	upload, ok := u.uploads[uploadID]
+	delete(u.uploads, uploadID)
+	u.mu.Unlock()
+	if !ok {
+		return errNoSuchUpload
+	}
+	return os.RemoveAll(upload.dir)
+}
+
+func (u *multipartUploads) close() error {
+	u.mu.Lock()
+	ids := make([]string, 0, len(u.uploads))
+	for id := range u.uploads {
+		ids = append(ids, id)
+	}
+	u.mu.Unlock()
+
+	var errs []error
+	for _, id := range ids {
+		if err := u.remove(id); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (upload *multipartUpload) partPath(n int) string {
+	return filepath.Join(upload.dir, fmt.Sprintf("part-%05d", n))
+}
+
+func (g *S3Gateway) createMultipartUpload(w http.ResponseWriter, bucket, key, p string) error {
+	uploadID, err := g.uploads.create(bucket, key, p)
+	if err != nil {
+		return err
+	}
+	writeXML(w, initiateMultipartUploadResult{Xmlns: s3Namespace, Bucket: bucket, Key: key, UploadID: uploadID})
+	return nil
+}
+
+// uploadPart stores one part on disk and returns its MD5 as the ETag.
+func (g *S3Gateway) uploadPart(w http.ResponseWriter, r *http.Request, query url.Values) error {
+	upload, err := g.uploads.get(query.Get("uploadId"))
+	if err != nil {
+		return err
+	}
+	n, err := strconv.Atoi(query.Get("partNumber"))
+	if err != nil || n < 1 || n > maxPartNumber {
+		return &s3Error{http.StatusBadRequest, "InvalidArgument", "part number must be between 1 and 10000"}
+	}
+
+	f, err := os.Create(upload.partPath(n))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	hash := md5.New()
+	if _, err := io.Copy(io.MultiWriter(f, hash), r.Body); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	etag := `"` + hex.EncodeToString(hash.Sum(nil)) + `"`
+	upload.mu.Lock()
+	upload.parts[n] = etag
+	upload.mu.Unlock()
+	w.Header().Set("ETag", etag)
+	return nil
+}
+
+// completeMultipartUpload joins the listed parts into one file and writes it to the VFS.
+func (g *S3Gateway) completeMultipartUpload(w http.ResponseWriter, r *http.Request, query url.Values) error {
+	uploadID := query.Get("uploadId")
+	upload, err := g.uploads.get(uploadID)
+	if err != nil {
+		return err
+	}
+	var req completeMultipartUpload
+	if err := xml.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Parts) == 0 {
+		return errMalformedXML
+	}
+
+	joined, err := os.CreateTemp(upload.dir, "complete-*")
+	if err != nil {
+		return err
+	}
+	defer joined.Close()
+
+	upload.mu.Lock()
+	defer upload.mu.Unlock()
+	for i, part := range req.Parts {
+		if i > 0 && part.PartNumber <= req.Parts[i-1].PartNumber {
+			return errInvalidPartOrder
+		}
+		etag, ok := upload.parts[part.PartNumber]
+		if !ok || strings.Trim(etag, `"`) != strings.Trim(part.ETag, `"`) {
+			return errInvalidPart
+		}
+		if err := appendFile(joined, upload.partPath(part.PartNumber)); err != nil {
+			return err
+		}
+	}
+	if _, err := joined.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	ctx := r.Context()
+	if err := g.mkdirAll(ctx, path.Dir(upload.path)); err != nil {
+		return err
+	}
+	if err := g.vfs.Write(ctx, upload.path, joined); err != nil {
+		return err
+	}
+	result := completeMultipartUploadResult{Xmlns: s3Namespace, Bucket: upload.bucket, Key: upload.key}
+	if node, err := g.vfs.Stat(ctx, upload.path); err == nil {
+		result.ETag = etagOf(node)
+	}
+	joined.Close()
+	if err := g.uploads.remove(uploadID); err != nil {
+		return err
+	}
+	writeXML(w, result)
+	return nil
+}
+
+func (g *S3Gateway) abortMultipartUpload(w http.ResponseWriter, query url.Values) error {
+	if err := g.uploads.remove(query.Get("uploadId")); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func appendFile(dst io.Writer, name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(dst, f)
+	return err
+}
+
+type initiateMultipartUploadResult struct {
+	XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	Bucket   string   `xml:"Bucket"`
+	Key      string   `xml:"Key"`
+	UploadID string   `xml:"UploadId"`
+}
+
+type completeMultipartUpload struct {
+	Parts []struct {
+		PartNumber int    `xml:"PartNumber"`
+		ETag       string `xml:"ETag"`
+	} `xml:"Part"`
+}
+
+type completeMultipartUploadResult struct {
+	XMLName xml.Name `xml:"CompleteMultipartUploadResult"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	Bucket  string   `xml:"Bucket"`
+	Key     string   `xml:"Key"`
+	ETag    string   `xml:"ETag"`
+}
@@ -0,0 +1,331 @@
+package serve
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"time"
+
+	"golang.org/x/net/webdav"
+
+	coreerrors "github.com/michaeldcanady/go-onedrive/internal/core/errors"
+	"github.com/michaeldcanady/go-onedrive/internal/core/logger"
+	"github.com/michaeldcanady/go-onedrive/internal/features/vfs"
+)
+
+var (
+	errReadOnly           = errors.New("server is read-only")
+	errInvalidDestination = errors.New("invalid destination header")
+	errInvalidDepth       = errors.New("invalid depth header")
+	errSameDestination    = errors.New("destination equals source")
+	errIsCollection       = errors.New("cannot put a collection")
+	errMissingParent      = errors.New("parent collection does not exist")
+)
+
+// WebDAVOptions configures a WebDAV handler.
+type WebDAVOptions struct {
+	// ReadOnly rejects every method that would modify the VFS.
+	ReadOnly bool
+}
+
+// NewWebDAVHandler returns an [http.Handler] serving v over WebDAV. PUT and COPY are
+// handled here so that they map directly onto [vfs.VFS.Write] and [vfs.VFS.Copy]; every
+// other method is served by the [webdav.Handler] on top of a VFS-backed file system.
+// Locks are held in memory and only guard requests made through this handler.
+func NewWebDAVHandler(v vfs.VFS, opts WebDAVOptions, l logger.Service) http.Handler {
+	locks := webdav.NewMemLS()
+	return &davHandler{
+		vfs:      v,
+		readOnly: opts.ReadOnly,
+		locks:    locks,
+		logger:   l,
+		dav: &webdav.Handler{
+			FileSystem: &davFS{vfs: v},
+			LockSystem: locks,
+			Logger: func(r *http.Request, err error) {
+				logRequest(l, r, err)
+			},
+		},
+	}
+}
+
+type davHandler struct {
+	vfs      vfs.VFS
+	readOnly bool
+	locks    webdav.LockSystem
+	logger   logger.Service
+	dav      *webdav.Handler
+}
+
+func (h *davHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var (
+		status int
+		err    error
+	)
+	switch r.Method {
+	case http.MethodOptions, http.MethodGet, http.MethodHead, "PROPFIND":
+	default:
+		if h.readOnly {
+			status, err = http.StatusForbidden, errReadOnly
+		}
+	}
+
+	if err == nil {
+		switch r.Method {
+		case http.MethodPut:
+			status, err = h.servePut(w, r)
+		case "COPY":
+			status, err = h.serveCopy(r)
+		case http.MethodGet:
+			// Setting the type up front stops the response from sniffing the first bytes,
+			// which would cost an extra ranged read.
+			w.Header().Set("Content-Type", contentType(r.URL.Path))
+			h.dav.ServeHTTP(w, r)
+			return
+		default:
+			h.dav.ServeHTTP(w, r)
+			return
+		}
+	}
+
+	if d, ok := coreerrors.RetryAfter(err); ok {
+		w.Header().Set("Retry-After", retryAfterSeconds(d))
+	}
+	if status != 0 {
+		w.WriteHeader(status)
+		if status != http.StatusNoContent {
+			w.Write([]byte(webdav.StatusText(status)))
+		}
+	}
+	logRequest(h.logger, r, err)
+}
+
+// servePut streams the request body into the VFS. An If-Match header is checked against
+// the current ETag and forwarded with [vfs.WithIfMatch], so the backend rejects the write
+// if the file changes in between.
+func (h *davHandler) servePut(w http.ResponseWriter, r *http.Request) (int, error) {
+	p := cleanPath(r.URL.Path)
+	release, status, err := h.confirmLocks(r, p, "")
+	if err != nil {
+		return status, err
+	}
+	defer release()
+
+	ctx := r.Context()
+	node, err := h.vfs.Stat(ctx, p)
+	exists := err == nil
+	switch {
+	case errors.Is(err, vfs.ErrNotFound):
+		if status, err := h.checkParent(r, p); err != nil {
+			return status, err
+		}
+	case err != nil:
+		return statusOf(err), err
+	case node.Type == vfs.DirectoryType:
+		return http.StatusMethodNotAllowed, errIsCollection
+	}
+
+	var opts []vfs.WriteOption
+	if v := r.Header.Get("If-Match"); v != "" {
+		if !exists || !matchETag(v, etagOf(node)) {
+			return http.StatusPreconditionFailed, vfs.ErrPrecondition
+		}
+		opts = append(opts, vfs.WithIfMatch(node.ETag))
+	}
+	if v := r.Header.Get("If-None-Match"); v != "" && exists && matchETag(v, etagOf(node)) {
+		return http.StatusPreconditionFailed, vfs.ErrPrecondition
+	}
+
+	if err := h.vfs.Write(ctx, p, r.Body, opts...); err != nil {
+		return statusOf(err), err
+	}
+	if node, err := h.vfs.Stat(ctx, p); err == nil {
+		w.Header().Set("ETag", etagOf(node))
+	}
+	if exists {
+		return http.StatusNoContent, nil
+	}
+	return http.StatusCreated, nil
+}
+
+// serveCopy copies a resource with [vfs.VFS.Copy], honouring the Depth and Overwrite
+// headers.
+func (h *davHandler) serveCopy(r *http.Request) (int, error) {
+	src := cleanPath(r.URL.Path)
+	dst, status, err := destination(r)
+	if err != nil {
+		return status, err
+	}
+	if dst == src {
+		return http.StatusForbidden, errSameDestination
+	}
+
+	// A COPY without a Depth header copies the whole collection.
+	recursive := true
+	switch r.Header.Get("Depth") {
+	case "", "infinity":
+	case "0":
+		recursive = false
+	default:
+		return http.StatusBadRequest, errInvalidDepth
+	}
+
+	release, status, err := h.confirmLocks(r, "", dst)
+	if err != nil {
+		return status, err
+	}
+	defer release()
+
+	ctx := r.Context()
+	srcNode, err := h.vfs.Stat(ctx, src)
+	if err != nil {
+		return statusOf(err), err
+	}
+
+	_, err = h.vfs.Stat(ctx, dst)
+	exists := err == nil
+	switch {
+	case exists && r.Header.Get("Overwrite") == "F":
+		return http.StatusPreconditionFailed, vfs.ErrAlreadyExists
+	case exists:
+		if _, err := h.vfs.Remove(ctx, dst, vfs.WithRecursive()); err != nil {
+			return statusOf(err), err
+		}
+	case errors.Is(err, vfs.ErrNotFound):
+		if status, err := h.checkParent(r, dst); err != nil {
+			return status, err
+		}
+	default:
+		return statusOf(err), err
+	}
+
+	if srcNode.Type == vfs.DirectoryType && !recursive {
+		err = h.vfs.Mkdir(ctx, dst)
+	} else {
+		_, err = h.vfs.Copy(ctx, src, dst, vfs.WithRecursive())
+	}
+	if err != nil {
+		return statusOf(err), err
+	}
+	if exists {
+		return http.StatusNoContent, nil
+	}
+	return http.StatusCreated, nil
+}
+
+// checkParent ensures the collection that would contain p exists.
+func (h *davHandler) checkParent(r *http.Request, p string) (int, error) {
+	parent, err := h.vfs.Stat(r.Context(), path.Dir(p))
+	switch {
+	case errors.Is(err, vfs.ErrNotFound):
+		return http.StatusConflict, errMissingParent
+	case err != nil:
+		return statusOf(err), err
+	case parent.Type != vfs.DirectoryType:
+		return http.StatusConflict, vfs.ErrNotADirectory
+	}
+	return 0, nil
+}
+
+// Patterns extracting the parenthesised lists of an If header and the state tokens
+// inside them.
+var (
+	ifListPattern    = regexp.MustCompile(`\(([^)]*)\)`)
+	lockTokenPattern = regexp.MustCompile(`<([^>]*)>`)
+)
+
+// confirmLocks performs the lock checks [webdav.Handler] makes before modifying src or
+// dst. Without an If header the resources must not be locked; otherwise one of the
+// submitted lock tokens must cover them.
+func (h *davHandler) confirmLocks(r *http.Request, src, dst string) (func(), int, error) {
+	now := time.Now()
+	hdr := r.Header.Get("If")
+	if hdr == "" {
+		// Short-lived locks conflict with any lock held by another client.
+		var tokens []string
+		release := func() {
+			for _, token := range tokens {
+				h.locks.Unlock(now, token)
+			}
+		}
+		for _, p := range []string{src, dst} {
+			if p == "" {
+				continue
+			}
+			token, err := h.locks.Create(now, webdav.LockDetails{Root: p, Duration: -1, ZeroDepth: true})
+			if err != nil {
+				release()
+				if errors.Is(err, webdav.ErrLocked) {
+					return nil, webdav.StatusLocked, err
+				}
+				return nil, http.StatusInternalServerError, err
+			}
+			tokens = append(tokens, token)
+		}
+		return release, 0, nil
+	}
+
+	var conditions []webdav.Condition
+	for _, list := range ifListPattern.FindAllStringSubmatch(hdr, -1) {
+		for _, token := range lockTokenPattern.FindAllStringSubmatch(list[1], -1) {
+			conditions = append(conditions, webdav.Condition{Token: token[1]})
+		}
+	}
+	release, err := h.locks.Confirm(now, src, dst, conditions...)
+	switch {
+	case errors.Is(err, webdav.ErrConfirmationFailed):
+		return nil, http.StatusPreconditionFailed, webdav.ErrLocked
+	case err != nil:
+		return nil, http.StatusInternalServerError, err
+	}
+	return release, 0, nil
+}
+
+// destination returns the VFS path named by the Destination header.
+func destination(r *http.Request) (string, int, error) {
+	hdr := r.Header.Get("Destination")
+	if hdr == "" {
+		return "", http.StatusBadRequest, errInvalidDestination
+	}
+	u, err := url.Parse(hdr)
+	if err != nil {
+		return "", http.StatusBadRequest, errInvalidDestination
+	}
+	if u.Host != "" && u.Host != r.Host {
+		return "", http.StatusBadGateway, errInvalidDestination
+	}
+	return cleanPath(u.Path), 0, nil
+}
+
+// statusOf maps a VFS error onto an HTTP status code.
+func statusOf(err error) int {
+	switch {
+	case errors.Is(err, vfs.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, vfs.ErrPermissionDenied):
+		return http.StatusForbidden
+	case errors.Is(err, vfs.ErrPrecondition):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, vfs.ErrAlreadyExists), errors.Is(err, vfs.ErrIsADirectory):
+		return http.StatusMethodNotAllowed
+	case errors.Is(err, vfs.ErrNotEmpty), errors.Is(err, vfs.ErrNotADirectory):
+		return http.StatusConflict
+	case errors.Is(err, vfs.ErrInvalidPath):
+		return http.StatusBadRequest
+	case errors.Is(err, vfs.ErrUnavailable), errors.Is(err, vfs.ErrThrottled):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func logRequest(l logger.Service, r *http.Request, err error) {
+	l = logger.WithContext(l, r.Context())
+	if err != nil {
+		l.Debug("webdav request failed", "method", r.Method, "path", r.URL.Path, "error", err)
+		return
+	}
+	l.Debug("webdav request", "method", r.Method, "path", r.URL.Path)
+}
@@ -0,0 +1,227 @@
+package serve
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"golang.org/x/net/webdav"
+
+	"github.com/michaeldcanady/go-onedrive/internal/features/vfs"
+)
+
+// davFS adapts a [vfs.VFS] to [webdav.FileSystem]. File content is uploaded by the PUT
+// handler, which streams request bodies straight into the VFS, so files opened for
+// writing here can only be created or truncated.
+type davFS struct {
+	vfs vfs.VFS
+}
+
+var _ webdav.FileSystem = (*davFS)(nil)
+
+func (fs *davFS) Mkdir(ctx context.Context, name string, perm os.FileMode) error {
+	return osError("mkdir", name, fs.vfs.Mkdir(ctx, cleanPath(name)))
+}
+
+func (fs *davFS) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (webdav.File, error) {
+	p := cleanPath(name)
+	if flag&(os.O_WRONLY|os.O_RDWR) != 0 {
+		if err := fs.create(ctx, p, flag); err != nil {
+			return nil, osError("open", name, err)
+		}
+	}
+	node, err := fs.vfs.Stat(ctx, p)
+	if err != nil {
+		return nil, osError("open", name, err)
+	}
+	return &vfsFile{ctx: ctx, vfs: fs.vfs, path: p, node: node}, nil
+}
+
+// create applies the O_CREATE, O_EXCL and O_TRUNC flags by writing an empty file.
+func (fs *davFS) create(ctx context.Context, p string, flag int) error {
+	_, err := fs.vfs.Stat(ctx, p)
+	switch {
+	case err == nil:
+		if flag&os.O_EXCL != 0 {
+			return vfs.ErrAlreadyExists
+		}
+		if flag&os.O_TRUNC == 0 {
+			return nil
+		}
+	case !errors.Is(err, vfs.ErrNotFound) || flag&os.O_CREATE == 0:
+		return err
+	}
+	return fs.vfs.Write(ctx, p, strings.NewReader(""))
+}
+
+func (fs *davFS) RemoveAll(ctx context.Context, name string) error {
+	_, err := fs.vfs.Remove(ctx, cleanPath(name), vfs.WithRecursive())
+	return osError("remove", name, err)
+}
+
+func (fs *davFS) Rename(ctx context.Context, oldName, newName string) error {
+	_, err := fs.vfs.Move(ctx, cleanPath(oldName), cleanPath(newName), vfs.WithRecursive())
+	return osError("rename", oldName, err)
+}
+
+func (fs *davFS) Stat(ctx context.Context, name string) (os.FileInfo, error) {
+	node, err := fs.vfs.Stat(ctx, cleanPath(name))
+	if err != nil {
+		return nil, osError("stat", name, err)
+	}
+	return &fileInfo{node: node}, nil
+}
+
+// vfsFile is an open file or directory. Content is streamed from the VFS and the stream
+// is reopened at the new offset after a seek, so ranged GETs fetch only what they need.
+type vfsFile struct {
+	ctx  context.Context
+	vfs  vfs.VFS
+	path string
+	node *vfs.Node
+
+	pos    int64
+	reader io.ReadCloser
+
+	entries []os.FileInfo
+	listed  bool
+}
+
+var _ webdav.File = (*vfsFile)(nil)
+
+func (f *vfsFile) Read(b []byte) (int, error) {
+	if f.node.Type == vfs.DirectoryType {
+		return 0, &os.PathError{Op: "read", Path: f.path, Err: vfs.ErrIsADirectory}
+	}
+	if f.pos >= f.node.Size {
+		return 0, io.EOF
+	}
+	if f.reader == nil {
+		r, err := f.vfs.Read(f.ctx, f.path, vfs.WithRange(f.pos, 0))
+		if err != nil {
+			return 0, osError("read", f.path, err)
+		}
+		f.reader = r
+	}
+	n, err := f.reader.Read(b)
+	f.pos += int64(n)
+	return n, err
+}
+
+func (f *vfsFile) Seek(offset int64, whence int) (int64, error) {
+	pos := offset
+	switch whence {
+	case io.SeekCurrent:
+		pos += f.pos
+	case io.SeekEnd:
+		pos += f.node.Size
+	}
+	if pos < 0 {
+		return 0, &os.PathError{Op: "seek", Path: f.path, Err: os.ErrInvalid}
+	}
+	if pos != f.pos && f.reader != nil {
+		f.reader.Close()
+		f.reader = nil
+	}
+	f.pos = pos
+	return pos, nil
+}
+
+func (f *vfsFile) Readdir(count int) ([]os.FileInfo, error) {
+	if !f.listed {
+		nodes, err := f.vfs.List(f.ctx, f.path)
+		if err != nil {
+			return nil, osError("readdir", f.path, err)
+		}
+		for _, n := range nodes {
+			f.entries = append(f.entries, &fileInfo{node: n})
+		}
+		f.listed = true
+	}
+	if count <= 0 {
+		entries := f.entries
+		f.entries = nil
+		return entries, nil
+	}
+	if len(f.entries) == 0 {
+		return nil, io.EOF
+	}
+	n := min(count, len(f.entries))
+	entries := f.entries[:n]
+	f.entries = f.entries[n:]
+	return entries, nil
+}
+
+func (f *vfsFile) Stat() (os.FileInfo, error) {
+	return &fileInfo{node: f.node}, nil
+}
+
+func (f *vfsFile) Write(b []byte) (int, error) {
+	return 0, &os.PathError{Op: "write", Path: f.path, Err: os.ErrPermission}
+}
+
+func (f *vfsFile) Close() error {
+	if f.reader != nil {
+		err := f.reader.Close()
+		f.reader = nil
+		return err
+	}
+	return nil
+}
+
+// fileInfo describes a [vfs.Node]. It provides ETags and content types directly, so that
+// PROPFIND never has to open files.
+type fileInfo struct {
+	node *vfs.Node
+}
+
+var (
+	_ webdav.ETager       = (*fileInfo)(nil)
+	_ webdav.ContentTyper = (*fileInfo)(nil)
+)
+
+func (fi *fileInfo) Name() string       { return fi.node.Name }
+func (fi *fileInfo) Size() int64        { return fi.node.Size }
+func (fi *fileInfo) ModTime() time.Time { return time.Unix(fi.node.ModifiedAt, 0) }
+func (fi *fileInfo) IsDir() bool        { return fi.node.Type == vfs.DirectoryType }
+func (fi *fileInfo) Sys() any           { return nil }
+
+func (fi *fileInfo) Mode() os.FileMode {
+	if fi.IsDir() {
+		return os.ModeDir | 0755
+	}
+	return 0644
+}
+
+func (fi *fileInfo) ETag(ctx context.Context) (string, error) {
+	return etagOf(fi.node), nil
+}
+
+func (fi *fileInfo) ContentType(ctx context.Context) (string, error) {
+	return contentType(fi.node.Name), nil
+}
+
+// cleanPath turns a request path into an absolute VFS path.
+func cleanPath(p string) string {
+	return path.Clean("/" + p)
+}
+
+// osError translates VFS errors into the os errors the webdav package checks for.
+func osError(op, name string, err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, vfs.ErrNotFound):
+		return &os.PathError{Op: op, Path: name, Err: os.ErrNotExist}
+	case errors.Is(err, vfs.ErrAlreadyExists):
+		return &os.PathError{Op: op, Path: name, Err: os.ErrExist}
+	case errors.Is(err, vfs.ErrPermissionDenied):
+		return &os.PathError{Op: op, Path: name, Err: os.ErrPermission}
+	default:
+		return err
+	}
+}
@@ -0,0 +1,254 @@
+package serve
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/michaeldcanady/go-onedrive/internal/core/logger/loggertest"
+	"github.com/michaeldcanady/go-onedrive/internal/features/vfs/vfstest"
+)
+
+func newDAVServer(t *testing.T, fs *vfstest.MemFS, opts WebDAVOptions) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(NewWebDAVHandler(fs, opts, loggertest.Nop{}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func do(t *testing.T, server *httptest.Server, method, p, body string, headers map[string]string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, server.URL+p, strings.NewReader(body))
+	require.NoError(t, err)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := server.Client().Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func readBody(t *testing.T, resp *http.Response) string {
+	t.Helper()
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestWebDAV_Propfind(t *testing.T) {
+	fs := vfstest.NewMemFS(map[string]string{"/docs/a.txt": "hello", "/docs/sub/": ""})
+	server := newDAVServer(t, fs, WebDAVOptions{})
+
+	resp := do(t, server, "PROPFIND", "/docs/", "", map[string]string{"Depth": "1"})
+	require.Equal(t, http.StatusMultiStatus, resp.StatusCode)
+	body := readBody(t, resp)
+
+	assert.Contains(t, body, "<D:href>/docs/a.txt</D:href>")
+	assert.Contains(t, body, "<D:href>/docs/sub/</D:href>")
+	assert.Contains(t, body, `<D:getetag>"1"</D:getetag>`)
+	assert.Contains(t, body, "<D:getcontenttype>text/plain; charset=utf-8</D:getcontenttype>")
+}
+
+func TestWebDAV_Get(t *testing.T) {
+	fs := vfstest.NewMemFS(map[string]string{"/docs/a.txt": "hello world"})
+	server := newDAVServer(t, fs, WebDAVOptions{})
+
+	t.Run("whole file", func(t *testing.T) {
+		resp := do(t, server, http.MethodGet, "/docs/a.txt", "", nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "hello world", readBody(t, resp))
+		assert.Equal(t, `"1"`, resp.Header.Get("ETag"))
+	})
+
+	t.Run("range", func(t *testing.T) {
+		resp := do(t, server, http.MethodGet, "/docs/a.txt", "", map[string]string{"Range": "bytes=6-"})
+		require.Equal(t, http.StatusPartialContent, resp.StatusCode)
+		assert.Equal(t, "world", readBody(t, resp))
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		resp := do(t, server, http.MethodGet, "/docs/missing.txt", "", nil)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
+
+func TestWebDAV_Put(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		headers    map[string]string
+		wantStatus int
+		wantData   string
+		wantMatch  []string
+	}{
+		{
+			name:       "creates a file",
+			path:       "/docs/b.txt",
+			wantStatus: http.StatusCreated,
+			wantData:   "new",
+		},
+		{
+			name:       "overwrites a file",
+			path:       "/docs/a.txt",
+			wantStatus: http.StatusNoContent,
+			wantData:   "new",
+		},
+		{
+			name:       "forwards a matching if-match",
+			path:       "/docs/a.txt",
+			headers:    map[string]string{"If-Match": `"1"`},
+			wantStatus: http.StatusNoContent,
+			wantData:   "new",
+			wantMatch:  []string{"1"},
+		},
+		{
+			name:       "rejects a stale if-match",
+			path:       "/docs/a.txt",
+			headers:    map[string]string{"If-Match": `"7"`},
+			wantStatus: http.StatusPreconditionFailed,
+			wantData:   "old",
+		},
+		{
+			name:       "rejects if-none-match on an existing file",
+			path:       "/docs/a.txt",
+			headers:    map[string]string{"If-None-Match": "*"},
+			wantStatus: http.StatusPreconditionFailed,
+			wantData:   "old",
+		},
+		{
+			name:       "requires the parent collection",
+			path:       "/missing/b.txt",
+			wantStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := vfstest.NewMemFS(map[string]string{"/docs/a.txt": "old"})
+			server := newDAVServer(t, fs, WebDAVOptions{})
+
+			resp := do(t, server, http.MethodPut, tt.path, "new", tt.headers)
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+			assert.Equal(t, tt.wantMatch, fs.IfMatch())
+
+			data, _ := fs.File(tt.path)
+			assert.Equal(t, tt.wantData, data)
+			if resp.StatusCode < 300 {
+				assert.NotEmpty(t, resp.Header.Get("ETag"))
+			}
+		})
+	}
+}
+
+func TestWebDAV_Collections(t *testing.T) {
+	fs := vfstest.NewMemFS(map[string]string{"/docs/a.txt": "hello"})
+	server := newDAVServer(t, fs, WebDAVOptions{})
+
+	resp := do(t, server, "MKCOL", "/docs/sub", "", nil)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.True(t, fs.Exists("/docs/sub"))
+
+	resp = do(t, server, "COPY", "/docs/a.txt", "", map[string]string{"Destination": server.URL + "/docs/sub/a.txt"})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	data, _ := fs.File("/docs/sub/a.txt")
+	assert.Equal(t, "hello", data)
+
+	resp = do(t, server, "COPY", "/docs/a.txt", "", map[string]string{"Destination": "/docs/sub/a.txt", "Overwrite": "F"})
+	assert.Equal(t, http.StatusPreconditionFailed, resp.StatusCode)
+
+	resp = do(t, server, "MOVE", "/docs/sub", "", map[string]string{"Destination": "/docs/moved"})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.True(t, fs.Exists("/docs/moved/a.txt"))
+	assert.False(t, fs.Exists("/docs/sub"))
+
+	resp = do(t, server, http.MethodDelete, "/docs/moved", "", nil)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.False(t, fs.Exists("/docs/moved"))
+
+	assert.Equal(t, []string{
+		"Mkdir /docs/sub",
+		"Copy /docs/a.txt /docs/sub/a.txt",
+		"Move /docs/sub /docs/moved",
+		"Remove /docs/moved",
+	}, fs.Calls())
+}
+
+func TestWebDAV_Locks(t *testing.T) {
+	fs := vfstest.NewMemFS(map[string]string{"/docs/a.txt": "old"})
+	server := newDAVServer(t, fs, WebDAVOptions{})
+
+	lock := `<?xml version="1.0" encoding="utf-8"?>
+<D:lockinfo xmlns:D="DAV:"><D:lockscope><D:exclusive/></D:lockscope><D:locktype><D:write/></D:locktype></D:lockinfo>`
+	resp := do(t, server, "LOCK", "/docs/a.txt", lock, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	token := resp.Header.Get("Lock-Token")
+	require.NotEmpty(t, token)
+
+	resp = do(t, server, http.MethodPut, "/docs/a.txt", "new", nil)
+	assert.Equal(t, http.StatusLocked, resp.StatusCode)
+
+	resp = do(t, server, http.MethodPut, "/docs/a.txt", "new", map[string]string{"If": "(" + token + ")"})
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	data, _ := fs.File("/docs/a.txt")
+	assert.Equal(t, "new", data)
+}
+
+func TestWebDAV_ReadOnly(t *testing.T) {
+	fs := vfstest.NewMemFS(map[string]string{"/docs/a.txt": "old"})
+	server := newDAVServer(t, fs, WebDAVOptions{ReadOnly: true})
+
+	resp := do(t, server, http.MethodGet, "/docs/a.txt", "", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	for _, method := range []string{http.MethodPut, http.MethodDelete, "MKCOL", "MOVE", "COPY", "LOCK"} {
+		resp := do(t, server, method, "/docs/a.txt", "new", map[string]string{"Destination": "/docs/b.txt"})
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode, method)
+	}
+	assert.Empty(t, fs.Calls())
+}
+
+func TestBasicAuth(t *testing.T) {
+	handler := BasicAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), "user", "secret")
+
+	tests := []struct {
+		name       string
+		user, pass string
+		wantStatus int
+	}{
+		{name: "valid", user: "user", pass: "secret", wantStatus: http.StatusOK},
+		{name: "wrong password", user: "user", pass: "nope", wantStatus: http.StatusUnauthorized},
+		{name: "missing", wantStatus: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.user != "" {
+				req.SetBasicAuth(tt.user, tt.pass)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
+
+func TestIsLoopback(t *testing.T) {
+	tests := map[string]bool{
+		"127.0.0.1:8080": true,
+		"localhost:8080": true,
+		"[::1]:8080":     true,
+		":8080":          false,
+		"0.0.0.0:8080":   false,
+		"10.0.0.1:8080":  false,
+		"invalid":        false,
+	}
+	for addr, want := range tests {
+		assert.Equal(t, want, IsLoopback(addr), addr)
+	}
+}
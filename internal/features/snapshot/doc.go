@@ -0,0 +1,9 @@
+// Package snapshot lets long-running commands, such as odc fuse and the odc serve
+// commands, work from an in-memory copy of state.db. bbolt locks the database for as long
+// as it is open, so a command that kept it open would block every other odc command
+// until it stopped.
+//
+// A [Snapshot] copies the mounts, identities, tokens and plugin metadata, and then
+// closes the database. Refreshed tokens are still written back, each by opening the
+// database just for the write, so rotated refresh tokens are not lost.
+package snapshot
@@ -0,0 +1,203 @@
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.etcd.io/bbolt"
+
+	"github.com/michaeldcanady/go-onedrive/internal/core/logger"
+	"github.com/michaeldcanady/go-onedrive/internal/core/plugins"
+	"github.com/michaeldcanady/go-onedrive/internal/features/config"
+	"github.com/michaeldcanady/go-onedrive/internal/features/identity"
+	"github.com/michaeldcanady/go-onedrive/internal/features/mount"
+	"github.com/michaeldcanady/go-onedrive/internal/features/storage"
+	"github.com/michaeldcanady/go-onedrive/internal/features/vfs"
+)
+
+// Snapshot is the in-memory state a long-running command works from once it has
+// released state.db.
+type Snapshot struct {
+	// Identities holds the identities and their tokens. Tokens saved to it are written
+	// back to state.db.
+	Identities *identity.WriteBackRepository
+	// Mounts lists the mounts recorded when the snapshot was taken.
+	Mounts mount.Service
+	// Plugins launches plugins, verifying them against the metadata recorded when the
+	// snapshot was taken.
+	Plugins plugins.Manager
+	// Tokens refreshes tokens itself and saves them to Identities.
+	Tokens identity.TokenService
+	// VFS serves the mounts recorded when the snapshot was taken. It asks a running agent
+	// for tokens before refreshing them with Tokens, and caches metadata in memory.
+	VFS vfs.VFS
+}
+
+// Close stops the plugins launched for the snapshot and writes back any token that could
+// not be written yet.
+func (s *Snapshot) Close(ctx context.Context) error {
+	return errors.Join(s.Plugins.Shutdown(ctx), s.Identities.Flush())
+}
+
+// Service takes snapshots of state.db.
+type Service interface {
+	// Take copies the mounts, identities, tokens and plugin metadata into memory, stops
+	// the plugins launched so far and closes state.db, so that other odc commands can
+	// open it while the caller runs.
+	Take(ctx context.Context) (*Snapshot, error)
+}
+
+// IdentityRepositoryFactory returns the identity repository kept in db, with the token
+// store the configuration selects.
+type IdentityRepositoryFactory func(db *bbolt.DB) (identity.Repository, error)
+
+// VFSFactory returns the VFS serving the mounts of ms.
+type VFSFactory func(ms mount.Service, pm plugins.Manager, ts identity.TokenService, is identity.Service) (vfs.VFS, error)
+
+type snapshotService struct {
+	storage       storage.Service[storage.BoltDB]
+	config        config.Service
+	pluginManager plugins.Manager
+	identities    IdentityRepositoryFactory
+	newVFS        VFSFactory
+	logger        logger.Service
+}
+
+// NewService returns a new [Service] for the database of s. pm is the plugin manager
+// that is stopped when a snapshot is taken.
+func NewService(s storage.Service[storage.BoltDB], c config.Service, pm plugins.Manager, identities IdentityRepositoryFactory, newVFS VFSFactory, l logger.Service) Service {
+	return &snapshotService{
+		storage:       s,
+		config:        c,
+		pluginManager: pm,
+		identities:    identities,
+		newVFS:        newVFS,
+		logger:        l,
+	}
+}
+
+func (s *snapshotService) Take(ctx context.Context) (*Snapshot, error) {
+	db := s.storage.DB()
+	if db == nil {
+		return nil, fmt.Errorf("state database is closed")
+	}
+	mounts, err := copyMounts(db)
+	if err != nil {
+		return nil, err
+	}
+	identities, err := s.copyIdentities(db)
+	if err != nil {
+		return nil, err
+	}
+	pluginRepo, err := copyPlugins(db)
+	if err != nil {
+		return nil, err
+	}
+
+	s.pluginManager.Shutdown(ctx)
+	if err := s.storage.Close(); err != nil {
+		return nil, fmt.Errorf("failed to release the state database: %w", err)
+	}
+
+	repo := identity.NewWriteBackRepository(identities, s.persist, s.logger)
+	pm := plugins.NewPluginManager(s.config, s.logger, pluginRepo)
+	tokens := identity.NewTokenService(repo, pm, s.config, s.logger)
+	socket, err := identity.DefaultAgentSocket()
+	if err != nil {
+		pm.Shutdown(ctx)
+		return nil, err
+	}
+	ts := identity.NewAgentClient(socket, tokens, s.logger)
+	is := identity.NewIdentityService(repo, pm, ts, s.logger)
+	ms := mount.NewMountService(mounts, s.logger)
+	v, err := s.newVFS(ms, pm, ts, is)
+	if err != nil {
+		pm.Shutdown(ctx)
+		return nil, err
+	}
+
+	return &Snapshot{
+		Identities: repo,
+		Mounts:     ms,
+		Plugins:    pm,
+		Tokens:     tokens,
+		VFS:        v,
+	}, nil
+}
+
+// persist calls fn with the identity repository in state.db, which is opened only for
+// the duration of the call.
+func (s *snapshotService) persist(fn func(identity.Repository) error) error {
+	return s.storage.Use(func(db *bbolt.DB) error {
+		repo, err := s.identities(db)
+		if err != nil {
+			return err
+		}
+		return fn(repo)
+	})
+}
+
+func copyMounts(db *bbolt.DB) (mount.Repository, error) {
+	stored, err := mount.NewBoltRepository(db)
+	if err != nil {
+		return nil, err
+	}
+	mounts, err := stored.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mounts: %w", err)
+	}
+	repo := mount.NewMemoryRepository()
+	for _, m := range mounts {
+		if err := repo.Save(m); err != nil {
+			return nil, err
+		}
+	}
+	return repo, nil
+}
+
+func (s *snapshotService) copyIdentities(db *bbolt.DB) (identity.Repository, error) {
+	stored, err := s.identities(db)
+	if err != nil {
+		return nil, err
+	}
+	identities, err := stored.ListIdentities()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read identities: %w", err)
+	}
+	repo := identity.NewMemoryRepository()
+	for _, i := range identities {
+		if err := repo.SaveIdentity(i); err != nil {
+			return nil, err
+		}
+		token, err := stored.GetToken(i.Provider, i.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read token for %s: %w", i.ID, err)
+		}
+		if token == nil {
+			continue
+		}
+		if err := repo.SaveToken(i.Provider, i.ID, token); err != nil {
+			return nil, err
+		}
+	}
+	return repo, nil
+}
+
+func copyPlugins(db *bbolt.DB) (plugins.Repository, error) {
+	stored, err := plugins.NewBoltRepository(db)
+	if err != nil {
+		return nil, err
+	}
+	metas, err := stored.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plugin metadata: %w", err)
+	}
+	repo := plugins.NewMemoryRepository()
+	for _, meta := range metas {
+		if err := repo.Set(meta.PluginPath, meta); err != nil {
+			return nil, err
+		}
+	}
+	return repo, nil
+}
@@ -0,0 +1,92 @@
+package snapshot
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+
+	"github.com/michaeldcanady/go-onedrive/internal/core/logger/loggertest"
+	"github.com/michaeldcanady/go-onedrive/internal/core/plugins"
+	"github.com/michaeldcanady/go-onedrive/internal/features/config"
+	"github.com/michaeldcanady/go-onedrive/internal/features/identity"
+	"github.com/michaeldcanady/go-onedrive/internal/features/mount"
+	"github.com/michaeldcanady/go-onedrive/internal/features/storage"
+	"github.com/michaeldcanady/go-onedrive/internal/features/vfs"
+)
+
+type fakeManager struct {
+	plugins.Manager
+	shutdown int
+}
+
+func (m *fakeManager) Shutdown(ctx context.Context) error {
+	m.shutdown++
+	return nil
+}
+
+func boltIdentities(db *bbolt.DB) (identity.Repository, error) {
+	return identity.NewBoltRepository(db)
+}
+
+func TestService_Take(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "state.db")
+	s, err := storage.NewStorageService(dbPath)
+	require.NoError(t, err)
+
+	mounts, err := mount.NewBoltRepository(s.DB())
+	require.NoError(t, err)
+	require.NoError(t, mounts.Save(&mount.Mount{Path: "/docs", Type: "local", IdentityID: "alice"}))
+	identities, err := boltIdentities(s.DB())
+	require.NoError(t, err)
+	require.NoError(t, identities.SaveIdentity(&identity.Identity{ID: "alice", Provider: "azure"}))
+	require.NoError(t, identities.SaveToken("azure", "alice", &identity.Token{AccessToken: "a1", RefreshToken: "r1"}))
+	pluginRepo, err := plugins.NewBoltRepository(s.DB())
+	require.NoError(t, err)
+	require.NoError(t, pluginRepo.Set("/plugins/storage-local", &plugins.Metadata{Name: "local", PluginPath: "/plugins/storage-local", Checksum: "abc"}))
+
+	cfg := config.NewConfigService(config.NewYAMLRepository(filepath.Join(dir, "config.yaml")), loggertest.Nop{})
+	pm := &fakeManager{}
+	var gotMounts mount.Service
+	svc := NewService(s, cfg, pm, boltIdentities, func(ms mount.Service, pm plugins.Manager, ts identity.TokenService, is identity.Service) (vfs.VFS, error) {
+		gotMounts = ms
+		return nil, nil
+	}, loggertest.Nop{})
+
+	snap, err := svc.Take(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, pm.shutdown, "plugins launched before the snapshot are stopped")
+	assert.Nil(t, s.DB(), "state.db is closed")
+
+	db, err := bbolt.Open(dbPath, 0600, &bbolt.Options{Timeout: time.Second})
+	require.NoError(t, err, "another command can open state.db")
+	require.NoError(t, db.Close())
+
+	ms, err := gotMounts.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, ms, 1)
+	assert.Equal(t, "/docs", ms[0].Path)
+	assert.Same(t, gotMounts, snap.Mounts, "the VFS serves the snapshot's mounts")
+	token, err := snap.Identities.GetToken("azure", "alice")
+	require.NoError(t, err)
+	assert.Equal(t, "r1", token.RefreshToken)
+
+	require.NoError(t, snap.Identities.SaveToken("azure", "alice", &identity.Token{AccessToken: "a2", RefreshToken: "r2"}))
+	assert.Nil(t, s.DB(), "writing a token back does not keep state.db open")
+	require.NoError(t, snap.Close(ctx))
+
+	require.NoError(t, s.Use(func(db *bbolt.DB) error {
+		repo, err := boltIdentities(db)
+		require.NoError(t, err)
+		token, err := repo.GetToken("azure", "alice")
+		require.NoError(t, err)
+		assert.Equal(t, "r2", token.RefreshToken, "rotated refresh tokens reach state.db")
+		return nil
+	}))
+}
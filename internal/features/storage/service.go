@@ -2,14 +2,25 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"go.etcd.io/bbolt"
+	bolterrors "go.etcd.io/bbolt/errors"
 )
 
+// openTimeout bounds how long opening the database waits for another process to release
+// its lock.
+const openTimeout = 2 * time.Second
+
+// ErrDatabaseInUse is returned when the database stays locked by another process for
+// longer than the open timeout.
+var ErrDatabaseInUse = errors.New("database in use by another odc command")
+
 // BoltDB is a type alias for the underlying bbolt database instance.
 type BoltDB = *bbolt.DB
 
@@ -22,13 +33,24 @@ type Service[T any] interface {
 	// Close releases all resources associated with the storage service.
 	// Subsequent calls to DB() after Close() will return nil.
 	Close() error
+
+	// Compact rewrites the database into a fresh file that replaces it, so that deleted
+	// values no longer linger in its free pages, and then closes it as Close does.
+	Compact() error
+
+	// Use calls fn with the database. Once the service has been closed, Use opens the
+	// database again for the duration of fn, so that a long-running command which released
+	// it can still write to it briefly.
+	Use(fn func(T) error) error
 }
 
 type storageService[T any] struct {
-	db        T
-	mu        sync.Mutex
-	path      string
-	closeFunc func(T) error
+	db          T
+	mu          sync.Mutex
+	path        string
+	reopenFunc  func(fn func(T) error) error
+	closeFunc   func(T) error
+	compactFunc func(T) error
 }
 
 // NewStorageService returns a new [Service] that manages a [bbolt] database at the specified path.
@@ -40,20 +62,83 @@ func NewStorageService(dbPath string) (Service[*bbolt.DB], error) {
 		return nil, fmt.Errorf("failed to create storage directory: %w", err)
 	}
 
-	db, err := bbolt.Open(dbPath, 0600, nil)
+	db, err := openBolt(dbPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open bbolt database: %w", err)
+		return nil, err
 	}
 
 	return &storageService[*bbolt.DB]{
 		db:   db,
 		path: dbPath,
+		reopenFunc: func(fn func(*bbolt.DB) error) error {
+			db, err := openBolt(dbPath)
+			if err != nil {
+				return err
+			}
+			if err := fn(db); err != nil {
+				db.Close()
+				return err
+			}
+			return db.Close()
+		},
 		closeFunc: func(db *bbolt.DB) error {
 			return db.Close()
 		},
+		compactFunc: func(db *bbolt.DB) error {
+			return compactBolt(db, dbPath)
+		},
 	}, nil
 }
 
+// openBolt opens the database at dbPath, giving up with [ErrDatabaseInUse] when another
+// process holds it for longer than [openTimeout].
+func openBolt(dbPath string) (*bbolt.DB, error) {
+	db, err := bbolt.Open(dbPath, 0600, &bbolt.Options{Timeout: openTimeout})
+	if errors.Is(err, bolterrors.ErrTimeout) {
+		return nil, fmt.Errorf("failed to open %s: %w", dbPath, ErrDatabaseInUse)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bbolt database: %w", err)
+	}
+	return db, nil
+}
+
+// compactBolt copies db into a new file beside dbPath, closes db and moves the copy over
+// it. db is closed even when compaction fails.
+func compactBolt(db *bbolt.DB, dbPath string) error {
+	tmp := dbPath + ".compact"
+	if err := os.Remove(tmp); err != nil && !os.IsNotExist(err) {
+		db.Close()
+		return fmt.Errorf("failed to remove stale compaction file: %w", err)
+	}
+
+	dst, err := bbolt.Open(tmp, 0600, nil)
+	if err != nil {
+		db.Close()
+		return fmt.Errorf("failed to create compacted database: %w", err)
+	}
+	if err := bbolt.Compact(dst, db, 0); err != nil {
+		dst.Close()
+		db.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("failed to compact database: %w", err)
+	}
+	if err := dst.Close(); err != nil {
+		db.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write compacted database: %w", err)
+	}
+	if err := db.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, dbPath); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to replace database: %w", err)
+	}
+	return nil
+}
+
 // NewService returns a new [Service] with the provided database and close function.
 func NewService[T any](db T, closeFunc func(T) error) Service[T] {
 	return &storageService[T]{
@@ -78,7 +163,37 @@ func (s *storageService[T]) Close() error {
 		var zero T
 		s.db = zero
 		s.closeFunc = nil
+		s.compactFunc = nil
 		return err
 	}
 	return nil
 }
+
+func (s *storageService[T]) Use(fn func(T) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closeFunc != nil {
+		return fn(s.db)
+	}
+	if s.reopenFunc == nil {
+		return errors.New("database is closed")
+	}
+	return s.reopenFunc(fn)
+}
+
+func (s *storageService[T]) Compact() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closeFunc == nil {
+		return errors.New("database is closed")
+	}
+	if s.compactFunc == nil {
+		return errors.New("compaction is not supported by this database")
+	}
+	err := s.compactFunc(s.db)
+	var zero T
+	s.db = zero
+	s.closeFunc = nil
+	s.compactFunc = nil
+	return err
+}
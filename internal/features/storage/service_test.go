@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+)
+
+func TestStorageService_Compact(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "state.db")
+	s, err := NewStorageService(dbPath)
+	require.NoError(t, err)
+
+	secret := []byte("plaintext-refresh-token")
+	require.NoError(t, s.DB().Update(func(tx *bbolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte("tokens"))
+		if err != nil {
+			return err
+		}
+		if err := b.Put([]byte("kept"), []byte("value")); err != nil {
+			return err
+		}
+		return b.Put([]byte("deleted"), secret)
+	}))
+	require.NoError(t, s.DB().Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket([]byte("tokens")).Delete([]byte("deleted"))
+	}))
+	data, err := os.ReadFile(dbPath)
+	require.NoError(t, err)
+	require.True(t, bytes.Contains(data, secret), "deleted values linger in free pages")
+
+	require.NoError(t, s.Compact())
+	assert.Nil(t, s.DB())
+	assert.NoFileExists(t, dbPath+".compact")
+
+	data, err = os.ReadFile(dbPath)
+	require.NoError(t, err)
+	assert.False(t, bytes.Contains(data, secret), "compaction drops deleted values")
+
+	db, err := bbolt.Open(dbPath, 0600, nil)
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, db.View(func(tx *bbolt.Tx) error {
+		assert.Equal(t, []byte("value"), tx.Bucket([]byte("tokens")).Get([]byte("kept")))
+		return nil
+	}))
+
+	assert.Error(t, s.Compact(), "a closed database cannot be compacted")
+}
+
+func TestNewStorageService_InUse(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "state.db")
+	s, err := NewStorageService(dbPath)
+	require.NoError(t, err)
+	defer s.Close()
+
+	_, err = NewStorageService(dbPath)
+	assert.ErrorIs(t, err, ErrDatabaseInUse)
+}
+
+func TestStorageService_Use(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "state.db")
+	s, err := NewStorageService(dbPath)
+	require.NoError(t, err)
+
+	put := func(key string) func(*bbolt.DB) error {
+		return func(db *bbolt.DB) error {
+			return db.Update(func(tx *bbolt.Tx) error {
+				b, err := tx.CreateBucketIfNotExists([]byte("kv"))
+				if err != nil {
+					return err
+				}
+				return b.Put([]byte(key), []byte("v"))
+			})
+		}
+	}
+	require.NoError(t, s.Use(put("open")))
+	require.NoError(t, s.Close())
+	require.NoError(t, s.Use(put("reopened")))
+	assert.Nil(t, s.DB(), "Use does not keep a reopened database open")
+
+	db, err := bbolt.Open(dbPath, 0600, nil)
+	require.NoError(t, err, "the reopened database is released")
+	defer db.Close()
+	require.NoError(t, db.View(func(tx *bbolt.Tx) error {
+		assert.NotNil(t, tx.Bucket([]byte("kv")).Get([]byte("open")))
+		assert.NotNil(t, tx.Bucket([]byte("kv")).Get([]byte("reopened")))
+		return nil
+	}))
+}
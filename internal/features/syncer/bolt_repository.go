@@ -0,0 +1,79 @@
+package syncer
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"go.etcd.io/bbolt"
+)
+
+var (
+	syncBucket = []byte("sync")
+)
+
+type boltRepository struct {
+	db *bbolt.DB
+}
+
+// NewBoltRepository creates a new bbolt-based sync state repository. Each
+// source/destination pair is stored in its own nested bucket.
+func NewBoltRepository(db *bbolt.DB) (Repository, error) {
+	err := db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(syncBucket)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize sync bucket: %w", err)
+	}
+
+	return &boltRepository{db: db}, nil
+}
+
+func pairKey(src, dst string) []byte {
+	return []byte(src + "\x00" + dst)
+}
+
+func (r *boltRepository) Load(src, dst string) (map[string]Entry, error) {
+	entries := make(map[string]Entry)
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(syncBucket).Bucket(pairKey(src, dst))
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			var e Entry
+			if err := json.Unmarshal(v, &e); err != nil {
+				return err
+			}
+			entries[string(k)] = e
+			return nil
+		})
+	})
+	return entries, err
+}
+
+func (r *boltRepository) Save(src, dst string, entries map[string]Entry) error {
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		root := tx.Bucket(syncBucket)
+		key := pairKey(src, dst)
+		if root.Bucket(key) != nil {
+			if err := root.DeleteBucket(key); err != nil {
+				return err
+			}
+		}
+		b, err := root.CreateBucket(key)
+		if err != nil {
+			return err
+		}
+		for p, e := range entries {
+			data, err := json.Marshal(e)
+			if err != nil {
+				return err
+			}
+			if err := b.Put([]byte(p), data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,5 @@
+// Package syncer synchronizes two directory trees in the virtual file system.
+// It diffs a source and destination, which may live on different mounts, plans the
+// minimal set of copies and deletes, and records what was synchronized so that later
+// runs can tell which side changed.
+package syncer
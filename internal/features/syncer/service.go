@@ -0,0 +1,486 @@
+package syncer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/michaeldcanady/go-onedrive/internal/core/logger"
+	"github.com/michaeldcanady/go-onedrive/internal/features/vfs"
+)
+
+// mtimeTolerance absorbs the timestamp precision differences between backends
+// when comparing modification times on a first sync.
+const mtimeTolerance = 2
+
+type syncService struct {
+	vfs    vfs.VFS
+	repo   Repository
+	logger logger.Service
+}
+
+// NewSyncService returns a new [Service] that synchronizes trees through the provided [vfs.VFS]
+// and records sync state in repo.
+func NewSyncService(v vfs.VFS, repo Repository, l logger.Service) Service {
+	return &syncService{
+		vfs:    v,
+		repo:   repo,
+		logger: l,
+	}
+}
+
+// step is a planned [Action] together with what the engine needs to apply it
+// and to record the resulting state.
+type step struct {
+	Action
+	// reverse is set when a copy flows from the destination back to the source.
+	reverse bool
+	// from is the node being copied.
+	from *vfs.Node
+}
+
+// planner diffs two trees and accumulates the steps needed to reconcile them.
+type planner struct {
+	fs       vfs.VFS
+	src, dst string
+	opts     Options
+	prev     map[string]Entry
+	// synced holds the entries that are already in sync and need no action.
+	synced map[string]Entry
+	steps  []step
+}
+
+func (s *syncService) Plan(ctx context.Context, src, dst string, opts Options) ([]Action, error) {
+	p, err := s.plan(ctx, src, dst, opts)
+	if err != nil {
+		return nil, err
+	}
+	return p.actions(), nil
+}
+
+func (s *syncService) Sync(ctx context.Context, src, dst string, opts Options) (*Result, error) {
+	l := logger.WithContext(s.logger, ctx)
+
+	p, err := s.plan(ctx, src, dst, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Actions: p.actions(), Summary: &vfs.Summary{}}
+	if p.opts.DryRun {
+		return result, nil
+	}
+
+	state := s.apply(ctx, p, result)
+	if err := s.repo.Save(p.src, p.dst, state); err != nil {
+		return result, fmt.Errorf("failed to save sync state: %w", err)
+	}
+
+	l.Info("sync completed", "src", p.src, "dst", p.dst, "actions", len(result.Actions), "failures", len(result.Summary.Failures))
+	if err := ctx.Err(); err != nil {
+		return result, err
+	}
+	return result, result.Summary.Err()
+}
+
+func (s *syncService) plan(ctx context.Context, src, dst string, opts Options) (*planner, error) {
+	// Plans are made from what the backends hold now, not from cached metadata that may
+	// miss changes made elsewhere.
+	ctx = vfs.WithoutCache(ctx)
+	src, dst = path.Clean(src), path.Clean(dst)
+	if src == dst || strings.HasPrefix(dst, src+"/") || strings.HasPrefix(src, dst+"/") {
+		return nil, fmt.Errorf("cannot sync %s with %s: %w", src, dst, vfs.ErrInvalidPath)
+	}
+
+	if opts.Mode == "" {
+		opts.Mode = ModeOneWay
+	}
+	if opts.Conflict == "" {
+		opts.Conflict = ConflictSkip
+	}
+	switch opts.Mode {
+	case ModeOneWay, ModeTwoWay:
+	default:
+		return nil, fmt.Errorf("unknown sync mode: %s", opts.Mode)
+	}
+	switch opts.Conflict {
+	case ConflictSkip, ConflictNewer, ConflictSource, ConflictDestination:
+	default:
+		return nil, fmt.Errorf("unknown conflict policy: %s", opts.Conflict)
+	}
+
+	srcRoot, err := s.vfs.Stat(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+	if srcRoot.Type != vfs.DirectoryType {
+		return nil, fmt.Errorf("%s: %w", src, vfs.ErrNotADirectory)
+	}
+
+	dstExists := true
+	dstRoot, err := s.vfs.Stat(ctx, dst)
+	switch {
+	case errors.Is(err, vfs.ErrNotFound):
+		dstExists = false
+	case err != nil:
+		return nil, err
+	case dstRoot.Type != vfs.DirectoryType:
+		return nil, fmt.Errorf("%s: %w", dst, vfs.ErrNotADirectory)
+	}
+
+	prev, err := s.repo.Load(src, dst)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load sync state: %w", err)
+	}
+
+	p := &planner{
+		fs:     s.vfs,
+		src:    src,
+		dst:    dst,
+		opts:   opts,
+		prev:   prev,
+		synced: make(map[string]Entry),
+	}
+	if !dstExists {
+		p.add(step{Action: Action{Op: OpMkdir, Target: dst, Reason: "destination does not exist"}})
+	}
+	if err := p.diffDir(ctx, "", true, dstExists); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *planner) actions() []Action {
+	actions := make([]Action, len(p.steps))
+	for i, s := range p.steps {
+		actions[i] = s.Action
+	}
+	return actions
+}
+
+func (p *planner) add(s step) {
+	p.steps = append(p.steps, s)
+}
+
+func (p *planner) srcPath(rel string) string { return path.Join(p.src, rel) }
+func (p *planner) dstPath(rel string) string { return path.Join(p.dst, rel) }
+
+// diffDir compares the children of the directory rel on each side that has it.
+func (p *planner) diffDir(ctx context.Context, rel string, inSrc, inDst bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var srcKids, dstKids map[string]*vfs.Node
+	var err error
+	if inSrc {
+		if srcKids, err = p.list(ctx, p.srcPath(rel)); err != nil {
+			return err
+		}
+	}
+	if inDst {
+		if dstKids, err = p.list(ctx, p.dstPath(rel)); err != nil {
+			return err
+		}
+	}
+
+	names := make([]string, 0, len(srcKids)+len(dstKids))
+	for name := range srcKids {
+		names = append(names, name)
+	}
+	for name := range dstKids {
+		if _, ok := srcKids[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		if err := p.diff(ctx, path.Join(rel, name), srcKids[name], dstKids[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *planner) list(ctx context.Context, dir string) (map[string]*vfs.Node, error) {
+	nodes, err := p.fs.List(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	kids := make(map[string]*vfs.Node, len(nodes))
+	for _, n := range nodes {
+		kids[n.Name] = n
+	}
+	return kids, nil
+}
+
+// diff plans the changes for a single entry; s or d is nil when the entry is missing on that side.
+func (p *planner) diff(ctx context.Context, rel string, s, d *vfs.Node) error {
+	sDir := s != nil && s.Type == vfs.DirectoryType
+	dDir := d != nil && d.Type == vfs.DirectoryType
+
+	switch {
+	case s != nil && d != nil && sDir != dDir:
+		p.add(step{Action: Action{Op: OpConflict, Path: rel, Target: p.dstPath(rel), Reason: "file on one side, directory on the other"}})
+		return nil
+	case sDir && dDir:
+		p.synced[rel] = Entry{Directory: true}
+		return p.diffDir(ctx, rel, true, true)
+	case sDir || dDir:
+		return p.diffLoneDir(ctx, rel, sDir)
+	case s != nil && d != nil:
+		p.diffFiles(rel, s, d)
+		return nil
+	default:
+		p.diffLoneFile(rel, s, d)
+		return nil
+	}
+}
+
+// diffLoneDir handles a directory that exists on only one side.
+func (p *planner) diffLoneDir(ctx context.Context, rel string, inSrc bool) error {
+	_, seen := p.prev[rel]
+
+	if p.opts.Mode == ModeOneWay {
+		if inSrc {
+			p.add(step{Action: Action{Op: OpMkdir, Path: rel, Target: p.dstPath(rel), Reason: "new in source"}})
+			return p.diffDir(ctx, rel, true, false)
+		}
+		if p.opts.Delete {
+			p.add(step{Action: Action{Op: OpDelete, Path: rel, Target: p.dstPath(rel), Reason: "not in source"}})
+		}
+		return nil
+	}
+
+	target := p.dstPath
+	here := p.srcPath
+	if !inSrc {
+		target, here = p.srcPath, p.dstPath
+	}
+
+	if !seen {
+		p.add(step{Action: Action{Op: OpMkdir, Path: rel, Target: target(rel), Reason: "new directory"}})
+		return p.diffDir(ctx, rel, inSrc, !inSrc)
+	}
+
+	// The directory was synchronized before, so the other side deleted it. Propagate
+	// the deletion unless something beneath it changed since, in which case it is
+	// recreated with the changed entries.
+	mark := len(p.steps)
+	if err := p.diffDir(ctx, rel, inSrc, !inSrc); err != nil {
+		return err
+	}
+	nested := p.steps[mark:]
+	if !slices.ContainsFunc(nested, func(s step) bool { return s.Op != OpDelete }) {
+		p.steps = append(p.steps[:mark], step{Action: Action{Op: OpDelete, Path: rel, Target: here(rel), Reason: "deleted on the other side"}})
+		return nil
+	}
+	p.steps = slices.Insert(p.steps, mark, step{Action: Action{Op: OpMkdir, Path: rel, Target: target(rel), Reason: "changed after deletion on the other side"}})
+	return nil
+}
+
+// diffLoneFile handles a file that exists on only one side.
+func (p *planner) diffLoneFile(rel string, s, d *vfs.Node) {
+	prev, seen := p.prev[rel]
+
+	if p.opts.Mode == ModeOneWay {
+		if s != nil {
+			p.add(p.copy(rel, s, false, "new in source"))
+		} else if p.opts.Delete {
+			p.add(step{Action: Action{Op: OpDelete, Path: rel, Target: p.dstPath(rel), Reason: "not in source"}})
+		}
+		return
+	}
+
+	if s != nil {
+		switch {
+		case !seen:
+			p.add(p.copy(rel, s, false, "new in source"))
+		case prev.Source.Equal(FingerprintOf(s)):
+			p.add(step{Action: Action{Op: OpDelete, Path: rel, Target: p.srcPath(rel), Reason: "deleted in destination"}})
+		default:
+			p.add(p.copy(rel, s, false, "modified in source, deleted in destination"))
+		}
+		return
+	}
+
+	switch {
+	case !seen:
+		p.add(p.copy(rel, d, true, "new in destination"))
+	case prev.Destination.Equal(FingerprintOf(d)):
+		p.add(step{Action: Action{Op: OpDelete, Path: rel, Target: p.dstPath(rel), Reason: "deleted in source"}})
+	default:
+		p.add(p.copy(rel, d, true, "modified in destination, deleted in source"))
+	}
+}
+
+// diffFiles handles a file that exists on both sides.
+func (p *planner) diffFiles(rel string, s, d *vfs.Node) {
+	prev, seen := p.prev[rel]
+	sfp, dfp := FingerprintOf(s), FingerprintOf(d)
+
+	if !seen {
+		if quickEqual(s, d) {
+			p.synced[rel] = Entry{Source: sfp, Destination: dfp}
+			return
+		}
+		if p.opts.Mode == ModeOneWay {
+			p.add(p.copy(rel, s, false, "differs from source"))
+			return
+		}
+		p.resolve(rel, s, d, "differs and has no sync history")
+		return
+	}
+
+	srcChanged := !prev.Source.Equal(sfp)
+	dstChanged := !prev.Destination.Equal(dfp)
+
+	switch {
+	case !srcChanged && !dstChanged:
+		p.synced[rel] = prev
+	case p.opts.Mode == ModeOneWay:
+		p.add(p.copy(rel, s, false, "differs from source"))
+	case srcChanged && !dstChanged:
+		p.add(p.copy(rel, s, false, "modified in source"))
+	case dstChanged && !srcChanged:
+		p.add(p.copy(rel, d, true, "modified in destination"))
+	default:
+		p.resolve(rel, s, d, "modified on both sides")
+	}
+}
+
+// resolve applies the conflict policy to a file that changed on both sides.
+func (p *planner) resolve(rel string, s, d *vfs.Node, reason string) {
+	switch p.opts.Conflict {
+	case ConflictSource:
+		p.add(p.copy(rel, s, false, reason+", keeping source"))
+		return
+	case ConflictDestination:
+		p.add(p.copy(rel, d, true, reason+", keeping destination"))
+		return
+	case ConflictNewer:
+		if s.ModifiedAt > d.ModifiedAt {
+			p.add(p.copy(rel, s, false, reason+", source is newer"))
+			return
+		}
+		if d.ModifiedAt > s.ModifiedAt {
+			p.add(p.copy(rel, d, true, reason+", destination is newer"))
+			return
+		}
+	}
+	p.add(step{Action: Action{Op: OpConflict, Path: rel, Target: p.dstPath(rel), Reason: reason}})
+}
+
+func (p *planner) copy(rel string, from *vfs.Node, reverse bool, reason string) step {
+	a := Action{Op: OpCopy, Path: rel, From: p.srcPath(rel), Target: p.dstPath(rel), Reason: reason}
+	if reverse {
+		a.From, a.Target = a.Target, a.From
+	}
+	return step{Action: a, reverse: reverse, from: from}
+}
+
+// quickEqual reports whether two files without sync history can be assumed identical.
+// A content hash both sides report decides; otherwise the files must match in size and
+// either CTag or modification time. Which side was written later says nothing about
+// its content.
+func quickEqual(s, d *vfs.Node) bool {
+	if s.Size != d.Size {
+		return false
+	}
+	for _, alg := range slices.Sorted(maps.Keys(s.Hashes)) {
+		if sum, ok := d.Hashes[alg]; ok {
+			return sum == s.Hashes[alg]
+		}
+	}
+	if s.CTag != "" && s.CTag == d.CTag {
+		return true
+	}
+	diff := s.ModifiedAt - d.ModifiedAt
+	return diff >= -mtimeTolerance && diff <= mtimeTolerance
+}
+
+// apply executes the planned steps, recording outcomes in result.Summary, and returns
+// the sync state to persist. Steps that fail keep their previous state.
+func (s *syncService) apply(ctx context.Context, p *planner, result *Result) map[string]Entry {
+	var mu sync.Mutex
+	state := maps.Clone(p.synced)
+	// Entries whose step does not complete keep the state from the previous run, so the
+	// next run detects the same change again.
+	for _, st := range p.steps {
+		if prev, ok := p.prev[st.Path]; ok && st.Path != "" {
+			state[st.Path] = prev
+		}
+	}
+
+	sched := vfs.NewScheduler(ctx, p.opts.Concurrency)
+	var deletes []step
+
+	for _, st := range p.steps {
+		switch st.Op {
+		case OpMkdir:
+			if err := s.vfs.Mkdir(ctx, st.Target); err != nil && !errors.Is(err, vfs.ErrAlreadyExists) {
+				sched.Fail(st.Target, err)
+				continue
+			}
+			sched.AddDirectory()
+			if st.Path != "" {
+				mu.Lock()
+				state[st.Path] = Entry{Directory: true}
+				mu.Unlock()
+			}
+		case OpCopy:
+			err := sched.Submit(st.From, func(ctx context.Context) (int64, error) {
+				summary, err := s.vfs.Copy(ctx, st.From, st.Target, vfs.WithTransferProgress(p.opts.Progress))
+				if err != nil {
+					return 0, err
+				}
+				written, err := s.vfs.Stat(ctx, st.Target)
+				if err != nil {
+					return summary.Bytes, err
+				}
+
+				entry := Entry{Source: FingerprintOf(st.from), Destination: FingerprintOf(written)}
+				if st.reverse {
+					entry = Entry{Source: FingerprintOf(written), Destination: FingerprintOf(st.from)}
+				}
+				mu.Lock()
+				state[st.Path] = entry
+				mu.Unlock()
+				return summary.Bytes, nil
+			})
+			if err != nil {
+				sched.Fail(st.From, err)
+			}
+		case OpDelete:
+			deletes = append(deletes, st)
+		}
+	}
+
+	result.Summary = sched.Wait()
+
+	// Deletes run last, deepest first, so nothing is removed before the copies that
+	// may still read from it have finished.
+	for _, st := range slices.Backward(deletes) {
+		if ctx.Err() != nil {
+			break
+		}
+		if _, err := s.vfs.Remove(ctx, st.Target, vfs.WithRecursive()); err != nil && !errors.Is(err, vfs.ErrNotFound) {
+			result.Summary.Fail(st.Target, err)
+			continue
+		}
+		delete(state, st.Path)
+		for rel := range state {
+			if strings.HasPrefix(rel, st.Path+"/") {
+				delete(state, rel)
+			}
+		}
+	}
+
+	return state
+}
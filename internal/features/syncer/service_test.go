@@ -0,0 +1,253 @@
+package syncer
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/michaeldcanady/go-onedrive/internal/core/logger/loggertest"
+	"github.com/michaeldcanady/go-onedrive/internal/features/vfs"
+	"github.com/michaeldcanady/go-onedrive/internal/features/vfs/vfstest"
+)
+
+// memRepository is an in-memory [Repository].
+type memRepository map[string]map[string]Entry
+
+func (r memRepository) Load(src, dst string) (map[string]Entry, error) {
+	return r[src+"\x00"+dst], nil
+}
+
+func (r memRepository) Save(src, dst string, entries map[string]Entry) error {
+	r[src+"\x00"+dst] = entries
+	return nil
+}
+
+// sized returns content of n bytes.
+func sized(n int) string {
+	return strings.Repeat("x", n)
+}
+
+type op struct {
+	Op     Op
+	Target string
+}
+
+func ops(actions []Action) []op {
+	out := make([]op, len(actions))
+	for i, a := range actions {
+		out[i] = op{a.Op, a.Target}
+	}
+	return out
+}
+
+func TestSyncService_OneWay(t *testing.T) {
+	ctx := context.Background()
+	fs := vfstest.NewMemFS(nil)
+	fs.Put("/src/a.txt", sized(10))
+	fs.Put("/src/docs/b.txt", sized(20))
+
+	svc := NewSyncService(fs, memRepository{}, loggertest.Nop{})
+
+	result, err := svc.Sync(ctx, "/src", "/dst", Options{})
+	require.NoError(t, err)
+	assert.Equal(t, []op{
+		{OpMkdir, "/dst"},
+		{OpCopy, "/dst/a.txt"},
+		{OpMkdir, "/dst/docs"},
+		{OpCopy, "/dst/docs/b.txt"},
+	}, ops(result.Actions))
+	assert.Equal(t, 2, result.Summary.Files)
+	assert.Equal(t, int64(30), result.Summary.Bytes)
+
+	actions, err := svc.Plan(ctx, "/src", "/dst", Options{})
+	require.NoError(t, err)
+	assert.Empty(t, actions, "a second run should find nothing to do")
+
+	fs.Put("/src/a.txt", sized(11))
+	fs.Put("/dst/extra.txt", sized(5))
+
+	actions, err = svc.Plan(ctx, "/src", "/dst", Options{})
+	require.NoError(t, err)
+	assert.Equal(t, []op{{OpCopy, "/dst/a.txt"}}, ops(actions))
+
+	result, err = svc.Sync(ctx, "/src", "/dst", Options{Delete: true})
+	require.NoError(t, err)
+	assert.Equal(t, []op{{OpCopy, "/dst/a.txt"}, {OpDelete, "/dst/extra.txt"}}, ops(result.Actions))
+	assert.False(t, fs.Exists("/dst/extra.txt"))
+}
+
+func TestSyncService_BypassesCache(t *testing.T) {
+	ctx := context.Background()
+	fs := vfstest.NewMemFS(nil)
+	fs.Put("/src/a.txt", sized(10))
+	cached := vfs.CachingMiddleware(vfstest.Mounts(nil), vfs.NewMemoryCache(), time.Hour, loggertest.Nop{})(fs)
+
+	svc := NewSyncService(cached, memRepository{}, loggertest.Nop{})
+	_, err := svc.Sync(ctx, "/src", "/dst", Options{})
+	require.NoError(t, err)
+
+	_, err = cached.List(ctx, "/src")
+	require.NoError(t, err)
+	fs.Put("/src/b.txt", sized(5))
+
+	actions, err := svc.Plan(ctx, "/src", "/dst", Options{})
+	require.NoError(t, err)
+	assert.Equal(t, []op{{OpCopy, "/dst/b.txt"}}, ops(actions), "changes made behind the cache are seen")
+}
+
+func TestSyncService_FirstRun(t *testing.T) {
+	ctx := context.Background()
+	fs := vfstest.NewMemFS(nil)
+	fs.Put("/src/a.txt", "source")
+	fs.Put("/dst/a.txt", "edited")
+	fs.Put("/src/b.txt", "same")
+	fs.Put("/dst/b.txt", "same")
+	fs.Node("/src/b.txt").Hashes = map[string]string{"sha256": "abc"}
+	fs.Node("/dst/b.txt").Hashes = map[string]string{"sha256": "abc"}
+	fs.Put("/src/c.txt", "source")
+	fs.Put("/dst/c.txt", "edited")
+	fs.Node("/src/c.txt").ModifiedAt = fs.Node("/dst/c.txt").ModifiedAt
+	fs.Node("/src/c.txt").Hashes = map[string]string{"sha256": "abc"}
+	fs.Node("/dst/c.txt").Hashes = map[string]string{"sha256": "def"}
+
+	svc := NewSyncService(fs, memRepository{}, loggertest.Nop{})
+	actions, err := svc.Plan(ctx, "/src", "/dst", Options{})
+	require.NoError(t, err)
+	assert.Equal(t, []op{
+		{OpCopy, "/dst/a.txt"},
+		{OpCopy, "/dst/c.txt"},
+	}, ops(actions), "a newer destination is not assumed to be a copy, and hashes outrank modification times")
+}
+
+func TestSyncService_TwoWay(t *testing.T) {
+	tests := []struct {
+		name     string
+		conflict ConflictPolicy
+		change   func(fs *vfstest.MemFS)
+		want     []op
+	}{
+		{
+			name:   "no changes",
+			change: func(fs *vfstest.MemFS) {},
+			want:   []op{},
+		},
+		{
+			name:   "source modified",
+			change: func(fs *vfstest.MemFS) { fs.Put("/src/a.txt", sized(11)) },
+			want:   []op{{OpCopy, "/dst/a.txt"}},
+		},
+		{
+			name:   "destination modified",
+			change: func(fs *vfstest.MemFS) { fs.Put("/dst/a.txt", sized(12)) },
+			want:   []op{{OpCopy, "/src/a.txt"}},
+		},
+		{
+			name:   "new in destination",
+			change: func(fs *vfstest.MemFS) { fs.Put("/dst/new/c.txt", sized(3)) },
+			want:   []op{{OpMkdir, "/src/new"}, {OpCopy, "/src/new/c.txt"}},
+		},
+		{
+			name:   "deleted in destination",
+			change: func(fs *vfstest.MemFS) { fs.Remove(context.Background(), "/dst/a.txt", vfs.WithRecursive()) },
+			want:   []op{{OpDelete, "/src/a.txt"}},
+		},
+		{
+			name:   "directory deleted in source",
+			change: func(fs *vfstest.MemFS) { fs.Remove(context.Background(), "/src/docs", vfs.WithRecursive()) },
+			want:   []op{{OpDelete, "/dst/docs"}},
+		},
+		{
+			name: "directory deleted in source after a change beneath it",
+			change: func(fs *vfstest.MemFS) {
+				fs.Remove(context.Background(), "/src/docs", vfs.WithRecursive())
+				fs.Put("/dst/docs/b.txt", sized(21))
+			},
+			want: []op{{OpMkdir, "/src/docs"}, {OpCopy, "/src/docs/b.txt"}},
+		},
+		{
+			name: "conflict skipped",
+			change: func(fs *vfstest.MemFS) {
+				fs.Put("/src/a.txt", sized(11))
+				fs.Put("/dst/a.txt", sized(12))
+			},
+			want: []op{{OpConflict, "/dst/a.txt"}},
+		},
+		{
+			name:     "conflict keeps source",
+			conflict: ConflictSource,
+			change: func(fs *vfstest.MemFS) {
+				fs.Put("/src/a.txt", sized(11))
+				fs.Put("/dst/a.txt", sized(12))
+			},
+			want: []op{{OpCopy, "/dst/a.txt"}},
+		},
+		{
+			name:     "conflict keeps newer",
+			conflict: ConflictNewer,
+			change: func(fs *vfstest.MemFS) {
+				fs.Put("/src/a.txt", sized(11))
+				fs.Put("/dst/a.txt", sized(12))
+			},
+			want: []op{{OpCopy, "/src/a.txt"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			fs := vfstest.NewMemFS(nil)
+			fs.Put("/src/a.txt", sized(10))
+			fs.Put("/src/docs/b.txt", sized(20))
+			repo := memRepository{}
+			svc := NewSyncService(fs, repo, loggertest.Nop{})
+
+			opts := Options{Mode: ModeTwoWay, Conflict: tt.conflict}
+			_, err := svc.Sync(ctx, "/src", "/dst", opts)
+			require.NoError(t, err)
+
+			tt.change(fs)
+
+			result, err := svc.Sync(ctx, "/src", "/dst", opts)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, ops(result.Actions))
+
+			actions, err := svc.Plan(ctx, "/src", "/dst", opts)
+			require.NoError(t, err)
+			for _, a := range actions {
+				assert.Equal(t, OpConflict, a.Op, "only unresolved conflicts should remain")
+			}
+		})
+	}
+}
+
+func TestSyncService_DryRun(t *testing.T) {
+	fs := vfstest.NewMemFS(nil)
+	fs.Put("/src/a.txt", sized(10))
+	repo := memRepository{}
+	svc := NewSyncService(fs, repo, loggertest.Nop{})
+
+	result, err := svc.Sync(context.Background(), "/src", "/dst", Options{DryRun: true})
+	require.NoError(t, err)
+	assert.Len(t, result.Actions, 2)
+	assert.False(t, fs.Exists("/dst"))
+	assert.Empty(t, repo)
+}
+
+func TestSyncService_InvalidPaths(t *testing.T) {
+	fs := vfstest.NewMemFS(nil)
+	fs.Put("/src/a.txt", sized(10))
+	svc := NewSyncService(fs, memRepository{}, loggertest.Nop{})
+
+	_, err := svc.Plan(context.Background(), "/src", "/src/sub", Options{})
+	assert.ErrorIs(t, err, vfs.ErrInvalidPath)
+
+	_, err = svc.Plan(context.Background(), "/src/a.txt", "/dst", Options{})
+	assert.ErrorIs(t, err, vfs.ErrNotADirectory)
+
+	_, err = svc.Plan(context.Background(), "/src", "/dst", Options{Conflict: "bogus"})
+	assert.Error(t, err)
+}
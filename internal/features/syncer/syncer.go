@@ -0,0 +1,132 @@
+package syncer
+
+import (
+	"context"
+
+	"github.com/michaeldcanady/go-onedrive/internal/features/vfs"
+)
+
+// Mode selects the direction in which changes are propagated.
+type Mode string
+
+const (
+	// ModeOneWay makes the destination mirror the source.
+	ModeOneWay Mode = "one-way"
+	// ModeTwoWay propagates changes, including deletions, in both directions.
+	ModeTwoWay Mode = "two-way"
+)
+
+// ConflictPolicy decides the outcome when a file changed on both sides since the last sync.
+type ConflictPolicy string
+
+const (
+	// ConflictSkip leaves both versions untouched and reports the conflict.
+	ConflictSkip ConflictPolicy = "skip"
+	// ConflictNewer keeps the version with the most recent modification time.
+	ConflictNewer ConflictPolicy = "newer"
+	// ConflictSource keeps the source version.
+	ConflictSource ConflictPolicy = "source"
+	// ConflictDestination keeps the destination version.
+	ConflictDestination ConflictPolicy = "destination"
+)
+
+// Options configures a [Service.Sync] run.
+type Options struct {
+	// Mode selects one- or two-way synchronization. Defaults to [ModeOneWay].
+	Mode Mode
+	// DryRun plans the actions without applying them or updating the sync state.
+	DryRun bool
+	// Delete removes destination entries that do not exist in the source (one-way only).
+	Delete bool
+	// Conflict resolves files changed on both sides (two-way only). Defaults to [ConflictSkip].
+	Conflict ConflictPolicy
+	// Concurrency is the number of files copied in parallel.
+	Concurrency int
+	// Progress, when set, receives updates for every file copied.
+	Progress vfs.ProgressFunc
+}
+
+// Op identifies the kind of change an [Action] applies.
+type Op string
+
+const (
+	// OpMkdir creates a directory.
+	OpMkdir Op = "mkdir"
+	// OpCopy copies a file from one side to the other.
+	OpCopy Op = "copy"
+	// OpDelete removes a file or directory tree.
+	OpDelete Op = "delete"
+	// OpConflict reports a file that was left untouched because both sides changed.
+	OpConflict Op = "conflict"
+)
+
+// Action is a single change planned by the sync engine.
+type Action struct {
+	Op Op `json:"op"`
+	// Path is the entry's path relative to the synchronized roots.
+	Path string `json:"path"`
+	// From is the absolute VFS path the change reads from (copies only).
+	From string `json:"from,omitempty"`
+	// Target is the absolute VFS path the change is applied to.
+	Target string `json:"target"`
+	// Reason explains why the action was planned.
+	Reason string `json:"reason"`
+}
+
+// Result reports the planned actions and, unless the run was a dry run, the outcome of applying them.
+type Result struct {
+	Actions []Action     `json:"actions"`
+	Summary *vfs.Summary `json:"summary"`
+}
+
+// Service compares and synchronizes directory trees.
+type Service interface {
+	// Plan computes the actions needed to synchronize dst with src without applying them.
+	Plan(ctx context.Context, src, dst string, opts Options) ([]Action, error)
+
+	// Sync computes and applies the actions needed to synchronize dst with src,
+	// then records the synchronized state.
+	Sync(ctx context.Context, src, dst string, opts Options) (*Result, error)
+}
+
+// Fingerprint captures the attributes used to detect that a file changed.
+type Fingerprint struct {
+	Tag        string `json:"tag,omitempty"`
+	Size       int64  `json:"size"`
+	ModifiedAt int64  `json:"modified_at"`
+}
+
+// Equal reports whether two fingerprints describe the same content. Content tags are
+// compared when both sides have one; otherwise size and modification time are used.
+func (f Fingerprint) Equal(o Fingerprint) bool {
+	if f.Tag != "" && o.Tag != "" {
+		return f.Tag == o.Tag && f.Size == o.Size
+	}
+	return f.Size == o.Size && f.ModifiedAt == o.ModifiedAt
+}
+
+// FingerprintOf derives a [Fingerprint] from a node, preferring the content tag over the entity tag.
+func FingerprintOf(n *vfs.Node) Fingerprint {
+	tag := n.CTag
+	if tag == "" {
+		tag = n.ETag
+	}
+	return Fingerprint{Tag: tag, Size: n.Size, ModifiedAt: n.ModifiedAt}
+}
+
+// Entry records both sides of a file as they were after the last successful sync.
+type Entry struct {
+	Source      Fingerprint `json:"source"`
+	Destination Fingerprint `json:"destination"`
+	// Directory is set for directory entries, which carry no fingerprints.
+	Directory bool `json:"directory,omitempty"`
+}
+
+// Repository persists the sync state of each source/destination pair.
+type Repository interface {
+	// Load returns the entries recorded for the pair, keyed by relative path.
+	Load(src, dst string) (map[string]Entry, error)
+
+	// Save replaces the entries recorded for the pair.
+	Save(src, dst string, entries map[string]Entry) error
+}
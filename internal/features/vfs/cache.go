@@ -0,0 +1,273 @@
+package vfs
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"maps"
+	"path"
+	"slices"
+	"time"
+
+	"github.com/michaeldcanady/go-onedrive/internal/core/logger"
+	"github.com/michaeldcanady/go-onedrive/internal/features/mount"
+)
+
+// DefaultCacheTTL is how long cached metadata is served without contacting the backend.
+const DefaultCacheTTL = 30 * time.Second
+
+// CacheEntry is a cached [VFS.Stat] or [VFS.List] result.
+type CacheEntry struct {
+	// Node is the cached Stat result.
+	Node *Node `json:"node,omitempty"`
+	// Children is the cached List result.
+	Children []*Node `json:"children,omitempty"`
+	// ETag is the directory's ETag as last seen before it was listed. It is used to
+	// revalidate the listing once it expires.
+	ETag string `json:"etag,omitempty"`
+	// CachedAt is when the entry was stored or last revalidated.
+	CachedAt time.Time `json:"cached_at"`
+}
+
+// CacheStore holds cached metadata keyed by VFS path. The paths it is given are prefixed
+// with a scope naming the backend of the mount they belong to. Implementations must be
+// safe for concurrent use.
+type CacheStore interface {
+	// Get returns the entry stored under key, or nil if there is none.
+	Get(key string) (*CacheEntry, error)
+
+	// Put stores an entry under key, replacing any previous entry.
+	Put(key string, entry *CacheEntry) error
+
+	// Invalidate drops the Stat and List entries for path and, when recursive is set,
+	// for every path beneath it.
+	Invalidate(path string, recursive bool) error
+}
+
+func statKey(p string) string { return "stat:" + p }
+func listKey(p string) string { return "list:" + p }
+
+type noCacheKey struct{}
+
+// WithoutCache returns a context whose reads bypass the cache and always reach the
+// backend. Results are still stored, so later reads see fresh data.
+func WithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noCacheKey{}, true)
+}
+
+func cacheDisabled(ctx context.Context) bool {
+	disabled, _ := ctx.Value(noCacheKey{}).(bool)
+	return disabled
+}
+
+// CachingMiddleware returns a [Middleware] that serves Stat and List results from store
+// for up to ttl. Entries are scoped to the identity, drive and other options of the mount
+// in ms they belong to, so they are not served once the mount points elsewhere. Expired
+// listings are revalidated against the directory's ETag before being fetched again.
+// Mutations made through the returned [VFS] invalidate the affected entries and their
+// parent directories.
+func CachingMiddleware(ms mount.Service, store CacheStore, ttl time.Duration, l logger.Service) Middleware {
+	return func(next VFS) VFS {
+		return &cachingMiddleware{
+			next:   next,
+			mounts: ms,
+			store:  store,
+			ttl:    ttl,
+			logger: l,
+			now:    time.Now,
+		}
+	}
+}
+
+type cachingMiddleware struct {
+	next   VFS
+	mounts mount.Service
+	store  CacheStore
+	ttl    time.Duration
+	logger logger.Service
+	now    func() time.Time
+}
+
+func (m *cachingMiddleware) Stat(ctx context.Context, p string) (*Node, error) {
+	p = path.Clean(p)
+	sp := m.scoped(ctx, p)
+	if !cacheDisabled(ctx) {
+		if e := m.get(ctx, statKey(sp)); e != nil && e.Node != nil && m.fresh(e) {
+			return e.Node, nil
+		}
+	}
+
+	node, err := m.next.Stat(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	m.put(ctx, statKey(sp), &CacheEntry{Node: node, CachedAt: m.now()})
+	return node, nil
+}
+
+func (m *cachingMiddleware) List(ctx context.Context, p string) ([]*Node, error) {
+	p = path.Clean(p)
+	sp := m.scoped(ctx, p)
+	if !cacheDisabled(ctx) {
+		if e := m.get(ctx, listKey(sp)); e != nil {
+			if m.fresh(e) || m.revalidate(ctx, p, sp, e) {
+				return e.Children, nil
+			}
+		}
+	}
+
+	// Capture the directory's ETag before listing. If the directory changes in between,
+	// the tag is already stale and the next revalidation refetches.
+	etag := m.knownETag(ctx, p)
+	nodes, err := m.next.List(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	m.put(ctx, listKey(sp), &CacheEntry{Children: nodes, ETag: etag, CachedAt: m.now()})
+	return nodes, nil
+}
+
+// revalidate reports whether an expired listing of p, cached under the scoped path sp,
+// is still current, renewing it if so.
+func (m *cachingMiddleware) revalidate(ctx context.Context, p, sp string, e *CacheEntry) bool {
+	if e.ETag == "" {
+		return false
+	}
+	node, err := m.next.Stat(ctx, p)
+	if err != nil {
+		return false
+	}
+	m.put(ctx, statKey(sp), &CacheEntry{Node: node, CachedAt: m.now()})
+	if node.ETag != e.ETag {
+		return false
+	}
+	e.CachedAt = m.now()
+	m.put(ctx, listKey(sp), e)
+	return true
+}
+
+// knownETag returns the directory's ETag from any cached Stat or parent listing.
+func (m *cachingMiddleware) knownETag(ctx context.Context, p string) string {
+	if e := m.get(ctx, statKey(m.scoped(ctx, p))); e != nil && e.Node != nil {
+		return e.Node.ETag
+	}
+	if p == "/" {
+		return ""
+	}
+	if e := m.get(ctx, listKey(m.scoped(ctx, path.Dir(p)))); e != nil {
+		name := path.Base(p)
+		for _, n := range e.Children {
+			if n.Name == name {
+				return n.ETag
+			}
+		}
+	}
+	return ""
+}
+
+func (m *cachingMiddleware) Mkdir(ctx context.Context, p string) error {
+	defer m.invalidate(ctx, p)
+	return m.next.Mkdir(ctx, p)
+}
+
+func (m *cachingMiddleware) Remove(ctx context.Context, p string, options ...TreeOption) (*Summary, error) {
+	defer m.invalidate(ctx, p)
+	return m.next.Remove(ctx, p, options...)
+}
+
+func (m *cachingMiddleware) Move(ctx context.Context, src, dst string, options ...TreeOption) (*Summary, error) {
+	defer m.invalidate(ctx, src, dst)
+	return m.next.Move(ctx, src, dst, options...)
+}
+
+func (m *cachingMiddleware) Copy(ctx context.Context, src, dst string, options ...TreeOption) (*Summary, error) {
+	defer m.invalidate(ctx, dst)
+	return m.next.Copy(ctx, src, dst, options...)
+}
+
+func (m *cachingMiddleware) Read(ctx context.Context, p string, options ...ReadOption) (io.ReadCloser, error) {
+	return m.next.Read(ctx, p, options...)
+}
+
+func (m *cachingMiddleware) Write(ctx context.Context, p string, reader io.Reader, options ...WriteOption) error {
+	defer m.invalidate(ctx, p)
+	return m.next.Write(ctx, p, reader, options...)
+}
+
+// Changes passes through to the backend and drops the cached entries it reports as changed.
+func (m *cachingMiddleware) Changes(ctx context.Context, p, cursor string) (*ChangeSet, error) {
+	set, err := m.next.Changes(ctx, p, cursor)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range set.Changes {
+		if c.Node != nil && c.Node.Path != "" {
+			m.invalidate(ctx, c.Node.Path)
+		}
+	}
+	return set, nil
+}
+
+func (m *cachingMiddleware) fresh(e *CacheEntry) bool {
+	return m.now().Sub(e.CachedAt) < m.ttl
+}
+
+// invalidate drops the trees rooted at paths along with their parents' entries.
+func (m *cachingMiddleware) invalidate(ctx context.Context, paths ...string) {
+	for _, p := range paths {
+		p = path.Clean(p)
+		m.drop(ctx, p, true)
+		if p != "/" {
+			m.drop(ctx, path.Dir(p), false)
+		}
+	}
+}
+
+func (m *cachingMiddleware) get(ctx context.Context, key string) *CacheEntry {
+	e, err := m.store.Get(key)
+	if err != nil {
+		logger.WithContext(m.logger, ctx).Warn("failed to read cache entry", "key", key, "error", err)
+		return nil
+	}
+	return e
+}
+
+func (m *cachingMiddleware) put(ctx context.Context, key string, e *CacheEntry) {
+	if err := m.store.Put(key, e); err != nil {
+		logger.WithContext(m.logger, ctx).Warn("failed to write cache entry", "key", key, "error", err)
+	}
+}
+
+func (m *cachingMiddleware) drop(ctx context.Context, p string, recursive bool) {
+	if err := m.store.Invalidate(m.scoped(ctx, p), recursive); err != nil {
+		logger.WithContext(m.logger, ctx).Warn("failed to invalidate cache entry", "path", p, "error", err)
+	}
+}
+
+// scoped returns p prefixed with the scope of the mount owning p. Paths outside every
+// mount, such as the root that lists the mount points, are returned unchanged.
+func (m *cachingMiddleware) scoped(ctx context.Context, p string) string {
+	mounts, err := m.mounts.List(ctx)
+	if err != nil {
+		logger.WithContext(m.logger, ctx).Warn("failed to list mounts for cache scope", "path", p, "error", err)
+		return p
+	}
+	mnt := matchMount(mounts, p)
+	if mnt == nil {
+		return p
+	}
+	return mountScope(mnt) + p
+}
+
+// mountScope identifies the backend a mount points at, changing whenever its type,
+// identity or options, such as the drive, do.
+func mountScope(mnt *mount.Mount) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00%s", mnt.Path, mnt.Type, mnt.IdentityProvider, mnt.IdentityID)
+	for _, k := range slices.Sorted(maps.Keys(mnt.Options)) {
+		fmt.Fprintf(h, "\x00%s=%s", k, mnt.Options[k])
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8]) + ":"
+}
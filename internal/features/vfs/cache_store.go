@@ -0,0 +1,141 @@
+package vfs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"go.etcd.io/bbolt"
+)
+
+var cacheBucket = []byte("vfs_cache")
+
+// cacheKinds lists the key prefixes a path may be cached under.
+var cacheKinds = []string{"stat:", "list:"}
+
+// NewMemoryCache returns a [CacheStore] that lives for the lifetime of the process.
+func NewMemoryCache() CacheStore {
+	return &memoryCache{entries: make(map[string]*CacheEntry)}
+}
+
+type memoryCache struct {
+	mu      sync.RWMutex
+	entries map[string]*CacheEntry
+}
+
+func (c *memoryCache) Get(key string) (*CacheEntry, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, nil
+	}
+	cp := *e
+	return &cp, nil
+}
+
+func (c *memoryCache) Put(key string, entry *CacheEntry) error {
+	cp := *entry
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = &cp
+	return nil
+}
+
+func (c *memoryCache) Invalidate(p string, recursive bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, kind := range cacheKinds {
+		delete(c.entries, kind+p)
+	}
+	if !recursive {
+		return nil
+	}
+	for key := range c.entries {
+		if underCachedPath(key, p) {
+			delete(c.entries, key)
+		}
+	}
+	return nil
+}
+
+// NewBoltCache returns a [CacheStore] persisted in db, so cached metadata survives
+// between invocations.
+func NewBoltCache(db *bbolt.DB) (CacheStore, error) {
+	err := db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(cacheBucket)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize cache bucket: %w", err)
+	}
+
+	return &boltCache{db: db}, nil
+}
+
+type boltCache struct {
+	db *bbolt.DB
+}
+
+func (c *boltCache) Get(key string) (*CacheEntry, error) {
+	var entry *CacheEntry
+	err := c.db.View(func(tx *bbolt.Tx) error {
+		data := tx.Bucket(cacheBucket).Get([]byte(key))
+		if data == nil {
+			return nil
+		}
+		entry = &CacheEntry{}
+		return json.Unmarshal(data, entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (c *boltCache) Put(key string, entry *CacheEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(cacheBucket).Put([]byte(key), data)
+	})
+}
+
+func (c *boltCache) Invalidate(p string, recursive bool) error {
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(cacheBucket)
+		var keys [][]byte
+		for _, kind := range cacheKinds {
+			keys = append(keys, []byte(kind+p))
+			if !recursive {
+				continue
+			}
+			// Keys sort by path, so everything beneath p follows its prefix.
+			prefix := []byte(kind + strings.TrimSuffix(p, "/") + "/")
+			cur := b.Cursor()
+			for k, _ := cur.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = cur.Next() {
+				keys = append(keys, bytes.Clone(k))
+			}
+		}
+		for _, k := range keys {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// underCachedPath reports whether the cache key refers to a path strictly beneath p.
+func underCachedPath(key, p string) bool {
+	for _, kind := range cacheKinds {
+		if rest, ok := strings.CutPrefix(key, kind); ok {
+			return strings.HasPrefix(rest, strings.TrimSuffix(p, "/")+"/")
+		}
+	}
+	return false
+}
@@ -0,0 +1,182 @@
+package vfs_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+
+	"github.com/michaeldcanady/go-onedrive/internal/core/logger/loggertest"
+	"github.com/michaeldcanady/go-onedrive/internal/features/mount"
+	"github.com/michaeldcanady/go-onedrive/internal/features/vfs"
+	"github.com/michaeldcanady/go-onedrive/internal/features/vfs/vfstest"
+)
+
+// countingVFS records the backend calls that reach it.
+type countingVFS struct {
+	*vfstest.MemFS
+	lists, stats int
+}
+
+func (c *countingVFS) List(ctx context.Context, p string) ([]*vfs.Node, error) {
+	c.lists++
+	return c.MemFS.List(ctx, p)
+}
+
+func (c *countingVFS) Stat(ctx context.Context, p string) (*vfs.Node, error) {
+	c.stats++
+	return c.MemFS.Stat(ctx, p)
+}
+
+func cacheStores(t *testing.T) map[string]func() vfs.CacheStore {
+	return map[string]func() vfs.CacheStore{
+		"memory": vfs.NewMemoryCache,
+		"bolt": func() vfs.CacheStore {
+			db, err := bbolt.Open(filepath.Join(t.TempDir(), "cache.db"), 0600, nil)
+			require.NoError(t, err)
+			t.Cleanup(func() { db.Close() })
+			store, err := vfs.NewBoltCache(db)
+			require.NoError(t, err)
+			return store
+		},
+	}
+}
+
+func TestCachingMiddleware(t *testing.T) {
+	ctx := context.Background()
+
+	for name, newStore := range cacheStores(t) {
+		t.Run(name, func(t *testing.T) {
+			setup := func() (*countingVFS, vfs.VFS, *time.Time) {
+				backend := &countingVFS{MemFS: vfstest.NewMemFS(map[string]string{"/d/a.txt": "", "/d/sub/b.txt": "", "/e/b.txt": ""})}
+				backend.Node("/d").ETag = "v1"
+				now := time.Unix(1000, 0)
+				mounts := vfstest.Mounts{{Path: "/d", Type: "local"}, {Path: "/e", Type: "local"}}
+				m := vfs.CachingMiddleware(mounts, newStore(), time.Minute, loggertest.Nop{})(backend)
+				vfs.SetCacheClock(m, func() time.Time { return now })
+				return backend, m, &now
+			}
+
+			t.Run("serves repeated reads from the cache", func(t *testing.T) {
+				backend, m, _ := setup()
+
+				_, err := m.List(ctx, "/d")
+				require.NoError(t, err)
+				nodes, err := m.List(ctx, "/d/")
+				require.NoError(t, err)
+				assert.Len(t, nodes, 2)
+				assert.Equal(t, 1, backend.lists)
+
+				_, err = m.Stat(ctx, "/d/a.txt")
+				require.NoError(t, err)
+				_, err = m.Stat(ctx, "/d/a.txt")
+				require.NoError(t, err)
+				assert.Equal(t, 1, backend.stats)
+			})
+
+			t.Run("bypasses reads without cache", func(t *testing.T) {
+				backend, m, _ := setup()
+
+				_, _ = m.List(ctx, "/d")
+				_, _ = m.List(vfs.WithoutCache(ctx), "/d")
+				assert.Equal(t, 2, backend.lists)
+			})
+
+			t.Run("revalidates expired listings by etag", func(t *testing.T) {
+				backend, m, now := setup()
+
+				_, _ = m.Stat(ctx, "/d")
+				_, _ = m.List(ctx, "/d")
+				*now = now.Add(2 * time.Minute)
+
+				_, _ = m.List(ctx, "/d")
+				assert.Equal(t, 1, backend.lists, "unchanged etag should renew the listing")
+				assert.Equal(t, 2, backend.stats)
+
+				*now = now.Add(2 * time.Minute)
+				backend.Node("/d").ETag = "v2"
+				_, _ = m.List(ctx, "/d")
+				assert.Equal(t, 2, backend.lists, "changed etag should refetch the listing")
+			})
+
+			t.Run("refetches expired listings without an etag", func(t *testing.T) {
+				backend, m, now := setup()
+
+				_, _ = m.List(ctx, "/d/sub")
+				*now = now.Add(2 * time.Minute)
+				_, _ = m.List(ctx, "/d/sub")
+				assert.Equal(t, 2, backend.lists)
+			})
+
+			t.Run("mutations invalidate the tree and its parent", func(t *testing.T) {
+				// Reads of /d, /d/sub and /d/sub/b.txt are made before and after each
+				// mutation, so the counts include the three initial backend calls.
+				tests := []struct {
+					name      string
+					mutate    func(v vfs.VFS) error
+					wantLists int
+					wantStats int
+				}{
+					{"write", func(v vfs.VFS) error { return v.Write(ctx, "/d/sub/c.txt", strings.NewReader("x")) }, 3, 1},
+					{"mkdir", func(v vfs.VFS) error { return v.Mkdir(ctx, "/d/sub/new") }, 3, 1},
+					{"remove", func(v vfs.VFS) error { _, err := v.Remove(ctx, "/d/sub", vfs.WithRecursive()); return err }, 4, 2},
+					{"move", func(v vfs.VFS) error { _, err := v.Move(ctx, "/d/sub", "/moved"); return err }, 4, 2},
+					{"copy", func(v vfs.VFS) error { _, err := v.Copy(ctx, "/e", "/d/sub"); return err }, 4, 2},
+				}
+
+				for _, tt := range tests {
+					t.Run(tt.name, func(t *testing.T) {
+						backend, m, _ := setup()
+
+						_, _ = m.List(ctx, "/d")
+						_, _ = m.List(ctx, "/d/sub")
+						_, _ = m.Stat(ctx, "/d/sub/b.txt")
+						require.NoError(t, tt.mutate(m))
+
+						_, _ = m.List(ctx, "/d")
+						_, _ = m.List(ctx, "/d/sub")
+						_, _ = m.Stat(ctx, "/d/sub/b.txt")
+						assert.Equal(t, tt.wantLists, backend.lists)
+						assert.Equal(t, tt.wantStats, backend.stats)
+					})
+				}
+			})
+
+			t.Run("mutations leave siblings cached", func(t *testing.T) {
+				backend, m, _ := setup()
+
+				_, _ = m.Stat(ctx, "/d/a.txt")
+				require.NoError(t, m.Write(ctx, "/d/sub/b.txt", strings.NewReader("x")))
+				_, _ = m.Stat(ctx, "/d/a.txt")
+				assert.Equal(t, 1, backend.stats)
+			})
+
+			t.Run("entries are scoped to the mount's identity and options", func(t *testing.T) {
+				backend := &countingVFS{MemFS: vfstest.NewMemFS(map[string]string{"/d/a.txt": ""})}
+				mnt := &mount.Mount{Path: "/d", Type: "onedrive", IdentityID: "alice", Options: map[string]string{"drive_id": "1"}}
+				m := vfs.CachingMiddleware(vfstest.Mounts{mnt}, newStore(), time.Minute, loggertest.Nop{})(backend)
+
+				_, _ = m.Stat(ctx, "/d/a.txt")
+				_, _ = m.Stat(ctx, "/d/a.txt")
+				assert.Equal(t, 1, backend.stats)
+
+				mnt.IdentityID = "bob"
+				_, _ = m.Stat(ctx, "/d/a.txt")
+				assert.Equal(t, 2, backend.stats, "another identity does not see the cached entry")
+
+				mnt.Options["drive_id"] = "2"
+				_, _ = m.Stat(ctx, "/d/a.txt")
+				assert.Equal(t, 3, backend.stats, "another drive does not see the cached entry")
+
+				require.NoError(t, m.Write(ctx, "/d/a.txt", strings.NewReader("x")))
+				_, _ = m.Stat(ctx, "/d/a.txt")
+				assert.Equal(t, 4, backend.stats, "mutations invalidate the scoped entries")
+			})
+		})
+	}
+}
@@ -20,7 +20,7 @@ var (
 	ErrNotADirectory = errors.New("not a directory")
 
 	// ErrIsADirectory is returned when a file operation is attempted on a directory.
-	ErrIsADirectory = errors.New("is a directory")
+	ErrIsADirectory = coreerrors.ErrIsADirectory
 
 	// ErrNotEmpty is returned when attempting to remove a non-empty directory.
 	ErrNotEmpty = coreerrors.ErrNotEmpty
@@ -31,6 +31,17 @@ var (
 	// ErrInternal is returned when an unexpected error occurs within the VFS or a storage plugin.
 	ErrInternal = coreerrors.ErrInternal
 
+	// ErrCursorExpired is returned by [VFS.Changes] when the backend can no longer resume from the
+	// supplied cursor. Callers should start over with an empty cursor.
+	ErrCursorExpired = coreerrors.ErrCursorExpired
+
+	// ErrPrecondition is returned when a conditional operation, such as a write made with
+	// [WithIfMatch], finds that the file has changed.
+	ErrPrecondition = coreerrors.ErrPrecondition
+
 	// ErrUnavailable is returned when the underlying storage backend or plugin is unreachable.
 	ErrUnavailable = coreerrors.ErrUnavailable
+
+	// ErrThrottled is returned when the storage backend is rate limiting requests.
+	ErrThrottled = coreerrors.ErrThrottled
 )
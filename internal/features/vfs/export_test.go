@@ -0,0 +1,21 @@
+package vfs
+
+import (
+	"context"
+	"time"
+)
+
+// SetCacheClock replaces the clock of a [VFS] returned by [CachingMiddleware].
+func SetCacheClock(v VFS, now func() time.Time) {
+	v.(*cachingMiddleware).now = now
+}
+
+// SetRetrySleep replaces the function a [VFS] returned by [RetryMiddleware] waits with.
+func SetRetrySleep(v VFS, sleep func(ctx context.Context, d time.Duration) error) {
+	v.(*retryMiddleware).sleep = sleep
+}
+
+// NativeCopy asks the backend of an orchestrator to copy src to dst itself.
+func NativeCopy(ctx context.Context, v VFS, src, dst string) (bool, error) {
+	return v.(*orchestrator).nativeCopy(ctx, src, dst)
+}
@@ -0,0 +1,50 @@
+package vfs_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/michaeldcanady/go-onedrive/internal/features/vfs"
+	"github.com/michaeldcanady/go-onedrive/internal/features/vfs/vfstest"
+)
+
+func TestOrchestrator_LocalPlugin(t *testing.T) {
+	ctx := context.Background()
+	v := vfstest.NewLocal(t, "/local")
+
+	require.NoError(t, v.Mkdir(ctx, "/local/docs"))
+	require.NoError(t, v.Write(ctx, "/local/docs/a.txt", strings.NewReader("hello world")))
+
+	data, err := os.ReadFile(v.Path("/local/docs/a.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", string(data), "writes reach the mounted directory")
+
+	nodes, err := v.List(ctx, "/local/docs")
+	require.NoError(t, err)
+	require.Len(t, nodes, 1)
+	assert.Equal(t, "a.txt", nodes[0].Name)
+	assert.Equal(t, int64(11), nodes[0].Size)
+
+	r, err := v.Read(ctx, "/local/docs/a.txt", vfs.WithRange(6, 5))
+	require.NoError(t, err)
+	data, err = io.ReadAll(r)
+	require.NoError(t, r.Close())
+	require.NoError(t, err)
+	assert.Equal(t, "world", string(data))
+
+	_, err = v.Copy(ctx, "/local/docs/a.txt", "/local/docs/b.txt")
+	require.NoError(t, err)
+	_, err = v.Move(ctx, "/local/docs/b.txt", "/local/c.txt")
+	require.NoError(t, err)
+	assert.FileExists(t, v.Path("/local/c.txt"))
+	assert.NoFileExists(t, v.Path("/local/docs/b.txt"))
+
+	_, err = v.Stat(ctx, "/local/missing.txt")
+	assert.ErrorIs(t, err, vfs.ErrNotFound, "plugin errors are translated")
+}
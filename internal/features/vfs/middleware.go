@@ -47,30 +47,30 @@ func (m *loggingMiddleware) Mkdir(ctx context.Context, path string) error {
 	return err
 }
 
-func (m *loggingMiddleware) Remove(ctx context.Context, path string) error {
+func (m *loggingMiddleware) Remove(ctx context.Context, path string, options ...TreeOption) (*Summary, error) {
 	start := time.Now()
-	err := m.next.Remove(ctx, path)
+	summary, err := m.next.Remove(ctx, path, options...)
 	m.log(ctx, "Remove", path, start, err)
-	return err
+	return summary, err
 }
 
-func (m *loggingMiddleware) Move(ctx context.Context, src, dst string) error {
+func (m *loggingMiddleware) Move(ctx context.Context, src, dst string, options ...TreeOption) (*Summary, error) {
 	start := time.Now()
-	err := m.next.Move(ctx, src, dst)
+	summary, err := m.next.Move(ctx, src, dst, options...)
 	m.logComplex(ctx, "Move", src, dst, start, err)
-	return err
+	return summary, err
 }
 
-func (m *loggingMiddleware) Copy(ctx context.Context, src, dst string) error {
+func (m *loggingMiddleware) Copy(ctx context.Context, src, dst string, options ...TreeOption) (*Summary, error) {
 	start := time.Now()
-	err := m.next.Copy(ctx, src, dst)
+	summary, err := m.next.Copy(ctx, src, dst, options...)
 	m.logComplex(ctx, "Copy", src, dst, start, err)
-	return err
+	return summary, err
 }
 
-func (m *loggingMiddleware) Read(ctx context.Context, path string) (io.ReadCloser, error) {
+func (m *loggingMiddleware) Read(ctx context.Context, path string, options ...ReadOption) (io.ReadCloser, error) {
 	start := time.Now()
-	reader, err := m.next.Read(ctx, path)
+	reader, err := m.next.Read(ctx, path, options...)
 	m.log(ctx, "Read", path, start, err)
 	return reader, err
 }
@@ -82,6 +82,13 @@ func (m *loggingMiddleware) Write(ctx context.Context, path string, reader io.Re
 	return err
 }
 
+func (m *loggingMiddleware) Changes(ctx context.Context, path, cursor string) (*ChangeSet, error) {
+	start := time.Now()
+	set, err := m.next.Changes(ctx, path, cursor)
+	m.log(ctx, "Changes", path, start, err)
+	return set, err
+}
+
 func (m *loggingMiddleware) log(ctx context.Context, op, path string, start time.Time, err error) {
 	l := logger.WithContext(m.logger, ctx)
 	duration := time.Since(start)
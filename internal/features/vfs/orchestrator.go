@@ -2,10 +2,17 @@ package vfs
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"path"
+	"slices"
 	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/michaeldcanady/go-onedrive/internal/core/logger"
 	"github.com/michaeldcanady/go-onedrive/internal/core/plugins"
@@ -20,8 +27,25 @@ type orchestrator struct {
 	tokens     identity.TokenService
 	identities identity.Service
 	logger     logger.Service
+
+	// mu guards capabilities and unsupported.
+	mu sync.Mutex
+	// capabilities caches what each backend's plugin reported through GetMetadata. A nil
+	// entry means the plugin did not report any.
+	capabilities map[string]*plugins.Capabilities
+	// unsupported records operations a backend answered with Unimplemented, so that later
+	// calls use the fallback strategy without asking again.
+	unsupported map[string]bool
 }
 
+// Operations whose strategy depends on the capabilities of a storage plugin.
+const (
+	opMove       = "move"
+	opCopy       = "copy"
+	opRangedRead = "ranged_read"
+	opETag       = "etag_concurrency"
+)
+
 // NewOrchestrator returns a new [VFS] implementation that orchestrates file operations
 // by routing them to the appropriate storage plugins based on active [mount.Mount] points.
 func NewOrchestrator(ms mount.Service, pm plugins.Manager, ts identity.TokenService, is identity.Service, l logger.Service) VFS {
@@ -31,12 +55,18 @@ func NewOrchestrator(ms mount.Service, pm plugins.Manager, ts identity.TokenServ
 		tokens:     ts,
 		identities: is,
 		logger:     l,
+
+		capabilities: make(map[string]*plugins.Capabilities),
+		unsupported:  make(map[string]bool),
 	}
 }
 
 func (o *orchestrator) List(ctx context.Context, path string) ([]*Node, error) {
 	client, relPath, options, err := o.prepare(ctx, path)
 	if err != nil {
+		if nodes := o.virtualDir(ctx, path); nodes != nil {
+			return nodes, nil
+		}
 		return nil, err
 	}
 
@@ -58,6 +88,9 @@ func (o *orchestrator) List(ctx context.Context, path string) ([]*Node, error) {
 func (o *orchestrator) Stat(ctx context.Context, path string) (*Node, error) {
 	client, relPath, options, err := o.prepare(ctx, path)
 	if err != nil {
+		if nodes := o.virtualDir(ctx, path); nodes != nil {
+			return virtualNode(path), nil
+		}
 		return nil, err
 	}
 
@@ -85,7 +118,71 @@ func (o *orchestrator) Mkdir(ctx context.Context, path string) error {
 	return plugins.FromGRPC(err)
 }
 
-func (o *orchestrator) Remove(ctx context.Context, path string) error {
+func (o *orchestrator) Remove(ctx context.Context, path string, options ...TreeOption) (*Summary, error) {
+	to := applyTreeOptions(options)
+	summary := &Summary{}
+
+	node, err := o.Stat(ctx, path)
+	if err != nil {
+		return summary, err
+	}
+
+	if node.Type == DirectoryType {
+		children, err := o.List(ctx, path)
+		if err != nil {
+			return summary, err
+		}
+		if len(children) > 0 {
+			if !to.Recursive {
+				return summary, fmt.Errorf("%s: %w", path, ErrNotEmpty)
+			}
+			o.removeChildren(ctx, path, children, summary)
+			if len(summary.Failures) > 0 {
+				// The directory still holds the entries that failed.
+				return summary, summary.Err()
+			}
+		}
+	}
+
+	if err := o.delete(ctx, path); err != nil {
+		return summary, err
+	}
+	summary.count(node)
+	return summary, nil
+}
+
+// removeChildren deletes the contents of dir depth-first, recording per-entry failures in s.
+// Directories are left in place when any of their own entries could not be deleted.
+func (o *orchestrator) removeChildren(ctx context.Context, dir string, children []*Node, s *Summary) {
+	for _, child := range children {
+		if err := ctx.Err(); err != nil {
+			s.Fail(dir, err)
+			return
+		}
+
+		p := path.Join(dir, child.Name)
+		if child.Type == DirectoryType {
+			grandchildren, err := o.List(ctx, p)
+			if err != nil {
+				s.Fail(p, err)
+				continue
+			}
+			failed := len(s.Failures)
+			o.removeChildren(ctx, p, grandchildren, s)
+			if len(s.Failures) > failed {
+				continue
+			}
+		}
+
+		if err := o.delete(ctx, p); err != nil {
+			s.Fail(p, err)
+			continue
+		}
+		s.count(child)
+	}
+}
+
+func (o *orchestrator) delete(ctx context.Context, path string) error {
 	client, relPath, options, err := o.prepare(ctx, path)
 	if err != nil {
 		return err
@@ -115,60 +212,250 @@ func (o *orchestrator) prepare(ctx context.Context, path string) (storage_proto.
 	return client, relPath, options, nil
 }
 
-func (o *orchestrator) Move(ctx context.Context, src, dst string) error {
+func (o *orchestrator) Move(ctx context.Context, src, dst string, options ...TreeOption) (*Summary, error) {
+	summary := &Summary{}
+
 	srcM, srcRel, err := o.resolvePath(ctx, src)
 	if err != nil {
-		return err
+		return summary, err
 	}
 	dstM, dstRel, err := o.resolvePath(ctx, dst)
 	if err != nil {
-		return err
+		return summary, err
 	}
 
-	if srcM.Path == dstM.Path {
+	if srcM.Path == dstM.Path && o.supports(ctx, srcM, opMove) {
 		// Same mount, try native move
+		node, err := o.Stat(ctx, src)
+		if err != nil {
+			return summary, err
+		}
+
 		client, err := o.getBackend(srcM)
 		if err != nil {
-			return err
+			return summary, err
 		}
 
 		token, _ := o.getToken(ctx, srcM)
-		options := o.getOptions(srcM, token)
+		opts := o.getOptions(srcM, token)
 
 		_, err = client.Move(ctx, &storage_proto.MoveRequest{
 			Source:      srcRel,
 			Destination: dstRel,
-			Options:     options,
+			Options:     opts,
 		})
-		return plugins.FromGRPC(err)
+		if err == nil {
+			summary.count(node)
+			return summary, nil
+		}
+		if !o.markUnsupported(ctx, srcM, opMove, err) {
+			return summary, plugins.FromGRPC(err)
+		}
 	}
 
-	// Cross-mount move: copy then delete
-	if err := o.Copy(ctx, src, dst); err != nil {
-		return err
+	// Cross-mount move, or a backend without a native move: copy the whole tree, then
+	// delete the source only if every entry arrived.
+	options = append(options, WithRecursive())
+	summary, err = o.Copy(ctx, src, dst, options...)
+	if err != nil {
+		return summary, err
+	}
+
+	removed, err := o.Remove(ctx, src, options...)
+	if err != nil {
+		summary.Failures = append(summary.Failures, removed.Failures...)
+		if len(removed.Failures) == 0 {
+			summary.Fail(src, err)
+		}
+		return summary, summary.Err()
+	}
+	return summary, nil
+}
+
+func (o *orchestrator) Copy(ctx context.Context, src, dst string, options ...TreeOption) (*Summary, error) {
+	to := applyTreeOptions(options)
+
+	src, dst = path.Clean(src), path.Clean(dst)
+	node, err := o.Stat(ctx, src)
+	if err != nil {
+		return &Summary{}, err
+	}
+
+	if node.Type == DirectoryType {
+		if !to.Recursive {
+			return &Summary{}, fmt.Errorf("%s: %w", src, ErrIsADirectory)
+		}
+		if dst == src || strings.HasPrefix(dst, src+"/") {
+			return &Summary{}, fmt.Errorf("cannot copy %s into itself: %w", src, ErrInvalidPath)
+		}
+	}
+
+	sched := NewScheduler(ctx, to.Concurrency)
+	if node.Type == DirectoryType {
+		err = o.copyTree(ctx, sched, src, dst, to.Progress)
+	} else {
+		err = sched.Submit(src, o.copyFile(src, dst, node.Size, to.Progress))
+	}
+
+	summary := sched.Wait()
+	if err != nil {
+		return summary, err
 	}
-	return o.Remove(ctx, src)
+	return summary, summary.Err()
 }
 
-func (o *orchestrator) Copy(ctx context.Context, src, dst string) error {
-	reader, err := o.Read(ctx, src)
+// copyTree creates dst and schedules a transfer for every file beneath the directory src.
+// Directories are created in walk order before any of their files are submitted. Per-entry
+// failures are recorded by sched; an error is only returned when dst itself cannot be
+// prepared or ctx is cancelled.
+func (o *orchestrator) copyTree(ctx context.Context, sched *Scheduler, src, dst string, progress ProgressFunc) error {
+	if err := o.Mkdir(ctx, dst); err != nil && !errors.Is(err, ErrAlreadyExists) {
+		return err
+	}
+
+	children, err := o.List(ctx, src)
 	if err != nil {
 		return err
 	}
-	defer reader.Close()
+	sched.AddDirectory()
 
-	return o.Write(ctx, dst, reader)
+	for _, child := range children {
+		childSrc, childDst := path.Join(src, child.Name), path.Join(dst, child.Name)
+		if child.Type != DirectoryType {
+			if err := sched.Submit(childSrc, o.copyFile(childSrc, childDst, child.Size, progress)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := o.copyTree(ctx, sched, childSrc, childDst, progress); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			sched.Fail(childSrc, err)
+		}
+	}
+	return nil
 }
 
-func (o *orchestrator) Read(ctx context.Context, path string) (io.ReadCloser, error) {
-	client, relPath, options, err := o.prepare(ctx, path)
+// copyFile returns a [TransferFunc] that copies src to dst, reporting progress against
+// size when progress is set. Copies within one mount are left to the backend; otherwise
+// the content is streamed through the host.
+func (o *orchestrator) copyFile(src, dst string, size int64, progress ProgressFunc) TransferFunc {
+	return func(ctx context.Context) (int64, error) {
+		start := time.Now()
+		if ok, err := o.nativeCopy(ctx, src, dst); ok || err != nil {
+			if err != nil {
+				return 0, err
+			}
+			if progress != nil {
+				p := Progress{Path: src, Transferred: size, Total: size, Elapsed: time.Since(start), Done: true}
+				if secs := p.Elapsed.Seconds(); secs > 0 {
+					p.Rate = float64(size) / secs
+				}
+				progress(p)
+			}
+			return 0, nil
+		}
+
+		reader, err := o.Read(ctx, src)
+		if err != nil {
+			return 0, err
+		}
+		defer reader.Close()
+
+		cr := &countingReader{r: reader}
+		var options []WriteOption
+		if progress != nil {
+			options = append(options, WithWriteProgress(size, func(p Progress) {
+				p.Path = src
+				progress(p)
+			}))
+		}
+		if err := o.Write(ctx, dst, cr, options...); err != nil {
+			return cr.n, err
+		}
+		return cr.n, nil
+	}
+}
+
+// nativeCopy asks the backend to copy src to dst when both are on the same mount. It
+// returns false without an error when they are not, or when the plugin does not
+// implement Copy, so that the caller can stream the content instead.
+func (o *orchestrator) nativeCopy(ctx context.Context, src, dst string) (bool, error) {
+	srcM, srcRel, err := o.resolvePath(ctx, src)
+	if err != nil {
+		return false, err
+	}
+	dstM, dstRel, err := o.resolvePath(ctx, dst)
+	if err != nil {
+		return false, err
+	}
+	if srcM.Path != dstM.Path {
+		return false, nil
+	}
+
+	if !o.supports(ctx, srcM, opCopy) {
+		return false, nil
+	}
+
+	client, err := o.getBackend(srcM)
+	if err != nil {
+		return false, err
+	}
+	token, _ := o.getToken(ctx, srcM)
+
+	_, err = client.Copy(ctx, &storage_proto.CopyRequest{
+		Source:      srcRel,
+		Destination: dstRel,
+		Options:     o.getOptions(srcM, token),
+	})
+	if o.markUnsupported(ctx, srcM, opCopy, err) {
+		return false, nil
+	}
+	if err != nil {
+		return true, plugins.FromGRPC(err)
+	}
+	return true, nil
+}
+
+func (o *orchestrator) Read(ctx context.Context, path string, options ...ReadOption) (io.ReadCloser, error) {
+	m, _, err := o.resolvePath(ctx, path)
 	if err != nil {
 		return nil, err
 	}
+	client, relPath, opts, err := o.prepare(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	var ro ReadOptions
+	for _, opt := range options {
+		opt(&ro)
+	}
+
+	// A backend without ranged reads streams the whole file; the range is applied here.
+	offset, length := ro.Offset, ro.Length
+	hostRange := (offset > 0 || length > 0) && !o.supports(ctx, m, opRangedRead)
+	if hostRange {
+		offset, length = 0, 0
+	}
+
+	var total int64
+	if ro.Progress != nil {
+		if node, err := o.Stat(ctx, path); err == nil {
+			total = max(node.Size-ro.Offset, 0)
+			if ro.Length > 0 {
+				total = min(total, ro.Length)
+			}
+		}
+	}
 
 	stream, err := client.Read(ctx, &storage_proto.ReadRequest{
 		Path:    relPath,
-		Options: options,
+		Options: opts,
+		Offset:  offset,
+		Length:  length,
 	})
 	if err != nil {
 		return nil, plugins.FromGRPC(err)
@@ -203,18 +490,48 @@ func (o *orchestrator) Read(ctx context.Context, path string) (io.ReadCloser, er
 		}
 	}()
 
-	return pr, nil
+	var rc io.ReadCloser = pr
+	if hostRange {
+		rc = newRangeReader(pr, ro.Offset, ro.Length)
+	}
+	if ro.Progress != nil {
+		return &progressReadCloser{newProgressReader(rc, path, total, ro.Progress), rc}, nil
+	}
+	return rc, nil
 }
 
 func (o *orchestrator) Write(ctx context.Context, path string, reader io.Reader, options ...WriteOption) error {
+	m, _, err := o.resolvePath(ctx, path)
+	if err != nil {
+		return err
+	}
 	client, relPath, opts, err := o.prepare(ctx, path)
 	if err != nil {
 		return err
 	}
 
 	// Apply functional options
+	wo := WriteOptions{Metadata: opts}
 	for _, opt := range options {
-		opt(opts)
+		opt(&wo)
+	}
+
+	// A backend without conditional writes is checked here instead. Unlike a native
+	// if_match the check is not atomic, but it still catches edits made in the meantime.
+	if etag := opts["if_match"]; etag != "" && !o.supports(ctx, m, opETag) {
+		node, err := o.Stat(ctx, path)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+		if node == nil || node.ETag != etag {
+			return fmt.Errorf("%s: %w", path, ErrPrecondition)
+		}
+	}
+
+	var progress *progressReader
+	if wo.Progress != nil {
+		progress = newProgressReader(reader, path, wo.Size, wo.Progress)
+		reader = progress
 	}
 
 	stream, err := client.Write(ctx)
@@ -247,8 +564,64 @@ func (o *orchestrator) Write(ctx context.Context, path string, reader io.Reader,
 		}
 	}
 
-	_, err = stream.CloseAndRecv()
-	return plugins.FromGRPC(err)
+	if _, err = stream.CloseAndRecv(); err != nil {
+		return plugins.FromGRPC(err)
+	}
+	if progress != nil {
+		progress.finish()
+	}
+	return nil
+}
+
+func (o *orchestrator) Changes(ctx context.Context, p, cursor string) (*ChangeSet, error) {
+	m, relPath, err := o.resolvePath(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := o.getBackend(m)
+	if err != nil {
+		return nil, err
+	}
+	token, _ := o.getToken(ctx, m)
+
+	stream, err := client.Delta(ctx, &storage_proto.DeltaRequest{
+		Path:    relPath,
+		Options: o.getOptions(m, token),
+		Cursor:  cursor,
+	})
+	if err != nil {
+		return nil, plugins.FromGRPC(err)
+	}
+
+	set := &ChangeSet{}
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, plugins.FromGRPC(err)
+		}
+		for _, c := range resp.Changes {
+			node := FromProtoNode(c.Node)
+			if node.Path != "" {
+				node.Path = path.Join(m.Path, node.Path)
+			}
+			set.Changes = append(set.Changes, Change{Node: node, Deleted: c.Deleted})
+		}
+		if resp.Cursor != "" {
+			set.Cursor = resp.Cursor
+		}
+	}
+
+	if set.Cursor == "" {
+		return nil, fmt.Errorf("%s: delta stream ended without a cursor: %w", p, ErrInternal)
+	}
+	if err := o.mounts.SaveCursor(ctx, path.Clean(p), set.Cursor); err != nil {
+		return nil, err
+	}
+	return set, nil
 }
 
 func (o *orchestrator) resolvePath(ctx context.Context, p string) (*mount.Mount, string, error) {
@@ -258,6 +631,55 @@ func (o *orchestrator) resolvePath(ctx context.Context, p string) (*mount.Mount,
 		return nil, "", err
 	}
 
+	bestMatch := matchMount(mounts, p)
+	if bestMatch == nil {
+		return nil, "", fmt.Errorf("no mount found for path: %s", p)
+	}
+
+	relPath := strings.TrimPrefix(p, bestMatch.Path)
+	if !strings.HasPrefix(relPath, "/") {
+		relPath = "/" + relPath
+	}
+
+	return bestMatch, relPath, nil
+}
+
+// virtualDir lists the mount points directly beneath p when p lies outside every mount
+// but contains some, such as "/" for mounts at /onedrive and /local. It returns nil
+// when p is not such a directory.
+func (o *orchestrator) virtualDir(ctx context.Context, p string) []*Node {
+	p = path.Clean(p)
+	mounts, err := o.mounts.List(ctx)
+	if err != nil || matchMount(mounts, p) != nil {
+		return nil
+	}
+
+	prefix := strings.TrimSuffix(p, "/") + "/"
+	seen := make(map[string]bool)
+	var nodes []*Node
+	for _, m := range mounts {
+		rest, ok := strings.CutPrefix(m.Path, prefix)
+		if !ok || rest == "" {
+			continue
+		}
+		name, _, _ := strings.Cut(rest, "/")
+		if !seen[name] {
+			seen[name] = true
+			nodes = append(nodes, virtualNode(path.Join(p, name)))
+		}
+	}
+	slices.SortFunc(nodes, func(a, b *Node) int { return strings.Compare(a.Name, b.Name) })
+	return nodes
+}
+
+// virtualNode describes a directory that exists only because mounts live beneath it.
+func virtualNode(p string) *Node {
+	p = path.Clean(p)
+	return &Node{Name: path.Base(p), Path: p, Type: DirectoryType}
+}
+
+// matchMount returns the mount with the longest path containing the clean path p, or nil.
+func matchMount(mounts []*mount.Mount, p string) *mount.Mount {
 	var bestMatch *mount.Mount
 	for _, m := range mounts {
 		// Ensure it's a full segment match: either exact match or prefix followed by /
@@ -267,22 +689,82 @@ func (o *orchestrator) resolvePath(ctx context.Context, p string) (*mount.Mount,
 			}
 		}
 	}
+	return bestMatch
+}
 
-	if bestMatch == nil {
-		return nil, "", fmt.Errorf("no mount found for path: %s", p)
+func (o *orchestrator) getBackend(m *mount.Mount) (storage_proto.StorageServiceClient, error) {
+	pluginName := fmt.Sprintf("storage-%s", m.Type)
+	return o.plugins.GetStoragePlugin(pluginName)
+}
+
+// backendKey identifies the plugin serving m in the capability caches.
+func backendKey(m *mount.Mount) string {
+	return m.Path + "\x00" + fmt.Sprintf("storage-%s", m.Type)
+}
+
+// getCapabilities returns the capabilities the plugin serving m reported, or nil when
+// they are unknown. The result is fetched once per backend.
+func (o *orchestrator) getCapabilities(ctx context.Context, m *mount.Mount) *plugins.Capabilities {
+	key := backendKey(m)
+	o.mu.Lock()
+	caps, ok := o.capabilities[key]
+	o.mu.Unlock()
+	if ok {
+		return caps
 	}
 
-	relPath := strings.TrimPrefix(p, bestMatch.Path)
-	if !strings.HasPrefix(relPath, "/") {
-		relPath = "/" + relPath
+	meta, err := o.plugins.GetMetadata(ctx, fmt.Sprintf("storage-%s", m.Type))
+	if err != nil {
+		logger.WithContext(o.logger, ctx).Debug("unable to read plugin capabilities", "mount", m.Path, "type", m.Type, "error", err)
+	} else {
+		caps = meta.Capabilities
 	}
 
-	return bestMatch, relPath, nil
+	o.mu.Lock()
+	o.capabilities[key] = caps
+	o.mu.Unlock()
+	return caps
 }
 
-func (o *orchestrator) getBackend(m *mount.Mount) (storage_proto.StorageServiceClient, error) {
-	pluginName := fmt.Sprintf("storage-%s", m.Type)
-	return o.plugins.GetStoragePlugin(pluginName)
+// supports reports whether the plugin serving m should be asked to perform op natively.
+// Operations are assumed supported when the plugin did not report its capabilities, until
+// a call returns Unimplemented.
+func (o *orchestrator) supports(ctx context.Context, m *mount.Mount, op string) bool {
+	o.mu.Lock()
+	unsupported := o.unsupported[backendKey(m)+"\x00"+op]
+	o.mu.Unlock()
+	if unsupported {
+		return false
+	}
+
+	caps := o.getCapabilities(ctx, m)
+	if caps == nil {
+		return true
+	}
+	switch op {
+	case opMove:
+		return caps.Move
+	case opCopy:
+		return caps.Copy
+	case opRangedRead:
+		return caps.RangedRead
+	case opETag:
+		return caps.ETagConcurrency
+	}
+	return true
+}
+
+// markUnsupported records op as unsupported by the plugin serving m when err is
+// Unimplemented, and reports whether it did.
+func (o *orchestrator) markUnsupported(ctx context.Context, m *mount.Mount, op string, err error) bool {
+	if status.Code(err) != codes.Unimplemented {
+		return false
+	}
+	logger.WithContext(o.logger, ctx).Debug("storage plugin does not implement operation, using fallback", "op", op, "mount", m.Path, "type", m.Type)
+	o.mu.Lock()
+	o.unsupported[backendKey(m)+"\x00"+op] = true
+	o.mu.Unlock()
+	return true
 }
 
 func (o *orchestrator) getToken(ctx context.Context, m *mount.Mount) (*identity.Token, error) {
@@ -329,3 +811,55 @@ func (o *orchestrator) getOptions(m *mount.Mount, t *identity.Token) map[string]
 	}
 	return opts
 }
+
+func applyTreeOptions(options []TreeOption) TreeOptions {
+	var to TreeOptions
+	for _, opt := range options {
+		opt(&to)
+	}
+	return to
+}
+
+// countingReader records the number of bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// rangeReader applies a byte range to a stream that starts at the beginning of a file.
+type rangeReader struct {
+	rc     io.ReadCloser
+	r      io.Reader
+	offset int64
+}
+
+// newRangeReader returns a reader that skips offset bytes of rc and then returns at most
+// length bytes, or the rest of rc when length is zero.
+func newRangeReader(rc io.ReadCloser, offset, length int64) *rangeReader {
+	rr := &rangeReader{rc: rc, r: rc, offset: offset}
+	if length > 0 {
+		rr.r = io.LimitReader(rc, offset+length)
+	}
+	return rr
+}
+
+func (r *rangeReader) Read(p []byte) (int, error) {
+	if r.offset > 0 {
+		skip := r.offset
+		r.offset = 0
+		if _, err := io.CopyN(io.Discard, r.r, skip); err != nil {
+			return 0, err
+		}
+	}
+	return r.r.Read(p)
+}
+
+func (r *rangeReader) Close() error {
+	return r.rc.Close()
+}
@@ -0,0 +1,459 @@
+package vfs_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/michaeldcanady/go-onedrive/internal/core/logger/loggertest"
+	"github.com/michaeldcanady/go-onedrive/internal/core/plugins"
+	identity_proto "github.com/michaeldcanady/go-onedrive/internal/features/plugins/proto/identity"
+	storage_proto "github.com/michaeldcanady/go-onedrive/internal/features/plugins/proto/storage"
+	"github.com/michaeldcanady/go-onedrive/internal/features/vfs"
+	"github.com/michaeldcanady/go-onedrive/internal/features/vfs/vfstest"
+)
+
+// fakeStorage is an in-memory storage plugin that counts the calls made to it.
+type fakeStorage struct {
+	storage_proto.UnimplementedStorageServiceServer
+
+	mu                    sync.Mutex
+	files                 map[string][]byte
+	dirs                  map[string]bool
+	fail                  map[string]error
+	noCopy                bool
+	caps                  *storage_proto.Capabilities
+	copies, reads, writes int
+	moves, deletes        int
+	lastRead              *storage_proto.ReadRequest
+}
+
+func (f *fakeStorage) GetMetadata(ctx context.Context, req *storage_proto.MetadataRequest) (*storage_proto.MetadataResponse, error) {
+	return &storage_proto.MetadataResponse{Name: "fake", Type: plugins.PluginTypeStorage, Capabilities: f.caps}, nil
+}
+
+func (f *fakeStorage) Move(ctx context.Context, req *storage_proto.MoveRequest) (*storage_proto.MoveResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.moves++
+	return nil, status.Error(codes.Unimplemented, "method Move not implemented")
+}
+
+func (f *fakeStorage) Delete(ctx context.Context, req *storage_proto.DeleteRequest) (*storage_proto.DeleteResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.deletes++
+	if err := f.fail[req.Path]; err != nil {
+		return nil, err
+	}
+	if f.dirs[req.Path] {
+		if len(f.children(req.Path)) > 0 {
+			return nil, status.Error(codes.FailedPrecondition, "directory not empty")
+		}
+		delete(f.dirs, req.Path)
+		return &storage_proto.DeleteResponse{}, nil
+	}
+	delete(f.files, req.Path)
+	return &storage_proto.DeleteResponse{}, nil
+}
+
+// children returns the entries directly beneath dir. The caller must hold f.mu.
+func (f *fakeStorage) children(dir string) []*storage_proto.Node {
+	var nodes []*storage_proto.Node
+	for d := range f.dirs {
+		if d != dir && path.Dir(d) == dir {
+			nodes = append(nodes, &storage_proto.Node{Name: path.Base(d), Path: d, Type: storage_proto.NodeType_DIRECTORY})
+		}
+	}
+	for p, data := range f.files {
+		if path.Dir(p) == dir {
+			nodes = append(nodes, &storage_proto.Node{Name: path.Base(p), Path: p, Type: storage_proto.NodeType_FILE, Size: int64(len(data))})
+		}
+	}
+	return nodes
+}
+
+func (f *fakeStorage) List(ctx context.Context, req *storage_proto.ListRequest) (*storage_proto.ListResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.dirs[req.Path] {
+		return nil, status.Error(codes.NotFound, req.Path)
+	}
+	return &storage_proto.ListResponse{Nodes: f.children(req.Path)}, nil
+}
+
+func (f *fakeStorage) Stat(ctx context.Context, req *storage_proto.StatRequest) (*storage_proto.StatResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.dirs[req.Path] {
+		return &storage_proto.StatResponse{Node: &storage_proto.Node{Name: path.Base(req.Path), Path: req.Path, Type: storage_proto.NodeType_DIRECTORY}}, nil
+	}
+	data, ok := f.files[req.Path]
+	if !ok {
+		return nil, status.Error(codes.NotFound, req.Path)
+	}
+	return &storage_proto.StatResponse{Node: &storage_proto.Node{Path: req.Path, Type: storage_proto.NodeType_FILE, Size: int64(len(data))}}, nil
+}
+
+func (f *fakeStorage) Mkdir(ctx context.Context, req *storage_proto.MkdirRequest) (*storage_proto.MkdirResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.dirs == nil {
+		f.dirs = map[string]bool{}
+	}
+	f.dirs[req.Path] = true
+	return &storage_proto.MkdirResponse{}, nil
+}
+
+func (f *fakeStorage) Read(req *storage_proto.ReadRequest, stream storage_proto.StorageService_ReadServer) error {
+	f.mu.Lock()
+	f.reads++
+	f.lastRead = req
+	data := f.files[req.Path]
+	f.mu.Unlock()
+	return stream.Send(&storage_proto.ReadResponse{Chunk: data})
+}
+
+func (f *fakeStorage) Write(stream storage_proto.StorageService_WriteServer) error {
+	var (
+		p    string
+		data []byte
+	)
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		p = req.Path
+		data = append(data, req.Chunk...)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.writes++
+	if err := f.fail[p]; err != nil {
+		return err
+	}
+	f.files[p] = data
+	return stream.SendAndClose(&storage_proto.WriteResponse{})
+}
+
+func (f *fakeStorage) Copy(ctx context.Context, req *storage_proto.CopyRequest) (*storage_proto.CopyResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.copies++
+	if f.noCopy {
+		return nil, status.Error(codes.Unimplemented, "method Copy not implemented")
+	}
+	data, ok := f.files[req.Source]
+	if !ok {
+		return nil, status.Error(codes.NotFound, req.Source)
+	}
+	f.files[req.Destination] = data
+	return &storage_proto.CopyResponse{}, nil
+}
+
+// fakePlugins is a [plugins.Manager] that returns storage clients by plugin name.
+type fakePlugins map[string]storage_proto.StorageServiceClient
+
+func (f fakePlugins) GetStoragePlugin(name string) (storage_proto.StorageServiceClient, error) {
+	if c, ok := f[name]; ok {
+		return c, nil
+	}
+	return nil, errors.New("unknown plugin " + name)
+}
+
+func (f fakePlugins) GetIdentityPlugin(name string) (identity_proto.IdentityPluginClient, error) {
+	return nil, errors.New("unknown plugin " + name)
+}
+
+func (f fakePlugins) ListPlugins(ctx context.Context) ([]*plugins.Metadata, error) { return nil, nil }
+func (f fakePlugins) Shutdown(ctx context.Context) error                           { return nil }
+
+func (f fakePlugins) Install(ctx context.Context, source string) (*plugins.Metadata, error) {
+	return nil, errors.New("not supported")
+}
+func (f fakePlugins) Remove(ctx context.Context, name string) error { return nil }
+func (f fakePlugins) Refresh(ctx context.Context) (*plugins.RefreshResult, error) {
+	return &plugins.RefreshResult{}, nil
+}
+
+func (f fakePlugins) GetMetadata(ctx context.Context, name string) (*plugins.Metadata, error) {
+	c, ok := f[name]
+	if !ok {
+		return nil, errors.New("unknown plugin " + name)
+	}
+	resp, err := c.GetMetadata(ctx, &storage_proto.MetadataRequest{})
+	if err != nil {
+		return nil, err
+	}
+	meta := &plugins.Metadata{Name: resp.Name, Type: resp.Type, PluginPath: name}
+	if c := resp.Capabilities; c != nil {
+		meta.Capabilities = &plugins.Capabilities{Move: c.Move, Copy: c.Copy, RangedRead: c.RangedRead, ETagConcurrency: c.EtagConcurrency, Delta: c.Delta, Hashes: c.Hashes}
+	}
+	return meta, nil
+}
+
+func serveStorage(t *testing.T, impl storage_proto.StorageServiceServer) storage_proto.StorageServiceClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	storage_proto.RegisterStorageServiceServer(srv, impl)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return storage_proto.NewStorageServiceClient(conn)
+}
+
+func TestOrchestrator_Copy(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T, noCopy bool) (vfs.VFS, *fakeStorage, *fakeStorage) {
+		one := &fakeStorage{files: map[string][]byte{"/a.txt": []byte("hello")}, noCopy: noCopy}
+		two := &fakeStorage{files: map[string][]byte{}}
+		mounts := vfstest.Mounts{{Path: "/one", Type: "one"}, {Path: "/two", Type: "two"}}
+		pm := fakePlugins{"storage-one": serveStorage(t, one), "storage-two": serveStorage(t, two)}
+		return vfs.NewOrchestrator(mounts, pm, nil, nil, loggertest.Nop{}), one, two
+	}
+
+	t.Run("same mount copies natively", func(t *testing.T) {
+		v, one, _ := setup(t, false)
+		var updates []vfs.Progress
+		summary, err := v.Copy(ctx, "/one/a.txt", "/one/b.txt", vfs.WithTransferProgress(func(p vfs.Progress) { updates = append(updates, p) }))
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, summary.Files)
+		assert.Zero(t, summary.Bytes, "no bytes pass through the host")
+		assert.Equal(t, "hello", string(one.files["/b.txt"]))
+		assert.Equal(t, 1, one.copies)
+		assert.Zero(t, one.reads)
+		assert.Zero(t, one.writes)
+		require.NotEmpty(t, updates)
+		assert.True(t, updates[len(updates)-1].Done)
+	})
+
+	t.Run("falls back to streaming when unimplemented", func(t *testing.T) {
+		v, one, _ := setup(t, true)
+		_, err := v.Copy(ctx, "/one/a.txt", "/one/b.txt")
+		require.NoError(t, err)
+		_, err = v.Copy(ctx, "/one/a.txt", "/one/c.txt")
+		require.NoError(t, err)
+
+		assert.Equal(t, "hello", string(one.files["/b.txt"]))
+		assert.Equal(t, "hello", string(one.files["/c.txt"]))
+		assert.Equal(t, 1, one.copies, "an unsupported Copy is not retried")
+		assert.Equal(t, 2, one.writes)
+	})
+
+	t.Run("different mounts stream", func(t *testing.T) {
+		v, one, two := setup(t, false)
+		_, err := v.Copy(ctx, "/one/a.txt", "/two/a.txt")
+		require.NoError(t, err)
+
+		assert.Equal(t, "hello", string(two.files["/a.txt"]))
+		assert.Zero(t, one.copies)
+		assert.Equal(t, 1, one.reads)
+		assert.Equal(t, 1, two.writes)
+	})
+
+	t.Run("native copy errors are translated", func(t *testing.T) {
+		v, _, _ := setup(t, false)
+		ok, err := vfs.NativeCopy(ctx, v, "/one/missing.txt", "/one/b.txt")
+		assert.True(t, ok)
+		assert.ErrorIs(t, err, vfs.ErrNotFound)
+	})
+}
+
+func TestOrchestrator_Capabilities(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T, caps *storage_proto.Capabilities) (vfs.VFS, *fakeStorage) {
+		one := &fakeStorage{files: map[string][]byte{"/a.txt": []byte("hello world")}, caps: caps}
+		mounts := vfstest.Mounts{{Path: "/one", Type: "one"}}
+		return vfs.NewOrchestrator(mounts, fakePlugins{"storage-one": serveStorage(t, one)}, nil, nil, loggertest.Nop{}), one
+	}
+
+	t.Run("move without native support copies and deletes", func(t *testing.T) {
+		v, one := setup(t, &storage_proto.Capabilities{Copy: true})
+		_, err := v.Move(ctx, "/one/a.txt", "/one/b.txt")
+		require.NoError(t, err)
+
+		assert.Zero(t, one.moves, "an unsupported Move is not attempted")
+		assert.Equal(t, 1, one.copies)
+		assert.Equal(t, "hello world", string(one.files["/b.txt"]))
+		assert.NotContains(t, one.files, "/a.txt")
+	})
+
+	t.Run("unimplemented move falls back when capabilities are unknown", func(t *testing.T) {
+		v, one := setup(t, nil)
+		_, err := v.Move(ctx, "/one/a.txt", "/one/b.txt")
+		require.NoError(t, err)
+		_, err = v.Move(ctx, "/one/b.txt", "/one/c.txt")
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, one.moves, "an unsupported Move is not retried")
+		assert.Equal(t, "hello world", string(one.files["/c.txt"]))
+		assert.Len(t, one.files, 1)
+	})
+
+	t.Run("ranged read is applied on the host", func(t *testing.T) {
+		v, one := setup(t, &storage_proto.Capabilities{})
+		r, err := v.Read(ctx, "/one/a.txt", vfs.WithRange(6, 3))
+		require.NoError(t, err)
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+
+		assert.Equal(t, "wor", string(data))
+		assert.Zero(t, one.lastRead.Offset)
+		assert.Zero(t, one.lastRead.Length)
+	})
+
+	t.Run("ranged read is sent to a capable backend", func(t *testing.T) {
+		v, one := setup(t, &storage_proto.Capabilities{RangedRead: true})
+		r, err := v.Read(ctx, "/one/a.txt", vfs.WithRange(6, 3))
+		require.NoError(t, err)
+		_, err = io.ReadAll(r)
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(6), one.lastRead.Offset)
+		assert.Equal(t, int64(3), one.lastRead.Length)
+	})
+
+	t.Run("if-match is checked on the host", func(t *testing.T) {
+		v, one := setup(t, &storage_proto.Capabilities{})
+		err := v.Write(ctx, "/one/a.txt", strings.NewReader("changed"), vfs.WithIfMatch("stale"))
+		assert.ErrorIs(t, err, vfs.ErrPrecondition)
+		assert.Zero(t, one.writes)
+	})
+}
+
+func TestOrchestrator_Tree(t *testing.T) {
+	ctx := context.Background()
+	denied := status.Error(codes.PermissionDenied, "denied")
+
+	setup := func(t *testing.T) (vfs.VFS, *fakeStorage, *fakeStorage) {
+		one := &fakeStorage{
+			files: map[string][]byte{"/dir/a.txt": []byte("hello"), "/dir/sub/b.txt": []byte("world!")},
+			dirs:  map[string]bool{"/": true, "/dir": true, "/dir/sub": true},
+			fail:  map[string]error{},
+		}
+		two := &fakeStorage{files: map[string][]byte{}, dirs: map[string]bool{"/": true}, fail: map[string]error{}}
+		mounts := vfstest.Mounts{{Path: "/one", Type: "one"}, {Path: "/two", Type: "two"}}
+		pm := fakePlugins{"storage-one": serveStorage(t, one), "storage-two": serveStorage(t, two)}
+		return vfs.NewOrchestrator(mounts, pm, nil, nil, loggertest.Nop{}), one, two
+	}
+
+	t.Run("copy without recursion refuses a directory", func(t *testing.T) {
+		v, _, two := setup(t)
+		_, err := v.Copy(ctx, "/one/dir", "/two/dir")
+		assert.ErrorIs(t, err, vfs.ErrIsADirectory)
+		assert.Empty(t, two.files)
+	})
+
+	t.Run("recursive copy across mounts", func(t *testing.T) {
+		v, one, two := setup(t)
+		summary, err := v.Copy(ctx, "/one/dir", "/two/dir", vfs.WithRecursive())
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, summary.Files)
+		assert.Equal(t, 2, summary.Directories)
+		assert.Equal(t, int64(11), summary.Bytes)
+		assert.True(t, two.dirs["/dir/sub"])
+		assert.Equal(t, "hello", string(two.files["/dir/a.txt"]))
+		assert.Equal(t, "world!", string(two.files["/dir/sub/b.txt"]))
+		assert.Len(t, one.files, 2, "the source is kept")
+	})
+
+	t.Run("copy keeps going after an entry fails", func(t *testing.T) {
+		v, _, two := setup(t)
+		two.fail["/dir/a.txt"] = denied
+		summary, err := v.Copy(ctx, "/one/dir", "/two/dir", vfs.WithRecursive())
+		require.Error(t, err)
+		assert.ErrorIs(t, err, vfs.ErrPermissionDenied)
+
+		require.Len(t, summary.Failures, 1)
+		assert.Equal(t, "/one/dir/a.txt", summary.Failures[0].Path)
+		assert.Equal(t, 1, summary.Files)
+		assert.Equal(t, "world!", string(two.files["/dir/sub/b.txt"]))
+	})
+
+	t.Run("remove refuses a non-empty directory without recursion", func(t *testing.T) {
+		v, one, _ := setup(t)
+		summary, err := v.Remove(ctx, "/one/dir")
+		assert.ErrorIs(t, err, vfs.ErrNotEmpty)
+		assert.Zero(t, summary.Files)
+		assert.Zero(t, one.deletes)
+		assert.Len(t, one.files, 2)
+	})
+
+	t.Run("recursive remove deletes the tree", func(t *testing.T) {
+		v, one, _ := setup(t)
+		summary, err := v.Remove(ctx, "/one/dir", vfs.WithRecursive())
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, summary.Files)
+		assert.Equal(t, 2, summary.Directories)
+		assert.Empty(t, one.files)
+		assert.Equal(t, map[string]bool{"/": true}, one.dirs)
+	})
+
+	t.Run("recursive remove keeps directories holding failed entries", func(t *testing.T) {
+		v, one, _ := setup(t)
+		one.fail["/dir/sub/b.txt"] = denied
+		summary, err := v.Remove(ctx, "/one/dir", vfs.WithRecursive())
+		require.Error(t, err)
+
+		require.Len(t, summary.Failures, 1)
+		assert.Equal(t, "/one/dir/sub/b.txt", summary.Failures[0].Path)
+		assert.ErrorIs(t, summary.Failures[0], vfs.ErrPermissionDenied)
+		assert.Equal(t, 1, summary.Files)
+		assert.Zero(t, summary.Directories)
+		assert.NotContains(t, one.files, "/dir/a.txt")
+		assert.True(t, one.dirs["/dir"])
+		assert.True(t, one.dirs["/dir/sub"])
+	})
+
+	t.Run("cross-mount move", func(t *testing.T) {
+		v, one, two := setup(t)
+		_, err := v.Move(ctx, "/one/dir", "/two/dir")
+		require.NoError(t, err)
+
+		assert.Len(t, two.files, 2)
+		assert.Empty(t, one.files)
+		assert.False(t, one.dirs["/dir"])
+	})
+
+	t.Run("cross-mount move keeps the source when a child copy fails", func(t *testing.T) {
+		v, one, two := setup(t)
+		two.fail["/dir/sub/b.txt"] = denied
+		summary, err := v.Move(ctx, "/one/dir", "/two/dir")
+		require.Error(t, err)
+		assert.ErrorIs(t, err, vfs.ErrPermissionDenied)
+
+		require.Len(t, summary.Failures, 1)
+		assert.Equal(t, "/one/dir/sub/b.txt", summary.Failures[0].Path)
+		assert.Zero(t, one.deletes, "nothing is deleted from the source")
+		assert.Len(t, one.files, 2)
+		assert.True(t, one.dirs["/dir/sub"])
+	})
+}
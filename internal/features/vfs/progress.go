@@ -0,0 +1,110 @@
+package vfs
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// progressInterval bounds how often a [ProgressFunc] is called while data is flowing.
+const progressInterval = 100 * time.Millisecond
+
+// Progress describes the state of a single file transfer.
+type Progress struct {
+	// Path is the VFS path of the file being transferred.
+	Path string `json:"path"`
+	// Transferred is the number of bytes moved so far.
+	Transferred int64 `json:"transferred"`
+	// Total is the expected size in bytes, or zero when unknown.
+	Total int64 `json:"total"`
+	// Rate is the average transfer rate in bytes per second.
+	Rate float64 `json:"rate"`
+	// Elapsed is the time since the transfer started.
+	Elapsed time.Duration `json:"elapsed"`
+	// Done is set on the final update for the transfer.
+	Done bool `json:"done"`
+}
+
+// ProgressFunc receives [Progress] updates. Updates for one transfer are delivered
+// sequentially, but concurrent transfers may call the same function in parallel.
+type ProgressFunc func(Progress)
+
+// progressReader reports the bytes read through it to a [ProgressFunc].
+type progressReader struct {
+	r  io.Reader
+	fn ProgressFunc
+
+	mu    sync.Mutex
+	state Progress
+	start time.Time
+	last  time.Time
+	done  bool
+}
+
+func newProgressReader(r io.Reader, path string, total int64, fn ProgressFunc) *progressReader {
+	now := time.Now()
+	p := &progressReader{
+		r:     r,
+		fn:    fn,
+		state: Progress{Path: path, Total: total},
+		start: now,
+		last:  now,
+	}
+	fn(p.snapshot(now))
+	return p
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.state.Transferred += int64(n)
+	if now := time.Now(); !p.done && now.Sub(p.last) >= progressInterval {
+		p.last = now
+		p.fn(p.snapshot(now))
+	}
+	return n, err
+}
+
+// finish delivers the final update. Later calls are ignored.
+func (p *progressReader) finish() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.done {
+		return
+	}
+	p.done = true
+	p.state.Done = true
+	p.fn(p.snapshot(time.Now()))
+}
+
+func (p *progressReader) snapshot(now time.Time) Progress {
+	s := p.state
+	s.Elapsed = now.Sub(p.start)
+	if secs := s.Elapsed.Seconds(); secs > 0 {
+		s.Rate = float64(s.Transferred) / secs
+	}
+	return s
+}
+
+// progressReadCloser delivers the final update when the stream ends or is closed.
+type progressReadCloser struct {
+	*progressReader
+	closer io.Closer
+}
+
+func (p *progressReadCloser) Read(b []byte) (int, error) {
+	n, err := p.progressReader.Read(b)
+	if err == io.EOF {
+		p.finish()
+	}
+	return n, err
+}
+
+func (p *progressReadCloser) Close() error {
+	p.finish()
+	return p.closer.Close()
+}
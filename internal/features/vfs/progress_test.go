@@ -0,0 +1,44 @@
+package vfs
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgressReadCloser(t *testing.T) {
+	var updates []Progress
+	content := strings.Repeat("x", 1000)
+
+	r := &progressReadCloser{
+		progressReader: newProgressReader(strings.NewReader(content), "/f", int64(len(content)), func(p Progress) {
+			updates = append(updates, p)
+		}),
+		closer: io.NopCloser(nil),
+	}
+
+	data, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Equal(t, content, string(data))
+	assert.NoError(t, r.Close())
+
+	if assert.GreaterOrEqual(t, len(updates), 2) {
+		first, last := updates[0], updates[len(updates)-1]
+		assert.Equal(t, "/f", first.Path)
+		assert.Zero(t, first.Transferred)
+		assert.False(t, first.Done)
+		assert.Equal(t, int64(len(content)), last.Total)
+		assert.Equal(t, int64(len(content)), last.Transferred)
+		assert.True(t, last.Done)
+	}
+
+	done := 0
+	for _, u := range updates {
+		if u.Done {
+			done++
+		}
+	}
+	assert.Equal(t, 1, done, "final update is delivered exactly once")
+}
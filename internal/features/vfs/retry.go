@@ -0,0 +1,282 @@
+package vfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"path"
+	"strconv"
+	"time"
+
+	coreerrors "github.com/michaeldcanady/go-onedrive/internal/core/errors"
+	"github.com/michaeldcanady/go-onedrive/internal/core/logger"
+	"github.com/michaeldcanady/go-onedrive/internal/features/mount"
+)
+
+// Mount options that override the [RetryPolicy] for a single mount.
+const (
+	OptionRetryMaxAttempts = "retry_max_attempts"
+	OptionRetryBaseDelay   = "retry_base_delay"
+	OptionRetryMaxDelay    = "retry_max_delay"
+)
+
+// RetryPolicy controls how transient backend failures are retried.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first. Values below 2
+	// disable retries.
+	MaxAttempts int
+	// BaseDelay is the backoff before the first retry. It doubles on each attempt.
+	BaseDelay time.Duration
+	// MaxDelay caps the computed backoff. A Retry-After hint from the backend is
+	// honoured even when it is longer.
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy is used for mounts that do not override it.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    30 * time.Second,
+}
+
+// backoff returns the delay before the given retry, using full jitter.
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	d := p.MaxDelay
+	if shift := retry - 1; shift < 32 && p.BaseDelay<<shift < p.MaxDelay {
+		d = p.BaseDelay << shift
+	}
+	if d <= 0 {
+		return 0
+	}
+	return rand.N(d) + 1
+}
+
+// RetryPolicyFromOptions applies the retry overrides in a mount's options to def.
+func RetryPolicyFromOptions(options map[string]string, def RetryPolicy) (RetryPolicy, error) {
+	p := def
+	if v, ok := options[OptionRetryMaxAttempts]; ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return def, fmt.Errorf("invalid %s: %w", OptionRetryMaxAttempts, err)
+		}
+		p.MaxAttempts = n
+	}
+	for key, dst := range map[string]*time.Duration{
+		OptionRetryBaseDelay: &p.BaseDelay,
+		OptionRetryMaxDelay:  &p.MaxDelay,
+	} {
+		if v, ok := options[key]; ok {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return def, fmt.Errorf("invalid %s: %w", key, err)
+			}
+			*dst = d
+		}
+	}
+	return p, nil
+}
+
+// isTransient reports whether err is a failure the backend expects to clear.
+func isTransient(err error) bool {
+	return errors.Is(err, ErrUnavailable) || errors.Is(err, ErrThrottled)
+}
+
+// RetryMiddleware returns a [Middleware] that retries idempotent operations failing with
+// [ErrUnavailable] or [ErrThrottled], using exponential backoff with jitter and any
+// Retry-After hint from the backend. The policy comes from the options of the mount
+// owning the path, falling back to def. Tree operations are not retried as a whole;
+// Write is retried only when its reader can be rewound.
+func RetryMiddleware(ms mount.Service, def RetryPolicy, l logger.Service) Middleware {
+	return func(next VFS) VFS {
+		return &retryMiddleware{
+			next:   next,
+			mounts: ms,
+			def:    def,
+			logger: l,
+			sleep:  sleepContext,
+		}
+	}
+}
+
+type retryMiddleware struct {
+	next   VFS
+	mounts mount.Service
+	def    RetryPolicy
+	logger logger.Service
+	sleep  func(ctx context.Context, d time.Duration) error
+}
+
+func (m *retryMiddleware) List(ctx context.Context, p string) ([]*Node, error) {
+	var nodes []*Node
+	err := m.do(ctx, "List", p, func(int) (err error) {
+		nodes, err = m.next.List(ctx, p)
+		return err
+	})
+	return nodes, err
+}
+
+func (m *retryMiddleware) Stat(ctx context.Context, p string) (*Node, error) {
+	var node *Node
+	err := m.do(ctx, "Stat", p, func(int) (err error) {
+		node, err = m.next.Stat(ctx, p)
+		return err
+	})
+	return node, err
+}
+
+func (m *retryMiddleware) Mkdir(ctx context.Context, p string) error {
+	return m.do(ctx, "Mkdir", p, func(attempt int) error {
+		err := m.next.Mkdir(ctx, p)
+		// An earlier attempt may have succeeded without us seeing the response.
+		if attempt > 1 && errors.Is(err, ErrAlreadyExists) {
+			return nil
+		}
+		return err
+	})
+}
+
+func (m *retryMiddleware) Remove(ctx context.Context, p string, options ...TreeOption) (*Summary, error) {
+	return m.next.Remove(ctx, p, options...)
+}
+
+func (m *retryMiddleware) Move(ctx context.Context, src, dst string, options ...TreeOption) (*Summary, error) {
+	return m.next.Move(ctx, src, dst, options...)
+}
+
+func (m *retryMiddleware) Copy(ctx context.Context, src, dst string, options ...TreeOption) (*Summary, error) {
+	return m.next.Copy(ctx, src, dst, options...)
+}
+
+// Read retries opening the file. Errors surfacing before the first byte is delivered
+// reopen it; once data has been returned, failures are passed to the caller.
+func (m *retryMiddleware) Read(ctx context.Context, p string, options ...ReadOption) (io.ReadCloser, error) {
+	var rc io.ReadCloser
+	err := m.do(ctx, "Read", p, func(int) error {
+		r, err := m.next.Read(ctx, p, options...)
+		if err != nil {
+			return err
+		}
+		// Plugins report most failures on the first chunk, so fetch it here.
+		buf := make([]byte, 32*1024)
+		n, err := r.Read(buf)
+		if err != nil && err != io.EOF {
+			r.Close()
+			return err
+		}
+		rc = &prefixReadCloser{prefix: buf[:n], eof: err == io.EOF, ReadCloser: r}
+		return nil
+	})
+	return rc, err
+}
+
+func (m *retryMiddleware) Write(ctx context.Context, p string, reader io.Reader, options ...WriteOption) error {
+	seeker, ok := reader.(io.Seeker)
+	if !ok {
+		return m.next.Write(ctx, p, reader, options...)
+	}
+	start, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return m.next.Write(ctx, p, reader, options...)
+	}
+
+	return m.do(ctx, "Write", p, func(attempt int) error {
+		if attempt > 1 {
+			if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+				return err
+			}
+		}
+		return m.next.Write(ctx, p, reader, options...)
+	})
+}
+
+func (m *retryMiddleware) Changes(ctx context.Context, p, cursor string) (*ChangeSet, error) {
+	var set *ChangeSet
+	err := m.do(ctx, "Changes", p, func(int) (err error) {
+		set, err = m.next.Changes(ctx, p, cursor)
+		return err
+	})
+	return set, err
+}
+
+// do runs fn until it succeeds, fails permanently, or the policy for p is exhausted.
+func (m *retryMiddleware) do(ctx context.Context, op, p string, fn func(attempt int) error) error {
+	policy := m.policy(ctx, p)
+	l := logger.WithContext(m.logger, ctx)
+
+	for attempt := 1; ; attempt++ {
+		err := fn(attempt)
+		if err == nil || !isTransient(err) || attempt >= policy.MaxAttempts {
+			return err
+		}
+
+		delay := policy.backoff(attempt)
+		if hint, ok := coreerrors.RetryAfter(err); ok {
+			delay = hint
+		}
+		// Give up now rather than sleep past the caller's deadline.
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return err
+		}
+
+		l.Warn("retrying vfs operation",
+			"operation", op,
+			"path", p,
+			"attempt", attempt,
+			"delay", delay,
+			"error", err,
+		)
+		if serr := m.sleep(ctx, delay); serr != nil {
+			return err
+		}
+	}
+}
+
+// policy returns the retry policy of the mount owning p.
+func (m *retryMiddleware) policy(ctx context.Context, p string) RetryPolicy {
+	mounts, err := m.mounts.List(ctx)
+	if err != nil {
+		return m.def
+	}
+	mnt := matchMount(mounts, path.Clean(p))
+	if mnt == nil {
+		return m.def
+	}
+	policy, err := RetryPolicyFromOptions(mnt.Options, m.def)
+	if err != nil {
+		logger.WithContext(m.logger, ctx).Warn("ignoring retry options", "mount", mnt.Path, "error", err)
+	}
+	return policy
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// prefixReadCloser replays bytes read ahead of the caller before continuing with the
+// underlying reader.
+type prefixReadCloser struct {
+	prefix []byte
+	eof    bool
+	io.ReadCloser
+}
+
+func (r *prefixReadCloser) Read(b []byte) (int, error) {
+	if len(r.prefix) > 0 {
+		n := copy(b, r.prefix)
+		r.prefix = r.prefix[n:]
+		return n, nil
+	}
+	if r.eof {
+		return 0, io.EOF
+	}
+	return r.ReadCloser.Read(b)
+}
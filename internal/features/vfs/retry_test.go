@@ -0,0 +1,176 @@
+package vfs_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	coreerrors "github.com/michaeldcanady/go-onedrive/internal/core/errors"
+	"github.com/michaeldcanady/go-onedrive/internal/core/logger/loggertest"
+	"github.com/michaeldcanady/go-onedrive/internal/features/mount"
+	"github.com/michaeldcanady/go-onedrive/internal/features/vfs"
+	"github.com/michaeldcanady/go-onedrive/internal/features/vfs/vfstest"
+)
+
+// flakyVFS fails the first calls to Stat, Read and Write with err.
+type flakyVFS struct {
+	*vfstest.MemFS
+	err      error
+	failures int
+	calls    int
+	written  []string
+}
+
+func (f *flakyVFS) fail() error {
+	f.calls++
+	if f.calls <= f.failures {
+		return f.err
+	}
+	return nil
+}
+
+func (f *flakyVFS) Stat(ctx context.Context, p string) (*vfs.Node, error) {
+	if err := f.fail(); err != nil {
+		return nil, err
+	}
+	return f.MemFS.Stat(ctx, p)
+}
+
+func (f *flakyVFS) Read(ctx context.Context, p string, options ...vfs.ReadOption) (io.ReadCloser, error) {
+	pr, pw := io.Pipe()
+	err := f.fail()
+	go func() {
+		if err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		_, _ = pw.Write([]byte("content"))
+		pw.Close()
+	}()
+	return pr, nil
+}
+
+func (f *flakyVFS) Write(ctx context.Context, p string, reader io.Reader, options ...vfs.WriteOption) error {
+	data, _ := io.ReadAll(reader)
+	f.written = append(f.written, string(data))
+	return f.fail()
+}
+
+func newRetryTest(failures int, err error, mounts ...*mount.Mount) (*flakyVFS, vfs.VFS, *[]time.Duration) {
+	backend := &flakyVFS{MemFS: vfstest.NewMemFS(map[string]string{"/m/a.txt": ""}), err: err, failures: failures}
+	policy := vfs.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Second, MaxDelay: 4 * time.Second}
+	m := vfs.RetryMiddleware(vfstest.Mounts(mounts), policy, loggertest.Nop{})(backend)
+	var slept []time.Duration
+	vfs.SetRetrySleep(m, func(ctx context.Context, d time.Duration) error {
+		slept = append(slept, d)
+		return ctx.Err()
+	})
+	return backend, m, &slept
+}
+
+func TestRetryMiddleware(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("retries transient failures", func(t *testing.T) {
+		backend, m, slept := newRetryTest(2, vfs.ErrUnavailable)
+
+		node, err := m.Stat(ctx, "/m/a.txt")
+		require.NoError(t, err)
+		assert.Equal(t, "a.txt", node.Name)
+		assert.Equal(t, 3, backend.calls)
+		require.Len(t, *slept, 2)
+		assert.LessOrEqual(t, (*slept)[0], time.Second)
+		assert.LessOrEqual(t, (*slept)[1], 2*time.Second)
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		backend, m, _ := newRetryTest(5, vfs.ErrThrottled)
+
+		_, err := m.Stat(ctx, "/m/a.txt")
+		assert.ErrorIs(t, err, vfs.ErrThrottled)
+		assert.Equal(t, 3, backend.calls)
+	})
+
+	t.Run("does not retry permanent failures", func(t *testing.T) {
+		backend, m, _ := newRetryTest(5, vfs.ErrPermissionDenied)
+
+		_, err := m.Stat(ctx, "/m/a.txt")
+		assert.ErrorIs(t, err, vfs.ErrPermissionDenied)
+		assert.Equal(t, 1, backend.calls)
+	})
+
+	t.Run("honours retry after", func(t *testing.T) {
+		hinted := &coreerrors.RetryAfterError{Err: vfs.ErrThrottled, RetryAfter: 10 * time.Second}
+		_, m, slept := newRetryTest(1, hinted)
+
+		_, err := m.Stat(ctx, "/m/a.txt")
+		require.NoError(t, err)
+		assert.Equal(t, []time.Duration{10 * time.Second}, *slept)
+	})
+
+	t.Run("stops before the context deadline", func(t *testing.T) {
+		hinted := &coreerrors.RetryAfterError{Err: vfs.ErrThrottled, RetryAfter: time.Hour}
+		backend, m, slept := newRetryTest(1, hinted)
+
+		dctx, cancel := context.WithTimeout(ctx, time.Minute)
+		defer cancel()
+		_, err := m.Stat(dctx, "/m/a.txt")
+		assert.ErrorIs(t, err, vfs.ErrThrottled)
+		assert.Equal(t, 1, backend.calls)
+		assert.Empty(t, *slept)
+	})
+
+	t.Run("uses the mount policy", func(t *testing.T) {
+		mnt := &mount.Mount{Path: "/m", Options: map[string]string{vfs.OptionRetryMaxAttempts: "1"}}
+		backend, m, _ := newRetryTest(1, vfs.ErrUnavailable, mnt)
+
+		_, err := m.Stat(ctx, "/m/a.txt")
+		assert.ErrorIs(t, err, vfs.ErrUnavailable)
+		assert.Equal(t, 1, backend.calls)
+	})
+
+	t.Run("reopens reads failing before the first byte", func(t *testing.T) {
+		backend, m, _ := newRetryTest(1, vfs.ErrUnavailable)
+
+		r, err := m.Read(ctx, "/m/a.txt")
+		require.NoError(t, err)
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		assert.Equal(t, "content", string(data))
+		assert.Equal(t, 2, backend.calls)
+	})
+
+	t.Run("rewinds seekable writes", func(t *testing.T) {
+		backend, m, _ := newRetryTest(1, vfs.ErrUnavailable)
+
+		err := m.Write(ctx, "/m/b.txt", bytes.NewReader([]byte("data")))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"data", "data"}, backend.written)
+	})
+
+	t.Run("does not retry unseekable writes", func(t *testing.T) {
+		backend, m, _ := newRetryTest(1, vfs.ErrUnavailable)
+
+		err := m.Write(ctx, "/m/b.txt", io.MultiReader(strings.NewReader("data")))
+		assert.ErrorIs(t, err, vfs.ErrUnavailable)
+		assert.Len(t, backend.written, 1)
+	})
+}
+
+func TestRetryPolicyFromOptions(t *testing.T) {
+	p, err := vfs.RetryPolicyFromOptions(map[string]string{
+		vfs.OptionRetryMaxAttempts: "6",
+		vfs.OptionRetryBaseDelay:   "250ms",
+	}, vfs.DefaultRetryPolicy)
+	require.NoError(t, err)
+	assert.Equal(t, vfs.RetryPolicy{MaxAttempts: 6, BaseDelay: 250 * time.Millisecond, MaxDelay: vfs.DefaultRetryPolicy.MaxDelay}, p)
+
+	_, err = vfs.RetryPolicyFromOptions(map[string]string{vfs.OptionRetryMaxDelay: "soon"}, vfs.DefaultRetryPolicy)
+	assert.Error(t, err)
+}
@@ -0,0 +1,94 @@
+package vfs
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultConcurrency is the number of parallel file transfers used when none is configured.
+const DefaultConcurrency = 4
+
+// TransferFunc performs a single file transfer and returns the number of bytes moved.
+type TransferFunc func(ctx context.Context) (int64, error)
+
+// Scheduler runs file transfers on a bounded pool of workers and aggregates their
+// outcomes into a [Summary]. It is safe for concurrent use.
+type Scheduler struct {
+	ctx   context.Context
+	slots chan struct{}
+	wg    sync.WaitGroup
+	start time.Time
+
+	mu      sync.Mutex
+	summary Summary
+}
+
+// NewScheduler returns a [Scheduler] that runs at most concurrency transfers at once.
+// A concurrency below one selects [DefaultConcurrency]. Transfers stop being started
+// once ctx is cancelled.
+func NewScheduler(ctx context.Context, concurrency int) *Scheduler {
+	if concurrency < 1 {
+		concurrency = DefaultConcurrency
+	}
+	return &Scheduler{
+		ctx:   ctx,
+		slots: make(chan struct{}, concurrency),
+		start: time.Now(),
+	}
+}
+
+// Submit queues fn, which transfers the file at path. It blocks until a worker is free
+// and returns the context's error, without running fn, once the context is cancelled.
+func (s *Scheduler) Submit(path string, fn TransferFunc) error {
+	select {
+	case <-s.ctx.Done():
+		return s.ctx.Err()
+	case s.slots <- struct{}{}:
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer func() {
+			<-s.slots
+			s.wg.Done()
+		}()
+
+		n, err := fn(s.ctx)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if err != nil {
+			s.summary.Fail(path, err)
+			return
+		}
+		s.summary.Files++
+		s.summary.Bytes += n
+	}()
+	return nil
+}
+
+// AddDirectory records a directory that was created or processed outside of a transfer.
+func (s *Scheduler) AddDirectory() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.summary.Directories++
+}
+
+// Fail records a failure for an entry that was not submitted as a transfer.
+func (s *Scheduler) Fail(path string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.summary.Fail(path, err)
+}
+
+// Wait blocks until every submitted transfer has finished and returns the aggregated summary.
+func (s *Scheduler) Wait() *Summary {
+	s.wg.Wait()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	summary := s.summary
+	summary.Duration = time.Since(s.start)
+	return &summary
+}
@@ -0,0 +1,109 @@
+package vfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduler(t *testing.T) {
+	errTransfer := errors.New("transfer failed")
+
+	tests := []struct {
+		name        string
+		concurrency int
+		files       int
+		failEvery   int
+		wantFiles   int
+		wantFailed  int
+	}{
+		{
+			name:        "runs every transfer",
+			concurrency: 3,
+			files:       10,
+			wantFiles:   10,
+		},
+		{
+			name:        "collects failures",
+			concurrency: 2,
+			files:       6,
+			failEvery:   3,
+			wantFiles:   4,
+			wantFailed:  2,
+		},
+		{
+			name:      "default concurrency",
+			files:     5,
+			wantFiles: 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limit := tt.concurrency
+			if limit < 1 {
+				limit = DefaultConcurrency
+			}
+
+			var running, peak atomic.Int32
+			sched := NewScheduler(context.Background(), tt.concurrency)
+			for i := 1; i <= tt.files; i++ {
+				err := sched.Submit(fmt.Sprintf("/f%d", i), func(ctx context.Context) (int64, error) {
+					n := running.Add(1)
+					defer running.Add(-1)
+					for {
+						p := peak.Load()
+						if n <= p || peak.CompareAndSwap(p, n) {
+							break
+						}
+					}
+					time.Sleep(5 * time.Millisecond)
+					if tt.failEvery > 0 && i%tt.failEvery == 0 {
+						return 0, errTransfer
+					}
+					return 10, nil
+				})
+				assert.NoError(t, err)
+			}
+			sched.AddDirectory()
+
+			summary := sched.Wait()
+			assert.LessOrEqual(t, int(peak.Load()), limit)
+			assert.Equal(t, tt.wantFiles, summary.Files)
+			assert.Equal(t, 1, summary.Directories)
+			assert.Equal(t, int64(tt.wantFiles*10), summary.Bytes)
+			assert.Len(t, summary.Failures, tt.wantFailed)
+			assert.Positive(t, summary.Duration)
+			if tt.wantFailed > 0 {
+				assert.ErrorIs(t, summary.Err(), errTransfer)
+			}
+		})
+	}
+}
+
+func TestScheduler_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	sched := NewScheduler(ctx, 1)
+
+	release := make(chan struct{})
+	assert.NoError(t, sched.Submit("/a", func(ctx context.Context) (int64, error) {
+		<-release
+		return 1, nil
+	}))
+
+	cancel()
+	err := sched.Submit("/b", func(ctx context.Context) (int64, error) {
+		t.Error("transfer started after cancellation")
+		return 0, nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	close(release)
+	summary := sched.Wait()
+	assert.Equal(t, 1, summary.Files)
+}
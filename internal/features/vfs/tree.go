@@ -0,0 +1,149 @@
+package vfs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	iofs "io/fs"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/michaeldcanady/go-onedrive/pkg/format"
+)
+
+// Summary reports the outcome of a [VFS.Copy], [VFS.Move] or [VFS.Remove] operation.
+// Recursive operations keep going after an entry fails, so a summary may record both
+// completed entries and failures.
+type Summary struct {
+	// Files is the number of files processed successfully.
+	Files int `json:"files"`
+	// Directories is the number of directories processed successfully.
+	Directories int `json:"directories"`
+	// Bytes is the number of bytes streamed through the host.
+	Bytes int64 `json:"bytes"`
+	// Duration is the wall-clock time the operation took, when measured.
+	Duration time.Duration `json:"duration,omitempty"`
+	// Failures lists every entry that could not be processed.
+	Failures []*EntryError `json:"failures,omitempty"`
+}
+
+// Fail records a failure for the entry at path.
+func (s *Summary) Fail(path string, err error) {
+	s.Failures = append(s.Failures, &EntryError{Path: path, Err: err})
+}
+
+// count records a successfully processed node.
+func (s *Summary) count(node *Node) {
+	if node.Type == DirectoryType {
+		s.Directories++
+		return
+	}
+	s.Files++
+}
+
+// Err returns nil when every entry succeeded, otherwise an error wrapping each failure.
+func (s *Summary) Err() error {
+	switch len(s.Failures) {
+	case 0:
+		return nil
+	case 1:
+		return s.Failures[0]
+	}
+
+	errs := make([]error, len(s.Failures))
+	for i, f := range s.Failures {
+		errs[i] = f
+	}
+	return fmt.Errorf("%d entries failed:\n%w", len(errs), errors.Join(errs...))
+}
+
+// Throughput returns the aggregate transfer rate in bytes per second.
+func (s *Summary) Throughput() float64 {
+	if s.Duration <= 0 {
+		return 0
+	}
+	return float64(s.Bytes) / s.Duration.Seconds()
+}
+
+// String returns a one-line description of the completed work.
+func (s *Summary) String() string {
+	out := fmt.Sprintf("%d files, %d directories, %d bytes", s.Files, s.Directories, s.Bytes)
+	if s.Duration > 0 {
+		out += fmt.Sprintf(" in %s (%s/s)", s.Duration.Round(time.Millisecond), format.Bytes(s.Throughput()))
+	}
+	return out
+}
+
+// EntryError describes the failure of a single entry within a tree operation.
+type EntryError struct {
+	Path string
+	Err  error
+}
+
+func (e *EntryError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+func (e *EntryError) Unwrap() error {
+	return e.Err
+}
+
+// MarshalJSON renders the entry with its error message.
+func (e *EntryError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Path  string `json:"path"`
+		Error string `json:"error"`
+	}{e.Path, e.Err.Error()})
+}
+
+// WalkFunc is called by [Walk] for each node in the tree. If listing a directory fails,
+// it is called a second time for that directory with the error. Returning [iofs.SkipDir]
+// from a directory skips its contents; any other error stops the walk.
+type WalkFunc func(path string, node *Node, err error) error
+
+// Walk visits root and, when it is a directory, every node beneath it in lexical order,
+// parents before children. Paths passed to fn are absolute VFS paths.
+func Walk(ctx context.Context, v VFS, root string, fn WalkFunc) error {
+	root = path.Clean(root)
+	node, err := v.Stat(ctx, root)
+	if err != nil {
+		return fn(root, nil, err)
+	}
+	err = walk(ctx, v, root, node, fn)
+	if err == iofs.SkipDir || err == iofs.SkipAll {
+		return nil
+	}
+	return err
+}
+
+func walk(ctx context.Context, v VFS, p string, node *Node, fn WalkFunc) error {
+	if err := fn(p, node, nil); err != nil || node.Type != DirectoryType {
+		return err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	children, err := v.List(ctx, p)
+	if err != nil {
+		if err := fn(p, node, err); err != nil && err != iofs.SkipDir {
+			return err
+		}
+		return nil
+	}
+
+	slices.SortFunc(children, func(a, b *Node) int { return strings.Compare(a.Name, b.Name) })
+	for _, child := range children {
+		if err := walk(ctx, v, path.Join(p, child.Name), child, fn); err != nil {
+			if err == iofs.SkipDir && child.Type == DirectoryType {
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
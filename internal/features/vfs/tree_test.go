@@ -0,0 +1,109 @@
+package vfs_test
+
+import (
+	"context"
+	"errors"
+	iofs "io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/michaeldcanady/go-onedrive/internal/features/vfs"
+	"github.com/michaeldcanady/go-onedrive/internal/features/vfs/vfstest"
+)
+
+// memTree returns a [vfstest.MemFS] holding empty files at paths. Paths ending in a
+// slash are directories.
+func memTree(paths ...string) *vfstest.MemFS {
+	files := make(map[string]string, len(paths))
+	for _, p := range paths {
+		files[p] = ""
+	}
+	return vfstest.NewMemFS(files)
+}
+
+func TestWalk(t *testing.T) {
+	errList := errors.New("list failed")
+
+	tests := []struct {
+		name    string
+		fs      *vfstest.MemFS
+		root    string
+		skip    string
+		listErr string
+		want    []string
+		wantErr []string
+	}{
+		{
+			name: "single file",
+			fs:   memTree("/a.txt"),
+			root: "/a.txt",
+			want: []string{"/a.txt"},
+		},
+		{
+			name: "parents before children in lexical order",
+			fs:   memTree("/d/", "/d/b.txt", "/d/a/", "/d/a/x.txt", "/d/c.txt"),
+			root: "/d",
+			want: []string{"/d", "/d/a", "/d/a/x.txt", "/d/b.txt", "/d/c.txt"},
+		},
+		{
+			name: "skip directory",
+			fs:   memTree("/d/", "/d/a/", "/d/a/x.txt", "/d/b.txt"),
+			root: "/d",
+			skip: "/d/a",
+			want: []string{"/d", "/d/a", "/d/b.txt"},
+		},
+		{
+			name:    "list failure is reported and walk continues",
+			fs:      memTree("/d/", "/d/a/", "/d/a/x.txt", "/d/b.txt"),
+			root:    "/d",
+			listErr: "/d/a",
+			want:    []string{"/d", "/d/a", "/d/b.txt"},
+			wantErr: []string{"/d/a"},
+		},
+		{
+			name:    "missing root",
+			fs:      memTree(),
+			root:    "/missing",
+			wantErr: []string{"/missing"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.listErr != "" {
+				tt.fs.FailList(tt.listErr, errList)
+			}
+
+			var visited, failed []string
+			err := vfs.Walk(context.Background(), tt.fs, tt.root, func(p string, n *vfs.Node, err error) error {
+				if err != nil {
+					failed = append(failed, p)
+					return nil
+				}
+				visited = append(visited, p)
+				if p == tt.skip {
+					return iofs.SkipDir
+				}
+				return nil
+			})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, visited)
+			assert.Equal(t, tt.wantErr, failed)
+		})
+	}
+}
+
+func TestSummary_Err(t *testing.T) {
+	s := &vfs.Summary{}
+	assert.NoError(t, s.Err())
+
+	s.Fail("/a", vfs.ErrNotFound)
+	assert.ErrorIs(t, s.Err(), vfs.ErrNotFound)
+	assert.EqualError(t, s.Err(), "/a: "+vfs.ErrNotFound.Error())
+
+	s.Fail("/b", vfs.ErrPermissionDenied)
+	assert.ErrorIs(t, s.Err(), vfs.ErrNotFound)
+	assert.ErrorIs(t, s.Err(), vfs.ErrPermissionDenied)
+}
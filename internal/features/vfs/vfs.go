@@ -18,6 +18,9 @@ type Node struct {
 	ModifiedAt int64    `json:"modified_at"`
 	ETag       string   `json:"etag"` // ETag used for optimistic concurrency in Write operations.
 	CTag       string   `json:"ctag"`
+	// Hashes maps a hash algorithm, such as "sha256", to the hex or base64 digest the
+	// backend reports for a file's content.
+	Hashes map[string]string `json:"hashes,omitempty"`
 }
 
 // NodeType distinguishes between files and directories.
@@ -28,6 +31,21 @@ const (
 	DirectoryType NodeType = 1
 )
 
+// Change describes a node that was created, modified or deleted.
+type Change struct {
+	// Node carries the node's latest metadata. Deleted nodes may only carry an ID and path.
+	Node *Node `json:"node"`
+	// Deleted is set when the node no longer exists.
+	Deleted bool `json:"deleted"`
+}
+
+// ChangeSet is the result of a [VFS.Changes] call.
+type ChangeSet struct {
+	Changes []Change `json:"changes"`
+	// Cursor resumes enumeration after the last change in the set.
+	Cursor string `json:"cursor"`
+}
+
 // VFS coordinates file operations across a unified virtual namespace.
 // It is the primary interface for all filesystem-like interactions in the application.
 type VFS interface {
@@ -41,34 +59,125 @@ type VFS interface {
 	Mkdir(ctx context.Context, path string) error
 
 	// Remove deletes the file or directory at the specified path.
-	Remove(ctx context.Context, path string) error
+	// Non-empty directories are only removed when [WithRecursive] is supplied.
+	Remove(ctx context.Context, path string, options ...TreeOption) (*Summary, error)
 
-	// Move renames or relocates a node. Cross-mount moves are handled as copy-then-delete.
-	Move(ctx context.Context, src, dst string) error
+	// Move renames or relocates a node. Cross-mount moves are handled as a recursive
+	// copy followed by a delete of the source tree.
+	Move(ctx context.Context, src, dst string, options ...TreeOption) (*Summary, error)
 
 	// Copy replicates a node. Cross-mount copies involve streaming data through the host.
-	Copy(ctx context.Context, src, dst string) error
+	// Directories are only copied when [WithRecursive] is supplied.
+	Copy(ctx context.Context, src, dst string, options ...TreeOption) (*Summary, error)
 
 	// Read opens a stream for reading the file's content.
 	// The caller is responsible for closing the returned [io.ReadCloser].
-	Read(ctx context.Context, path string) (io.ReadCloser, error)
+	Read(ctx context.Context, path string, options ...ReadOption) (io.ReadCloser, error)
 
 	// Write streams data to the specified path, creating or overwriting the file.
 	Write(ctx context.Context, path string, reader io.Reader, options ...WriteOption) error
+
+	// Changes reports the nodes beneath path that changed since cursor was issued. An empty
+	// cursor enumerates every node. Paths in the result are absolute VFS paths, and the
+	// returned cursor is recorded for path so it can be retrieved from the mount service.
+	Changes(ctx context.Context, path, cursor string) (*ChangeSet, error)
+}
+
+// ReadOptions holds the settings applied by [ReadOption] values.
+type ReadOptions struct {
+	// Offset is the byte position at which reading starts.
+	Offset int64
+	// Length limits the number of bytes returned. Zero reads to the end of the file.
+	Length int64
+	// Progress, when set, receives updates as the content is consumed.
+	Progress ProgressFunc
+}
+
+// ReadOption configures the behavior of a [VFS.Read] operation.
+type ReadOption func(*ReadOptions)
+
+// WithRange restricts a read to length bytes starting at offset. A length of zero reads to the end of the file.
+func WithRange(offset, length int64) ReadOption {
+	return func(opts *ReadOptions) {
+		opts.Offset = offset
+		opts.Length = length
+	}
+}
+
+// WithReadProgress reports the progress of a read to fn. The total size is taken from
+// the file's metadata.
+func WithReadProgress(fn ProgressFunc) ReadOption {
+	return func(opts *ReadOptions) {
+		opts.Progress = fn
+	}
+}
+
+// TreeOptions holds the settings applied by [TreeOption] values.
+type TreeOptions struct {
+	// Recursive allows the operation to descend into directories.
+	Recursive bool
+	// Concurrency is the number of file transfers run in parallel. Values below one
+	// select [DefaultConcurrency].
+	Concurrency int
+	// Progress, when set, receives updates for every file transferred.
+	Progress ProgressFunc
+}
+
+// TreeOption configures the behavior of a [VFS.Copy], [VFS.Move] or [VFS.Remove] operation.
+type TreeOption func(*TreeOptions)
+
+// WithRecursive allows an operation to walk the whole tree beneath a directory.
+func WithRecursive() TreeOption {
+	return func(opts *TreeOptions) {
+		opts.Recursive = true
+	}
+}
+
+// WithConcurrency sets the number of file transfers a copy or move runs in parallel.
+func WithConcurrency(n int) TreeOption {
+	return func(opts *TreeOptions) {
+		opts.Concurrency = n
+	}
+}
+
+// WithTransferProgress reports the progress of every file a copy or move transfers to fn.
+func WithTransferProgress(fn ProgressFunc) TreeOption {
+	return func(opts *TreeOptions) {
+		opts.Progress = fn
+	}
+}
+
+// WriteOptions holds the settings applied by [WriteOption] values.
+type WriteOptions struct {
+	// Metadata is forwarded to the storage plugin as request options.
+	Metadata map[string]string
+	// Size is the expected number of bytes, used as the progress total. Zero means unknown.
+	Size int64
+	// Progress, when set, receives updates as the content is sent.
+	Progress ProgressFunc
 }
 
 // WriteOption configures the behavior of a [VFS.Write] operation.
-type WriteOption func(map[string]string)
+type WriteOption func(*WriteOptions)
 
 // WithIfMatch enables optimistic concurrency by requiring the file's ETag to match the provided value.
 func WithIfMatch(etag string) WriteOption {
-	return func(opts map[string]string) {
+	return func(opts *WriteOptions) {
 		if etag != "" {
-			opts["if_match"] = etag
+			opts.Metadata["if_match"] = etag
 		}
 	}
 }
 
+// WithWriteProgress reports the progress of a write to fn. size is the expected number
+// of bytes, or zero when it is not known in advance.
+func WithWriteProgress(size int64, fn ProgressFunc) WriteOption {
+	return func(opts *WriteOptions) {
+		opts.Size = size
+		opts.Progress = fn
+	}
+}
+
 func FromProtoNode(p *storage_proto.Node) *Node {
 	return &Node{
 		ID:         p.Id,
@@ -79,5 +188,6 @@ func FromProtoNode(p *storage_proto.Node) *Node {
 		ModifiedAt: p.ModifiedAt,
 		ETag:       p.Etag,
 		CTag:       p.Ctag,
+		Hashes:     p.Hashes,
 	}
 }
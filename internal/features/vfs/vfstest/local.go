@@ -0,0 +1,73 @@
+package vfstest
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+
+	"github.com/michaeldcanady/go-onedrive/internal/core/logger/loggertest"
+	"github.com/michaeldcanady/go-onedrive/internal/core/plugins"
+	"github.com/michaeldcanady/go-onedrive/internal/features/config"
+	"github.com/michaeldcanady/go-onedrive/internal/features/mount"
+	"github.com/michaeldcanady/go-onedrive/internal/features/plugins/storage/local"
+	"github.com/michaeldcanady/go-onedrive/internal/features/vfs"
+	"github.com/michaeldcanady/go-onedrive/pkg/pluginsdk"
+)
+
+// Local is an orchestrator whose mounts are all served by the local storage plugin.
+type Local struct {
+	vfs.VFS
+	// Mounts lists the mounts, each backed by its own temporary directory.
+	Mounts Mounts
+}
+
+// NewLocal returns a [*Local] with a mount at each of mountPaths. The local plugin is
+// served in-process through a plugin registry, as odc serves it.
+func NewLocal(t testing.TB, mountPaths ...string) *Local {
+	t.Helper()
+	dir := t.TempDir()
+
+	db, err := bbolt.Open(filepath.Join(dir, "state.db"), 0600, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	repo, err := plugins.NewBoltRepository(db)
+	require.NoError(t, err)
+
+	cfg := config.NewConfigService(config.NewYAMLRepository(filepath.Join(dir, "config.yaml")), loggertest.Nop{})
+	require.NoError(t, cfg.Set(config.KeyCorePluginsDir, filepath.Join(dir, "plugins")))
+	require.NoError(t, cfg.Set(config.KeyCoreLogDir, filepath.Join(dir, "logs")))
+
+	registry := plugins.NewRegistry()
+	registry.RegisterStorage("storage-local", local.NewStoragePlugin(),
+		plugins.WithGRPCServer(plugins.CustomGRPCServerWithErrors(pluginsdk.ToStatus)))
+	pm := plugins.NewPluginManagerWithRegistry(cfg, loggertest.Nop{}, repo, registry)
+	t.Cleanup(func() { pm.Shutdown(context.Background()) })
+
+	mounts := make(Mounts, 0, len(mountPaths))
+	for _, p := range mountPaths {
+		root := filepath.Join(dir, "mounts", filepath.FromSlash(p))
+		require.NoError(t, os.MkdirAll(root, 0755))
+		mounts = append(mounts, &mount.Mount{Path: p, Type: "local", Options: map[string]string{"root_path": root}})
+	}
+	return &Local{VFS: vfs.NewOrchestrator(mounts, pm, nil, nil, loggertest.Nop{}), Mounts: mounts}
+}
+
+// Path returns the host path backing the VFS path p, or an empty string when p is not
+// beneath any mount.
+func (l *Local) Path(p string) string {
+	var best *mount.Mount
+	for _, m := range l.Mounts {
+		if (p == m.Path || strings.HasPrefix(p, strings.TrimSuffix(m.Path, "/")+"/")) && (best == nil || len(m.Path) > len(best.Path)) {
+			best = m
+		}
+	}
+	if best == nil {
+		return ""
+	}
+	return filepath.Join(best.Options["root_path"], filepath.FromSlash(strings.TrimPrefix(p, best.Path)))
+}
@@ -0,0 +1,313 @@
+// Package vfstest provides [vfs.VFS] implementations for tests.
+package vfstest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/michaeldcanady/go-onedrive/internal/features/vfs"
+)
+
+// MemFS is an in-memory [vfs.VFS] keyed by absolute path. Every write gives a file a
+// new numeric ETag and a later modification time, and the mutating calls made to it
+// are recorded.
+type MemFS struct {
+	mu      sync.Mutex
+	nodes   map[string]*vfs.Node
+	data    map[string][]byte
+	clock   int64
+	listErr map[string]error
+	ifMatch []string
+	calls   []string
+}
+
+// NewMemFS returns a [*MemFS] holding files, keyed by path. Paths ending in a slash
+// are created as empty directories.
+func NewMemFS(files map[string]string) *MemFS {
+	m := &MemFS{
+		nodes:   map[string]*vfs.Node{"/": {Name: "/", Path: "/", Type: vfs.DirectoryType}},
+		data:    map[string][]byte{},
+		clock:   100,
+		listErr: map[string]error{},
+	}
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		if strings.HasSuffix(p, "/") {
+			m.mkdirAll(strings.TrimSuffix(p, "/"))
+			continue
+		}
+		m.put(p, []byte(files[p]))
+	}
+	return m
+}
+
+// Put creates or replaces the file at p, creating its parent directories.
+func (m *MemFS) Put(p, content string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.put(p, []byte(content))
+}
+
+// File returns the content of the file at p.
+func (m *MemFS) File(p string) (string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.data[p]
+	return string(data), ok
+}
+
+// Exists reports whether a file or directory exists at p.
+func (m *MemFS) Exists(p string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.nodes[p]
+	return ok
+}
+
+// Node returns the node stored at p, or nil. Changes to it are seen by later calls.
+func (m *MemFS) Node(p string) *vfs.Node {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.nodes[p]
+}
+
+// FailList makes List of the directory p fail with err.
+func (m *MemFS) FailList(p string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.listErr[p] = err
+}
+
+// IfMatch returns the ETags writes were conditioned on, in order.
+func (m *MemFS) IfMatch() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.ifMatch...)
+}
+
+// Calls returns the Mkdir, Remove, Move and Copy calls made, in order.
+func (m *MemFS) Calls() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.calls...)
+}
+
+func (m *MemFS) put(p string, data []byte) {
+	m.mkdirAll(path.Dir(p))
+	version := 1
+	if n, ok := m.nodes[p]; ok {
+		version, _ = strconv.Atoi(n.ETag)
+		version++
+	}
+	m.clock += 10
+	m.nodes[p] = &vfs.Node{
+		Name:       path.Base(p),
+		Path:       p,
+		Type:       vfs.FileType,
+		Size:       int64(len(data)),
+		ModifiedAt: m.clock,
+		ETag:       strconv.Itoa(version),
+	}
+	m.data[p] = data
+}
+
+func (m *MemFS) mkdirAll(p string) {
+	for dir := p; dir != "/"; dir = path.Dir(dir) {
+		if _, ok := m.nodes[dir]; !ok {
+			m.nodes[dir] = &vfs.Node{Name: path.Base(dir), Path: dir, Type: vfs.DirectoryType}
+		}
+	}
+}
+
+func (m *MemFS) List(ctx context.Context, p string) ([]*vfs.Node, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.listErr[p]; err != nil {
+		return nil, err
+	}
+	if _, ok := m.nodes[p]; !ok {
+		return nil, vfs.ErrNotFound
+	}
+	var nodes []*vfs.Node
+	for k, n := range m.nodes {
+		if k != "/" && path.Dir(k) == p {
+			cp := *n
+			nodes = append(nodes, &cp)
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+	return nodes, nil
+}
+
+func (m *MemFS) Stat(ctx context.Context, p string) (*vfs.Node, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if n, ok := m.nodes[p]; ok {
+		cp := *n
+		return &cp, nil
+	}
+	return nil, vfs.ErrNotFound
+}
+
+func (m *MemFS) Mkdir(ctx context.Context, p string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, "Mkdir "+p)
+	if _, ok := m.nodes[p]; ok {
+		return vfs.ErrAlreadyExists
+	}
+	m.nodes[p] = &vfs.Node{Name: path.Base(p), Path: p, Type: vfs.DirectoryType}
+	return nil
+}
+
+func (m *MemFS) Remove(ctx context.Context, p string, options ...vfs.TreeOption) (*vfs.Summary, error) {
+	to := treeOptions(options)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, "Remove "+p)
+	if _, ok := m.nodes[p]; !ok {
+		return nil, vfs.ErrNotFound
+	}
+	summary := &vfs.Summary{}
+	for k, n := range m.nodes {
+		if k != p && strings.HasPrefix(k, p+"/") {
+			if !to.Recursive {
+				return nil, vfs.ErrNotEmpty
+			}
+			count(summary, n)
+		}
+	}
+	for k := range m.nodes {
+		if k == p || strings.HasPrefix(k, p+"/") {
+			delete(m.nodes, k)
+			delete(m.data, k)
+		}
+	}
+	return summary, nil
+}
+
+func (m *MemFS) Move(ctx context.Context, src, dst string, options ...vfs.TreeOption) (*vfs.Summary, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, "Move "+src+" "+dst)
+	return m.transfer(src, dst, true)
+}
+
+func (m *MemFS) Copy(ctx context.Context, src, dst string, options ...vfs.TreeOption) (*vfs.Summary, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, "Copy "+src+" "+dst)
+	return m.transfer(src, dst, false)
+}
+
+// transfer copies or moves the tree at src to dst. Moved nodes keep their metadata, and
+// copied files are written anew.
+func (m *MemFS) transfer(src, dst string, remove bool) (*vfs.Summary, error) {
+	if _, ok := m.nodes[src]; !ok {
+		return nil, vfs.ErrNotFound
+	}
+	var paths []string
+	for k := range m.nodes {
+		if k == src || strings.HasPrefix(k, src+"/") {
+			paths = append(paths, k)
+		}
+	}
+	sort.Strings(paths)
+
+	summary := &vfs.Summary{}
+	for _, k := range paths {
+		n := m.nodes[k]
+		target := dst + strings.TrimPrefix(k, src)
+		switch {
+		case remove:
+			cp := *n
+			cp.Path, cp.Name = target, path.Base(target)
+			m.nodes[target] = &cp
+			if data, ok := m.data[k]; ok {
+				m.data[target] = data
+			}
+			delete(m.nodes, k)
+			delete(m.data, k)
+		case n.Type == vfs.DirectoryType:
+			m.mkdirAll(target)
+		default:
+			m.put(target, m.data[k])
+		}
+		count(summary, m.nodes[target])
+		if !remove {
+			summary.Bytes += m.nodes[target].Size
+		}
+	}
+	return summary, nil
+}
+
+func (m *MemFS) Read(ctx context.Context, p string, options ...vfs.ReadOption) (io.ReadCloser, error) {
+	var ro vfs.ReadOptions
+	for _, opt := range options {
+		opt(&ro)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.data[p]
+	if !ok {
+		return nil, vfs.ErrNotFound
+	}
+	data = data[min(ro.Offset, int64(len(data))):]
+	if ro.Length > 0 {
+		data = data[:min(ro.Length, int64(len(data)))]
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (m *MemFS) Write(ctx context.Context, p string, r io.Reader, options ...vfs.WriteOption) error {
+	wo := vfs.WriteOptions{Metadata: map[string]string{}}
+	for _, opt := range options {
+		opt(&wo)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if etag, ok := wo.Metadata["if_match"]; ok {
+		m.ifMatch = append(m.ifMatch, etag)
+		if n, exists := m.nodes[p]; exists && n.ETag != etag {
+			return vfs.ErrPrecondition
+		}
+	}
+	m.put(p, data)
+	return nil
+}
+
+func (m *MemFS) Changes(ctx context.Context, p, cursor string) (*vfs.ChangeSet, error) {
+	return nil, errors.ErrUnsupported
+}
+
+// count records n in summary.
+func count(summary *vfs.Summary, n *vfs.Node) {
+	if n.Type == vfs.DirectoryType {
+		summary.Directories++
+		return
+	}
+	summary.Files++
+}
+
+func treeOptions(options []vfs.TreeOption) vfs.TreeOptions {
+	var to vfs.TreeOptions
+	for _, opt := range options {
+		opt(&to)
+	}
+	return to
+}
@@ -0,0 +1,27 @@
+package vfstest
+
+import (
+	"context"
+
+	"github.com/michaeldcanady/go-onedrive/internal/features/mount"
+	"github.com/michaeldcanady/go-onedrive/internal/features/vfs"
+)
+
+// Mounts is a read-only [mount.Service] listing a fixed set of mounts.
+type Mounts []*mount.Mount
+
+func (s Mounts) Add(ctx context.Context, m *mount.Mount) error    { return nil }
+func (s Mounts) List(ctx context.Context) ([]*mount.Mount, error) { return s, nil }
+func (s Mounts) Remove(ctx context.Context, path string) error    { return nil }
+func (s Mounts) Get(ctx context.Context, path string) (*mount.Mount, error) {
+	for _, m := range s {
+		if m.Path == path {
+			return m, nil
+		}
+	}
+	return nil, vfs.ErrNotFound
+}
+func (s Mounts) Cursor(ctx context.Context, path string) (string, error) { return "", nil }
+func (s Mounts) SaveCursor(ctx context.Context, path, cursor string) error {
+	return nil
+}
@@ -0,0 +1,18 @@
+package format
+
+import "fmt"
+
+// Bytes renders a byte count (or a rate in bytes per second) using binary unit
+// prefixes, e.g. "512 B" or "1.5 MiB".
+func Bytes(n float64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%.0f B", n)
+	}
+	exp := 0
+	for n >= unit*unit && exp < 5 {
+		n /= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", n/unit, "KMGTPE"[exp])
+}
@@ -0,0 +1,146 @@
+package pluginsdk
+
+import (
+	"context"
+	"io"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+// Options that the host passes with every request. Mounts may add backend-specific ones.
+const (
+	// OptionRootPath is the directory a filesystem-like backend is rooted at.
+	OptionRootPath = "root_path"
+	// OptionToken is the access token of the mount's identity.
+	OptionToken = "token"
+	// OptionDriveID selects the drive of backends with several.
+	OptionDriveID = "drive_id"
+	// OptionIfMatch is the ETag a Write expects the existing file to have.
+	OptionIfMatch = "if_match"
+)
+
+// Options are the options of a request: the mount's options and the ones listed above.
+type Options map[string]string
+
+// RootPath returns [OptionRootPath], or "." when it is not set.
+func (o Options) RootPath() string {
+	if root := o[OptionRootPath]; root != "" {
+		return root
+	}
+	return "."
+}
+
+// Token returns [OptionToken].
+func (o Options) Token() string { return o[OptionToken] }
+
+// DriveID returns [OptionDriveID].
+func (o Options) DriveID() string { return o[OptionDriveID] }
+
+// IfMatch returns [OptionIfMatch].
+func (o Options) IfMatch() string { return o[OptionIfMatch] }
+
+// Join returns the host path of p beneath [Options.RootPath]. p is cleaned as an
+// absolute path first, so it cannot escape the root.
+func (o Options) Join(p string) string {
+	return filepath.Join(o.RootPath(), filepath.FromSlash(path.Clean("/"+p)))
+}
+
+// Node describes a file or directory.
+type Node struct {
+	// ID is the backend's identifier for the item, if it has one.
+	ID string
+	// Name is the final element of Path. It is filled in from Path when empty.
+	Name string
+	// Path is the slash-separated path within the mount. When empty, it is filled in
+	// from the request, or for List from the directory path and Name.
+	Path    string
+	Dir     bool
+	Size    int64
+	ModTime time.Time
+	ETag    string
+	CTag    string
+	// Hashes maps algorithm names, such as "sha256", to hex-encoded digests.
+	Hashes map[string]string
+}
+
+// Backend is the interface every storage plugin implements. Paths are slash-separated
+// and relative to the mount. Errors should wrap the sentinels in this package or
+// [io/fs] so the host can tell them apart; see [ToStatus].
+type Backend interface {
+	// Stat describes the item at path.
+	Stat(ctx context.Context, opts Options, path string) (*Node, error)
+	// List describes the children of the directory at path.
+	List(ctx context.Context, opts Options, path string) ([]*Node, error)
+	// Mkdir creates the directory at path and any missing parents. It fails with
+	// [ErrAlreadyExists] when an item already exists at path.
+	Mkdir(ctx context.Context, opts Options, path string) (*Node, error)
+	// Open returns the content of the file at path.
+	Open(ctx context.Context, opts Options, path string) (io.ReadCloser, error)
+	// Write replaces the file at path with the content of r, creating missing parents.
+	// When [Options.IfMatch] is set and the backend reports ETag concurrency in [Info],
+	// it must fail with [ErrPrecondition] unless the existing file has that ETag.
+	Write(ctx context.Context, opts Options, path string, r io.Reader) (*Node, error)
+	// Remove deletes the item at path, and everything beneath it when it is a directory.
+	Remove(ctx context.Context, opts Options, path string) error
+}
+
+// RangeOpener is implemented by backends that can read part of a file without fetching
+// what precedes it. Other backends have ranges applied by the SDK.
+type RangeOpener interface {
+	// OpenRange returns length bytes of the file at path starting at offset. A length
+	// of zero reads to the end of the file.
+	OpenRange(ctx context.Context, opts Options, path string, offset, length int64) (io.ReadCloser, error)
+}
+
+// Mover is implemented by backends that can move items natively. Without it the host
+// copies and deletes.
+type Mover interface {
+	Move(ctx context.Context, opts Options, src, dst string) (*Node, error)
+}
+
+// Copier is implemented by backends that can copy items, including directories,
+// without streaming them through the host.
+type Copier interface {
+	Copy(ctx context.Context, opts Options, src, dst string) (*Node, error)
+}
+
+// Drive is a top-level storage area of a backend, such as a OneDrive drive.
+type Drive struct {
+	ID   string
+	Name string
+	Type string
+}
+
+// DriveLister is implemented by backends with more than one drive.
+type DriveLister interface {
+	ListDrives(ctx context.Context, opts Options) ([]Drive, error)
+}
+
+// Change is an item reported by [DeltaSource].
+type Change struct {
+	Node    *Node
+	Deleted bool
+}
+
+// DeltaSource is implemented by backends that can report what changed beneath a path.
+type DeltaSource interface {
+	// Delta calls emit for every item beneath path that changed since cursor was
+	// issued, or for every item when cursor is empty. It returns the cursor for the
+	// next call. A cursor the backend no longer accepts fails with [ErrCursorExpired].
+	Delta(ctx context.Context, opts Options, path, cursor string, emit func(Change) error) (string, error)
+}
+
+// Info describes a plugin to the host.
+type Info struct {
+	// Name is the storage type the plugin serves, e.g. "example" for storage-example.
+	Name string
+	// Version is reported by odc plugin list.
+	Version string
+	// Providers lists the identity providers whose tokens the backend accepts.
+	Providers []string
+	// ETagConcurrency is set when Write honours [Options.IfMatch] atomically.
+	ETagConcurrency bool
+	// Hashes lists the algorithms reported in [Node.Hashes].
+	Hashes []string
+}
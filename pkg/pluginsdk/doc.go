@@ -0,0 +1,15 @@
+// Package pluginsdk helps write odc storage plugins. A plugin implements the small,
+// filesystem-like [Backend] interface, plus any optional interfaces such as [Mover] or
+// [DeltaSource] it supports natively, and hands it to [Serve] from its main function:
+//
+//	func main() {
+//		pluginsdk.Serve(pluginsdk.Info{Name: "example", Version: version}, &exampleBackend{})
+//	}
+//
+// The SDK speaks the odc storage gRPC protocol on the backend's behalf. It chunks Read and
+// Write streams, fills in node names and paths, reports capabilities from the interfaces
+// the backend implements, and translates errors into the gRPC statuses the host
+// understands (see [ToStatus]). The binary must be named storage-<name> to be found by odc.
+//
+// The pluginsdktest package runs a conformance suite against a Backend.
+package pluginsdk
@@ -0,0 +1,83 @@
+package pluginsdk
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	coreerrors "github.com/michaeldcanady/go-onedrive/internal/core/errors"
+	"github.com/michaeldcanady/go-onedrive/internal/core/plugins"
+)
+
+// Errors a [Backend] returns, wrapped or as-is, so the host can act on them. The
+// equivalent [io/fs] errors are recognized as well.
+var (
+	// ErrNotFound means the item does not exist.
+	ErrNotFound = coreerrors.ErrNotFound
+	// ErrAlreadyExists means an item is in the way.
+	ErrAlreadyExists = coreerrors.ErrAlreadyExists
+	// ErrPermissionDenied means the identity may not access the item.
+	ErrPermissionDenied = coreerrors.ErrPermissionDenied
+	// ErrInvalidPath means the path cannot name an item in this backend.
+	ErrInvalidPath = coreerrors.ErrInvalidPath
+	// ErrNotEmpty means a directory has children where none are allowed.
+	ErrNotEmpty = coreerrors.ErrNotEmpty
+	// ErrPrecondition means a Write's [Options.IfMatch] did not match.
+	ErrPrecondition = coreerrors.ErrPrecondition
+	// ErrCursorExpired means a Delta cursor is no longer accepted.
+	ErrCursorExpired = coreerrors.ErrCursorExpired
+	// ErrUnavailable means the backend is temporarily unreachable. The host retries.
+	ErrUnavailable = coreerrors.ErrUnavailable
+	// ErrThrottled means the backend asked to slow down. The host retries.
+	ErrThrottled = coreerrors.ErrThrottled
+)
+
+// RetryAfter annotates err with the delay the backend asked for before the next
+// attempt. It is passed to the host with [ErrUnavailable] and [ErrThrottled].
+func RetryAfter(err error, d time.Duration) error {
+	return &coreerrors.RetryAfterError{Err: err, RetryAfter: d}
+}
+
+// ToStatus translates an error returned by a [Backend] into the gRPC status that
+// [plugins.FromGRPC] turns back into the same error on the host. Status errors are
+// returned unchanged, and unrecognized errors are sent as [codes.Unknown] with their
+// message.
+func ToStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	retryAfter, _ := coreerrors.RetryAfter(err)
+	switch {
+	case errors.Is(err, ErrNotFound), errors.Is(err, fs.ErrNotExist):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, ErrAlreadyExists), errors.Is(err, fs.ErrExist):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, ErrPermissionDenied), errors.Is(err, fs.ErrPermission):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, ErrInvalidPath), errors.Is(err, fs.ErrInvalid):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, ErrNotEmpty), errors.Is(err, ErrCursorExpired):
+		// The host tells these apart by the sentinel's text in the message.
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, ErrPrecondition):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, ErrThrottled):
+		return plugins.RetryableError(codes.ResourceExhausted, err.Error(), retryAfter)
+	case errors.Is(err, ErrUnavailable):
+		return plugins.RetryableError(codes.Unavailable, err.Error(), retryAfter)
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	default:
+		return status.Error(codes.Unknown, err.Error())
+	}
+}
@@ -0,0 +1,98 @@
+package pluginsdk_test
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/michaeldcanady/go-onedrive/pkg/pluginsdk"
+)
+
+// dirBackend serves the directory named by the root_path option.
+type dirBackend struct{}
+
+func (dirBackend) node(full string) (*pluginsdk.Node, error) {
+	info, err := os.Stat(full)
+	if err != nil {
+		return nil, err
+	}
+	return &pluginsdk.Node{Dir: info.IsDir(), Size: info.Size(), ModTime: info.ModTime()}, nil
+}
+
+func (b dirBackend) Stat(ctx context.Context, opts pluginsdk.Options, p string) (*pluginsdk.Node, error) {
+	return b.node(opts.Join(p))
+}
+
+func (b dirBackend) List(ctx context.Context, opts pluginsdk.Options, p string) ([]*pluginsdk.Node, error) {
+	entries, err := os.ReadDir(opts.Join(p))
+	if err != nil {
+		return nil, err
+	}
+	var nodes []*pluginsdk.Node
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		nodes = append(nodes, &pluginsdk.Node{Name: e.Name(), Dir: e.IsDir(), Size: info.Size(), ModTime: info.ModTime()})
+	}
+	return nodes, nil
+}
+
+func (b dirBackend) Mkdir(ctx context.Context, opts pluginsdk.Options, p string) (*pluginsdk.Node, error) {
+	if _, err := os.Stat(opts.Join(p)); err == nil {
+		return nil, &fs.PathError{Op: "mkdir", Path: p, Err: fs.ErrExist}
+	}
+	if err := os.MkdirAll(opts.Join(p), 0755); err != nil {
+		return nil, err
+	}
+	return b.node(opts.Join(p))
+}
+
+func (dirBackend) Open(ctx context.Context, opts pluginsdk.Options, p string) (io.ReadCloser, error) {
+	return os.Open(opts.Join(p))
+}
+
+func (b dirBackend) Write(ctx context.Context, opts pluginsdk.Options, p string, r io.Reader) (*pluginsdk.Node, error) {
+	full := opts.Join(p)
+	if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.Create(full)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+	return b.node(full)
+}
+
+func (dirBackend) Remove(ctx context.Context, opts pluginsdk.Options, p string) error {
+	full := opts.Join(p)
+	if _, err := os.Stat(full); err != nil {
+		return err
+	}
+	return os.RemoveAll(full)
+}
+
+func (b dirBackend) Move(ctx context.Context, opts pluginsdk.Options, src, dst string) (*pluginsdk.Node, error) {
+	if err := os.MkdirAll(filepath.Dir(opts.Join(dst)), 0755); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(opts.Join(src), opts.Join(dst)); err != nil {
+		return nil, err
+	}
+	return b.node(opts.Join(dst))
+}
+
+func Example() {
+	// In the main function of a binary named storage-dir:
+	pluginsdk.Serve(pluginsdk.Info{Name: "dir", Version: "1.0.0"}, dirBackend{})
+}
@@ -0,0 +1,4 @@
+package pluginsdk
+
+// NewServer exposes the storage service for tests.
+var NewServer = newServer
@@ -0,0 +1,156 @@
+package pluginsdk_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	coreerrors "github.com/michaeldcanady/go-onedrive/internal/core/errors"
+	"github.com/michaeldcanady/go-onedrive/internal/core/plugins"
+	storage_proto "github.com/michaeldcanady/go-onedrive/internal/features/plugins/proto/storage"
+	"github.com/michaeldcanady/go-onedrive/pkg/pluginsdk"
+	"github.com/michaeldcanady/go-onedrive/pkg/pluginsdk/pluginsdktest"
+)
+
+func TestConformance(t *testing.T) {
+	pluginsdktest.Run(t, pluginsdk.Info{Name: "dir"}, dirBackend{}, pluginsdk.Options{
+		pluginsdk.OptionRootPath: t.TempDir(),
+	})
+}
+
+func TestToStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code codes.Code
+		want error
+	}{
+		{"not found", fmt.Errorf("item: %w", pluginsdk.ErrNotFound), codes.NotFound, coreerrors.ErrNotFound},
+		{"fs not exist", fs.ErrNotExist, codes.NotFound, coreerrors.ErrNotFound},
+		{"fs exist", fs.ErrExist, codes.AlreadyExists, coreerrors.ErrAlreadyExists},
+		{"permission", fs.ErrPermission, codes.PermissionDenied, coreerrors.ErrPermissionDenied},
+		{"invalid path", pluginsdk.ErrInvalidPath, codes.InvalidArgument, coreerrors.ErrInvalidPath},
+		{"not empty", fmt.Errorf("dir: %w", pluginsdk.ErrNotEmpty), codes.FailedPrecondition, coreerrors.ErrNotEmpty},
+		{"cursor expired", pluginsdk.ErrCursorExpired, codes.FailedPrecondition, coreerrors.ErrCursorExpired},
+		{"precondition", pluginsdk.ErrPrecondition, codes.Aborted, coreerrors.ErrPrecondition},
+		{"throttled", pluginsdk.ErrThrottled, codes.ResourceExhausted, coreerrors.ErrThrottled},
+		{"unavailable", pluginsdk.ErrUnavailable, codes.Unavailable, coreerrors.ErrUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := pluginsdk.ToStatus(tt.err)
+			assert.Equal(t, tt.code, status.Code(st))
+			assert.ErrorIs(t, plugins.FromGRPC(st), tt.want)
+		})
+	}
+
+	t.Run("retry after", func(t *testing.T) {
+		st := pluginsdk.ToStatus(pluginsdk.RetryAfter(pluginsdk.ErrThrottled, 3*time.Second))
+		d, ok := coreerrors.RetryAfter(plugins.FromGRPC(st))
+		assert.True(t, ok)
+		assert.Equal(t, 3*time.Second, d)
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		st := pluginsdk.ToStatus(errors.New("boom"))
+		assert.Equal(t, codes.Unknown, status.Code(st))
+		assert.Equal(t, "boom", status.Convert(st).Message())
+	})
+
+	t.Run("status passes through", func(t *testing.T) {
+		st := status.Error(codes.Unimplemented, "nope")
+		assert.Equal(t, st, pluginsdk.ToStatus(st))
+	})
+}
+
+func TestOptions_Join(t *testing.T) {
+	opts := pluginsdk.Options{pluginsdk.OptionRootPath: "/srv/data"}
+	assert.Equal(t, "/srv/data/a/b.txt", opts.Join("a/b.txt"))
+	assert.Equal(t, "/srv/data/etc/passwd", opts.Join("../../etc/passwd"), "paths cannot escape the root")
+	assert.Equal(t, "/srv/data", opts.Join("/"))
+	assert.Equal(t, "a", pluginsdk.Options{}.Join("a"))
+}
+
+func TestSendChunks(t *testing.T) {
+	content := strings.Repeat("x", pluginsdk.ChunkSize*2+10)
+	var sizes []int
+	var got strings.Builder
+	err := pluginsdk.SendChunks(strings.NewReader(content), func(chunk []byte) error {
+		sizes = append(sizes, len(chunk))
+		got.Write(chunk)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int{pluginsdk.ChunkSize, pluginsdk.ChunkSize, 10}, sizes)
+	assert.Equal(t, content, got.String())
+
+	sendErr := errors.New("stream closed")
+	err = pluginsdk.SendChunks(strings.NewReader(content), func([]byte) error { return sendErr })
+	assert.ErrorIs(t, err, sendErr)
+}
+
+func TestChunkReader(t *testing.T) {
+	chunks := [][]byte{[]byte("b"), nil, []byte("cd")}
+	r := pluginsdk.NewChunkReader([]byte("a"), func() ([]byte, error) {
+		if len(chunks) == 0 {
+			return nil, io.EOF
+		}
+		c := chunks[0]
+		chunks = chunks[1:]
+		return c, nil
+	})
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "abcd", string(data))
+
+	recvErr := errors.New("stream broken")
+	r = pluginsdk.NewChunkReader(nil, func() ([]byte, error) { return nil, recvErr })
+	_, err = io.ReadAll(r)
+	assert.ErrorIs(t, err, recvErr)
+}
+
+func TestServer_Metadata(t *testing.T) {
+	srv := pluginsdk.NewServer(pluginsdk.Info{Name: "dir", Version: "1.2.3", Hashes: []string{"sha256"}}, dirBackend{})
+	meta, err := srv.GetMetadata(context.Background(), &storage_proto.MetadataRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "dir", meta.Name)
+	assert.Equal(t, "1.2.3", meta.Version)
+	assert.Equal(t, plugins.PluginTypeStorage, meta.Type)
+	assert.True(t, meta.Capabilities.Move, "dirBackend is a Mover")
+	assert.False(t, meta.Capabilities.Copy)
+	assert.False(t, meta.Capabilities.RangedRead)
+	assert.False(t, meta.Capabilities.Delta)
+	assert.Equal(t, []string{"sha256"}, meta.Capabilities.Hashes)
+
+	drives, err := srv.ListDrives(context.Background(), &storage_proto.ListDrivesRequest{})
+	require.NoError(t, err)
+	require.Len(t, drives.Drives, 1, "backends without drives have one")
+}
+
+// unrangedBackend hides the seeking of the files dirBackend opens, so the SDK has to
+// discard data to apply a range.
+type unrangedBackend struct{ dirBackend }
+
+func (b unrangedBackend) Open(ctx context.Context, opts pluginsdk.Options, p string) (io.ReadCloser, error) {
+	rc, err := b.dirBackend.Open(ctx, opts, p)
+	if err != nil {
+		return nil, err
+	}
+	return struct{ io.ReadCloser }{rc}, nil
+}
+
+func TestConformance_WithoutSeeking(t *testing.T) {
+	pluginsdktest.Run(t, pluginsdk.Info{Name: "dir"}, unrangedBackend{}, pluginsdk.Options{
+		pluginsdk.OptionRootPath: t.TempDir(),
+	})
+}
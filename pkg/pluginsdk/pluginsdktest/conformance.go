@@ -0,0 +1,21 @@
+// Package pluginsdktest checks that a storage plugin behaves the way the odc host
+// expects. The suite drives the plugin through a table of scenarios, including the edge
+// cases where backends tend to differ, and asserts the node fields and gRPC status codes
+// the host relies on.
+package pluginsdktest
+
+import (
+	"testing"
+
+	"github.com/michaeldcanady/go-onedrive/internal/features/plugins/storage/storagetest"
+	"github.com/michaeldcanady/go-onedrive/pkg/pluginsdk"
+)
+
+// Run serves b over an in-memory gRPC connection and runs the conformance suite against
+// it, passing opts with every request. Each scenario works in its own directory beneath
+// the root of the backend; the backend should start empty.
+func Run(t *testing.T, info pluginsdk.Info, b pluginsdk.Backend, opts pluginsdk.Options) {
+	t.Helper()
+	client := storagetest.ServeFunc(t, pluginsdk.NewHandler(info, b).Register, nil)
+	storagetest.Run(t, client, opts)
+}
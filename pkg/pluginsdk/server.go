@@ -0,0 +1,315 @@
+package pluginsdk
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+
+	"github.com/hashicorp/go-plugin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/michaeldcanady/go-onedrive/internal/core/plugins"
+	storage_proto "github.com/michaeldcanady/go-onedrive/internal/features/plugins/proto/storage"
+)
+
+const (
+	// ChunkSize is the size of the chunks Read streams are sent in.
+	ChunkSize = 32 << 10
+	// deltaBatchSize bounds the number of changes sent in a single Delta message.
+	deltaBatchSize = 256
+)
+
+// Serve runs b as an odc storage plugin. It is called from the plugin's main function
+// and returns when the host stops the plugin.
+func Serve(info Info, b Backend) {
+	plugin.Serve(&plugin.ServeConfig{
+		HandshakeConfig: plugins.HandshakeConfig,
+		Plugins:         map[string]plugin.Plugin{plugins.PluginTypeStorage: &plugins.StorageGRPCPlugin{Impl: newServer(info, b)}},
+		GRPCServer:      plugins.CustomGRPCServer,
+	})
+}
+
+// Handler is the gRPC storage service serving a backend. It lets the service be
+// registered with a gRPC server other than the one [Serve] starts, as pluginsdktest
+// does, without the generated protocol types being part of this package's API.
+type Handler struct {
+	server storage_proto.StorageServiceServer
+}
+
+// NewHandler returns a [*Handler] serving b.
+func NewHandler(info Info, b Backend) *Handler {
+	return &Handler{server: newServer(info, b)}
+}
+
+// Register registers the storage service with s.
+func (h *Handler) Register(s grpc.ServiceRegistrar) {
+	storage_proto.RegisterStorageServiceServer(s, h.server)
+}
+
+// newServer returns the gRPC storage service serving b.
+func newServer(info Info, b Backend) storage_proto.StorageServiceServer {
+	return &server{info: info, backend: b}
+}
+
+type server struct {
+	storage_proto.UnimplementedStorageServiceServer
+	info    Info
+	backend Backend
+}
+
+func (s *server) GetMetadata(ctx context.Context, req *storage_proto.MetadataRequest) (*storage_proto.MetadataResponse, error) {
+	_, move := s.backend.(Mover)
+	_, cp := s.backend.(Copier)
+	_, ranged := s.backend.(RangeOpener)
+	_, delta := s.backend.(DeltaSource)
+	return &storage_proto.MetadataResponse{
+		Name:               s.info.Name,
+		Type:               plugins.PluginTypeStorage,
+		Version:            s.info.Version,
+		SupportedProviders: s.info.Providers,
+		Capabilities: &storage_proto.Capabilities{
+			Move:            move,
+			Copy:            cp,
+			RangedRead:      ranged,
+			EtagConcurrency: s.info.ETagConcurrency,
+			Delta:           delta,
+			Hashes:          s.info.Hashes,
+		},
+	}, nil
+}
+
+func (s *server) Stat(ctx context.Context, req *storage_proto.StatRequest) (*storage_proto.StatResponse, error) {
+	n, err := s.backend.Stat(ctx, req.Options, req.Path)
+	if err != nil {
+		return nil, ToStatus(err)
+	}
+	return &storage_proto.StatResponse{Node: toProto(n, req.Path)}, nil
+}
+
+func (s *server) List(ctx context.Context, req *storage_proto.ListRequest) (*storage_proto.ListResponse, error) {
+	nodes, err := s.backend.List(ctx, req.Options, req.Path)
+	if err != nil {
+		return nil, ToStatus(err)
+	}
+	resp := &storage_proto.ListResponse{Nodes: make([]*storage_proto.Node, 0, len(nodes))}
+	for _, n := range nodes {
+		resp.Nodes = append(resp.Nodes, toProto(n, path.Join(req.Path, n.Name)))
+	}
+	return resp, nil
+}
+
+func (s *server) Mkdir(ctx context.Context, req *storage_proto.MkdirRequest) (*storage_proto.MkdirResponse, error) {
+	n, err := s.backend.Mkdir(ctx, req.Options, req.Path)
+	if err != nil {
+		return nil, ToStatus(err)
+	}
+	return &storage_proto.MkdirResponse{Node: toProto(n, req.Path)}, nil
+}
+
+// Read streams the file in [ChunkSize] chunks. Ranges are applied by the backend when
+// it is a [RangeOpener] and by discarding data otherwise.
+func (s *server) Read(req *storage_proto.ReadRequest, stream storage_proto.StorageService_ReadServer) error {
+	ctx := stream.Context()
+	if req.Offset < 0 || req.Length < 0 {
+		return status.Errorf(codes.InvalidArgument, "invalid range %d+%d", req.Offset, req.Length)
+	}
+
+	var (
+		rc  io.ReadCloser
+		err error
+	)
+	ranged, native := s.backend.(RangeOpener)
+	if native && (req.Offset > 0 || req.Length > 0) {
+		rc, err = ranged.OpenRange(ctx, req.Options, req.Path, req.Offset, req.Length)
+	} else {
+		rc, err = s.backend.Open(ctx, req.Options, req.Path)
+	}
+	if err != nil {
+		return ToStatus(err)
+	}
+	defer rc.Close()
+
+	var r io.Reader = rc
+	if !native {
+		if r, err = applyRange(rc, req.Offset, req.Length); err != nil {
+			return ToStatus(err)
+		}
+	}
+	return ToStatus(SendChunks(r, func(chunk []byte) error {
+		return stream.Send(&storage_proto.ReadResponse{Chunk: chunk})
+	}))
+}
+
+// applyRange skips offset bytes of r and limits it to length bytes, if positive.
+func applyRange(r io.Reader, offset, length int64) (io.Reader, error) {
+	if offset > 0 {
+		if seeker, ok := r.(io.Seeker); ok {
+			if _, err := seeker.Seek(offset, io.SeekStart); err != nil {
+				return nil, err
+			}
+		} else if _, err := io.CopyN(io.Discard, r, offset); err != nil && err != io.EOF {
+			return nil, err
+		}
+	}
+	if length > 0 {
+		r = io.LimitReader(r, length)
+	}
+	return r, nil
+}
+
+// Write passes the streamed chunks to the backend as a single reader.
+func (s *server) Write(stream storage_proto.StorageService_WriteServer) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	r := NewChunkReader(first.Chunk, func() ([]byte, error) {
+		req, err := stream.Recv()
+		if err != nil {
+			return nil, err
+		}
+		return req.Chunk, nil
+	})
+
+	n, err := s.backend.Write(stream.Context(), first.Options, first.Path, r)
+	if err != nil {
+		return ToStatus(err)
+	}
+	return stream.SendAndClose(&storage_proto.WriteResponse{Node: toProto(n, first.Path)})
+}
+
+func (s *server) Delete(ctx context.Context, req *storage_proto.DeleteRequest) (*storage_proto.DeleteResponse, error) {
+	if err := s.backend.Remove(ctx, req.Options, req.Path); err != nil {
+		return nil, ToStatus(err)
+	}
+	return &storage_proto.DeleteResponse{Success: true}, nil
+}
+
+func (s *server) Move(ctx context.Context, req *storage_proto.MoveRequest) (*storage_proto.MoveResponse, error) {
+	mover, ok := s.backend.(Mover)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "move is not supported")
+	}
+	n, err := mover.Move(ctx, req.Options, req.Source, req.Destination)
+	if err != nil {
+		return nil, ToStatus(err)
+	}
+	return &storage_proto.MoveResponse{Node: toProto(n, req.Destination)}, nil
+}
+
+func (s *server) Copy(ctx context.Context, req *storage_proto.CopyRequest) (*storage_proto.CopyResponse, error) {
+	copier, ok := s.backend.(Copier)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "copy is not supported")
+	}
+	n, err := copier.Copy(ctx, req.Options, req.Source, req.Destination)
+	if err != nil {
+		return nil, ToStatus(err)
+	}
+	return &storage_proto.CopyResponse{Node: toProto(n, req.Destination)}, nil
+}
+
+func (s *server) ListDrives(ctx context.Context, req *storage_proto.ListDrivesRequest) (*storage_proto.ListDrivesResponse, error) {
+	drives, err := s.drives(ctx, req.Options)
+	if err != nil {
+		return nil, err
+	}
+	resp := &storage_proto.ListDrivesResponse{}
+	for _, d := range drives {
+		resp.Drives = append(resp.Drives, &storage_proto.Drive{Id: d.ID, Name: d.Name, Type: d.Type})
+	}
+	return resp, nil
+}
+
+func (s *server) GetDrive(ctx context.Context, req *storage_proto.GetDriveRequest) (*storage_proto.GetDriveResponse, error) {
+	drives, err := s.drives(ctx, req.Options)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range drives {
+		if d.ID == req.DriveId {
+			return &storage_proto.GetDriveResponse{Drive: &storage_proto.Drive{Id: d.ID, Name: d.Name, Type: d.Type}}, nil
+		}
+	}
+	return nil, status.Errorf(codes.NotFound, "drive %s not found", req.DriveId)
+}
+
+// drives returns the backend's drives, or a single drive named after the plugin when it
+// is not a [DriveLister].
+func (s *server) drives(ctx context.Context, opts Options) ([]Drive, error) {
+	lister, ok := s.backend.(DriveLister)
+	if !ok {
+		return []Drive{{ID: "/", Name: s.info.Name, Type: s.info.Name}}, nil
+	}
+	drives, err := lister.ListDrives(ctx, opts)
+	if err != nil {
+		return nil, ToStatus(err)
+	}
+	return drives, nil
+}
+
+// Delta sends the changes the backend emits in batches, with the new cursor on the last
+// message.
+func (s *server) Delta(req *storage_proto.DeltaRequest, stream storage_proto.StorageService_DeltaServer) error {
+	source, ok := s.backend.(DeltaSource)
+	if !ok {
+		return status.Error(codes.Unimplemented, "delta is not supported")
+	}
+
+	batch := make([]*storage_proto.Change, 0, deltaBatchSize)
+	cursor, err := source.Delta(stream.Context(), req.Options, req.Path, req.Cursor, func(c Change) error {
+		if c.Node == nil {
+			return fmt.Errorf("delta change without a node")
+		}
+		batch = append(batch, &storage_proto.Change{Node: toProto(c.Node, c.Node.Path), Deleted: c.Deleted})
+		if len(batch) < deltaBatchSize {
+			return nil
+		}
+		if err := stream.Send(&storage_proto.DeltaResponse{Changes: batch}); err != nil {
+			return err
+		}
+		batch = make([]*storage_proto.Change, 0, deltaBatchSize)
+		return nil
+	})
+	if err != nil {
+		return ToStatus(err)
+	}
+	return stream.Send(&storage_proto.DeltaResponse{Changes: batch, Cursor: cursor})
+}
+
+// toProto converts n, filling in its path and name from p when they are empty.
+func toProto(n *Node, p string) *storage_proto.Node {
+	if n == nil {
+		return nil
+	}
+	nodePath := n.Path
+	if nodePath == "" {
+		nodePath = p
+	}
+	name := n.Name
+	if name == "" {
+		name = path.Base(nodePath)
+	}
+	t := storage_proto.NodeType_FILE
+	if n.Dir {
+		t = storage_proto.NodeType_DIRECTORY
+	}
+	var modified int64
+	if !n.ModTime.IsZero() {
+		modified = n.ModTime.Unix()
+	}
+	return &storage_proto.Node{
+		Id:         n.ID,
+		Name:       name,
+		Path:       nodePath,
+		Type:       t,
+		Size:       n.Size,
+		ModifiedAt: modified,
+		Etag:       n.ETag,
+		Ctag:       n.CTag,
+		Hashes:     n.Hashes,
+	}
+}
@@ -0,0 +1,47 @@
+package pluginsdk
+
+import "io"
+
+// SendChunks reads r to the end and passes its content to send in chunks of at most
+// [ChunkSize] bytes. The slice passed to send is reused between calls.
+func SendChunks(r io.Reader, send func(chunk []byte) error) error {
+	buf := make([]byte, ChunkSize)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if serr := send(buf[:n]); serr != nil {
+				return serr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// NewChunkReader returns a reader over first followed by the chunks returned by next,
+// until next returns an error. [io.EOF] from next ends the reader cleanly.
+func NewChunkReader(first []byte, next func() ([]byte, error)) io.Reader {
+	return &chunkReader{chunk: first, next: next}
+}
+
+type chunkReader struct {
+	chunk []byte
+	next  func() ([]byte, error)
+	err   error
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for len(r.chunk) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		r.chunk, r.err = r.next()
+	}
+	n := copy(p, r.chunk)
+	r.chunk = r.chunk[n:]
+	return n, nil
+}